@@ -0,0 +1,878 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"markdown-note-taking-app/internal/backup"
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/export"
+	"markdown-note-taking-app/internal/importer"
+	"markdown-note-taking-app/internal/instance"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/reminder"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// command is a non-interactive subcommand run instead of the TUI
+type command struct {
+	usage string
+	run   func(service *storage.Service, args []string) error
+
+	// standalone, if set, runs instead of run without opening the database
+	standalone func(args []string) error
+
+	// exclusive commands change or remove notes the app may be showing, or
+	// the encryption it's reading them with, so they claim the vault as
+	// the app does and refuse to run while it's open
+	exclusive bool
+}
+
+// commands lists the available subcommands by name
+var commands = map[string]command{
+	"backup": {
+		usage: "backup <dir> [--full]    Back up notes changed since the last backup (or all with --full)",
+		run:   runBackup,
+	},
+	"restore": {
+		usage:     "restore <dir>    Restore the latest backup in <dir> into an empty database",
+		run:       runRestore,
+		exclusive: true,
+	},
+	"check": {
+		usage: "check    Check the database file and the references between its tables for damage",
+		run:   runCheck,
+	},
+	"export": {
+		usage: "export <dir>    Write every note to <dir> as markdown with frontmatter",
+		run:   runExport,
+	},
+	"export-joplin": {
+		usage: "export-joplin <dir>    Write every note in the layout Joplin imports as Markdown + Front Matter",
+		run:   runExportJoplin,
+	},
+	"import": {
+		usage:     "import <dir>    Import markdown files, updating notes exported earlier",
+		run:       runImport,
+		exclusive: true,
+	},
+	"clip": {
+		usage: "clip <url>    Save the article of a web page as a note tagged \"clipped\", with the page's address in its frontmatter",
+		run:   runClip,
+	},
+	"add": {
+		usage: "add [--title <title>] [text...]    Create a note from the arguments or from text piped to stdin",
+		run:   runAdd,
+	},
+	"daemon": {
+		usage: "daemon    Stay in the background and show desktop notifications for tasks whose @due date has come",
+		run:   runDaemon,
+	},
+	"quick": {
+		usage: "quick    Write a note in a small capture window and save it with Ctrl+S, e.g. from a global hotkey",
+		run:   runQuick,
+	},
+	"batch": {
+		usage:     "batch --query <query> --action archive|trash|delete|add-tag|remove-tag|export [--tag <tag>] [--dir <dir>] [--dry-run]    Apply an action to every note matching a search",
+		run:       runBatch,
+		exclusive: true,
+	},
+	"digest": {
+		usage:     "digest --query <query> [--title <title>] [--dry-run]    Compile the matching notes into one digest note and archive them",
+		run:       runDigest,
+		exclusive: true,
+	},
+	"export-json": {
+		usage: "export-json [file.json]    Write every note, tag and tag association as JSON to a file or stdout",
+		run:   runExportJSON,
+	},
+	"export-html": {
+		usage: "export-html [--theme dark|light|print] <id|uuid> [file.html]    Write a note as a standalone themed HTML page to a file or stdout",
+		run:   runExportHTML,
+	},
+	"export-pandoc": {
+		usage: "export-pandoc --format pdf|docx [--tag <tag> | --id <id|uuid>] <dir>    Convert notes to PDF or DOCX with pandoc, one file per note",
+		run:   runExportPandoc,
+	},
+	"import-json": {
+		usage:     "import-json [file.json]    Add the notes and tags of a JSON export read from a file or stdin",
+		run:       runImportJSON,
+		exclusive: true,
+	},
+	"encrypt": {
+		usage:     "encrypt    Encrypt note content with a passphrase asked for on every start",
+		run:       runEncrypt,
+		exclusive: true,
+	},
+	"decrypt": {
+		usage:     "decrypt    Decrypt every note and stop asking for a passphrase",
+		run:       runDecrypt,
+		exclusive: true,
+	},
+	"keys": {
+		usage:      "keys [--export [file.md|file.txt]]    Print the keyboard shortcuts, or export them as markdown",
+		standalone: runKeys,
+	},
+	"vaults": {
+		usage:      "vaults    List the vaults that --vault <name> can open",
+		standalone: runVaults,
+	},
+}
+
+// runCommand runs the subcommand named by args[0] against the database
+func runCommand(dbPath string, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if cmd.standalone != nil {
+		if err := cmd.standalone(args[1:]); err != nil {
+			return fmt.Errorf("%w\nusage: tuinotes %s", err, cmd.usage)
+		}
+		return nil
+	}
+
+	if cmd.exclusive {
+		server, err := instance.Listen(dbPath)
+		if errors.Is(err, instance.ErrRunning) {
+			return fmt.Errorf("%w; quit it before running %s", err, args[0])
+		}
+		if err != nil {
+			slog.Warn("failed to claim the database", "err", err)
+		} else {
+			defer server.Close()
+			server.Handle(func(string) error {
+				return fmt.Errorf("tuinotes %s is running on this database", args[0])
+			})
+		}
+	}
+
+	service, err := storage.NewService(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer service.Close()
+
+	if service.Locked() {
+		passphrase, err := readPassphrase("Passphrase: ")
+		if err != nil {
+			return err
+		}
+		if err := service.Unlock(passphrase); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.run(service, args[1:]); err != nil {
+		return fmt.Errorf("%w\nusage: tuinotes %s", err, cmd.usage)
+	}
+	return nil
+}
+
+// runExport exports all notes to a directory
+func runExport(service *storage.Service, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+
+	dir, err := export.ExpandHome(args[0])
+	if err != nil {
+		return err
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return err
+	}
+
+	paths, err := export.MarkdownDir(notes, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d notes to %s\n", len(paths), dir)
+	return nil
+}
+
+// runExportJoplin exports all notes for importing into Joplin
+func runExportJoplin(service *storage.Service, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+
+	dir, err := export.ExpandHome(args[0])
+	if err != nil {
+		return err
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return err
+	}
+
+	paths, err := export.JoplinDir(notes, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d notes to %s\n", len(paths), dir)
+	fmt.Println("In Joplin, choose File > Import > MD - Markdown + Front Matter (Directory) and pick this directory")
+	return nil
+}
+
+// runImport imports markdown files from a directory
+func runImport(service *storage.Service, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+
+	dir, err := export.ExpandHome(args[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.MarkdownDirWith(service, dir, importer.Options{ResolveLinks: true})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %s: %d created, %d updated, %d unchanged\n",
+		dir, result.Created, result.Updated, result.Unchanged)
+	return nil
+}
+
+// runExportJSON writes the whole database as JSON
+func runExportJSON(service *storage.Service, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if len(args) == 0 || args[0] == "-" {
+		return service.ExportJSON(os.Stdout)
+	}
+
+	path, err := export.ExpandHome(args[0])
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := service.ExportJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("Exported notes to %s\n", path)
+	return nil
+}
+
+// runExportHTML writes one note as an HTML page styled by the configured
+// theme, or the one given with --theme
+func runExportHTML(service *storage.Service, args []string) error {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		return err
+	}
+	opts := export.HTMLOptions{
+		Theme:      cfg.HTMLTheme,
+		Stylesheet: cfg.HTMLStylesheet,
+		Autolinks:  cfg.AutolinkRules(),
+	}
+
+	flags := flag.NewFlagSet("export-html", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	theme := flags.String("theme", "", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *theme != "" {
+		// A theme asked for by name wins over the configured stylesheet
+		opts.Theme, opts.Stylesheet = *theme, ""
+	}
+
+	args = flags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("expected a note ID and an optional file")
+	}
+	note, err := service.FindNote(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 || args[1] == "-" {
+		page, err := export.NoteHTML(note, opts)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(os.Stdout, page)
+		return err
+	}
+
+	path, err := export.ExpandHome(args[1])
+	if err != nil {
+		return err
+	}
+	if err := export.HTMLFile(note, path, opts); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %q to %s\n", note.Title, path)
+	return nil
+}
+
+// runExportPandoc converts every note, the notes carrying a tag or a
+// single note to PDF or DOCX files
+func runExportPandoc(service *storage.Service, args []string) error {
+	flags := flag.NewFlagSet("export-pandoc", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "pdf", "")
+	tag := flags.String("tag", "", "")
+	id := flags.String("id", "", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+	if *tag != "" && *id != "" {
+		return fmt.Errorf("expected --tag or --id, not both")
+	}
+
+	dir, err := export.ExpandHome(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var notes []*models.Note
+	if *id != "" {
+		note, err := service.FindNote(*id)
+		if err != nil {
+			return err
+		}
+		notes = []*models.Note{note}
+	} else {
+		filter := models.NoteFilter{}
+		if *tag != "" {
+			filter.TagNames = []string{*tag}
+		}
+		if notes, err = service.GetAllNotes(filter); err != nil {
+			return err
+		}
+	}
+	if len(notes) == 0 {
+		fmt.Println("No notes to export")
+		return nil
+	}
+
+	paths, err := export.PandocDir(notes, dir, *format, func(done, total int, note *models.Note) {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done+1, total, note.Title)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d notes to %s\n", len(paths), dir)
+	return nil
+}
+
+// runImportJSON adds the contents of a JSON export
+func runImportJSON(service *storage.Service, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	input := io.Reader(os.Stdin)
+	source := "stdin"
+	if len(args) == 1 && args[0] != "-" {
+		path, err := export.ExpandHome(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close()
+		input, source = file, path
+	}
+
+	result, err := service.ImportJSON(input)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d notes from %s (%d new tags)\n", result.Notes, source, result.TagsCreated)
+	return nil
+}
+
+// runBackup writes a full or incremental backup
+func runBackup(service *storage.Service, args []string) error {
+	full := false
+	var dirs []string
+	for _, arg := range args {
+		if arg == "--full" {
+			full = true
+		} else {
+			dirs = append(dirs, arg)
+		}
+	}
+	if len(dirs) != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+
+	dir, err := export.ExpandHome(dirs[0])
+	if err != nil {
+		return err
+	}
+
+	entry, err := backup.Create(service, dir, !full)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s backup %s: %d notes stored, %d deleted since last backup\n",
+		entry.Kind, entry.File, entry.Changed, entry.Deleted)
+	return nil
+}
+
+// runCheck checks the database's integrity, failing when there are problems
+func runCheck(service *storage.Service, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	problems, err := service.CheckIntegrity()
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check found %d problems", len(problems))
+	}
+	fmt.Println("Integrity check passed")
+	return nil
+}
+
+// runRestore restores the latest backup chain
+func runRestore(service *storage.Service, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a directory")
+	}
+
+	dir, err := export.ExpandHome(args[0])
+	if err != nil {
+		return err
+	}
+
+	count, err := backup.Restore(service, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d notes from %s\n", count, dir)
+	return nil
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinPiped() bool {
+	return !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// runAdd creates a note from the arguments or piped stdin and prints its ID
+func runAdd(service *storage.Service, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	title := flags.String("title", "", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	content := strings.Join(flags.Args(), " ")
+	if content == "" && stdinPiped() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		content = string(data)
+	}
+	content = strings.TrimRight(content, "\n")
+	if strings.TrimSpace(content) == "" && *title == "" {
+		return fmt.Errorf("expected text to add")
+	}
+
+	if *title == "" {
+		*title = firstLine(content)
+	}
+	note, err := service.CreateNote(*title, content)
+	if err != nil {
+		return err
+	}
+	fmt.Println(note.ID)
+	return nil
+}
+
+// runClip saves the article of a web page as a note and prints its ID
+func runClip(service *storage.Service, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a URL")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	note, err := importer.Clip(ctx, service, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(note.ID)
+	return nil
+}
+
+// runQuick opens the quick capture window and prints the ID of the note
+// saved in it
+func runQuick(service *storage.Service, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+
+	capture := ui.NewQuickCapture(service)
+	if _, err := tea.NewProgram(capture, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("failed to run quick capture: %w", err)
+	}
+	if note := capture.Saved(); note != nil {
+		fmt.Println(note.ID)
+	}
+	return nil
+}
+
+// runDaemon reminds about due tasks until interrupted
+func runDaemon(service *storage.Service, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		return err
+	}
+	notifier, err := reminder.NewNotifier()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	slog.Info("reminding about due tasks", "at", cfg.RemindAt, "snooze_minutes", cfg.SnoozeMinutes)
+	fmt.Fprintf(os.Stderr, "Reminding about tasks due from %s each day. Press Ctrl+C to stop.\n", cfg.RemindAt)
+	daemon := reminder.NewDaemon(service, notifier, cfg.ReminderTime(), time.Duration(cfg.SnoozeMinutes)*time.Minute)
+	return daemon.Run(ctx)
+}
+
+// firstLine returns the first non-blank line of content without heading
+// marks, shortened to make a title
+func firstLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 80 {
+			line = string(runes[:80])
+		}
+		return line
+	}
+	return "Untitled"
+}
+
+// batchNotebooks are the notebooks the archive and trash batch actions
+// move notes to, so either can be undone by moving them back
+var batchNotebooks = map[string]string{
+	"archive": models.ArchiveNotebook,
+	"trash":   models.TrashNotebook,
+}
+
+// runBatch applies one action to every note matching a search query. Each
+// action changes the matching notes in a single statement or transaction,
+// so it applies to all of them or none.
+func runBatch(service *storage.Service, args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	query := flags.String("query", "", "")
+	action := flags.String("action", "", "")
+	tag := flags.String("tag", "", "")
+	dir := flags.String("dir", "", "")
+	dryRun := flags.Bool("dry-run", false, "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if strings.TrimSpace(*query) == "" {
+		return fmt.Errorf("expected --query")
+	}
+
+	// Check the action before touching anything
+	var outcome string
+	switch *action {
+	case "archive", "trash":
+		outcome = fmt.Sprintf("moved to %s", batchNotebooks[*action])
+	case "delete":
+		outcome = "deleted"
+	case "add-tag", "remove-tag":
+		if strings.TrimSpace(*tag) == "" {
+			return fmt.Errorf("%s needs --tag", *action)
+		}
+		outcome = fmt.Sprintf("tagged %q", *tag)
+		if *action == "remove-tag" {
+			outcome = fmt.Sprintf("untagged %q", *tag)
+		}
+	case "export":
+		if *dir == "" {
+			return fmt.Errorf("export needs --dir")
+		}
+		expanded, err := export.ExpandHome(*dir)
+		if err != nil {
+			return err
+		}
+		*dir = expanded
+		outcome = "exported to " + *dir
+	case "":
+		return fmt.Errorf("expected --action")
+	default:
+		return fmt.Errorf("unknown action %q", *action)
+	}
+
+	notes, err := service.SearchNotes(*query, 0)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Println("No notes match")
+		return nil
+	}
+
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		ids[i] = note.ID
+	}
+
+	if *dryRun {
+		for _, note := range notes {
+			fmt.Printf("%6d  %s\n", note.ID, note.Title)
+		}
+		fmt.Printf("%d notes would be %s (dry run, nothing changed)\n", len(notes), outcome)
+		return nil
+	}
+
+	switch *action {
+	case "archive", "trash":
+		err = service.MoveNotesToNotebook(ids, batchNotebooks[*action])
+	case "delete":
+		err = service.DeleteNotes(ids)
+	case "add-tag":
+		err = service.AddTagToNotes(ids, *tag)
+	case "remove-tag":
+		err = service.RemoveTagFromNotes(ids, *tag)
+	case "export":
+		_, err = export.MarkdownDir(notes, *dir)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d notes %s\n", len(notes), outcome)
+	return nil
+}
+
+// runDigest compiles the notes matching a search query into a digest note
+// with a section per day and archives them. Notes already archived and
+// earlier digests are left out.
+func runDigest(service *storage.Service, args []string) error {
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	query := flags.String("query", "", "")
+	title := flags.String("title", "", "")
+	dryRun := flags.Bool("dry-run", false, "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if strings.TrimSpace(*query) == "" {
+		return fmt.Errorf("expected --query")
+	}
+
+	matches, err := service.SearchNotes(*query, 0)
+	if err != nil {
+		return err
+	}
+	var notes []*models.Note
+	for _, note := range matches {
+		if !note.Archived() && !hasTag(note, storage.DigestTag) {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) == 0 {
+		fmt.Println("No notes match")
+		return nil
+	}
+
+	if *dryRun {
+		for _, note := range notes {
+			fmt.Printf("%6d  %s\n", note.ID, note.Title)
+		}
+		fmt.Printf("%d notes would be compiled into a digest and moved to %s (dry run, nothing changed)\n",
+			len(notes), models.ArchiveNotebook)
+		return nil
+	}
+
+	digest, err := service.CreateDigest(notes, *title)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d notes compiled into %q (%d) and moved to %s\n",
+		len(notes), digest.Title, digest.ID, models.ArchiveNotebook)
+	return nil
+}
+
+// hasTag reports whether a note carries the named tag
+func hasTag(note *models.Note, name string) bool {
+	for _, tag := range note.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPassphrase prompts for a passphrase without echoing it. When stdin
+// is piped, e.g. into add, the prompt reads from the terminal instead.
+func readPassphrase(prompt string) (string, error) {
+	input := os.Stdin
+	if stdinPiped() {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return "", fmt.Errorf("failed to open terminal for passphrase: %w", err)
+		}
+		defer tty.Close()
+		input = tty
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(input.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// runEncrypt enables encryption with a new passphrase
+func runEncrypt(service *storage.Service, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if service.EncryptionEnabled() {
+		return fmt.Errorf("notes are already encrypted")
+	}
+
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return err
+	}
+	confirm, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase != confirm {
+		return fmt.Errorf("passphrases don't match")
+	}
+
+	if err := service.EnableEncryption(passphrase); err != nil {
+		return err
+	}
+	fmt.Println("Notes encrypted. The passphrase can't be recovered if it's lost.")
+	return nil
+}
+
+// runDecrypt disables encryption. runCommand has already unlocked the notes.
+func runDecrypt(service *storage.Service, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if err := service.DisableEncryption(); err != nil {
+		return err
+	}
+	fmt.Println("Notes decrypted")
+	return nil
+}
+
+// runKeys prints the keyboard shortcut reference. With --export it's
+// written as markdown to stdout or a file, as plain text for .txt files.
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(ui.KeyReferenceText())
+		return nil
+	}
+	if args[0] != "--export" || len(args) > 2 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if len(args) == 1 {
+		fmt.Print(ui.KeyReferenceMarkdown())
+		return nil
+	}
+
+	path, err := export.ExpandHome(args[1])
+	if err != nil {
+		return err
+	}
+	reference := ui.KeyReferenceMarkdown()
+	if strings.EqualFold(filepath.Ext(path), ".txt") {
+		reference = ui.KeyReferenceText()
+	}
+	if err := os.WriteFile(path, []byte(reference), 0644); err != nil {
+		return fmt.Errorf("failed to write key reference: %w", err)
+	}
+	fmt.Printf("Wrote keyboard shortcuts to %s\n", path)
+	return nil
+}
+
+// runVaults lists the vaults, marking the one opened by default
+func runVaults(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		return err
+	}
+	vaults, err := cfg.AllVaults()
+	if err != nil {
+		return err
+	}
+	current, err := cfg.FindVault("")
+	if err != nil {
+		return err
+	}
+
+	for _, vault := range vaults {
+		marker := " "
+		if vault.Name == current.Name {
+			marker = "*"
+		}
+		fmt.Printf("%s %-16s %s\n", marker, vault.Name, vault.Path)
+	}
+	return nil
+}
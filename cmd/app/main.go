@@ -1,37 +1,253 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/instance"
+	applog "markdown-note-taking-app/internal/log"
+	"markdown-note-taking-app/internal/storage"
 	"markdown-note-taking-app/internal/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	// Use a local database file
-	homeDir, err := os.UserHomeDir()
+	flags, args, err := parseFlags(os.Args[1:])
 	if err != nil {
-		fmt.Printf("Error getting home directory: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	dbPath := filepath.Join(homeDir, ".markdown-notes.db")
+	// Log to a file, with debug messages under --debug; the app works
+	// without a log
+	logPath, logFile, err := startLog(flags.debug)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	} else {
+		defer logFile.Close()
+	}
+
+	// Load user preferences
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Sort titles and tags for the configured language
+	if err := storage.SetLocale(cfg.Locale); err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ui.SetKeymap(cfg.Keymap)
+
+	// --db opens the default vault from another database for this run
+	if flags.db != "" {
+		path, err := filepath.Abs(flags.db)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		config.SetDatabasePath(path)
+		if flags.vault == "" {
+			flags.vault = config.DefaultVault
+		}
+	}
+
+	// Databases at the old default location move to the new one; until
+	// that works, they're used where they are
+	if path, err := cfg.MigrateLegacyDatabase(); err != nil {
+		slog.Warn("failed to move the database to the default location", "err", err)
+	} else if path != "" {
+		slog.Info("moved the database to the default location", "path", path)
+		fmt.Fprintf(os.Stderr, "Moved the notes database to %s\n", path)
+	}
+
+	// Open the vault named by --vault, or the configured one
+	vault, err := cfg.FindVault(flags.vault)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Report slow schema upgrades while the vault opens
+	storage.SetMigrationProgress(showMigrationProgress)
+
+	// Text piped in without a subcommand becomes a new note
+	if len(args) == 0 && stdinPiped() {
+		args = []string{"add"}
+	}
+
+	// tuinotes open <title> opens the note in the app already running on
+	// the vault, or starts the app on it
+	var openTitle string
+	if len(args) > 0 && args[0] == "open" {
+		openTitle = strings.TrimSpace(strings.Join(args[1:], " "))
+		if openTitle == "" {
+			fmt.Println("Error: expected a note title\nusage: tuinotes open <title>    Open a note, in the app if it's already running")
+			os.Exit(1)
+		}
+		forwarded, err := instance.Open(vault.Path, openTitle)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if forwarded {
+			return
+		}
+		args = nil
+	}
+
+	// Run a subcommand instead of the TUI when one is given
+	if len(args) > 0 {
+		slog.Info("running command", "command", args[0], "vault", vault.Name)
+		if err := runCommand(vault.Path, args); err != nil {
+			slog.Error("command failed", "command", args[0], "err", err)
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Only one instance may have the vault open; the app works without
+	// the claim if the socket can't be made
+	server, err := instance.Listen(vault.Path)
+	if errors.Is(err, instance.ErrRunning) {
+		fmt.Printf("Error: %v\nUse tuinotes open <title> to open a note in it.\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Warn("failed to claim the database", "err", err)
+	} else {
+		defer server.Close()
+	}
 
 	// Create the app
-	app, err := ui.NewApp(dbPath)
+	slog.Info("starting", "vault", vault.Name, "path", vault.Path)
+	app, err := ui.NewApp(vault, cfg)
 	if err != nil {
+		slog.Error("failed to create app", "err", err)
 		fmt.Printf("Error creating app: %v\n", err)
 		os.Exit(1)
 	}
 	defer app.Close()
+	if server != nil {
+		app.SetInstance(server)
+	}
+	if openTitle != "" {
+		app.OpenOnStart(openTitle)
+	}
+
+	// Vaults opened from the TUI can't print over it
+	storage.SetMigrationProgress(nil)
 
 	// Run the program
 	p := tea.NewProgram(app, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	if server != nil {
+		server.Handle(ui.OpenHandler(p))
+	}
+	_, err = p.Run()
+	if app.Crashed() || errors.Is(err, tea.ErrProgramPanic) {
+		reportCrash(app, logPath)
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Error("program failed", "err", err)
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// startLog opens the log file, returning its path and the file to close
+func startLog(debug bool) (string, io.Closer, error) {
+	path, err := applog.DefaultPath()
+	if err != nil {
+		return "", nil, err
+	}
+	file, err := applog.Setup(path, debug)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, file, nil
+}
+
+// reportCrash writes the unsaved notes to a recovery file once the
+// terminal is restored after a panic, and says where they and the details
+// went. logPath is empty when there's no log.
+func reportCrash(app *ui.App, logPath string) {
+	fmt.Fprintln(os.Stderr, "tuinotes crashed.")
+	dir, err := applog.StateDir()
+	if err == nil {
+		var path string
+		if path, err = app.WriteRecovery(filepath.Join(dir, "recovery")); path != "" {
+			fmt.Fprintf(os.Stderr, "Unsaved changes were written to %s\n", path)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unsaved changes couldn't be saved: %v\n", err)
+	}
+	if logPath != "" {
+		fmt.Fprintf(os.Stderr, "The details are in %s\n", logPath)
+	}
+}
+
+// globalFlags are the options given before the subcommand
+type globalFlags struct {
+	vault string // --vault <name>: the vault to open
+	db    string // --db <file>: the default vault's database
+	debug bool   // --debug: also log debug messages
+}
+
+// parseFlags takes the options before the subcommand off args. Options
+// with a value take it as the next argument or after "=".
+func parseFlags(args []string) (globalFlags, []string, error) {
+	var flags globalFlags
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		var dest *string
+		switch name {
+		case "--debug":
+			flags.debug = true
+			args = args[1:]
+			continue
+		case "--vault":
+			dest = &flags.vault
+		case "--db":
+			dest = &flags.db
+		default:
+			return flags, args, nil
+		}
+
+		if hasValue {
+			args = args[1:]
+		} else if len(args) < 2 {
+			return flags, nil, fmt.Errorf("%s needs a value", name)
+		} else {
+			value, args = args[1], args[2:]
+		}
+		if value == "" {
+			return flags, nil, fmt.Errorf("%s needs a value", name)
+		}
+		*dest = value
+	}
+	return flags, args, nil
+}
+
+// showMigrationProgress prints each step of a database upgrade to stderr,
+// so a slow start doesn't look like a hang
+func showMigrationProgress(step, total int, description string) {
+	if step == 0 {
+		fmt.Fprintf(os.Stderr, "Upgrading the database. %s\n", description)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", step, total, description)
+}
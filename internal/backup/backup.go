@@ -0,0 +1,316 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+// Backup kinds
+const (
+	KindFull        = "full"
+	KindIncremental = "incremental"
+)
+
+// ErrEncrypted is returned when backing up encrypted notes, which the
+// plaintext snapshots would expose
+var ErrEncrypted = errors.New("backups aren't available for encrypted notes; use tuinotes decrypt first")
+
+// manifestFile is the name of the manifest inside a backup directory
+const manifestFile = "manifest.json"
+
+// Manifest records the backups in a directory and the state of every note as
+// of the latest one, which incremental backups diff against
+type Manifest struct {
+	Backups      []Entry        `json:"backups"`
+	Fingerprints map[int]string `json:"fingerprints"`
+}
+
+// Entry describes one backup file
+type Entry struct {
+	File    string    `json:"file"`
+	Kind    string    `json:"kind"`
+	Created time.Time `json:"created"`
+	Changed int       `json:"changed"` // notes stored in the file
+	Deleted int       `json:"deleted"` // notes removed since the previous backup
+}
+
+// snapshot is the content of a backup file. Incremental snapshots hold only
+// the notes changed since the previous backup plus the IDs of every note
+// that existed, so deletions can be replayed. Properties holds the custom
+// properties of the stored notes by note ID.
+type snapshot struct {
+	Kind       string                    `json:"kind"`
+	Created    time.Time                 `json:"created"`
+	NoteIDs    []int                     `json:"note_ids"`
+	Notes      []*models.Note            `json:"notes"`
+	Properties map[int][]models.Property `json:"properties,omitempty"`
+}
+
+// Create writes a backup of all notes to dir. Incremental backups store only
+// notes updated since the last backup or whose tags or notebook changed; the
+// first backup in a directory is always full. Snapshots hold plaintext
+// notes, so encrypted vaults are refused and files are readable only by
+// their owner.
+func Create(service *storage.Service, dir string, incremental bool) (Entry, error) {
+	if service.EncryptionEnabled() {
+		return Entry{}, ErrEncrypted
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to load notes: %w", err)
+	}
+	properties, err := service.GetAllNoteProperties()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	kind := KindFull
+	var since time.Time
+	if incremental && len(manifest.Backups) > 0 {
+		kind = KindIncremental
+		since = manifest.Backups[len(manifest.Backups)-1].Created
+	}
+
+	now := time.Now()
+	snap := snapshot{Kind: kind, Created: now, Properties: map[int][]models.Property{}}
+	fingerprints := make(map[int]string, len(notes))
+	for _, note := range notes {
+		fp := fingerprint(note, properties[note.ID])
+		fingerprints[note.ID] = fp
+		snap.NoteIDs = append(snap.NoteIDs, note.ID)
+
+		if kind == KindFull || note.UpdatedAt.After(since) || manifest.Fingerprints[note.ID] != fp {
+			snap.Notes = append(snap.Notes, note)
+			if len(properties[note.ID]) > 0 {
+				snap.Properties[note.ID] = properties[note.ID]
+			}
+		}
+	}
+
+	deleted := 0
+	if kind == KindIncremental {
+		for id := range manifest.Fingerprints {
+			if _, exists := fingerprints[id]; !exists {
+				deleted++
+			}
+		}
+	}
+
+	// The sequence number keeps names unique when backups are taken within
+	// the same millisecond
+	entry := Entry{
+		File:    fmt.Sprintf("backup-%s-%04d-%s.json", now.Format("20060102-150405.000"), len(manifest.Backups)+1, kind),
+		Kind:    kind,
+		Created: now,
+		Changed: len(snap.Notes),
+		Deleted: deleted,
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode backup: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, entry.File), data, 0600); err != nil {
+		return Entry{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	manifest.Backups = append(manifest.Backups, entry)
+	manifest.Fingerprints = fingerprints
+	if err := saveManifest(dir, manifest); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Restore rebuilds the notes of the latest backup in dir by replaying the
+// most recent full backup and every incremental one after it. The target
+// database must be empty. Returns the number of notes restored.
+func Restore(service *storage.Service, dir string) (int, error) {
+	existing, err := service.GetAllNotes(models.NoteFilter{Limit: 1})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check database: %w", err)
+	}
+	if len(existing) > 0 {
+		return 0, fmt.Errorf("database already contains notes; restore into an empty database")
+	}
+
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	chain, err := restoreChain(manifest)
+	if err != nil {
+		return 0, err
+	}
+
+	notes := map[int]*models.Note{}
+	properties := map[int][]models.Property{}
+	for _, entry := range chain {
+		snap, err := loadSnapshot(filepath.Join(dir, entry.File))
+		if err != nil {
+			return 0, err
+		}
+
+		for _, note := range snap.Notes {
+			notes[note.ID] = note
+			properties[note.ID] = snap.Properties[note.ID]
+		}
+
+		// Drop notes that no longer existed when this backup was taken
+		alive := make(map[int]bool, len(snap.NoteIDs))
+		for _, id := range snap.NoteIDs {
+			alive[id] = true
+		}
+		for id := range notes {
+			if !alive[id] {
+				delete(notes, id)
+			}
+		}
+	}
+
+	// Restore in ID order so IDs are reused as they were
+	ids := make([]int, 0, len(notes))
+	for id := range notes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		note := notes[id]
+		tags := note.Tags
+		if err := service.ImportNote(note); err != nil {
+			return 0, fmt.Errorf("failed to restore note %d: %w", id, err)
+		}
+		for _, tag := range tags {
+			if err := service.AddTagToNote(note.ID, tag.Name); err != nil {
+				return 0, fmt.Errorf("failed to restore tags of note %d: %w", id, err)
+			}
+		}
+		for _, property := range properties[id] {
+			if err := service.SetNoteProperty(note.ID, property.Key, property.Value); err != nil {
+				return 0, fmt.Errorf("failed to restore properties of note %d: %w", id, err)
+			}
+		}
+	}
+
+	return len(ids), nil
+}
+
+// restoreChain returns the latest full backup followed by later incrementals
+func restoreChain(manifest *Manifest) ([]Entry, error) {
+	for i := len(manifest.Backups) - 1; i >= 0; i-- {
+		if manifest.Backups[i].Kind == KindFull {
+			return manifest.Backups[i:], nil
+		}
+	}
+	return nil, fmt.Errorf("no full backup found")
+}
+
+// LoadManifest reads the manifest in dir, returning an empty one if missing
+func LoadManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{Fingerprints: map[int]string{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse backup manifest: %w", err)
+	}
+	if manifest.Fingerprints == nil {
+		manifest.Fingerprints = map[int]string{}
+	}
+	return manifest, nil
+}
+
+// saveManifest writes the manifest atomically so an interrupted backup
+// never leaves it half-written
+func saveManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+
+	tmp := filepath.Join(dir, manifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, manifestFile)); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return nil
+}
+
+// loadSnapshot reads a backup file
+func loadSnapshot(path string) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", filepath.Base(path), err)
+	}
+
+	snap := &snapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", filepath.Base(path), err)
+	}
+	return snap, nil
+}
+
+// fingerprint hashes everything a backup preserves about a note, catching
+// changes such as retagging that don't touch updated_at
+func fingerprint(note *models.Note, properties []models.Property) string {
+	tagNames := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		tagNames[i] = tag.Name
+	}
+	sort.Strings(tagNames)
+
+	pairs := make([]string, len(properties))
+	for i, property := range properties {
+		pairs[i] = property.Key + "\x01" + property.Value
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		note.Title,
+		note.Content,
+		note.Notebook,
+		note.Color,
+		strconv.FormatBool(note.Pinned),
+		strconv.Itoa(note.SortOrder),
+		note.CreatedAt.UTC().Format(time.RFC3339Nano),
+		note.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		strings.Join(tagNames, "\x00"),
+		strings.Join(pairs, "\x00"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
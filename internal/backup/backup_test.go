@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+func TestIncrementalBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	keep, _ := service.CreateNote("Keep", "unchanged")
+	edit, _ := service.CreateNote("Edit", "before")
+	remove, _ := service.CreateNote("Remove", "gone soon")
+
+	entry, err := Create(service, backupDir, true)
+	if err != nil {
+		t.Fatalf("Failed to create first backup: %v", err)
+	}
+	if entry.Kind != KindFull || entry.Changed != 3 {
+		t.Errorf("Expected a full backup of 3 notes, got %+v", entry)
+	}
+
+	// Change one note, retag another, delete one and add one
+	edit.Content = "after"
+	if err := service.UpdateNote(edit); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if err := service.AddTagToNote(keep.ID, "pinned"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.DeleteNote(remove.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if _, err := service.CreateNote("New", "fresh"); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	entry, err = Create(service, backupDir, true)
+	if err != nil {
+		t.Fatalf("Failed to create incremental backup: %v", err)
+	}
+	if entry.Kind != KindIncremental || entry.Changed != 3 || entry.Deleted != 1 {
+		t.Errorf("Expected 3 changed and 1 deleted note, got %+v", entry)
+	}
+
+	// A new color label or pin is caught although the notes' times don't
+	// change
+	if err := service.SetNoteColor(edit.ID, "green"); err != nil {
+		t.Fatalf("Failed to color note: %v", err)
+	}
+	if err := service.PinNote(keep.ID, true); err != nil {
+		t.Fatalf("Failed to pin note: %v", err)
+	}
+	entry, err = Create(service, backupDir, true)
+	if err != nil {
+		t.Fatalf("Failed to create incremental backup: %v", err)
+	}
+	if entry.Changed != 2 {
+		t.Errorf("Expected the recolored and pinned notes in the backup, got %+v", entry)
+	}
+
+	// So is a new custom property
+	if err := service.SetNoteProperty(edit.ID, "status", "done"); err != nil {
+		t.Fatalf("Failed to set property: %v", err)
+	}
+	entry, err = Create(service, backupDir, true)
+	if err != nil {
+		t.Fatalf("Failed to create incremental backup: %v", err)
+	}
+	if entry.Changed != 1 {
+		t.Errorf("Expected the note with the new property in the backup, got %+v", entry)
+	}
+
+	// Nothing changed since, so the next incremental is empty
+	entry, err = Create(service, backupDir, true)
+	if err != nil {
+		t.Fatalf("Failed to create incremental backup: %v", err)
+	}
+	if entry.Changed != 0 || entry.Deleted != 0 {
+		t.Errorf("Expected an empty incremental backup, got %+v", entry)
+	}
+
+	restored, err := storage.NewService(filepath.Join(dir, "restored.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer restored.Close()
+
+	count, err := Restore(restored, backupDir)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 restored notes, got %d", count)
+	}
+
+	note, err := restored.GetNote(edit.ID)
+	if err != nil || note.Content != "after" || note.Color != "green" {
+		t.Errorf("Expected edited note to be restored with its latest content and color, got %+v (%v)", note, err)
+	}
+	properties, err := restored.GetNoteProperties(edit.ID)
+	if err != nil || len(properties) != 1 || properties[0] != (models.Property{Key: "status", Value: "done"}) {
+		t.Errorf("Expected edited note to keep its property, got %+v (%v)", properties, err)
+	}
+	note, err = restored.GetNote(keep.ID)
+	if err != nil || len(note.Tags) != 1 || note.Tags[0].Name != "pinned" || !note.Pinned || note.SortOrder != 1 {
+		t.Errorf("Expected retagged note to keep its tag and pin, got %+v (%v)", note, err)
+	}
+	if _, err := restored.GetNote(remove.ID); err == nil {
+		t.Error("Expected deleted note to stay deleted")
+	}
+
+	// Restoring over existing notes is refused
+	if _, err := Restore(restored, backupDir); err == nil {
+		t.Error("Expected restore into a non-empty database to fail")
+	}
+
+	all, _ := restored.GetAllNotes(models.NoteFilter{})
+	if len(all) != 3 {
+		t.Errorf("Expected 3 notes after refused restore, got %d", len(all))
+	}
+}
+
+func TestBackupRefusesEncryptedNotes(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	if _, err := service.CreateNote("Secret", "launch codes"); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.EnableEncryption("correct horse"); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+
+	if _, err := Create(service, backupDir, true); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted, got %v", err)
+	}
+	if _, err := os.Stat(backupDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup directory to be written, got %v", err)
+	}
+}
@@ -0,0 +1,611 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/mdformat"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// Renderer names accepted in the config file
+const (
+	RendererNative  = "native"
+	RendererGlamour = "glamour"
+)
+
+// Hyperlink modes accepted in the config file
+const (
+	HyperlinksAuto   = "auto"
+	HyperlinksAlways = "always"
+	HyperlinksNever  = "never"
+)
+
+// Image modes accepted in the config file
+const (
+	ImagesPlaceholder = "placeholder"
+	ImagesAuto        = "auto"
+	ImagesKitty       = "kitty"
+	ImagesITerm2      = "iterm2"
+	ImagesSixel       = "sixel"
+)
+
+// Note list layouts accepted in the config file
+const (
+	LayoutCompact  = "compact"
+	LayoutDetailed = "detailed"
+	LayoutCard     = "card"
+	LayoutTable    = "table"
+)
+
+// Sync providers accepted in the config file
+const (
+	SyncGit    = "git"
+	SyncWebDAV = "webdav"
+)
+
+// HTML export themes accepted in the config file
+const (
+	HTMLThemeDark  = "dark"
+	HTMLThemeLight = "light"
+	HTMLThemePrint = "print"
+)
+
+// Key presets of text fields accepted in the config file
+const (
+	KeymapDefault = "default"
+	KeymapEmacs   = "emacs"
+)
+
+// DefaultVault names the vault kept in the default database, which is
+// always available even when no vaults are configured
+const DefaultVault = "default"
+
+// Vault is a separate notes database that can be opened instead of the
+// default one
+type Vault struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+
+	// SyncDir, WebDAVURL and WatchDir sync the vault on their own. The
+	// top-level settings only sync the default vault, since syncing two
+	// databases into one place would remove each other's notes.
+	SyncDir   string `json:"sync_dir,omitempty"`
+	WebDAVURL string `json:"webdav_url,omitempty"`
+	WatchDir  string `json:"watch_dir,omitempty"`
+}
+
+// Autolink turns text matching Pattern, a regular expression, into a link
+// to URL in previews and HTML exports. URL may refer to the match as $0
+// and to capture groups as $1, $2, ...
+type Autolink struct {
+	Pattern string `json:"pattern"`
+	URL     string `json:"url"`
+}
+
+// CaptureHook binds Key to Command, a shell command whose output becomes
+// note text, e.g. a speech-to-text tool. Key is written as the app shows
+// keys, such as "alt+r" or "ctrl+x".
+type CaptureHook struct {
+	Key     string `json:"key"`
+	Command string `json:"command"`
+}
+
+// SavedSearch is a search kept in the sidebar under Name
+type SavedSearch struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Config holds user preferences loaded from the config file
+type Config struct {
+	// Renderer selects the markdown renderer used for previews ("native" or "glamour")
+	Renderer string `json:"renderer"`
+
+	// SmartTypography renders dashes, ellipses and quotes typographically in
+	// previews and exports. Stored notes are never changed.
+	SmartTypography bool `json:"smart_typography"`
+
+	// Hyperlinks controls clickable OSC 8 links in the preview ("auto", "always" or "never")
+	Hyperlinks string `json:"hyperlinks"`
+
+	// Images selects how the preview shows images ("placeholder", "auto",
+	// "kitty", "iterm2" or "sixel")
+	Images string `json:"images"`
+
+	// ListLayout selects how the notes list renders rows ("compact", "detailed", "card" or "table")
+	ListLayout string `json:"list_layout"`
+
+	// Locale orders titles and tags by the rules of a language, given as a
+	// BCP 47 tag such as "de" or "ja". Empty uses the language-neutral order.
+	Locale string `json:"locale"`
+
+	// Keymap selects the key preset of text fields ("default" or "emacs").
+	// The emacs preset keeps the text Ctrl+K, Ctrl+U, Ctrl+W and Alt+D cut
+	// for Ctrl+Y to paste back, as in readline.
+	Keymap string `json:"keymap"`
+
+	// TwoPane shows the notes list and a live preview of the selected note
+	// side by side on large terminals
+	TwoPane bool `json:"two_pane"`
+
+	// HideSidebar hides the sidebar of notebooks, tags and special views
+	// shown beside the notes list on large terminals
+	HideSidebar bool `json:"hide_sidebar"`
+
+	// SavedSearches are listed in the sidebar, where picking one runs it
+	SavedSearches []SavedSearch `json:"saved_searches"`
+
+	// NoTagSuggestions saves notes without suggesting tags for them
+	NoTagSuggestions bool `json:"no_tag_suggestions"`
+
+	// ListLimit caps how many notes the list loads and SearchLimit how many
+	// results a search fetches. The list offers to show the rest; 0 shows
+	// everything up front.
+	ListLimit   int `json:"list_limit"`
+	SearchLimit int `json:"search_limit"`
+
+	// LockAfterMinutes returns an encrypted database to the unlock screen
+	// after this many minutes without input. 0 never locks.
+	LockAfterMinutes int `json:"lock_after_minutes"`
+
+	// SyncProvider selects where notes are synced ("git" or "webdav")
+	SyncProvider string `json:"sync_provider"`
+
+	// SyncDir is a git repository notes are exported to and synced through.
+	// Empty disables git sync.
+	SyncDir string `json:"sync_dir"`
+
+	// WebDAVURL is the collection notes are synced with by the WebDAV
+	// provider. The password may instead be set in TUINOTES_WEBDAV_PASSWORD.
+	WebDAVURL      string `json:"webdav_url"`
+	WebDAVUser     string `json:"webdav_user"`
+	WebDAVPassword string `json:"webdav_password"`
+
+	// WatchDir is a directory notes are mirrored into as markdown files
+	// while the app runs. Files changed there by other programs are read
+	// back within moments. Empty disables the mirror.
+	WatchDir string `json:"watch_dir"`
+
+	// RemindAt is the time of day, as HH:MM, that tuinotes daemon reminds
+	// about tasks due that day, and SnoozeMinutes how long snoozing a
+	// reminder puts it off
+	RemindAt      string `json:"remind_at"`
+	SnoozeMinutes int    `json:"snooze_minutes"`
+
+	// Autolinks turn references such as issue keys into links when notes
+	// are rendered. Stored notes are never changed.
+	Autolinks []Autolink `json:"autolinks"`
+
+	// FormatOnSave tidies the markdown of notes as the editor saves them:
+	// headings, trailing spaces, list markers and, with FormatWidth set,
+	// long lines. FormatListMarker is the bullet lists use. Notes with
+	// "format: false" in their frontmatter are left alone.
+	FormatOnSave     bool   `json:"format_on_save"`
+	FormatWidth      int    `json:"format_width"`
+	FormatListMarker string `json:"format_list_marker"`
+
+	// CaptureHooks run commands on a key and take what they print: the
+	// editor inserts it at the cursor and the notes list starts a new note
+	// with it
+	CaptureHooks []CaptureHook `json:"capture_hooks"`
+
+	// HTMLTheme styles HTML exports ("dark", "light" or "print"), and
+	// HTMLStylesheet, if set, is a CSS file used instead
+	HTMLTheme      string `json:"html_theme"`
+	HTMLStylesheet string `json:"html_stylesheet"`
+
+	// Vaults are further databases that can be switched to, and Vault
+	// names the one opened on start. Empty opens the default vault.
+	Vaults []Vault `json:"vaults"`
+	Vault  string  `json:"vault"`
+
+	// path is where the config was loaded from
+	path string
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Renderer:   RendererNative,
+		Hyperlinks: HyperlinksAuto,
+		Images:     ImagesPlaceholder,
+		ListLayout: LayoutCompact,
+		Keymap:     KeymapDefault,
+
+		FormatListMarker: "-",
+
+		ListLimit:        1000,
+		SearchLimit:      100,
+		LockAfterMinutes: 10,
+		SyncProvider:     SyncGit,
+		RemindAt:         "09:00",
+		SnoozeMinutes:    60,
+		HTMLTheme:        HTMLThemeDark,
+	}
+}
+
+// DefaultPath returns the config file location, honoring XDG_CONFIG_HOME
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "tuinotes", "config.json"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "tuinotes", "config.json"), nil
+}
+
+// DatabaseEnv names the environment variable that moves the default
+// vault's database
+const DatabaseEnv = "TUINOTES_DB"
+
+// databasePath is the default vault's database when set with --db
+var databasePath string
+
+// SetDatabasePath makes path the default vault's database, taking
+// precedence over TUINOTES_DB
+func SetDatabasePath(path string) {
+	databasePath = path
+}
+
+// DefaultDatabasePath returns the database file of the default vault: the
+// one given with --db or TUINOTES_DB, or else notes.db under
+// $XDG_DATA_HOME/tuinotes or ~/.local/share/tuinotes. A database still at
+// the old location, ~/.markdown-notes.db, is used until it's moved.
+func DefaultDatabasePath() (string, error) {
+	if databasePath != "" {
+		return databasePath, nil
+	}
+	if path := os.Getenv(DatabaseEnv); path != "" {
+		return expandHome(path)
+	}
+
+	path, legacy, err := databasePaths()
+	if err != nil {
+		return "", err
+	}
+	if !fileExists(path) && fileExists(legacy) {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// MigrateLegacyDatabase moves a database at the old location,
+// ~/.markdown-notes.db, to the default one. It's left alone when --db or
+// TUINOTES_DB says where the database is, a configured vault uses it, or
+// there's a database at the default location already. It returns the new
+// path when the database was moved.
+func (c *Config) MigrateLegacyDatabase() (string, error) {
+	if databasePath != "" || os.Getenv(DatabaseEnv) != "" {
+		return "", nil
+	}
+	path, legacy, err := databasePaths()
+	if err != nil {
+		return "", err
+	}
+	if fileExists(path) || !fileExists(legacy) {
+		return "", nil
+	}
+	for _, vault := range c.Vaults {
+		if vaultPath, err := expandHome(vault.Path); err == nil && filepath.Clean(vaultPath) == legacy {
+			return "", nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create database directory: %w", err)
+	}
+	// The write-ahead log holds changes not yet in the database file. The
+	// database goes first, so a move that can't be done moves nothing.
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if !fileExists(legacy + suffix) {
+			continue
+		}
+		if err := os.Rename(legacy+suffix, path+suffix); err != nil {
+			return "", fmt.Errorf("failed to move %s to %s: %w", legacy, path, err)
+		}
+	}
+	return path, nil
+}
+
+// databasePaths returns the default database location and the old one
+func databasePaths() (string, string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacy := filepath.Join(homeDir, ".markdown-notes.db")
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "tuinotes", "notes.db"), legacy, nil
+	}
+	return filepath.Join(homeDir, ".local", "share", "tuinotes", "notes.db"), legacy, nil
+}
+
+// fileExists reports whether something exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Load reads the config file at path, falling back to defaults when it doesn't exist
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	cfg.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// LoadDefault loads the config from the default location
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Save writes the config back to the file it was loaded from
+func (c *Config) Save() error {
+	if c.path == "" {
+		return fmt.Errorf("config has no file path")
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated config
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Path returns the file the config was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// AutolinkRules compiles the autolinks for rendering
+func (c *Config) AutolinkRules() []utils.AutolinkRule {
+	rules := make([]utils.AutolinkRule, 0, len(c.Autolinks))
+	for _, autolink := range c.Autolinks {
+		if re, err := regexp.Compile(autolink.Pattern); err == nil {
+			rules = append(rules, utils.AutolinkRule{Pattern: re, URL: autolink.URL})
+		}
+	}
+	return rules
+}
+
+// FormatOptions returns how notes are formatted on save
+func (c *Config) FormatOptions() mdformat.Options {
+	return mdformat.Options{Width: c.FormatWidth, ListMarker: c.FormatListMarker}
+}
+
+// ReminderTime returns how long after midnight reminders for tasks due
+// that day are sent
+func (c *Config) ReminderTime() time.Duration {
+	at, err := time.Parse("15:04", c.RemindAt)
+	if err != nil {
+		return 9 * time.Hour
+	}
+	return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+}
+
+// AllVaults returns the default vault followed by the configured ones. A
+// configured vault named "default" replaces the built-in one.
+func (c *Config) AllVaults() ([]Vault, error) {
+	var vaults []Vault
+	if _, ok := c.findConfigured(DefaultVault); !ok {
+		path, err := DefaultDatabasePath()
+		if err != nil {
+			return nil, err
+		}
+		vaults = append(vaults, Vault{
+			Name:      DefaultVault,
+			Path:      path,
+			SyncDir:   c.SyncDir,
+			WebDAVURL: c.WebDAVURL,
+			WatchDir:  c.WatchDir,
+		})
+	}
+
+	vaults = append(vaults, c.Vaults...)
+	for i := range vaults {
+		path, err := expandHome(vaults[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		syncDir, err := expandHome(vaults[i].SyncDir)
+		if err != nil {
+			return nil, err
+		}
+		watchDir, err := expandHome(vaults[i].WatchDir)
+		if err != nil {
+			return nil, err
+		}
+		vaults[i].Path, vaults[i].SyncDir, vaults[i].WatchDir = path, syncDir, watchDir
+	}
+	return vaults, nil
+}
+
+// FindVault returns the vault called name, or the one opened on start
+// when name is empty
+func (c *Config) FindVault(name string) (Vault, error) {
+	if name == "" {
+		name = c.Vault
+	}
+	if name == "" {
+		name = DefaultVault
+	}
+
+	vaults, err := c.AllVaults()
+	if err != nil {
+		return Vault{}, err
+	}
+	for _, vault := range vaults {
+		if vault.Name == name {
+			return vault, nil
+		}
+	}
+	return Vault{}, fmt.Errorf("unknown vault %q", name)
+}
+
+// findConfigured returns the configured vault called name
+func (c *Config) findConfigured(name string) (Vault, bool) {
+	for _, vault := range c.Vaults {
+		if vault.Name == name {
+			return vault, true
+		}
+	}
+	return Vault{}, false
+}
+
+// expandHome replaces a leading ~ in path with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
+
+// normalize replaces invalid values with defaults
+func (c *Config) normalize() {
+	defaults := Default()
+
+	switch c.Renderer {
+	case RendererNative, RendererGlamour:
+	default:
+		c.Renderer = defaults.Renderer
+	}
+
+	switch c.Hyperlinks {
+	case HyperlinksAuto, HyperlinksAlways, HyperlinksNever:
+	default:
+		c.Hyperlinks = defaults.Hyperlinks
+	}
+
+	switch c.Images {
+	case ImagesPlaceholder, ImagesAuto, ImagesKitty, ImagesITerm2, ImagesSixel:
+	default:
+		c.Images = defaults.Images
+	}
+
+	switch c.ListLayout {
+	case LayoutCompact, LayoutDetailed, LayoutCard, LayoutTable:
+	default:
+		c.ListLayout = defaults.ListLayout
+	}
+
+	switch c.Keymap {
+	case KeymapDefault, KeymapEmacs:
+	default:
+		c.Keymap = defaults.Keymap
+	}
+
+	switch c.FormatListMarker {
+	case "-", "*", "+":
+	default:
+		c.FormatListMarker = defaults.FormatListMarker
+	}
+	c.FormatWidth = max(c.FormatWidth, 0)
+
+	switch c.SyncProvider {
+	case SyncGit, SyncWebDAV:
+	default:
+		c.SyncProvider = defaults.SyncProvider
+	}
+
+	switch c.HTMLTheme {
+	case HTMLThemeDark, HTMLThemeLight, HTMLThemePrint:
+	default:
+		c.HTMLTheme = defaults.HTMLTheme
+	}
+
+	if c.ListLimit < 0 {
+		c.ListLimit = 0
+	}
+	if c.SearchLimit < 0 {
+		c.SearchLimit = 0
+	}
+
+	if c.LockAfterMinutes < 0 {
+		c.LockAfterMinutes = 0
+	}
+
+	if _, err := time.Parse("15:04", c.RemindAt); err != nil {
+		c.RemindAt = defaults.RemindAt
+	}
+	if c.SnoozeMinutes <= 0 {
+		c.SnoozeMinutes = defaults.SnoozeMinutes
+	}
+
+	// Autolinks need a pattern that compiles and can't match empty text,
+	// which would link every position in a note
+	autolinks := c.Autolinks[:0]
+	for _, autolink := range c.Autolinks {
+		re, err := regexp.Compile(autolink.Pattern)
+		if err == nil && autolink.URL != "" && !re.MatchString("") {
+			autolinks = append(autolinks, autolink)
+		}
+	}
+	c.Autolinks = autolinks
+
+	// Saved searches need a query, and are named by it when unnamed
+	searches := c.SavedSearches[:0]
+	for _, search := range c.SavedSearches {
+		search.Query = strings.TrimSpace(search.Query)
+		if search.Query == "" {
+			continue
+		}
+		if search.Name = strings.TrimSpace(search.Name); search.Name == "" {
+			search.Name = search.Query
+		}
+		searches = append(searches, search)
+	}
+	c.SavedSearches = searches
+
+	// Vaults need a name to be chosen by and a database to open
+	vaults := c.Vaults[:0]
+	for _, vault := range c.Vaults {
+		vault.Name = strings.TrimSpace(vault.Name)
+		if vault.Name != "" && vault.Path != "" {
+			vaults = append(vaults, vault)
+		}
+	}
+	c.Vaults = vaults
+}
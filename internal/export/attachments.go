@@ -0,0 +1,131 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"markdown-note-taking-app/internal/utils"
+)
+
+// AttachmentsDir is the subdirectory of an export holding the local files
+// notes link to
+const AttachmentsDir = "attachments"
+
+// attachmentCopier copies the files linked from exported notes into the
+// export's attachments directory, each file once
+type attachmentCopier struct {
+	dir    string            // attachments directory
+	copied map[string]string // absolute source path -> file name in dir
+	taken  map[string]bool   // file names in use
+}
+
+// newAttachmentCopier creates a copier for the export in dir
+func newAttachmentCopier(dir string) *attachmentCopier {
+	return &attachmentCopier{
+		dir:    filepath.Join(dir, AttachmentsDir),
+		copied: map[string]string{},
+		taken:  map[string]bool{},
+	}
+}
+
+// rewrite copies the local files content links to and points the links at
+// the copies. It returns the new content and, for each rewritten link, the
+// new and original targets, so an import can restore the originals. Links
+// to missing files are left as they are.
+func (c *attachmentCopier) rewrite(content string) (string, []string, []string, error) {
+	var targets, sources []string
+	var copyErr error
+	content = utils.RewriteLocalLinks(content, func(target string) string {
+		if copyErr != nil {
+			return target
+		}
+		source, ok := localFile(target)
+		if !ok {
+			return target
+		}
+
+		name, err := c.copy(source)
+		if err != nil {
+			copyErr = err
+			return target
+		}
+		exported := AttachmentsDir + "/" + name
+		targets = append(targets, exported)
+		sources = append(sources, target)
+		return exported
+	})
+	return content, targets, sources, copyErr
+}
+
+// copy copies source into the attachments directory unless it was copied
+// already, and returns its file name there. Files sharing a name get
+// numeric suffixes.
+func (c *attachmentCopier) copy(source string) (string, error) {
+	if name, ok := c.copied[source]; ok {
+		return name, nil
+	}
+
+	ext := filepath.Ext(source)
+	stem := strings.ReplaceAll(strings.TrimSuffix(filepath.Base(source), ext), " ", "-")
+	name := stem + ext
+	for i := 2; c.taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	if err := copyFile(source, filepath.Join(c.dir, name)); err != nil {
+		return "", err
+	}
+	c.copied[source] = name
+	c.taken[name] = true
+	return name, nil
+}
+
+// localFile resolves a link target to a regular file the way the preview
+// resolves images: ~ is the home directory and relative paths start in the
+// working directory
+func localFile(target string) (string, bool) {
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	path, err := ExpandHome(target)
+	if err != nil {
+		return "", false
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// copyFile copies the file at from to to
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return fmt.Errorf("failed to open attachment: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(to)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy attachment %s: %w", from, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write attachment %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"text/template"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdownRenderer converts note content to HTML. Raw HTML in notes is
+// left out of the output.
+var markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// htmlThemes holds the stylesheets bundled for HTML exports, one per theme
+//
+//go:embed themes/*.css
+var htmlThemes embed.FS
+
+// HTMLThemes lists the bundled themes for HTML exports
+var HTMLThemes = []string{"dark", "light", "print"}
+
+// DefaultHTMLTheme matches the colors of the terminal app
+const DefaultHTMLTheme = "dark"
+
+// HTMLOptions selects the look of an HTML export
+type HTMLOptions struct {
+	Theme      string // one of HTMLThemes, DefaultHTMLTheme when empty
+	Stylesheet string // CSS file embedded instead of the theme, if set
+
+	// Autolinks turn matching references in the note into links
+	Autolinks []utils.AutolinkRule
+}
+
+// stylesheet returns the CSS embedded in pages exported with opts
+func (opts HTMLOptions) stylesheet() (string, error) {
+	if opts.Stylesheet != "" {
+		path, err := ExpandHome(opts.Stylesheet)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stylesheet: %w", err)
+		}
+		return string(data), nil
+	}
+
+	theme := opts.Theme
+	if theme == "" {
+		theme = DefaultHTMLTheme
+	}
+	data, err := htmlThemes.ReadFile("themes/" + theme + ".css")
+	if err != nil {
+		return "", fmt.Errorf("unknown HTML theme %q, expected one of %s", theme, strings.Join(HTMLThemes, ", "))
+	}
+	return string(data), nil
+}
+
+// htmlPage is the standalone document a note is rendered into, with the
+// theme's stylesheet embedded so the file stands on its own
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+{{.Stylesheet}}</style>
+</head>
+<body>
+<main>
+<header>
+<h1>{{.Title}}</h1>
+<p class="meta">Updated {{.Updated}}{{if .Notebook}} · {{.Notebook}}{{end}}</p>
+{{if .Tags}}<p class="meta">{{range .Tags}}<span class="tag">#{{.}}</span>{{end}}</p>{{end}}
+</header>
+{{.Body}}
+</main>
+</body>
+</html>
+`))
+
+// htmlPageData fills in htmlPage. Strings are escaped before they're put
+// here; text/template doesn't escape.
+type htmlPageData struct {
+	Title      string
+	Notebook   string
+	Updated    string
+	Tags       []string
+	Body       string
+	Stylesheet string
+}
+
+// NoteHTML renders a note as a standalone HTML page with the stylesheet
+// opts selects embedded. Frontmatter is left out.
+func NoteHTML(note *models.Note, opts HTMLOptions) (string, error) {
+	stylesheet, err := opts.stylesheet()
+	if err != nil {
+		return "", err
+	}
+
+	content := note.Content
+	if _, body, ok := utils.ParseFrontmatter(content); ok {
+		content = body
+	}
+
+	content = utils.Autolink(content, opts.Autolinks)
+
+	var body bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(content), &body); err != nil {
+		return "", fmt.Errorf("failed to render %q: %w", note.Title, err)
+	}
+
+	tags := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		tags[i] = html.EscapeString(tag.Name)
+	}
+
+	var page strings.Builder
+	err = htmlPage.Execute(&page, htmlPageData{
+		Title:      html.EscapeString(note.Title),
+		Notebook:   html.EscapeString(note.Notebook),
+		Updated:    note.UpdatedAt.Format("2006-01-02 15:04"),
+		Tags:       tags,
+		Body:       body.String(),
+		Stylesheet: stylesheet,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render %q: %w", note.Title, err)
+	}
+	return page.String(), nil
+}
+
+// HTMLFile writes a note to path as a standalone HTML page
+func HTMLFile(note *models.Note, path string, opts HTMLOptions) error {
+	page, err := NoteHTML(note, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
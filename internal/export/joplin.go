@@ -0,0 +1,115 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// joplinTimeFormat is how Joplin writes dates in front matter
+const joplinTimeFormat = "2006-01-02 15:04:05Z"
+
+// JoplinDir writes notes in the layout Joplin's "Markdown + Front Matter"
+// import reads: one folder per notebook, notes without one at the top, and
+// each file starting with front matter holding the title, tags and
+// created/updated dates. Linked local files are copied to an attachments
+// folder, which Joplin turns into resources. Returns the paths of the
+// written files.
+func JoplinDir(notes []*models.Note, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	attachments := newAttachmentCopier(dir)
+	taken := map[string]bool{}
+	var paths []string
+	for _, note := range notes {
+		folder := joplinFolder(note.Notebook)
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			return paths, fmt.Errorf("failed to create notebook folder: %w", err)
+		}
+
+		stem := filepath.Join(folder, utils.Slugify(note.Title))
+		path := stem + ".md"
+		for i := 2; taken[path]; i++ {
+			path = fmt.Sprintf("%s-%d.md", stem, i)
+		}
+		taken[path] = true
+
+		content := note.Content
+		if _, body, ok := utils.ParseFrontmatter(content); ok {
+			content = body
+		}
+
+		// Links are relative to the note's file, which may be a folder down
+		prefix := ""
+		if folder != "" {
+			prefix = "../"
+		}
+		var copyErr error
+		content = utils.RewriteLocalLinks(content, func(target string) string {
+			source, ok := localFile(target)
+			if !ok || copyErr != nil {
+				return target
+			}
+			name, err := attachments.copy(source)
+			if err != nil {
+				copyErr = err
+				return target
+			}
+			return prefix + AttachmentsDir + "/" + name
+		})
+		if copyErr != nil {
+			return paths, copyErr
+		}
+
+		full := filepath.Join(dir, path)
+		if err := os.WriteFile(full, []byte(joplinFrontmatter(note).String()+content), 0644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", full, err)
+		}
+		paths = append(paths, full)
+	}
+	return paths, nil
+}
+
+// joplinFrontmatter builds the front matter Joplin reads a note's metadata from
+func joplinFrontmatter(note *models.Note) utils.Frontmatter {
+	var fm utils.Frontmatter
+	fm.Set("title", note.Title)
+	fm.Set("created", note.CreatedAt.UTC().Format(joplinTimeFormat))
+	fm.Set("updated", note.UpdatedAt.UTC().Format(joplinTimeFormat))
+
+	tags := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		tags[i] = tag.Name
+	}
+	if len(tags) > 0 {
+		fm.SetList("tags", tags)
+	}
+	return fm
+}
+
+// joplinFolder returns the folder a notebook is exported to. Characters
+// that can't appear in folder names are replaced, and the attachments
+// folder name is kept free.
+func joplinFolder(notebook string) string {
+	notebook = strings.TrimSpace(notebook)
+	if notebook == "" {
+		return ""
+	}
+	folder := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, notebook)
+	folder = strings.Trim(folder, ". ")
+	if folder == "" || folder == AttachmentsDir {
+		folder = "_" + folder
+	}
+	return folder
+}
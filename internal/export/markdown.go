@@ -0,0 +1,120 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// MarkdownDir writes each note to dir as a markdown file named after its
+// title, with frontmatter carrying the note's IDs and tags so the files can be
+// edited and imported again without duplicating notes. A note's previous
+// export in the same directory is overwritten; other clashing names get a
+// numeric suffix. Local files the notes link to are copied to an
+// attachments subdirectory and the links rewritten to match; the
+// frontmatter records the original links for importing. Returns the paths
+// of the written files.
+func MarkdownDir(notes []*models.Note, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	attachments := newAttachmentCopier(dir)
+	var paths []string
+	for _, note := range notes {
+		path, err := notePath(dir, note)
+		if err != nil {
+			return paths, err
+		}
+
+		content, targets, sources, err := attachments.rewrite(note.Content)
+		if err != nil {
+			return paths, err
+		}
+		fm := NoteFrontmatter(note)
+		if len(targets) > 0 {
+			fm.SetList("attachments", targets)
+			fm.SetList("attachment_sources", sources)
+		}
+
+		if err := os.WriteFile(path, []byte(fm.String()+content), 0644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// NoteMarkdown renders a note as a markdown document with metadata frontmatter.
+// The body is the note content unchanged so imports restore it exactly.
+func NoteMarkdown(note *models.Note) string {
+	return NoteFrontmatter(note).String() + note.Content
+}
+
+// NoteFrontmatter builds the frontmatter describing a note
+func NoteFrontmatter(note *models.Note) utils.Frontmatter {
+	var fm utils.Frontmatter
+	fm.Set("id", strconv.Itoa(note.ID))
+	fm.Set("uuid", note.UUID)
+	fm.Set("slug", utils.Slugify(note.Title))
+	fm.Set("title", note.Title)
+	if note.Notebook != "" {
+		fm.Set("notebook", note.Notebook)
+	}
+	fm.Set("created", note.CreatedAt.Format(time.RFC3339))
+	fm.Set("updated", note.UpdatedAt.Format(time.RFC3339))
+
+	tagNames := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		tagNames[i] = tag.Name
+	}
+	fm.SetList("tags", tagNames)
+
+	return fm
+}
+
+// notePath picks the file for a note: its slug, reusing a file that already
+// holds this note and skipping files that hold other notes
+func notePath(dir string, note *models.Note) (string, error) {
+	stem := utils.Slugify(note.Title)
+	path := filepath.Join(dir, stem+".md")
+	for i := 2; ; i++ {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check %s: %w", path, err)
+		}
+
+		fm, _, ok := utils.ParseFrontmatter(string(data))
+		if uuid, found := fm.Get("uuid"); ok && found {
+			if uuid == note.UUID {
+				return path, nil
+			}
+		} else if id, _ := fm.Get("id"); ok && id == strconv.Itoa(note.ID) {
+			// Exported before notes had UUIDs
+			return path, nil
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", stem, i))
+	}
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// PandocFormats lists the formats PandocDir can produce, by file extension
+var PandocFormats = []string{"pdf", "docx"}
+
+// ErrPandocMissing is returned when pandoc isn't installed
+var ErrPandocMissing = errors.New("pandoc is not installed or not on the PATH; see https://pandoc.org/installing.html")
+
+// PandocDir converts each note to format with pandoc, writing one file per
+// note to dir named after its title. progress, if set, is called before
+// each note is converted. PDF output also needs a LaTeX engine such as
+// pdflatex, which pandoc reports when missing. Returns the paths of the
+// written files.
+func PandocDir(notes []*models.Note, dir, format string, progress func(done, total int, note *models.Note)) ([]string, error) {
+	if !isPandocFormat(format) {
+		return nil, fmt.Errorf("unsupported format %q, expected one of %s", format, strings.Join(PandocFormats, ", "))
+	}
+	pandoc, err := exec.LookPath("pandoc")
+	if err != nil {
+		return nil, ErrPandocMissing
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	taken := map[string]bool{}
+	var paths []string
+	for i, note := range notes {
+		if progress != nil {
+			progress(i, len(notes), note)
+		}
+
+		stem := utils.Slugify(note.Title)
+		name := stem + "." + format
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s-%d.%s", stem, n, format)
+		}
+		taken[name] = true
+
+		path := filepath.Join(dir, name)
+		if err := runPandoc(pandoc, note, path); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// runPandoc converts one note to the file at path, whose extension picks
+// the output format. Frontmatter is left out and the title passed as
+// metadata instead.
+func runPandoc(pandoc string, note *models.Note, path string) error {
+	content := note.Content
+	if _, body, ok := utils.ParseFrontmatter(content); ok {
+		content = body
+	}
+
+	// Relative image links resolve from the working directory, as in the
+	// preview
+	cmd := exec.Command(pandoc,
+		"--from", "gfm",
+		"--standalone",
+		"--metadata", "title="+note.Title,
+		"--resource-path", ".",
+		"--output", path)
+	cmd.Stdin = strings.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("failed to convert %q: %s", note.Title, msg)
+		}
+		return fmt.Errorf("failed to convert %q: %w", note.Title, err)
+	}
+	return nil
+}
+
+// isPandocFormat reports whether format is one of PandocFormats
+func isPandocFormat(format string) bool {
+	for _, f := range PandocFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Formats of pasted text told apart by PastedMarkdown
+const (
+	FormatPlain    = "plain text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "HTML"
+)
+
+var (
+	// htmlTag matches the tags that give away text copied as HTML
+	htmlTag = regexp.MustCompile(`(?i)</(a|b|blockquote|body|code|div|em|h[1-6]|html|i|li|ol|p|pre|span|strong|table|td|tr|ul)>|<br\s*/?>|<!doctype html`)
+
+	// markdownBlock and markdownInline match the markdown syntax that plain
+	// text rarely has
+	markdownBlock  = regexp.MustCompile("(?m)^ {0,3}(#{1,6} |[-*+] \\[[ xX]\\] |> |```|~~~|\\|.*\\|\\s*$|[-*_]{3,}\\s*$)")
+	markdownInline = regexp.MustCompile("\\[[^\\]\\n]+\\]\\([^)\\s]+\\)|\\*\\*[^*\\n]+\\*\\*|`[^`\\n]+`")
+)
+
+// PastedMarkdown converts text from the clipboard to markdown and names the
+// format it was found to be in: HTML is converted, while markdown and plain
+// text are kept as they are
+func PastedMarkdown(text string) (markdown, format string, err error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	switch {
+	case strings.HasPrefix(text, "<") && htmlTag.MatchString(text):
+		markdown, err := HTMLToMarkdown(text, nil)
+		return markdown, FormatHTML, err
+	case markdownBlock.MatchString(text) || markdownInline.MatchString(text):
+		return text, FormatMarkdown, nil
+	}
+	return text, FormatPlain, nil
+}
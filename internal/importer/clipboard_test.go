@@ -0,0 +1,25 @@
+package importer
+
+import "testing"
+
+func TestPastedMarkdown(t *testing.T) {
+	tests := []struct {
+		text, want, format string
+	}{
+		{"<p>Hello <b>world</b></p>\r\n<ul><li>one</li></ul>", "Hello **world**\n\n- one", FormatHTML},
+		{"<!DOCTYPE html><html><head><title>Page</title></head><body><h1>Hi</h1></body></html>", "# Hi", FormatHTML},
+		{"# Plan\n\n- [ ] call", "# Plan\n\n- [ ] call", FormatMarkdown},
+		{"see [docs](https://example.com)", "see [docs](https://example.com)", FormatMarkdown},
+		{"  Buy milk\nand eggs <3  \n", "Buy milk\nand eggs <3", FormatPlain},
+		{"<3 you, see you at 5", "<3 you, see you at 5", FormatPlain},
+	}
+	for _, tt := range tests {
+		got, format, err := PastedMarkdown(tt.text)
+		if err != nil {
+			t.Fatalf("PastedMarkdown(%q) failed: %v", tt.text, err)
+		}
+		if got != tt.want || format != tt.format {
+			t.Errorf("PastedMarkdown(%q) = %q, %s, want %q, %s", tt.text, got, format, tt.want, tt.format)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToMarkdown converts an HTML document or fragment to markdown.
+// Relative links and images are resolved against base when it's set.
+func HTMLToMarkdown(source string, base *url.URL) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(source), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return nodesToMarkdown(nodes, base), nil
+}
+
+// nodesToMarkdown converts HTML nodes to markdown
+func nodesToMarkdown(nodes []*html.Node, base *url.URL) string {
+	c := &converter{base: base}
+	for _, n := range nodes {
+		c.node(n)
+	}
+	return c.String()
+}
+
+// blockElements start a block of their own rather than flowing with the
+// text around them
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Body: true, atom.Center: true, atom.Dd: true, atom.Details: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Figcaption: true,
+	atom.Figure: true, atom.Footer: true, atom.Form: true, atom.H1: true,
+	atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Html: true, atom.Li: true,
+	atom.Main: true, atom.Nav: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Summary: true, atom.Table: true, atom.Ul: true,
+}
+
+// skippedElements are never converted
+var skippedElements = map[atom.Atom]bool{
+	atom.Button: true, atom.Canvas: true, atom.Head: true, atom.Iframe: true,
+	atom.Input: true, atom.Noscript: true, atom.Object: true, atom.Script: true,
+	atom.Select: true, atom.Style: true, atom.Svg: true, atom.Template: true,
+	atom.Textarea: true, atom.Title: true,
+}
+
+// converter writes HTML as markdown blocks: paragraphs, headings, lists
+// and so on, separated by blank lines
+type converter struct {
+	base   *url.URL
+	blocks []string
+	inline strings.Builder // text of the paragraph being written
+	tight  bool            // blocks are separated by a newline alone, as in list items
+}
+
+// String returns the markdown written so far
+func (c *converter) String() string {
+	c.flush()
+	if c.tight {
+		return strings.Join(c.blocks, "\n")
+	}
+	return strings.Join(c.blocks, "\n\n")
+}
+
+// flush ends the paragraph being written
+func (c *converter) flush() {
+	lines := strings.Split(c.inline.String(), "\n")
+	for i, line := range lines {
+		// Keep the two spaces of a hard line break
+		if strings.HasSuffix(line, "  ") && i < len(lines)-1 {
+			lines[i] = strings.TrimSpace(line) + "  "
+		} else {
+			lines[i] = strings.TrimSpace(line)
+		}
+	}
+	c.inline.Reset()
+	if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+		c.blocks = append(c.blocks, text)
+	}
+}
+
+// block adds a finished block
+func (c *converter) block(text string) {
+	c.flush()
+	if strings.TrimSpace(text) != "" {
+		c.blocks = append(c.blocks, text)
+	}
+}
+
+// sub returns a converter for the content of an element
+func (c *converter) sub(n *html.Node, tight bool) string {
+	inner := &converter{base: c.base, tight: tight}
+	inner.children(n)
+	return inner.String()
+}
+
+// children converts the children of n
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+// node converts n
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.DocumentNode:
+		c.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if skippedElements[n.DataAtom] || hidden(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := singleLine(c.inlineOf(n)); text != "" {
+			level := int(n.Data[1] - '0')
+			c.block(strings.Repeat("#", level) + " " + text)
+		}
+	case atom.Hr:
+		c.block("---")
+	case atom.Pre:
+		c.block(codeBlock(n))
+	case atom.Blockquote:
+		c.block(prefixLines(c.sub(n, false), "> ", ">"))
+	case atom.Ul, atom.Ol:
+		c.block(c.list(n))
+	case atom.Table:
+		c.block(c.table(n))
+	case atom.Br:
+		c.inline.WriteString("  \n")
+	case atom.Img:
+		c.inline.WriteString(c.image(n))
+	case atom.A:
+		c.link(n)
+	case atom.Strong, atom.B:
+		c.wrap(n, "**")
+	case atom.Em, atom.I, atom.Cite:
+		c.wrap(n, "*")
+	case atom.Del, atom.S, atom.Strike:
+		c.wrap(n, "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		if code := textContent(n); strings.TrimSpace(code) != "" {
+			c.inline.WriteString(inlineCode(code))
+		}
+	default:
+		if blockElements[n.DataAtom] {
+			c.flush()
+			c.children(n)
+			c.flush()
+		} else {
+			c.children(n)
+		}
+	}
+}
+
+// text writes text with its runs of whitespace collapsed to a space
+func (c *converter) text(data string) {
+	if data == "" {
+		return
+	}
+	if unicode.IsSpace(rune(data[0])) {
+		c.space()
+	}
+	text := escapeMarkdown(strings.Join(strings.Fields(data), " "))
+	c.inline.WriteString(text)
+	if text != "" && unicode.IsSpace(rune(data[len(data)-1])) {
+		c.space()
+	}
+}
+
+// space writes a space between words, unless one is already there
+func (c *converter) space() {
+	written := c.inline.String()
+	if written != "" && !strings.HasSuffix(written, " ") && !strings.HasSuffix(written, "\n") {
+		c.inline.WriteString(" ")
+	}
+}
+
+// inlineOf returns the content of n as a single paragraph
+func (c *converter) inlineOf(n *html.Node) string {
+	return strings.Join(strings.Split(c.sub(n, false), "\n\n"), " ")
+}
+
+// wrap writes the content of n between marks, keeping the spaces around
+// it outside so the emphasis still applies
+func (c *converter) wrap(n *html.Node, mark string) {
+	inner := c.inlineOf(n)
+	if strings.TrimSpace(inner) == "" {
+		if textContent(n) != "" {
+			c.space()
+		}
+		return
+	}
+	if hasSpace(n, true) {
+		c.space()
+	}
+	c.inline.WriteString(mark + strings.TrimSpace(inner) + mark)
+	if hasSpace(n, false) {
+		c.space()
+	}
+}
+
+// link writes a link, or just its text when it goes nowhere useful
+func (c *converter) link(n *html.Node) {
+	text := strings.TrimSpace(c.inlineOf(n))
+	href := strings.TrimSpace(attr(n, "href"))
+	if text == "" {
+		return
+	}
+	if hasSpace(n, true) {
+		c.space()
+	}
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:"):
+		c.inline.WriteString(text)
+	case attr(n, "title") != "":
+		c.inline.WriteString("[" + text + "](" + c.resolve(href) + " " + strconv.Quote(attr(n, "title")) + ")")
+	default:
+		c.inline.WriteString("[" + text + "](" + c.resolve(href) + ")")
+	}
+	if hasSpace(n, false) {
+		c.space()
+	}
+}
+
+// image returns the markdown of an image, or nothing for images without
+// a usable source
+func (c *converter) image(n *html.Node) string {
+	src := attr(n, "src")
+	// Lazily loaded images keep the real source in a data attribute
+	for _, lazy := range []string{"data-src", "data-original", "data-lazy-src"} {
+		if v := attr(n, lazy); v != "" && (src == "" || strings.HasPrefix(src, "data:")) {
+			src = v
+		}
+	}
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	alt := strings.Join(strings.Fields(attr(n, "alt")), " ")
+	return "![" + escapeMarkdown(alt) + "](" + c.resolve(src) + ")"
+}
+
+// list converts a ul or ol, nesting lists inside its items
+func (c *converter) list(n *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		content := c.sub(li, true)
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+prefixLines(content, indent, "")[len(indent):])
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts a table to a GitHub table, taking the first row as the
+// header
+func (c *converter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						text := singleLine(c.inlineOf(cell))
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			case atom.Table:
+				// Nested tables become part of the cell text
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolve makes a link absolute against the page it came from
+func (c *converter) resolve(href string) string {
+	ref, err := url.Parse(href)
+	if err != nil || c.base == nil {
+		return strings.ReplaceAll(href, " ", "%20")
+	}
+	return c.base.ResolveReference(ref).String()
+}
+
+// codeBlock converts a pre element to a fenced code block, naming the
+// language of a "language-*" class
+func codeBlock(n *html.Node) string {
+	code := strings.Trim(textContent(n), "\n")
+	language := ""
+	for el := n; el != nil; el = el.FirstChild {
+		for _, class := range strings.Fields(attr(el, "class")) {
+			if lang, ok := strings.CutPrefix(class, "language-"); ok && language == "" {
+				language = lang
+			}
+		}
+		if el.DataAtom != atom.Pre && el.DataAtom != atom.Code {
+			break
+		}
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// inlineCode wraps code in enough backticks to hold the backticks in it
+func inlineCode(code string) string {
+	code = strings.Join(strings.Fields(code), " ")
+	ticks := "`"
+	for strings.Contains(code, ticks) {
+		ticks += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return ticks + " " + code + " " + ticks
+	}
+	return ticks + code + ticks
+}
+
+// prefixLines prefixes every line of text, using blank for empty lines
+func prefixLines(text, prefix, blank string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blank
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// singleLine joins the lines of text with spaces
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// escapeMarkdown escapes the characters of text that markdown would read
+// as formatting. Underscores inside words, as in snake_case, are left
+// alone since they don't start emphasis.
+func escapeMarkdown(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '\\', '*', '`', '[', ']':
+			b.WriteRune('\\')
+		case '_':
+			inWord := i > 0 && i < len(runes)-1 && isWordRune(runes[i-1]) && isWordRune(runes[i+1])
+			if !inWord {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// textContent returns all the text inside n as it is in the page
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Br {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// hasSpace reports whether the text of n starts, or with leading unset
+// ends, with whitespace
+func hasSpace(n *html.Node, leading bool) bool {
+	text := textContent(n)
+	if text == "" {
+		return false
+	}
+	if leading {
+		return unicode.IsSpace(rune(text[0]))
+	}
+	return unicode.IsSpace(rune(text[len(text)-1]))
+}
+
+// attr returns an attribute of n
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hidden reports whether n is hidden from readers of the page
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if a.Val == "true" {
+				return true
+			}
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(a.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
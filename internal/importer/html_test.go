@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/one")
+	tests := []struct {
+		name, html, want string
+	}{
+		{"paragraphs", "<p>First  line\n of text.</p><p>Second</p>", "First line of text.\n\nSecond"},
+		{"headings", "<h1>Title</h1><h3>Sub <em>part</em></h3>", "# Title\n\n### Sub *part*"},
+		{"emphasis keeps spaces outside", "<p>a<strong> bold </strong>word and <code>x := 1</code></p>", "a **bold** word and `x := 1`"},
+		{"relative links resolve", `<p>See <a href="../two">the next post</a> or <a href="#top">top</a>.</p>`, "See [the next post](https://example.com/two) or top."},
+		{"images", `<img src="/a.png" alt="A chart"><img src="data:image/png;base64,xx" data-src="b.png">`, "![A chart](https://example.com/a.png)![](https://example.com/posts/b.png)"},
+		{"nested lists", "<ul><li>One<ul><li>Inner</li></ul></li><li>Two</li></ul><ol start=\"3\"><li>Three</li></ol>", "- One\n  - Inner\n- Two\n\n3. Three"},
+		{"code blocks", "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"```\")\n}</code></pre>", "````go\nfunc main() {\n\tfmt.Println(\"```\")\n}\n````"},
+		{"blockquotes", "<blockquote><p>Quoted</p><p>twice</p></blockquote>", "> Quoted\n>\n> twice"},
+		{"tables", "<table><tr><th>Name</th><th>Size</th></tr><tr><td>a|b</td><td>2</td></tr></table>", "| Name | Size |\n| --- | --- |\n| a\\|b | 2 |"},
+		{"escapes formatting", "<p>2 * 3 [x] snake_case _under_</p>", `2 \* 3 \[x\] snake_case \_under\_`},
+		{"drops scripts and hidden text", `<p>Shown<script>alert(1)</script><span style="display: none">hidden</span></p>`, "Shown"},
+		{"line breaks", "<p>one<br>two</p>", "one  \ntwo"},
+	}
+	for _, tt := range tests {
+		got, err := HTMLToMarkdown(tt.html, base)
+		if err != nil {
+			t.Fatalf("%s: HTMLToMarkdown failed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: HTMLToMarkdown = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// Result counts what an import did
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// parsedNote is a note read from a markdown file
+type parsedNote struct {
+	exported bool // file carries the identifying frontmatter an export writes
+	id       int
+	uuid     string
+	slug     string
+	title    string
+	content  string
+	notebook string
+	created  time.Time
+	updated  time.Time
+	tags     []string
+
+	// links an export rewrote to its attachments, mapped to the originals
+	attachments map[string]string
+}
+
+// importer matches imported files against the notes already in storage
+type importer struct {
+	service *storage.Service
+	byID    map[int]*models.Note
+	byUUID  map[string]*models.Note
+	bySlug  map[string]*models.Note
+}
+
+// Options adjusts how files are imported
+type Options struct {
+	// ResolveLinks points relative links to files in the import directory
+	// at their absolute paths, so images and attachments still resolve once
+	// the note lives in the database
+	ResolveLinks bool
+}
+
+// MarkdownDir imports every .md file in dir. Files exported by
+// export.MarkdownDir update the notes they came from instead of creating
+// duplicates: a note matches by UUID or, for files without one, by ID when
+// its slug matches too (so IDs from another vault are never trusted alone),
+// and otherwise by slug. A file with a UUID no note here has becomes a new
+// note keeping it. A file counts as an export when its frontmatter has a
+// uuid, or both an id and a slug; other files become new notes that keep
+// their frontmatter in the content, so a foreign file never overwrites a
+// note it shares a title with.
+func MarkdownDir(service *storage.Service, dir string) (Result, error) {
+	return MarkdownDirWith(service, dir, Options{})
+}
+
+// MarkdownDirWith imports every .md file in dir like MarkdownDir, adjusted
+// by opts. Links an export rewrote to its attachments directory are always
+// restored to the links the note had before.
+func MarkdownDirWith(service *storage.Service, dir string, opts Options) (Result, error) {
+	var result Result
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read import directory: %w", err)
+	}
+
+	imp, err := newImporter(service)
+	if err != nil {
+		return result, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parsed := parseNoteFile(entry.Name(), string(data))
+		parsed.content = restoreLinks(parsed, dir, opts)
+		created, changed, err := imp.upsert(parsed)
+		if err != nil {
+			return result, fmt.Errorf("failed to import %s: %w", path, err)
+		}
+
+		switch {
+		case created:
+			result.Created++
+		case changed:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	return result, nil
+}
+
+// newImporter loads the existing notes used for matching
+func newImporter(service *storage.Service) (*importer, error) {
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	imp := &importer{
+		service: service,
+		byID:    map[int]*models.Note{},
+		byUUID:  map[string]*models.Note{},
+		bySlug:  map[string]*models.Note{},
+	}
+	for _, note := range notes {
+		imp.index(note)
+	}
+	return imp, nil
+}
+
+// index makes a note available for matching
+func (imp *importer) index(note *models.Note) {
+	imp.byID[note.ID] = note
+	imp.byUUID[note.UUID] = note
+	slug := utils.Slugify(note.Title)
+	if _, exists := imp.bySlug[slug]; !exists {
+		imp.bySlug[slug] = note
+	}
+}
+
+// match finds the stored note an exported file came from
+func (imp *importer) match(p parsedNote) *models.Note {
+	if !p.exported {
+		return nil
+	}
+	if p.uuid != "" {
+		// A UUID not seen here belongs to a new note, even when a note
+		// here has the same title
+		return imp.byUUID[p.uuid]
+	}
+	if note, ok := imp.byID[p.id]; ok && utils.Slugify(note.Title) == p.slug {
+		return note
+	}
+	return imp.bySlug[p.slug]
+}
+
+// upsert creates or updates the note for a parsed file
+func (imp *importer) upsert(p parsedNote) (created, changed bool, err error) {
+	note := imp.match(p)
+
+	if note == nil {
+		note = &models.Note{
+			UUID:      p.uuid,
+			Title:     p.title,
+			Content:   p.content,
+			Notebook:  p.notebook,
+			CreatedAt: p.created,
+			UpdatedAt: p.updated,
+		}
+		if err := imp.service.ImportNote(note); err != nil {
+			return false, false, err
+		}
+		imp.index(note)
+		if _, err := imp.syncTags(note, p); err != nil {
+			return true, false, err
+		}
+		return true, true, nil
+	}
+
+	if note.Title != p.title || note.Content != p.content || note.Notebook != p.notebook {
+		note.Title = p.title
+		note.Content = p.content
+		note.Notebook = p.notebook
+		if err := imp.service.UpdateNote(note); err != nil {
+			return false, false, err
+		}
+		changed = true
+	}
+
+	tagsChanged, err := imp.syncTags(note, p)
+	return false, changed || tagsChanged, err
+}
+
+// syncTags makes the note's tags match the file, adding and removing tags
+// by name. Tags themselves are never renamed: a file only speaks for its
+// own note, while a tag may be on notes that weren't exported with it.
+func (imp *importer) syncTags(note *models.Note, p parsedNote) (bool, error) {
+	changed := false
+	desired := map[string]bool{}
+	for _, name := range p.tags {
+		desired[tagKey(name)] = true
+	}
+
+	current := map[string]bool{}
+	for _, tag := range note.Tags {
+		current[tagKey(tag.Name)] = true
+		if !desired[tagKey(tag.Name)] {
+			if err := imp.service.RemoveTagFromNote(note.ID, tag.ID); err != nil {
+				return changed, err
+			}
+			changed = true
+		}
+	}
+
+	for _, name := range p.tags {
+		if current[tagKey(name)] {
+			continue
+		}
+		if err := imp.service.AddTagToNote(note.ID, name); err != nil {
+			return changed, err
+		}
+		current[tagKey(name)] = true
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// tagKey returns the name tags are compared by, ignoring case and spacing
+func tagKey(name string) string {
+	return strings.ToLower(utils.NormalizeTag(name))
+}
+
+// parseNoteFile reads a note from a markdown file, using its frontmatter when
+// present and falling back to the first heading or the file name for the title.
+// Frontmatter from other tools stays part of the content.
+func parseNoteFile(name, data string) parsedNote {
+	fm, body, _ := utils.ParseFrontmatter(data)
+	p := parsedNote{content: body}
+
+	p.title, _ = fm.Get("title")
+	if p.title == "" {
+		p.title = titleFromContent(body, strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+
+	slug, hasSlug := fm.Get("slug")
+	p.slug = slug
+	if p.slug == "" {
+		p.slug = utils.Slugify(p.title)
+	}
+
+	id, hasID := fm.Get("id")
+	if hasID {
+		p.id, _ = strconv.Atoi(id)
+	}
+	if uuid, ok := fm.Get("uuid"); ok && utils.IsUUID(uuid) {
+		p.uuid = strings.ToLower(uuid)
+	}
+
+	p.exported = p.uuid != "" || hasID && hasSlug
+	if !p.exported {
+		p.content = data
+	}
+	p.notebook, _ = fm.Get("notebook")
+
+	if created, ok := fm.Get("created"); ok {
+		p.created, _ = time.Parse(time.RFC3339, created)
+	}
+	if updated, ok := fm.Get("updated"); ok {
+		p.updated, _ = time.Parse(time.RFC3339, updated)
+	}
+
+	targets, sources := fm.List("attachments"), fm.List("attachment_sources")
+	if len(targets) == len(sources) && len(targets) > 0 {
+		p.attachments = map[string]string{}
+		for i, target := range targets {
+			p.attachments[target] = sources[i]
+		}
+	}
+
+	p.tags = fm.List("tags")
+
+	return p
+}
+
+// titleFromContent returns the text of the first "# " heading, or fallback
+func titleFromContent(content, fallback string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return fallback
+}
+
+// restoreLinks returns the note's content with links to exported
+// attachments pointed back at the original files and, when opts asks for
+// it, relative links to files in dir made absolute
+func restoreLinks(p parsedNote, dir string, opts Options) string {
+	if p.attachments == nil && !opts.ResolveLinks {
+		return p.content
+	}
+	return utils.RewriteLocalLinks(p.content, func(target string) string {
+		if source, ok := p.attachments[target]; ok {
+			return source
+		}
+		if !opts.ResolveLinks || filepath.IsAbs(target) || strings.HasPrefix(target, "~") {
+			return target
+		}
+
+		path := target
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			path = unescaped
+		}
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			return target
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return target
+	})
+}
@@ -0,0 +1,325 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdown-note-taking-app/internal/export"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := service.CreateNote("Weekly plan", "- ship import")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(note.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	other, err := service.CreateNote("Standup", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(other.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+
+	// Only the first note is exported
+	exportDir := filepath.Join(dir, "export")
+	exported, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	paths, err := export.MarkdownDir([]*models.Note{exported}, exportDir)
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("Failed to export: %v (%d files)", err, len(paths))
+	}
+
+	// Importing an untouched export changes nothing
+	result, err := MarkdownDir(service, exportDir)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result != (Result{Unchanged: 1}) {
+		t.Errorf("Expected an unchanged note, got %+v", result)
+	}
+
+	// Edit the file externally: new content, renamed tag, extra tag
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	edited := strings.Replace(string(data), "- ship import", "- ship import\n- write docs", 1)
+	edited = strings.Replace(edited, "tags: [work]", "tags: [job, urgent]", 1)
+	if err := os.WriteFile(paths[0], []byte(edited), 0644); err != nil {
+		t.Fatalf("Failed to edit export: %v", err)
+	}
+
+	// A file without frontmatter becomes a new note
+	if err := os.WriteFile(filepath.Join(exportDir, "loose.md"), []byte("# Loose idea\n\ntext"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err = MarkdownDir(service, exportDir)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result != (Result{Created: 1, Updated: 1}) {
+		t.Errorf("Expected one created and one updated note, got %+v", result)
+	}
+
+	updated, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if updated.Content != "- ship import\n- write docs" {
+		t.Errorf("Unexpected content %q", updated.Content)
+	}
+	var names []string
+	for _, tag := range updated.Tags {
+		names = append(names, tag.Name)
+	}
+	if strings.Join(names, ",") != "job,urgent" {
+		t.Errorf("Expected tags job,urgent, got %v", names)
+	}
+
+	// Only the imported note's tags changed; the other note keeps work
+	untouched, err := service.GetNote(other.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if len(untouched.Tags) != 1 || untouched.Tags[0].Name != "work" {
+		t.Errorf("Expected the other note to keep its work tag, got %v", untouched.Tags)
+	}
+}
+
+func TestAttachmentsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	// Two different files share a name; a third link points nowhere
+	for _, sub := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, sub, "chart.png"), []byte(sub), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	content := "![a](" + filepath.Join(dir, "a", "chart.png") + ")\n" +
+		"![b](" + filepath.Join(dir, "b", "chart.png") + ")\n" +
+		"[gone](" + filepath.Join(dir, "missing.pdf") + ")"
+	if _, err := service.CreateNote("Report", content); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	exportDir := filepath.Join(dir, "export")
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	paths, err := export.MarkdownDir(notes, exportDir)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	exported := string(data)
+	if !strings.Contains(exported, "![a](attachments/chart.png)") || !strings.Contains(exported, "![b](attachments/chart-2.png)") {
+		t.Errorf("Expected links to the copied attachments, got:\n%s", exported)
+	}
+	if !strings.Contains(exported, "missing.pdf") {
+		t.Errorf("Expected the link to a missing file kept, got:\n%s", exported)
+	}
+	if copied, err := os.ReadFile(filepath.Join(exportDir, "attachments", "chart-2.png")); err != nil || string(copied) != "b" {
+		t.Errorf("Expected the second file copied, got %q (%v)", copied, err)
+	}
+
+	// Importing the export restores the original links
+	result, err := MarkdownDirWith(service, exportDir, Options{ResolveLinks: true})
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result != (Result{Unchanged: 1}) {
+		t.Errorf("Expected an unchanged note, got %+v", result)
+	}
+
+	// Relative links of other files are resolved against the import directory
+	looseDir := filepath.Join(dir, "loose")
+	if err := os.MkdirAll(filepath.Join(looseDir, "img"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(looseDir, "img", "photo.png"), []byte("p"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(looseDir, "trip.md"), []byte("# Trip\n\n![photo](img/photo.png) [web](https://x.io)"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := MarkdownDirWith(service, looseDir, Options{ResolveLinks: true}); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	notes, err = service.SearchNotes("title:trip", 0)
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("Expected the imported note, got %d (%v)", len(notes), err)
+	}
+	want := "![photo](" + filepath.Join(looseDir, "img", "photo.png") + ") [web](https://x.io)"
+	if !strings.Contains(notes[0].Content, want) {
+		t.Errorf("Expected %q in %q", want, notes[0].Content)
+	}
+}
+
+func TestMatchByUUID(t *testing.T) {
+	dir := t.TempDir()
+	source, err := storage.NewService(filepath.Join(dir, "source.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer source.Close()
+	target, err := storage.NewService(filepath.Join(dir, "target.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer target.Close()
+
+	note, err := source.CreateNote("Recipes", "pancakes")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	exportDir := filepath.Join(dir, "export")
+	if _, err := export.MarkdownDir([]*models.Note{note}, exportDir); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// The note keeps its UUID in another database
+	if result, err := MarkdownDir(target, exportDir); err != nil || result != (Result{Created: 1}) {
+		t.Fatalf("Expected one note created, got %+v, %v", result, err)
+	}
+	imported, err := target.GetNoteByUUID(note.UUID)
+	if err != nil {
+		t.Fatalf("Expected the UUID to be kept: %v", err)
+	}
+
+	// A renamed copy still updates the same note there
+	note.Title = "Breakfast recipes"
+	if err := source.UpdateNote(note); err != nil {
+		t.Fatalf("Failed to rename note: %v", err)
+	}
+	renamedDir := filepath.Join(dir, "renamed")
+	if _, err := export.MarkdownDir([]*models.Note{note}, renamedDir); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if result, err := MarkdownDir(target, renamedDir); err != nil || result != (Result{Updated: 1}) {
+		t.Fatalf("Expected one note updated, got %+v, %v", result, err)
+	}
+	if renamed, err := target.GetNote(imported.ID); err != nil || renamed.Title != "Breakfast recipes" {
+		t.Errorf("Expected the imported note renamed, got %+v, %v", renamed, err)
+	}
+}
+
+func TestForeignFrontmatterCreatesNote(t *testing.T) {
+	dir := t.TempDir()
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := service.CreateNote("Reading list", "keep me")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(note.ID, "books"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+
+	// A Jekyll-style file sharing the note's title is not an export
+	importDir := filepath.Join(dir, "import")
+	if err := os.MkdirAll(importDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	foreign := "---\ntitle: Reading list\nlayout: post\n---\nsomeone else's list"
+	if err := os.WriteFile(filepath.Join(importDir, "reading.md"), []byte(foreign), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if result, err := MarkdownDir(service, importDir); err != nil || result != (Result{Created: 1}) {
+		t.Fatalf("Expected one note created, got %+v, %v", result, err)
+	}
+
+	original, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if original.Content != "keep me" || len(original.Tags) != 1 {
+		t.Errorf("Expected the existing note untouched, got %q with tags %v", original.Content, original.Tags)
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list notes: %v", err)
+	}
+	var created *models.Note
+	for _, n := range notes {
+		if n.ID != note.ID {
+			created = n
+		}
+	}
+	if created == nil || created.Content != foreign {
+		t.Errorf("Expected a new note keeping its frontmatter, got %+v", created)
+	}
+}
+
+func TestDifferentUUIDSameTitle(t *testing.T) {
+	dir := t.TempDir()
+	source, err := storage.NewService(filepath.Join(dir, "source.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer source.Close()
+	target, err := storage.NewService(filepath.Join(dir, "target.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer target.Close()
+
+	note, err := source.CreateNote("Recipes", "pancakes")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	local, err := target.CreateNote("Recipes", "soup")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	exportDir := filepath.Join(dir, "export")
+	if _, err := export.MarkdownDir([]*models.Note{note}, exportDir); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// The notes only share a title, so the file becomes a note of its own
+	if result, err := MarkdownDir(target, exportDir); err != nil || result != (Result{Created: 1}) {
+		t.Fatalf("Expected one note created, got %+v, %v", result, err)
+	}
+	if kept, err := target.GetNote(local.ID); err != nil || kept.Content != "soup" || kept.UUID != local.UUID {
+		t.Errorf("Expected the local note untouched, got %+v, %v", kept, err)
+	}
+	if imported, err := target.GetNoteByUUID(note.UUID); err != nil || imported.Content != "pancakes" {
+		t.Errorf("Expected a new note keeping the file's UUID, got %+v, %v", imported, err)
+	}
+}
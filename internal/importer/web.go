@@ -0,0 +1,347 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// ClippedTag is the tag of notes clipped from web pages
+const ClippedTag = "clipped"
+
+// maxPageSize is the most of a web page read when clipping it
+const maxPageSize = 10 << 20
+
+// clipClient fetches the pages clipped
+var clipClient = &http.Client{Timeout: 30 * time.Second}
+
+// Article is the main text of a web page
+type Article struct {
+	Title    string
+	Markdown string
+	URL      string // after any redirects
+}
+
+// Clip fetches a web page and saves its article as a note tagged
+// ClippedTag, with the page's address in the note's frontmatter
+func Clip(ctx context.Context, service *storage.Service, pageURL string) (*models.Note, error) {
+	article, err := FetchArticle(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var fm utils.Frontmatter
+	fm.Set("source", article.URL)
+	fm.SetList("tags", []string{ClippedTag})
+	title := article.Title
+	if title == "" {
+		title = article.URL
+	}
+	return service.CreateNote(title, fm.String()+"\n"+article.Markdown+"\n")
+}
+
+// FetchArticle fetches a web page and extracts its article
+func FetchArticle(ctx context.Context, pageURL string) (Article, error) {
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Scheme != "http" && page.Scheme != "https" || page.Host == "" {
+		return Article{}, fmt.Errorf("invalid URL %q: expected an http or https address", pageURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page.String(), nil)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "tuinotes")
+	resp, err := clipClient.Do(req)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Article{}, fmt.Errorf("failed to fetch %s: %s", pageURL, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); contentType != "" && !strings.Contains(mediaType, "html") {
+		return Article{}, fmt.Errorf("failed to clip %s: not a web page but %s", pageURL, mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageSize), contentType)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	return ParseArticle(body, resp.Request.URL)
+}
+
+// ParseArticle extracts the article of an HTML page as markdown. Like
+// reader modes, it scores the elements of the page by the paragraphs of
+// text they hold, takes the best and leaves out navigation, sidebars,
+// comments and the like. Links are resolved against page.
+func ParseArticle(r io.Reader, page *url.URL) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	article := Article{Title: pageTitle(doc)}
+	if page != nil {
+		article.URL = page.String()
+	}
+	removeClutter(doc)
+	article.Markdown = nodesToMarkdown(articleNodes(doc), page)
+	if strings.TrimSpace(article.Markdown) == "" {
+		return Article{}, fmt.Errorf("failed to find any text in the page")
+	}
+	return article, nil
+}
+
+// pageTitle returns the title of a page, preferring the title meant for
+// sharing, which usually leaves out the site's name
+func pageTitle(doc *html.Node) string {
+	var ogTitle, title, heading string
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Meta:
+			if property := attr(n, "property"); property == "og:title" || attr(n, "name") == "twitter:title" {
+				if ogTitle == "" {
+					ogTitle = singleLine(attr(n, "content"))
+				}
+			}
+		case atom.Title:
+			if title == "" {
+				title = singleLine(textContent(n))
+			}
+		case atom.H1:
+			if heading == "" {
+				heading = singleLine(textContent(n))
+			}
+		}
+		return true
+	})
+	switch {
+	case ogTitle != "":
+		return ogTitle
+	case title != "":
+		// Drop the site name from "Article title | Site"
+		for _, separator := range []string{" | ", " - ", " – ", " — ", " · "} {
+			if i := strings.LastIndex(title, separator); i > 0 && len(strings.Fields(title[:i])) >= 3 {
+				return title[:i]
+			}
+		}
+		return title
+	}
+	return heading
+}
+
+var (
+	// unlikelyContent matches the classes and IDs of page parts that aren't
+	// the article, unless they also match maybeContent
+	unlikelyContent = regexp.MustCompile(`(?i)-ad-|banner|breadcrumb|combx|comment|community|cookie|consent|disqus|footer|gdpr|header|menu|modal|nav|newsletter|pager|popup|promo|related|remark|share|shoutbox|sidebar|social|sponsor|subscribe|tweet`)
+	maybeContent    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	// positiveContent and negativeContent adjust the scores of elements by
+	// their classes and IDs
+	positiveContent = regexp.MustCompile(`(?i)article|body|content|entry|h-entry|hentry|main|page|post|story|text`)
+	negativeContent = regexp.MustCompile(`(?i)-ad-|byline|comment|contact|foot|hidden|masthead|media|meta|promo|related|scroll|share|shopping|sidebar|sponsor|tags|tool|widget`)
+)
+
+// clutterElements are left out of every article
+var clutterElements = map[atom.Atom]bool{
+	atom.Aside: true, atom.Button: true, atom.Footer: true, atom.Form: true,
+	atom.Header: true, atom.Iframe: true, atom.Nav: true, atom.Noscript: true,
+	atom.Script: true, atom.Select: true, atom.Style: true, atom.Svg: true,
+	atom.Textarea: true,
+}
+
+// removeClutter removes the elements that are never part of the article
+func removeClutter(doc *html.Node) {
+	var remove []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || n.DataAtom == atom.Html || n.DataAtom == atom.Body {
+			return true
+		}
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+			return true
+		}
+		names := attr(n, "class") + " " + attr(n, "id")
+		if clutterElements[n.DataAtom] || hidden(n) || attr(n, "role") == "navigation" ||
+			unlikelyContent.MatchString(names) && !maybeContent.MatchString(names) {
+			remove = append(remove, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// articleNodes picks the elements of a page that make up its article: the
+// best scoring element and the siblings that look like more of it. Each
+// paragraph scores for its length and commas, and passes its score on to
+// its parent and, less of it, to its grandparents. An element's score is
+// then cut by how much of its text is links.
+func articleNodes(doc *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	walk(doc, func(n *html.Node) bool {
+		if !scoresText(n) {
+			return true
+		}
+		text := singleLine(textContent(n))
+		if len(text) < 25 {
+			return true
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text)/100), 3)
+		ancestor := n.Parent
+		for level := 0; level < 3 && ancestor != nil && ancestor.Type == html.ElementNode; level++ {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+			}
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / float64(level*3)
+			}
+			ancestor = ancestor.Parent
+		}
+		return true
+	})
+
+	var top *html.Node
+	for n, score := range scores {
+		scores[n] = score * (1 - linkDensity(n))
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+	if top == nil || top.Parent == nil {
+		if body := findElement(doc, atom.Body); body != nil {
+			return []*html.Node{body}
+		}
+		return []*html.Node{doc}
+	}
+
+	// Keep siblings that score well or are paragraphs of plain text, as
+	// when an article is split across several containers
+	threshold := max(10, scores[top]*0.2)
+	var nodes []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		switch {
+		case sibling == top:
+			nodes = append(nodes, sibling)
+		case sibling.Type != html.ElementNode:
+		case scores[sibling] >= threshold:
+			nodes = append(nodes, sibling)
+		case sibling.DataAtom == atom.P:
+			text := singleLine(textContent(sibling))
+			if len(text) > 80 && linkDensity(sibling) < 0.25 || len(text) > 0 && linkDensity(sibling) == 0 && strings.Contains(text, ". ") {
+				nodes = append(nodes, sibling)
+			}
+		}
+	}
+	return nodes
+}
+
+// scoresText reports whether n is a paragraph whose text counts towards
+// the score of its ancestors: a p, pre or td, or a div holding text
+// without blocks of its own
+func scoresText(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td:
+		return true
+	case atom.Div:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && blockElements[child.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore scores an element by its kind and its classes and ID
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if positiveContent.MatchString(name) {
+			score += 25
+		}
+		if negativeContent.MatchString(name) {
+			score -= 25
+		}
+	}
+	return score
+}
+
+// linkDensity returns the share of the text of n that is in links
+func linkDensity(n *html.Node) float64 {
+	total := len(singleLine(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	walk(n, func(child *html.Node) bool {
+		if child.DataAtom == atom.A {
+			linked += len(singleLine(textContent(child)))
+			return false
+		}
+		return true
+	})
+	return float64(linked) / float64(total)
+}
+
+// findElement returns the first element of a kind in the tree under n
+func findElement(n *html.Node, kind atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(child *html.Node) bool {
+		if found == nil && child.DataAtom == kind {
+			found = child
+		}
+		return found == nil
+	})
+	return found
+}
+
+// walk visits n and the tree under it in document order, skipping the
+// children of nodes visit returns false for
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+)
+
+const clipPage = `<!DOCTYPE html>
+<html><head>
+<title>Growing tomatoes on a balcony | Garden Weekly</title>
+<script>var tracking = true;</script>
+</head><body>
+<header><nav><a href="/">Home</a> <a href="/about">About</a></nav></header>
+<div class="sidebar"><p>Popular posts, more posts, even more posts, and the rest of them.</p></div>
+<div id="main-content" class="post">
+  <h2>Start with the pot</h2>
+  <p>Tomatoes need room for their roots, so pick a pot of at least twenty litres, with holes in the bottom.</p>
+  <p>Water deeply, in the morning, and keep the soil moist but never soaked, or the fruit will split.</p>
+  <p>See <a href="/guides/soil">our soil guide</a> for what to fill it with.</p>
+</div>
+<div class="comments"><p>Great post, thanks for sharing, I will try this, for sure, this summer.</p></div>
+<footer><p>Copyright Garden Weekly, all rights reserved, since forever.</p></footer>
+</body></html>`
+
+func TestClip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/tomatoes", http.StatusMovedPermanently)
+		case "/tomatoes":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, clipPage)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, "{}")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	service, err := storage.NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := Clip(context.Background(), service, server.URL+"/old")
+	if err != nil {
+		t.Fatalf("Clip failed: %v", err)
+	}
+	if note.Title != "Growing tomatoes on a balcony" {
+		t.Errorf("Title = %q, want the page title without the site name", note.Title)
+	}
+
+	fm, body, ok := utils.ParseFrontmatter(note.Content)
+	if source, _ := fm.Get("source"); !ok || source != server.URL+"/tomatoes" {
+		t.Errorf("source = %q, want the page's address after redirects", source)
+	}
+	for _, want := range []string{"## Start with the pot", "at least twenty litres", "[our soil guide](" + server.URL + "/guides/soil)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("clipped body lacks %q:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"Home", "Popular posts", "Great post", "Copyright", "tracking"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("clipped body has %q from outside the article:\n%s", unwanted, body)
+		}
+	}
+
+	saved, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if len(saved.Tags) != 1 || saved.Tags[0].Name != ClippedTag {
+		t.Errorf("Tags = %v, want only %q", saved.Tags, ClippedTag)
+	}
+
+	for _, path := range []string{"/missing", "/data.json"} {
+		if _, err := Clip(context.Background(), service, server.URL+path); err == nil {
+			t.Errorf("Clip(%s) succeeded, want an error", path)
+		}
+	}
+	if _, err := Clip(context.Background(), service, "ftp://example.com/file"); err == nil {
+		t.Error("Clip of a non-web URL succeeded, want an error")
+	}
+}
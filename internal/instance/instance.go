@@ -0,0 +1,180 @@
+package instance
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrRunning is returned when another instance has the database open
+var ErrRunning = errors.New("tuinotes is already running with this database")
+
+// timeout bounds how long a request to the running instance may take
+const timeout = 5 * time.Second
+
+// request asks the running instance to open a note
+type request struct {
+	Open string `json:"open"`
+}
+
+// reply answers a request; Error is empty when it succeeded
+type reply struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Server holds the socket that marks a database as open in this instance
+// and answers requests from later invocations
+type Server struct {
+	mu       sync.Mutex
+	listener net.Listener
+	open     func(title string) error
+}
+
+// SocketPath returns the socket of the instance with dbPath open, in
+// XDG_RUNTIME_DIR or else the temporary directory
+func SocketPath(dbPath string) string {
+	if abs, err := filepath.Abs(dbPath); err == nil {
+		dbPath = abs
+	}
+	sum := sha256.Sum256([]byte(dbPath))
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("tuinotes-%d-%s.sock", os.Getuid(), hex.EncodeToString(sum[:8])))
+}
+
+// Listen claims dbPath for this instance. It returns ErrRunning when
+// another instance has claimed it. A socket left behind by an instance
+// that crashed is replaced.
+func Listen(dbPath string) (*Server, error) {
+	s := &Server{}
+	listener, err := listen(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	s.listener = listener
+	go s.serve(listener)
+	return s, nil
+}
+
+// listen opens the socket for dbPath
+func listen(dbPath string) (net.Listener, error) {
+	path := SocketPath(dbPath)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		// Only a socket nobody answers on may be replaced
+		if conn, dialErr := net.DialTimeout("unix", path, timeout); dialErr == nil {
+			conn.Close()
+			return nil, ErrRunning
+		}
+		os.Remove(path)
+		if listener, err = net.Listen("unix", path); err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+		}
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to secure %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// Handle sets the function that opens the note titled title for a
+// request. Requests fail until it's set.
+func (s *Server) Handle(open func(title string) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.open = open
+}
+
+// Move claims dbPath instead of the database claimed before, as when
+// switching vaults. On error the previous claim is kept.
+func (s *Server) Move(dbPath string) error {
+	listener, err := listen(dbPath)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.listener
+	s.listener = listener
+	s.mu.Unlock()
+
+	previous.Close()
+	go s.serve(listener)
+	return nil
+}
+
+// Close releases the claim on the database
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listener.Close()
+}
+
+// serve answers requests on listener until it's closed
+func (s *Server) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.answer(conn)
+	}
+}
+
+// answer handles one request
+func (s *Server) answer(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	var req request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(reply{Error: "invalid request"})
+		return
+	}
+
+	s.mu.Lock()
+	open := s.open
+	s.mu.Unlock()
+
+	var resp reply
+	if open == nil {
+		resp.Error = "tuinotes is still starting"
+	} else if err := open(req.Open); err != nil {
+		resp.Error = err.Error()
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// Open asks the instance with dbPath open to open the note titled title.
+// It reports false when no instance is running.
+func Open(dbPath, title string) (bool, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(dbPath), timeout)
+	if err != nil {
+		return false, nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(request{Open: title}); err != nil {
+		return true, fmt.Errorf("failed to send request: %w", err)
+	}
+	var resp reply
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return true, fmt.Errorf("failed to read reply: %w", err)
+	}
+	if resp.Error != "" {
+		return true, errors.New(resp.Error)
+	}
+	return true, nil
+}
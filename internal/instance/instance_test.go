@@ -0,0 +1,80 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestSingleInstance(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "notes.db")
+
+	if running, err := Open(dbPath, "Plan"); running || err != nil {
+		t.Errorf("Expected no instance to forward to, got %v, %v", running, err)
+	}
+
+	server, err := Listen(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer server.Close()
+	if _, err := Listen(dbPath); !errors.Is(err, ErrRunning) {
+		t.Errorf("Expected a second instance to be refused, got %v", err)
+	}
+
+	// Requests reach the handler, and its errors come back
+	if _, err := Open(dbPath, "Plan"); err == nil {
+		t.Error("Expected a request before the handler is set to fail")
+	}
+	var opened []string
+	server.Handle(func(title string) error {
+		if title == "Missing" {
+			return fmt.Errorf("no note titled %q", title)
+		}
+		opened = append(opened, title)
+		return nil
+	})
+	if running, err := Open(dbPath, "Plan"); !running || err != nil {
+		t.Errorf("Expected the request to be forwarded, got %v, %v", running, err)
+	}
+	if _, err := Open(dbPath, "Missing"); err == nil || err.Error() != `no note titled "Missing"` {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+	if len(opened) != 1 || opened[0] != "Plan" {
+		t.Errorf("Expected Plan to be opened, got %v", opened)
+	}
+
+	// Moving to another database releases the first
+	other := filepath.Join(t.TempDir(), "other.db")
+	if err := server.Move(other); err != nil {
+		t.Fatalf("Failed to move: %v", err)
+	}
+	if running, _ := Open(dbPath, "Plan"); running {
+		t.Error("Expected the first database to be released")
+	}
+	if running, _ := Open(other, "Plan"); !running {
+		t.Error("Expected the other database to be claimed")
+	}
+}
+
+func TestStaleSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "notes.db")
+
+	// A crashed instance leaves its socket behind
+	listener, err := net.Listen("unix", SocketPath(dbPath))
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	server, err := Listen(dbPath)
+	if err != nil {
+		t.Fatalf("Expected the stale socket to be replaced, got %v", err)
+	}
+	server.Close()
+}
@@ -0,0 +1,282 @@
+// Package mdformat tidies the markdown of notes: headings, trailing spaces,
+// list markers and long lines. Code, tables, HTML and frontmatter are kept
+// exactly as written.
+package mdformat
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"markdown-note-taking-app/internal/utils"
+)
+
+// OptOutKey is the frontmatter field that, set to false, leaves a note's
+// formatting alone
+const OptOutKey = "format"
+
+// Options adjusts how notes are formatted
+type Options struct {
+	// Width wraps lines of text longer than this many characters at
+	// spaces. Lines are only ever broken, never joined. 0 doesn't wrap.
+	Width int
+
+	// ListMarker is the bullet of unordered list items: "-", "*" or "+".
+	// Empty keeps each item's own.
+	ListMarker string
+}
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*))?$`)
+	closingHashes  = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	setextLine     = regexp.MustCompile(`^ {0,3}(={3,}|-{3,})[ \t]*$`)
+	thematicBreak  = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	linkDefinition = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	fenceLine      = regexp.MustCompile("^([ \t]*)(`{3,}|~{3,}|\\$\\$)")
+
+	// blockStart matches text that would start a block of its own at the
+	// beginning of a line: a heading, list item, quote, fence, table or
+	// HTML
+	blockStart = regexp.MustCompile("^[ \t]*(#{1,6}([ \t]|$)|[-+*]([ \t]|$)|\\d{1,9}[.)]([ \t]|$)|>|```|~~~|\\$\\$|\\||<[a-zA-Z/!]|=+[ \t]*$|-+[ \t]*$)")
+)
+
+// OptedOut reports whether a note's frontmatter turns formatting off, as
+// with "format: false"
+func OptedOut(content string) bool {
+	fm, _, ok := utils.ParseFrontmatter(content)
+	if !ok {
+		return false
+	}
+	value, _ := fm.Get(OptOutKey)
+	switch strings.ToLower(value) {
+	case "false", "no", "off":
+		return true
+	}
+	return false
+}
+
+// Format returns content tidied up:
+//   - "#" headings have one space after the hashes and no closing ones,
+//     underlined headings become "#" headings, and headings have a blank
+//     line before and after them
+//   - trailing spaces are trimmed, a hard line break of two or more
+//     spaces becoming a backslash
+//   - runs of blank lines are cut to one
+//   - bullets use opts.ListMarker and numbered items end in "."
+//   - lines of text longer than opts.Width are wrapped
+//
+// Notes that opt out in their frontmatter are returned unchanged.
+func Format(content string, opts Options) string {
+	if OptedOut(content) {
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	front := ""
+	if _, body, ok := utils.ParseFrontmatter(content); ok && strings.HasSuffix(content, body) {
+		front = content[:len(content)-len(body)]
+		content = body
+	}
+
+	f := &formatter{opts: opts}
+	f.format(strings.Split(content, "\n"))
+	lines := f.out
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return front
+	}
+	return front + strings.Join(lines, "\n") + "\n"
+}
+
+// formatter formats the lines of a note into out
+type formatter struct {
+	opts      Options
+	out       []string
+	inList    bool // within a list, where indented lines continue its items
+	needBlank bool // a heading was just written and wants a blank line after it
+}
+
+// emit writes lines, keeping one blank line after a heading
+func (f *formatter) emit(lines ...string) {
+	for _, line := range lines {
+		if f.needBlank && line != "" && !f.lastBlank() {
+			f.out = append(f.out, "")
+		}
+		f.needBlank = false
+		f.out = append(f.out, line)
+	}
+}
+
+// lastBlank reports whether nothing or a blank line was written last
+func (f *formatter) lastBlank() bool {
+	return len(f.out) == 0 || f.out[len(f.out)-1] == ""
+}
+
+// format formats lines
+func (f *formatter) format(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		next := ""
+		if i+1 < len(lines) {
+			next = lines[i+1]
+		}
+
+		// Code and math are kept exactly as written
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			end := i
+			if m[2] != "$$" || strings.Count(trimmed, "$$") < 2 {
+				end = closingFence(lines, i, m[2])
+			}
+			f.emit(lines[i : end+1]...)
+			i = end
+			continue
+		}
+		if trimmed == "" {
+			if !f.lastBlank() {
+				f.out = append(f.out, "")
+			}
+			continue
+		}
+		if !f.inList && f.lastBlank() && utils.IndentWidth(leadingSpace(line)) >= 4 {
+			end := i
+			for end+1 < len(lines) && (strings.TrimSpace(lines[end+1]) == "" || utils.IndentWidth(leadingSpace(lines[end+1])) >= 4) {
+				end++
+			}
+			for end > i && strings.TrimSpace(lines[end]) == "" {
+				end--
+			}
+			f.emit(lines[i : end+1]...)
+			i = end
+			continue
+		}
+
+		if _, ok := utils.ParseListItem(line); ok && !thematicBreak.MatchString(line) {
+			f.inList = true
+		} else if f.lastBlank() && leadingSpace(line) == "" {
+			f.inList = false
+		}
+
+		switch {
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			f.heading(len(m[1]), closingHashes.ReplaceAllString(strings.TrimSpace(m[2]), ""))
+		case setextLine.MatchString(next) && f.lastBlank() && !blockStart.MatchString(line) && !f.inList:
+			level := 1
+			if strings.TrimSpace(next)[0] == '-' {
+				level = 2
+			}
+			f.heading(level, trimmed)
+			i++
+		case thematicBreak.MatchString(line), linkDefinition.MatchString(line),
+			strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, "<"):
+			f.emit(strings.TrimRight(line, " \t"))
+		default:
+			f.text(line, next)
+		}
+	}
+}
+
+// heading writes a "#" heading with a blank line before it
+func (f *formatter) heading(level int, text string) {
+	f.inList = false
+	if !f.lastBlank() {
+		f.out = append(f.out, "")
+	}
+	heading := strings.Repeat("#", level)
+	if text != "" {
+		heading += " " + text
+	}
+	f.emit(heading)
+	f.needBlank = true
+}
+
+// text writes a line of text, a list item or a quote
+func (f *formatter) text(line, next string) {
+	text := strings.TrimRight(line, " \t")
+	hardBreak := strings.HasSuffix(line, "  ") && !strings.HasSuffix(text, `\`)
+	if hardBreak && strings.TrimSpace(next) != "" && !blockStart.MatchString(next) {
+		text += `\`
+	}
+	f.emit(f.wrap(f.listMarker(text))...)
+}
+
+// listMarker gives a list item line the configured marker
+func (f *formatter) listMarker(line string) string {
+	item, ok := utils.ParseListItem(line)
+	if !ok {
+		return line
+	}
+	at := len(item.Indent)
+	switch {
+	case item.Ordered && item.Delim == ")":
+		at += len(item.Marker) - 1
+		return line[:at] + "." + line[at+1:]
+	case !item.Ordered && f.opts.ListMarker != "":
+		return line[:at] + f.opts.ListMarker + line[at+1:]
+	}
+	return line
+}
+
+// wrap breaks a line longer than the width at spaces. Continuation lines
+// keep the line's quote markers and indentation, lined up with the text
+// of a list item.
+func (f *formatter) wrap(line string) []string {
+	if f.opts.Width <= 0 || utf8.RuneCountInString(line) <= f.opts.Width {
+		return []string{line}
+	}
+
+	_, inner := utils.BlockquoteDepth(line)
+	quote := line[:len(line)-len(inner)]
+	if !strings.HasPrefix(strings.TrimSpace(line), ">") {
+		quote = ""
+		inner = line
+	}
+	first, continuation := quote+leadingSpace(inner), quote+leadingSpace(inner)
+	if item, ok := utils.ParseListItem(inner); ok {
+		first = quote + inner[:utils.ListMarkerEnd(inner)]
+		continuation = quote + strings.Repeat(" ", len(item.Indent)+len(item.Marker)+1)
+	}
+	words := strings.Fields(strings.TrimPrefix(line, first))
+	if len(words) == 0 {
+		return []string{line}
+	}
+
+	var lines []string
+	current := first + words[0]
+	for _, word := range words[1:] {
+		// A word that would start a heading, list or the like on a line
+		// of its own stays where it is
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > f.opts.Width && !blockStart.MatchString(word) {
+			lines = append(lines, current)
+			current = continuation + word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
+
+// closingFence returns the index of the line closing the fence opened at
+// start, or the last line when it's never closed
+func closingFence(lines []string, start int, fence string) int {
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if fence == "$$" {
+			if strings.HasSuffix(trimmed, "$$") {
+				return i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// leadingSpace returns the whitespace a line starts with
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,69 @@
+package mdformat
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		opts           Options
+	}{
+		{
+			name: "headings",
+			in:   "#  Title ##\nText\n\nSub\n---\n###\n#tag stays",
+			want: "# Title\n\nText\n\n## Sub\n\n###\n\n#tag stays\n",
+		},
+		{
+			name: "trailing spaces and blank lines",
+			in:   "one  \ntwo \n\n\n\nthree\t\n\n",
+			want: "one\\\ntwo\n\nthree\n",
+		},
+		{
+			name: "list markers",
+			in:   "* one\n  + nested\n2) two\n* * *\n**bold** line",
+			want: "- one\n  - nested\n2. two\n* * *\n**bold** line\n",
+			opts: Options{ListMarker: "-"},
+		},
+		{
+			name: "wrapping",
+			in:   "The quick brown fox jumps over the lazy dog\n- [ ] a task that is long enough to wrap\n> quoted text that goes on and on",
+			want: "The quick brown fox\njumps over the lazy\ndog\n- [ ] a task that is\n  long enough to\n  wrap\n> quoted text that\n> goes on and on\n",
+			opts: Options{Width: 20},
+		},
+		{
+			name: "wrapping never starts a block",
+			in:   "costs more than 10 - 12 dollars",
+			want: "costs more than 10 -\n12 dollars\n",
+			opts: Options{Width: 18},
+		},
+		{
+			name: "code and tables are kept",
+			in:   "```\n#not a heading   \n* x\n```\n\n    indented  code\n\n| a  |  b |   \n$$\nx  \n$$",
+			want: "```\n#not a heading   \n* x\n```\n\n    indented  code\n\n| a  |  b |\n$$\nx  \n$$\n",
+			opts: Options{Width: 10, ListMarker: "+"},
+		},
+		{
+			name: "frontmatter is kept",
+			in:   "---\ntitle:   x\n---\n#  Body",
+			want: "---\ntitle:   x\n---\n# Body\n",
+		},
+		{
+			name: "opting out",
+			in:   "---\nformat: false\n---\n#  Left   \n\n\n",
+			want: "---\nformat: false\n---\n#  Left   \n\n\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.in, tt.opts); got != tt.want {
+			t.Errorf("%s: Format = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatIsStable(t *testing.T) {
+	opts := Options{Width: 30, ListMarker: "*"}
+	in := "Heading\n=======\nsome text with a hard break  \nand a list:\n\n+ item one which is rather long indeed\n+ item two\n\n> quote"
+	once := Format(in, opts)
+	if twice := Format(once, opts); twice != once {
+		t.Errorf("Format changed its own output:\n%q\n%q", once, twice)
+	}
+}
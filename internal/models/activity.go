@@ -0,0 +1,13 @@
+package models
+
+// ActivityDay counts the notes created and edited on one day
+type ActivityDay struct {
+	Date    string // YYYY-MM-DD in local time
+	Created int
+	Updated int // Notes whose last edit fell on this day
+}
+
+// Total returns the day's activity, creations and edits together
+func (d ActivityDay) Total() int {
+	return d.Created + d.Updated
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Attachment is a file attached to a note, kept in the attachments store
+type Attachment struct {
+	ID        int
+	NoteID    int
+	Name      string // file name as attached
+	Path      string // absolute path of the stored copy
+	Size      int64
+	CreatedAt time.Time
+}
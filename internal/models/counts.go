@@ -0,0 +1,18 @@
+package models
+
+// NameCount is how many notes carry a notebook or tag name
+type NameCount struct {
+	Name  string
+	Count int
+	Color string // color label of a tag, empty for notebooks
+}
+
+// NoteCounts holds the note counts shown in the sidebar
+type NoteCounts struct {
+	All       int // Notes outside the TrashNotebook
+	Pinned    int
+	Archived  int         // Notes in the ArchiveNotebook
+	Trash     int         // Notes in the TrashNotebook
+	Notebooks []NameCount // Notebooks other than the archive and trash, by name
+	Tags      []NameCount // Tags used by at least one note and their parents, as a tree
+}
@@ -0,0 +1,67 @@
+package models
+
+import "math"
+
+// VaultHealth summarises maintenance problems across the whole vault
+type VaultHealth struct {
+	TotalNotes      int
+	UntaggedNotes   int // Notes without any tag
+	TotalTags       int
+	OrphanTags      int      // Tags not attached to any note
+	StaleNotes      int      // Notes not updated within the stale window
+	DuplicateNotes  int      // Notes sharing a title with another note
+	DuplicateTitles []string // Titles used by more than one note
+	PageCount       int      // Database pages in use
+	FreePages       int      // Unused database pages left behind by deletes
+}
+
+// UntaggedPercent returns the share of notes without tags
+func (h VaultHealth) UntaggedPercent() float64 {
+	return percent(h.UntaggedNotes, h.TotalNotes)
+}
+
+// OrphanPercent returns the share of tags no note uses
+func (h VaultHealth) OrphanPercent() float64 {
+	return percent(h.OrphanTags, h.TotalTags)
+}
+
+// StalePercent returns the share of notes that haven't been touched recently
+func (h VaultHealth) StalePercent() float64 {
+	return percent(h.StaleNotes, h.TotalNotes)
+}
+
+// DuplicatePercent returns the share of notes that look like duplicates
+func (h VaultHealth) DuplicatePercent() float64 {
+	return percent(h.DuplicateNotes, h.TotalNotes)
+}
+
+// FragmentationPercent returns the share of database pages that are free
+func (h VaultHealth) FragmentationPercent() float64 {
+	return percent(h.FreePages, h.PageCount)
+}
+
+// Score rates the vault from 0 to 100. Each problem costs points in
+// proportion to how much of the vault it affects.
+func (h VaultHealth) Score() int {
+	penalty := h.UntaggedPercent()*0.3 +
+		h.OrphanPercent()*0.15 +
+		h.StalePercent()*0.15 +
+		h.DuplicatePercent()*0.3 +
+		h.FragmentationPercent()*0.1
+	return int(math.Round(math.Max(0, 100-penalty)))
+}
+
+// percent returns part as a percentage of total, 0 when total is 0
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+// DuplicatePair is two notes that look like copies of each other
+type DuplicatePair struct {
+	Older, Newer *Note   // By creation, so the original usually comes first
+	TitleScore   float64 // Similarity of the titles, from 0 to 1
+	ContentScore float64 // Share of word runs the contents have in common, from 0 to 1
+}
@@ -1,31 +1,115 @@
 package models
 
 import (
+	"math"
 	"time"
 )
 
 // Note represents a markdown note
 type Note struct {
-	ID        int       `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Content   string    `json:"content" db:"content"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	Tags      []Tag     `json:"tags,omitempty" db:"-"`
+	ID        int        `json:"id" db:"id"`
+	UUID      string     `json:"uuid" db:"uuid"` // Identifies the note across machines and exports
+	Title     string     `json:"title" db:"title"`
+	Content   string     `json:"content" db:"content"`
+	Notebook  string     `json:"notebook,omitempty" db:"notebook"`
+	Aliases   []string   `json:"aliases,omitempty" db:"aliases"` // From the frontmatter
+	Date      *time.Time `json:"date,omitempty" db:"note_date"`  // From the frontmatter
+	Pinned    bool       `json:"pinned,omitempty" db:"pinned"`
+	SortOrder int        `json:"sort_order,omitempty" db:"sort_order"` // Position among pinned notes, from 1
+	Color     string     `json:"color,omitempty" db:"color"`           // Name of the color label, empty for none
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	Tags      []Tag      `json:"tags,omitempty" db:"-"`
+
+	// Summaries leave Content empty and carry its excerpt and word count
+	// instead; see storage.Service.GetAllSummaries
+	Summary   bool   `json:"-" db:"-"`
+	Excerpt   string `json:"-" db:"-"`
+	WordCount int    `json:"-" db:"word_count"`
+}
+
+// NoColor matches notes without a color label in NoteFilter.Color
+const NoColor = "none"
+
+// ArchiveNotebook is the notebook archived notes are moved to
+const ArchiveNotebook = "Archive"
+
+// TrashNotebook is the notebook notes are moved to instead of being deleted
+// outright, e.g. when their mirrored file disappears
+const TrashNotebook = "Trash"
+
+// Archived reports whether the note is in the archive notebook
+func (n *Note) Archived() bool {
+	return n.Notebook == ArchiveNotebook
 }
 
 // Tag represents a tag that can be assigned to notes
 type Tag struct {
-	ID   int    `json:"id" db:"id"`
-	Name string `json:"name" db:"name"`
+	ID    int    `json:"id" db:"id"`
+	Name  string `json:"name" db:"name"`
+	Color string `json:"color,omitempty" db:"color"` // one of the color label names, empty for none
+}
+
+// TagUsage holds usage statistics for a tag
+type TagUsage struct {
+	Tag       Tag
+	NoteCount int        // Number of notes carrying the tag
+	LastUsed  *time.Time // Most recent update of a note carrying the tag, nil if unused
 }
 
+// Score ranks a tag by how often and how recently it's used. Usage counts
+// decay with a 30-day half-life so tags used last week outrank tags used
+// heavily last year.
+func (u TagUsage) Score(now time.Time) float64 {
+	if u.NoteCount == 0 || u.LastUsed == nil {
+		return 0
+	}
+	days := now.Sub(*u.LastUsed).Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return float64(u.NoteCount) * math.Pow(0.5, days/30)
+}
+
+// SortField identifies a note column used for ordering
+type SortField string
+
+const (
+	SortByUpdated SortField = "updated_at"
+	SortByCreated SortField = "created_at"
+	SortByTitle   SortField = "title"
+	SortByID      SortField = "id"
+	SortByTags    SortField = "tags"  // Number of tags
+	SortByWords   SortField = "words" // Word count
+)
+
 // NoteFilter represents filters for querying notes
 type NoteFilter struct {
 	SearchQuery string
 	TagIDs      []int
 	Limit       int
-	Offset      int
+	After       int // Page after the note with this ID in the sort order, 0 for the first page
+
+	// Structured search conditions, usually produced by utils.ParseQuery
+	Terms           []string   // Each term must appear in the title or content
+	ExcludeTerms    []string   // None of these may appear in the title or content
+	TitleTerms      []string   // Each term must appear in the title
+	Notebook        string     // Notes must belong to this notebook
+	Color           string     // Notes must carry this color label, NoColor for none
+	TagNames        []string   // Notes must carry every one of these tags
+	ExcludeTagNames []string   // Notes must carry none of these tags
+	CreatedAfter    *time.Time // Inclusive lower bound on created_at
+	CreatedBefore   *time.Time // Exclusive upper bound on created_at
+	UpdatedAfter    *time.Time // Inclusive lower bound on updated_at
+	UpdatedBefore   *time.Time // Exclusive upper bound on updated_at
+	Untagged        bool       // Only notes without any tag
+	Duplicates      bool       // Only notes sharing their title with another note
+	Pinned          bool       // Only pinned notes
+
+	SortBy        SortField // Primary sort, defaults to SortByUpdated
+	SecondarySort SortField // Tie-breaker for equal primary values, defaults to SortByID
+	Reverse       bool      // Flip the direction of the primary sort
+	PinnedFirst   bool      // List pinned notes first, in their manual order
 }
 
 // NewNote creates a new note with timestamps
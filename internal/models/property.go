@@ -0,0 +1,7 @@
+package models
+
+// Property is a custom key-value pair stored with a note
+type Property struct {
+	Key   string `json:"key" db:"key"`
+	Value string `json:"value" db:"value"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Reminder records a notification sent for a due task. The key identifies
+// the task by its note, text and due date, so a rescheduled task is
+// reminded about again.
+type Reminder struct {
+	Key          string
+	NotifiedAt   time.Time
+	SnoozedUntil *time.Time // nil unless the reminder was snoozed
+}
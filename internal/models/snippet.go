@@ -0,0 +1,8 @@
+package models
+
+// Snippet is text the editor expands in place of its trigger
+type Snippet struct {
+	ID      int
+	Trigger string // typed before Tab to expand, such as ;sig
+	Body    string // may hold placeholders such as {{date}} and {{cursor}}
+}
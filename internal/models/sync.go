@@ -0,0 +1,10 @@
+package models
+
+// SyncRevision records a note file as it was at the last sync with a remote
+// server. A file changed since then has a different hash locally, or a
+// different ETag remotely.
+type SyncRevision struct {
+	Path string // URL of the file on the server
+	Hash string // SHA-256 of the file content, hex encoded
+	ETag string // entity tag the server returned, empty when unknown
+}
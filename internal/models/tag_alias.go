@@ -0,0 +1,8 @@
+package models
+
+// TagAlias is another spelling of a tag. Tagging a note with the alias
+// tags it with the canonical tag instead.
+type TagAlias struct {
+	Alias string
+	Tag   string
+}
@@ -0,0 +1,101 @@
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	gosync "sync"
+)
+
+// ErrNoNotifier is returned when no program to show notifications with is
+// installed
+var ErrNoNotifier = errors.New("no way to show notifications: install notify-send (libnotify) or run on macOS")
+
+// snoozeLabel labels the button that snoozes a reminder
+const snoozeLabel = "Snooze"
+
+// NewNotifier returns the notifier for this system: osascript on macOS and
+// notify-send elsewhere
+func NewNotifier() (Notifier, error) {
+	if runtime.GOOS == "darwin" {
+		if path, err := exec.LookPath("osascript"); err == nil {
+			return &osascript{path: path}, nil
+		}
+		return nil, ErrNoNotifier
+	}
+	if path, err := exec.LookPath("notify-send"); err == nil {
+		return &notifySend{path: path}, nil
+	}
+	return nil, ErrNoNotifier
+}
+
+// notifySend shows notifications with notify-send, offering a snooze
+// button where it supports actions
+type notifySend struct {
+	path string
+
+	mu        gosync.Mutex
+	noActions bool // this notify-send is too old for --action
+}
+
+// Notify shows a notification and waits for it to close
+func (n *notifySend) Notify(title, body string) (bool, error) {
+	n.mu.Lock()
+	actions := !n.noActions
+	n.mu.Unlock()
+
+	if actions {
+		out, err := exec.Command(n.path, "--app-name=tuinotes", "--wait",
+			"--action=snooze="+snoozeLabel, title, body).Output()
+		if err == nil {
+			return strings.TrimSpace(string(out)) == "snooze", nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || !strings.Contains(string(exitErr.Stderr), "Unknown option") {
+			return false, fmt.Errorf("failed to run notify-send: %w", err)
+		}
+		// Versions before libnotify 0.7.10 reject --action; show plain
+		// notifications from now on
+		n.mu.Lock()
+		n.noActions = true
+		n.mu.Unlock()
+	}
+
+	if out, err := exec.Command(n.path, "--app-name=tuinotes", title, body).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to run notify-send: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return false, nil
+}
+
+// osascript shows reminders as alerts with osascript, since macOS
+// notifications sent from scripts can't have buttons
+type osascript struct {
+	path string
+}
+
+// alertScript shows an alert titled by the first argument with the second
+// as its message, and prints the button pressed. Unanswered alerts close
+// after ten minutes.
+var alertScript = []string{
+	"on run argv",
+	`display alert (item 1 of argv) message (item 2 of argv) buttons {"` + snoozeLabel + `", "OK"} default button "OK" giving up after 600`,
+	"return button returned of result",
+	"end run",
+}
+
+// Notify shows an alert and waits for it to close
+func (o *osascript) Notify(title, body string) (bool, error) {
+	var args []string
+	for _, line := range alertScript {
+		args = append(args, "-e", line)
+	}
+	args = append(args, title, body)
+
+	out, err := exec.Command(o.path, args...).Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to run osascript: %w", err)
+	}
+	return strings.TrimSpace(string(out)) == snoozeLabel, nil
+}
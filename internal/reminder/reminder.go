@@ -0,0 +1,159 @@
+// Package reminder sends desktop notifications for tasks whose @due date
+// has come, for tuinotes daemon. What was sent and snoozed is kept in the
+// database, so restarting the daemon doesn't remind about a task twice.
+package reminder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// checkInterval is how often the daemon looks for due tasks
+const checkInterval = time.Minute
+
+// Due is an open task whose reminder is due
+type Due struct {
+	Key  string
+	Note *models.Note
+	Task utils.Task
+}
+
+// Key identifies a task across edits that leave its text and due date
+// alone, even when lines move
+func Key(note *models.Note, task utils.Task) string {
+	sum := sha256.Sum256([]byte(note.UUID + "\n" + task.Text + "\n" + task.Due.Format("2006-01-02")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Pending returns the open tasks to remind about at now: those past the
+// time of day at on their due date that weren't reminded about yet, or
+// were snoozed until before now. Reminders of tasks that are done, gone
+// or no longer due are forgotten. Archived notes never remind.
+func Pending(service *storage.Service, now time.Time, at time.Duration) ([]Due, error) {
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	reminders, err := service.GetReminders()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Due
+	current := map[string]bool{}
+	for _, note := range notes {
+		if note.Notebook == models.ArchiveNotebook {
+			continue
+		}
+		for _, task := range utils.ParseTasks(note.Content) {
+			if task.Done || task.Due == nil || now.Before(task.Due.Add(at)) {
+				continue
+			}
+			key := Key(note, task)
+			current[key] = true
+			reminder := reminders[key]
+			if reminder != nil && (reminder.SnoozedUntil == nil || now.Before(*reminder.SnoozedUntil)) {
+				continue
+			}
+			pending = append(pending, Due{Key: key, Note: note, Task: task})
+		}
+	}
+
+	var stale []string
+	for key := range reminders {
+		if !current[key] {
+			stale = append(stale, key)
+		}
+	}
+	if err := service.DeleteReminders(stale); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// Notifier shows a notification. Notify blocks until the notification is
+// dismissed where that's known, and reports whether it was snoozed.
+type Notifier interface {
+	Notify(title, body string) (snoozed bool, err error)
+}
+
+// Daemon reminds about due tasks until stopped
+type Daemon struct {
+	service  *storage.Service
+	notifier Notifier
+	at       time.Duration // time of day reminders for a due date are sent
+	snooze   time.Duration
+	now      func() time.Time
+}
+
+// NewDaemon creates a daemon sending reminders for the notes in service
+// at the time of day at, which snoozing puts off by snooze
+func NewDaemon(service *storage.Service, notifier Notifier, at, snooze time.Duration) *Daemon {
+	return &Daemon{service: service, notifier: notifier, at: at, snooze: snooze, now: time.Now}
+}
+
+// Run checks for due tasks now and every minute until ctx is done
+func (d *Daemon) Run(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.Check(); err != nil {
+			// The database may be busy or mid-upgrade; try again later
+			slog.Warn("failed to check reminders", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check sends the reminders due now and returns how many it sent. Each is
+// recorded before it's shown, so a notification left open isn't sent
+// again by the next check.
+func (d *Daemon) Check() (int, error) {
+	now := d.now()
+	pending, err := Pending(d.service, now, d.at)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, due := range pending {
+		if err := d.service.MarkReminded(due.Key, now); err != nil {
+			return 0, err
+		}
+		slog.Info("reminding", "note", due.Note.Title, "task", due.Task.Text)
+		// Notifications may stay open until dismissed
+		go d.remind(due, now)
+	}
+	return len(pending), nil
+}
+
+// remind shows the notification for a due task and snoozes it if asked
+func (d *Daemon) remind(due Due, now time.Time) {
+	title := "Due today: " + due.Task.Text
+	if due.Task.Due.Add(24 * time.Hour).Before(now) {
+		title = "Overdue: " + due.Task.Text
+	}
+	body := fmt.Sprintf("%s, due %s", due.Note.Title, due.Task.Due.Format("Mon Jan 2"))
+
+	snoozed, err := d.notifier.Notify(title, body)
+	if err != nil {
+		slog.Warn("failed to show reminder", "task", due.Task.Text, "err", err)
+		return
+	}
+	if snoozed {
+		if err := d.service.SnoozeReminder(due.Key, d.now().Add(d.snooze)); err != nil {
+			slog.Warn("failed to snooze reminder", "task", due.Task.Text, "err", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package reminder
+
+import (
+	"path/filepath"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"markdown-note-taking-app/internal/storage"
+)
+
+// fakeNotifier records notifications, snoozing those titled in snooze
+type fakeNotifier struct {
+	shown  chan string
+	snooze map[string]bool
+}
+
+func (f *fakeNotifier) Notify(title, body string) (bool, error) {
+	f.shown <- title
+	return f.snooze[title], nil
+}
+
+// clock is a time the test moves forward while the daemon reads it
+type clock struct {
+	mu  gosync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// expectShown waits for the notifications titled titles, in any order
+func expectShown(t *testing.T, notifier *fakeNotifier, titles ...string) {
+	t.Helper()
+	want := map[string]bool{}
+	for _, title := range titles {
+		want[title] = true
+	}
+	for range titles {
+		select {
+		case got := <-notifier.shown:
+			if !want[got] {
+				t.Errorf("Unexpected notification %q", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected %q to be shown", titles)
+		}
+	}
+}
+
+// waitSnoozed waits for the daemon to record a snooze
+func waitSnoozed(t *testing.T, service *storage.Service) {
+	t.Helper()
+	for range 100 {
+		reminders, err := service.GetReminders()
+		if err != nil {
+			t.Fatalf("Failed to get reminders: %v", err)
+		}
+		for _, reminder := range reminders {
+			if reminder.SnoozedUntil != nil {
+				return
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected a reminder to be snoozed")
+}
+
+func TestDaemon(t *testing.T) {
+	service, err := storage.NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	content := "- [ ] Pay rent @due(2026-03-02)\n" +
+		"- [x] Book flights @due(2026-03-01)\n" +
+		"- [ ] Call the bank @due(2026-02-27)\n" +
+		"- [ ] Renew passport @due(2026-04-01)\n" +
+		"- [ ] Water plants"
+	note, err := service.CreateNote("Errands", content)
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	notifier := &fakeNotifier{shown: make(chan string, 10), snooze: map[string]bool{"Overdue: Call the bank": true}}
+	daemon := NewDaemon(service, notifier, 9*time.Hour, time.Hour)
+	now := &clock{now: time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local)}
+	daemon.now = now.Now
+
+	// Before the reminder time only the overdue task is due
+	if sent, err := daemon.Check(); err != nil || sent != 1 {
+		t.Fatalf("Expected 1 reminder, got %d (%v)", sent, err)
+	}
+	expectShown(t, notifier, "Overdue: Call the bank")
+	waitSnoozed(t, service)
+
+	// Tasks due today are reminded about from the reminder time, and the
+	// snoozed one comes back an hour after it was snoozed
+	now.Add(90 * time.Minute)
+	if sent, err := daemon.Check(); err != nil || sent != 2 {
+		t.Fatalf("Expected 2 reminders, got %d (%v)", sent, err)
+	}
+	expectShown(t, notifier, "Due today: Pay rent", "Overdue: Call the bank")
+	waitSnoozed(t, service)
+
+	// Reminders already sent aren't sent again
+	now.Add(30 * time.Minute)
+	if sent, err := daemon.Check(); err != nil || sent != 0 {
+		t.Errorf("Expected no reminders, got %d (%v)", sent, err)
+	}
+
+	// Moving the due date reminds about the task again
+	note.Content = "- [ ] Pay rent @due(2026-03-02)\n- [ ] Call the bank @due(2026-03-01)"
+	if err := service.UpdateNote(note); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if sent, err := daemon.Check(); err != nil || sent != 1 {
+		t.Fatalf("Expected 1 reminder, got %d (%v)", sent, err)
+	}
+	expectShown(t, notifier, "Overdue: Call the bank")
+
+	// Reminders of tasks no longer listed are forgotten
+	reminders, err := service.GetReminders()
+	if err != nil {
+		t.Fatalf("Failed to get reminders: %v", err)
+	}
+	if len(reminders) != 2 {
+		t.Errorf("Expected 2 reminders kept, got %d", len(reminders))
+	}
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// GetActivity counts the notes created and edited on each day from since,
+// a YYYY-MM-DD date, onwards. Days are in local time and days without
+// activity are left out. Only a note's last edit is known, so an edit
+// counts once the note was changed after the second it was created in.
+func (s *Service) GetActivity(since string) ([]models.ActivityDay, error) {
+	rows, err := s.db.Query(`
+		SELECT day, SUM(created), SUM(updated) FROM (
+			SELECT date(created_at, 'localtime') AS day, 1 AS created, 0 AS updated FROM notes
+			UNION ALL
+			SELECT date(updated_at, 'localtime'), 0, 1 FROM notes
+			WHERE datetime(updated_at) != datetime(created_at)
+		)
+		WHERE day >= ?
+		GROUP BY day
+		ORDER BY day`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	var days []models.ActivityDay
+	for rows.Next() {
+		var day models.ActivityDay
+		if err := rows.Scan(&day.Date, &day.Created, &day.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
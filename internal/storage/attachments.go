@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// DefaultAttachmentsDir returns where attached files are stored,
+// honoring XDG_DATA_HOME
+func DefaultAttachmentsDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "tuinotes", "attachments"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "share", "tuinotes", "attachments"), nil
+}
+
+// SetAttachmentsDir overrides the directory attached files are stored in
+func (s *Service) SetAttachmentsDir(dir string) {
+	s.attachmentsDir = dir
+}
+
+// AttachmentsDir returns the directory attached files are stored in
+func (s *Service) AttachmentsDir() (string, error) {
+	if s.attachmentsDir != "" {
+		return s.attachmentsDir, nil
+	}
+	return DefaultAttachmentsDir()
+}
+
+// AttachFile copies the file at source into the attachments store and
+// attaches it to a note. Stored files are named after their content, so
+// attaching the same file twice keeps one copy.
+func (s *Service) AttachFile(noteID int, source string) (*models.Attachment, error) {
+	if _, err := s.notes.GetByID(noteID); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file", source)
+	}
+
+	dir, err := s.AttachmentsDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := storeAttachment(dir, source)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		NoteID:    noteID,
+		Name:      filepath.Base(source),
+		Path:      filepath.Join(dir, file),
+		Size:      info.Size(),
+		CreatedAt: time.Now(),
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO attachments (note_id, name, file, size, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		noteID, attachment.Name, file, attachment.Size, attachment.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach file: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment ID: %w", err)
+	}
+	attachment.ID = int(id)
+	return attachment, nil
+}
+
+// GetAttachments returns the files attached to a note, oldest first
+func (s *Service) GetAttachments(noteID int) ([]*models.Attachment, error) {
+	dir, err := s.AttachmentsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, note_id, name, file, size, created_at FROM attachments
+		WHERE note_id = ? ORDER BY id`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*models.Attachment
+	for rows.Next() {
+		attachment := &models.Attachment{}
+		var file, createdAt string
+		if err := rows.Scan(&attachment.ID, &attachment.NoteID, &attachment.Name, &file, &attachment.Size, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachment.Path = filepath.Join(dir, file)
+		if attachment.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// RemoveAttachment detaches a file from its note. The stored copy is kept
+// since other notes, or notes in other vaults, may share it, and links to
+// it would otherwise break.
+func (s *Service) RemoveAttachment(id int) error {
+	result, err := s.db.Exec(`DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// storeAttachment copies source into dir under a name made of a hash of
+// its content and its file name, unless it's stored already, and returns
+// that name
+func storeAttachment(dir, source string) (string, error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to open attachment: %w", err)
+	}
+	defer in.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, in); err != nil {
+		return "", fmt.Errorf("failed to read attachment: %w", err)
+	}
+	name := strings.ReplaceAll(filepath.Base(source), " ", "-")
+	file := hex.EncodeToString(hash.Sum(nil))[:16] + "-" + name
+	path := filepath.Join(dir, file)
+	if _, err := os.Stat(path); err == nil {
+		return file, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	// Copy to a temporary file first so an interrupted copy can't be
+	// mistaken for the stored file later
+	tmp, err := os.CreateTemp(dir, ".attaching-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to store attachment: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return file, nil
+}
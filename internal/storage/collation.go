@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// driverName is the sqlite3 driver registered with the locale collation
+const driverName = "sqlite3_tuinotes"
+
+// localeCollation names the SQLite collation that orders titles and tag
+// names by the rules of the configured locale, e.g. "ä" next to "a" in
+// German and kana in gojūon order in Japanese
+const localeCollation = "LOCALE"
+
+// collator compares strings for the locale collation. A Collator isn't
+// safe for concurrent use, so comparisons take the lock.
+var collator = struct {
+	sync.Mutex
+	*collate.Collator
+}{Collator: newCollator(language.Und)}
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterCollation(localeCollation, compareLocale)
+		},
+	})
+}
+
+// newCollator returns a case-insensitive collator for tag
+func newCollator(tag language.Tag) *collate.Collator {
+	return collate.New(tag, collate.IgnoreCase)
+}
+
+// SetLocale selects the locale titles and tags are sorted by, given as a
+// BCP 47 tag such as "de" or "ja-JP". Empty selects the language-neutral
+// Unicode order.
+func SetLocale(locale string) error {
+	tag := language.Und
+	if locale != "" {
+		var err error
+		if tag, err = language.Parse(locale); err != nil {
+			return fmt.Errorf("failed to parse locale %q: %w", locale, err)
+		}
+	}
+
+	collator.Lock()
+	defer collator.Unlock()
+	collator.Collator = newCollator(tag)
+	return nil
+}
+
+// compareLocale orders two strings for the locale collation
+func compareLocale(a, b string) int {
+	collator.Lock()
+	defer collator.Unlock()
+	return collator.CompareString(a, b)
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// GetNoteCounts counts the notes in the whole vault but the trash, the
+// pinned, archived and trashed ones, and those in each notebook and under
+// each tag
+func (s *Service) GetNoteCounts() (*models.NoteCounts, error) {
+	counts := &models.NoteCounts{}
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(notebook != ? COLLATE NOCASE), 0),
+			COALESCE(SUM(pinned), 0),
+			COALESCE(SUM(notebook = ? COLLATE NOCASE), 0),
+			COALESCE(SUM(notebook = ? COLLATE NOCASE), 0)
+		FROM notes`, models.TrashNotebook, models.ArchiveNotebook, models.TrashNotebook).
+		Scan(&counts.All, &counts.Pinned, &counts.Archived, &counts.Trash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notes: %w", err)
+	}
+
+	counts.Notebooks, err = s.db.queryNameCounts(`
+		SELECT notebook, COUNT(*) FROM notes
+		WHERE notebook != ''
+			AND notebook != ? COLLATE NOCASE
+			AND notebook != ? COLLATE NOCASE
+		GROUP BY notebook COLLATE NOCASE
+		ORDER BY notebook COLLATE NOCASE`, models.ArchiveNotebook, models.TrashNotebook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notes by notebook: %w", err)
+	}
+
+	counts.Tags, err = s.countNotesByTag()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notes by tag: %w", err)
+	}
+	return counts, nil
+}
+
+// countNotesByTag counts the notes under each tag in use and each parent
+// of a nested tag. A parent counts the notes carrying it or any tag nested
+// under it once each. Tags are ordered as a tree, parents before children.
+func (s *Service) countNotesByTag() ([]models.NameCount, error) {
+	rows, err := s.db.Query(`
+		SELECT t.name, t.color, nt.note_id FROM tags t
+		JOIN note_tags nt ON nt.tag_id = t.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Tags are told apart ignoring case, as searches for them are
+	names := map[string]string{}
+	colors := map[string]string{}
+	notes := map[string]map[int]bool{}
+	for rows.Next() {
+		var name, color string
+		var noteID int
+		if err := rows.Scan(&name, &color, &noteID); err != nil {
+			return nil, err
+		}
+		colors[strings.ToLower(name)] = color
+		for _, tag := range append(utils.TagAncestors(name), name) {
+			key := strings.ToLower(tag)
+			if _, ok := names[key]; !ok {
+				names[key] = tag
+				notes[key] = map[int]bool{}
+			}
+			notes[key][noteID] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	// Comparing level by level keeps children right after their parent
+	slices.SortFunc(keys, func(a, b string) int {
+		return slices.Compare(strings.Split(a, utils.TagSeparator), strings.Split(b, utils.TagSeparator))
+	})
+
+	counts := make([]models.NameCount, len(keys))
+	for i, key := range keys {
+		counts[i] = models.NameCount{Name: names[key], Count: len(notes[key]), Color: colors[key]}
+	}
+	return counts, nil
+}
+
+// queryNameCounts runs a query selecting names and counts
+func (db *DB) queryNameCounts(query string, args ...any) ([]models.NameCount, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.NameCount
+	for rows.Next() {
+		var c models.NameCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
@@ -1,11 +1,18 @@
 package storage
 
 import (
+	"crypto/cipher"
 	"database/sql"
 	"embed"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/utils"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -13,9 +20,18 @@ import (
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// connectionParams configure every connection: WAL lets the notes be read
+// while a save is written, busy_timeout waits up to five seconds for a lock
+// instead of failing at once, foreign_keys enforces the references between
+// tables, and synchronous=NORMAL is safe under WAL while syncing less often
+const connectionParams = "_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_synchronous=NORMAL"
+
 // DB represents the database connection
 type DB struct {
 	*sql.DB
+
+	key    *encryptionKey // nil unless note encryption is enabled
+	cipher cipher.AEAD    // set once unlocked with the passphrase
 }
 
 // NewDB creates a new database connection
@@ -28,7 +44,7 @@ func NewDB(dbPath string) (*DB, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(driverName, dataSourceName(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -38,41 +54,349 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &DB{db}
+	database := &DB{DB: db}
 
 	// Run migrations
-	if err := database.runMigrations(); err != nil {
+	if err := database.runMigrations(dbPath); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if database.key, err = database.loadEncryptionKey(); err != nil {
+		return nil, err
+	}
+
 	return database, nil
 }
 
-// runMigrations executes all SQL migration files
-func (db *DB) runMigrations() error {
-	files, err := migrationsFS.ReadDir("migrations")
+// dataSourceName adds the connection parameters to a database path,
+// keeping any the path already has
+func dataSourceName(dbPath string) string {
+	if strings.Contains(dbPath, "?") {
+		return dbPath + "&" + connectionParams
+	}
+	return dbPath + "?" + connectionParams
+}
+
+// runMigrations applies the numbered migrations the database hasn't had
+// yet, in order, and adds missing columns, all in one transaction so a
+// failure leaves the database as it was. Applied migrations are recorded
+// in schema_migrations and never run again. When anything is pending for
+// a database holding notes, it's backed up next to dbPath first.
+func (db *DB) runMigrations(dbPath string) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := db.appliedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
+		return err
+	}
+	if latest := migrations[len(migrations)-1].version; len(applied) > 0 && applied[len(applied)-1] > latest {
+		return fmt.Errorf("database schema version %d is newer than this version supports (%d)", applied[len(applied)-1], latest)
 	}
 
-	for _, file := range files {
-		if filepath.Ext(file.Name()) != ".sql" {
-			continue
+	var pendingMigrations []migration
+	for _, m := range migrations {
+		if !slices.Contains(applied, m.version) {
+			pendingMigrations = append(pendingMigrations, m)
 		}
+	}
+	pendingColumns, err := db.pendingColumns()
+	if err != nil {
+		return err
+	}
+	total := len(pendingMigrations) + len(pendingColumns)
+	if total == 0 {
+		return nil
+	}
 
-		content, err := migrationsFS.ReadFile(filepath.Join("migrations", file.Name()))
+	var progress MigrationProgress
+	if notes := db.countNotes(); notes > 0 {
+		if notes >= largeTableRows {
+			progress = currentMigrationProgress()
+		}
+		backup, err := db.backupBeforeMigration(dbPath)
 		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", file.Name(), err)
+			return err
+		}
+		if backup != "" {
+			slog.Info("backed up database before migrating", "backup", backup)
+			if progress != nil {
+				progress(0, total, "Backed up the database to "+backup)
+			}
+		}
+	}
+	step := 0
+	report := func(description string) {
+		step++
+		if progress != nil {
+			progress(step, total, description)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(schemaMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	// Columns added before migrations were numbered go in after the
+	// migrations of that time and before any later one
+	columnsAdded := false
+	for _, m := range pendingMigrations {
+		if m.version > legacySchemaVersion && !columnsAdded {
+			if err := addColumns(tx, report); err != nil {
+				return err
+			}
+			columnsAdded = true
 		}
+		report("Applying migration " + m.file)
+		if err := m.apply(tx); err != nil {
+			return err
+		}
+	}
+	if !columnsAdded {
+		if err := addColumns(tx, report); err != nil {
+			return err
+		}
+	}
 
-		if _, err := db.Exec(string(content)); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", file.Name(), err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+	slog.Info("migrated database", "path", dbPath, "migrations", len(pendingMigrations), "columns", len(pendingColumns))
+	return nil
+}
+
+// columnAdditions lists columns added to existing tables before
+// migrations were numbered, when every migration file ran on each start
+// and ALTER TABLE couldn't live in them. They're applied only when missing.
+// New columns go in a numbered migration instead. backfill, if set, fills
+// in the new column for existing rows right after it's added. Backfills
+// run before the database is unlocked, so content they read may still be
+// encrypted.
+var columnAdditions = []struct {
+	table      string
+	column     string
+	definition string
+	backfill   func(tx *sql.Tx) error
+}{
+	{"notes", "notebook", "TEXT NOT NULL DEFAULT ''", nil},
+	{"notes", "word_count", "INTEGER NOT NULL DEFAULT 0", backfillWordCounts},
+	{"notes", "note_date", "TEXT", nil},
+	{"notes", "aliases", "TEXT NOT NULL DEFAULT ''", backfillNoteMetadata},
+	{"notes", "encrypted", "INTEGER NOT NULL DEFAULT 0", nil},
+	{"notes", "pinned", "INTEGER NOT NULL DEFAULT 0", nil},
+	{"notes", "sort_order", "INTEGER NOT NULL DEFAULT 0", nil},
+	{"notes", "color", "TEXT NOT NULL DEFAULT ''", nil},
+	{"notes", "uuid", "TEXT NOT NULL DEFAULT ''", backfillUUIDs},
+	{"tags", "color", "TEXT NOT NULL DEFAULT ''", nil},
+}
+
+// pendingColumns returns the names of the column additions the database
+// lacks, as "table.column"
+func (db *DB) pendingColumns() ([]string, error) {
+	var pending []string
+	for _, c := range columnAdditions {
+		exists, err := hasColumn(db, c.table, c.column)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			pending = append(pending, c.table+"."+c.column)
 		}
 	}
+	return pending, nil
+}
+
+// addColumns applies any missing column additions, reporting each one
+func addColumns(tx *sql.Tx, report func(description string)) error {
+	for _, c := range columnAdditions {
+		exists, err := hasColumn(tx, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
 
+		report(fmt.Sprintf("Adding %s.%s", c.table, c.column))
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", c.table, c.column, err)
+		}
+		if c.backfill != nil {
+			if err := c.backfill(tx); err != nil {
+				return fmt.Errorf("failed to backfill %s.%s: %w", c.table, c.column, err)
+			}
+		}
+	}
 	return nil
 }
 
+// backfillWordCounts computes the stored word count of every note
+func backfillWordCounts(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, content FROM notes`)
+	if err != nil {
+		return err
+	}
+	counts := map[int]int{}
+	for rows.Next() {
+		var id int
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		counts[id] = utils.WordCount(content)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, count := range counts {
+		if _, err := tx.Exec(`UPDATE notes SET word_count = ? WHERE id = ?`, count, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillNoteMetadata stores the aliases and date declared in the
+// frontmatter of every note
+func backfillNoteMetadata(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, content FROM notes`)
+	if err != nil {
+		return err
+	}
+	metadata := map[int]utils.NoteMetadata{}
+	for rows.Next() {
+		var id int
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		if meta := utils.ParseNoteMetadata(content); meta.Aliases != nil || meta.Date != nil {
+			metadata[id] = meta
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, meta := range metadata {
+		aliases, noteDate := encodeMetadata(meta)
+		if _, err := tx.Exec(`UPDATE notes SET aliases = ?, note_date = ? WHERE id = ?`, aliases, noteDate, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillUUIDs gives every note a UUID and makes them unique. Existing
+// notes get UUIDs derived from their title and creation time, which sync
+// carries over, so a note synced to several machines before UUIDs existed
+// gets the same one on each.
+func backfillUUIDs(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, title, created_at FROM notes WHERE uuid = '' ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	uuids := map[int]string{}
+	taken := map[string]bool{}
+	for rows.Next() {
+		var id int
+		var title, createdAt string
+		if err := rows.Scan(&id, &title, &createdAt); err != nil {
+			rows.Close()
+			return err
+		}
+		if created, err := time.Parse(time.RFC3339, createdAt); err == nil {
+			createdAt = created.UTC().Format(time.RFC3339)
+		}
+		uuid := utils.NameUUID(createdAt + "\n" + title)
+		if taken[uuid] {
+			// Notes sharing a title and creation time can't share the UUID
+			uuid = NewNoteUUID()
+		}
+		taken[uuid] = true
+		uuids[id] = uuid
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, uuid := range uuids {
+		if _, err := tx.Exec(`UPDATE notes SET uuid = ? WHERE id = ?`, uuid, id); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_notes_uuid ON notes(uuid)`)
+	return err
+}
+
+// queryer runs queries on a database or within a transaction
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// execer runs statements on a database or within a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// hasColumn reports whether a table has a column. A missing table has none.
+func hasColumn(q queryer, table, column string) (bool, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	return false, nil
+}
+
+// hasTable reports whether the database has a table
+func hasTable(q queryer, table string) (bool, error) {
+	rows, err := q.Query(`SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?`, table)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return exists, nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
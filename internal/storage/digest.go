@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// DigestTag is given to digest notes, which digests leave out
+const DigestTag = "digest"
+
+// digestDateLayout titles the dated sections of a digest
+const digestDateLayout = "2006-01-02"
+
+// CreateDigest compiles notes into one new note with a section per day,
+// then archives them. An empty title names the digest after the days it
+// covers. The digest goes in the notebook the notes share, if
+// any, and is tagged DigestTag. Archiving happens only once the digest is
+// stored, so nothing is lost if either step fails.
+func (s *Service) CreateDigest(notes []*models.Note, title string) (*models.Note, error) {
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("no notes to compile into a digest")
+	}
+
+	if strings.TrimSpace(title) == "" {
+		title = "Digest " + digestSpan(digestRange(notes))
+	}
+
+	digest, err := s.CreateNote(title, DigestContent(notes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create digest: %w", err)
+	}
+
+	notebook := notes[0].Notebook
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		ids[i] = note.ID
+		if note.Notebook != notebook {
+			notebook = ""
+		}
+	}
+	if notebook != "" {
+		if err := s.notes.SetNotebook([]int{digest.ID}, notebook); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.AddTagToNote(digest.ID, DigestTag); err != nil {
+		return nil, err
+	}
+
+	if err := s.notes.SetNotebook(ids, models.ArchiveNotebook); err != nil {
+		return nil, fmt.Errorf("failed to archive digested notes: %w", err)
+	}
+	return s.notes.GetByID(digest.ID)
+}
+
+// DigestContent compiles notes into markdown with a "## <date>" section per
+// day, oldest first, holding each note of the day under its title.
+// Headings within notes are moved down to fit, and frontmatter is dropped.
+func DigestContent(notes []*models.Note) string {
+	sorted := append([]*models.Note(nil), notes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return digestDate(sorted[i]).Before(digestDate(sorted[j]))
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Digest of %d notes, %s.\n", len(sorted), digestSpan(digestRange(sorted)))
+
+	day := ""
+	for _, note := range sorted {
+		if date := digestDate(note).Format(digestDateLayout); date != day {
+			day = date
+			fmt.Fprintf(&b, "\n## %s\n", day)
+		}
+
+		content := note.Content
+		if _, body, ok := utils.ParseFrontmatter(content); ok {
+			content = body
+		}
+		fmt.Fprintf(&b, "\n### %s\n", note.Title)
+		if content = strings.TrimSpace(utils.DemoteHeadings(content, 3)); content != "" {
+			b.WriteString("\n" + content + "\n")
+		}
+	}
+	return b.String()
+}
+
+// digestRange returns the first and last day notes are filed under
+func digestRange(notes []*models.Note) (time.Time, time.Time) {
+	first, last := digestDate(notes[0]), digestDate(notes[0])
+	for _, note := range notes[1:] {
+		date := digestDate(note)
+		if date.Before(first) {
+			first = date
+		}
+		if date.After(last) {
+			last = date
+		}
+	}
+	return first, last
+}
+
+// digestSpan describes the days between first and last
+func digestSpan(first, last time.Time) string {
+	from, to := first.Format(digestDateLayout), last.Format(digestDateLayout)
+	if from == to {
+		return from
+	}
+	return from + " to " + to
+}
+
+// digestDate returns the day a note is filed under in a digest: the date
+// in its frontmatter, or when it was created
+func digestDate(note *models.Note) time.Time {
+	if note.Date != nil {
+		return *note.Date
+	}
+	return note.CreatedAt.Local()
+}
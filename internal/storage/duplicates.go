@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+const (
+	// duplicateContent is the content similarity from which two notes are
+	// likely duplicates whatever their titles
+	duplicateContent = 0.6
+	// duplicateTitle is the title similarity from which two notes are
+	// likely duplicates if their content is at least duplicateSharedContent
+	// similar, or both are empty
+	duplicateTitle         = 0.75
+	duplicateSharedContent = 0.3
+
+	// minHashes is the length of the MinHash signatures candidates are
+	// found by, split into bands of a few hashes. Two notes become
+	// candidates when any band of their signatures matches. Bands of
+	// titleRows catch 95% of titles duplicateTitle similar while leaving
+	// out most titles that merely share a word like "Meeting"; bands of
+	// contentRows catch almost all content duplicateContent similar.
+	minHashes   = 32
+	titleRows   = 4
+	contentRows = 2
+)
+
+// noteFingerprint holds what notes are compared by
+type noteFingerprint struct {
+	note    *models.Note
+	title   []uint64 // utils.TitleGrams
+	content []uint64 // utils.Shingles
+}
+
+// FindDuplicates returns the pairs of notes that are likely duplicates,
+// most similar first. Notes are compared by the trigrams of their titles
+// and the runs of words in their content. Rather than comparing every note
+// with every other, candidates are paired by MinHash signatures of both,
+// so large vaults are searched quickly.
+func (s *Service) FindDuplicates() ([]models.DuplicatePair, error) {
+	notes, err := s.notes.GetAll(models.NoteFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	prints := make([]noteFingerprint, len(notes))
+	for i, note := range notes {
+		prints[i] = noteFingerprint{note: note, title: utils.TitleGrams(note.Title), content: utils.Shingles(note.Content)}
+	}
+
+	candidates := map[[2]int]bool{}
+	pairCandidates(prints, func(p noteFingerprint) []uint64 { return p.title }, titleRows, candidates)
+	pairCandidates(prints, func(p noteFingerprint) []uint64 { return p.content }, contentRows, candidates)
+
+	var pairs []models.DuplicatePair
+	for candidate := range candidates {
+		a, b := prints[candidate[0]], prints[candidate[1]]
+		title := utils.Jaccard(a.title, b.title)
+		content := utils.Jaccard(a.content, b.content)
+		bothEmpty := len(a.content) == 0 && len(b.content) == 0
+		if content < duplicateContent && (title < duplicateTitle || content < duplicateSharedContent && !bothEmpty) {
+			continue
+		}
+
+		older, newer := a.note, b.note
+		if newer.CreatedAt.Before(older.CreatedAt) || newer.CreatedAt.Equal(older.CreatedAt) && newer.ID < older.ID {
+			older, newer = newer, older
+		}
+		pairs = append(pairs, models.DuplicatePair{Older: older, Newer: newer, TitleScore: title, ContentScore: content})
+	}
+
+	slices.SortFunc(pairs, func(a, b models.DuplicatePair) int {
+		return cmp.Or(
+			cmp.Compare(b.ContentScore, a.ContentScore),
+			cmp.Compare(b.TitleScore, a.TitleScore),
+			cmp.Compare(a.Older.ID, b.Older.ID),
+			cmp.Compare(a.Newer.ID, b.Newer.ID),
+		)
+	})
+	return pairs, nil
+}
+
+// pairCandidates adds the pairs of notes, as indexes into prints ordered
+// low to high, whose MinHash signatures of the set picked by set share a
+// band of rows hashes
+func pairCandidates(prints []noteFingerprint, set func(noteFingerprint) []uint64, rows int, candidates map[[2]int]bool) {
+	type band struct {
+		index  int
+		hashes [max(titleRows, contentRows)]uint64
+	}
+	buckets := map[band][]int{}
+	for i, p := range prints {
+		items := set(p)
+		if len(items) == 0 {
+			continue
+		}
+		signature := minHash(items)
+		for start := 0; start < minHashes; start += rows {
+			key := band{index: start}
+			copy(key.hashes[:], signature[start:start+rows])
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	for _, bucket := range buckets {
+		for i, a := range bucket {
+			for _, b := range bucket[i+1:] {
+				candidates[[2]int{a, b}] = true
+			}
+		}
+	}
+}
+
+// minHash returns the MinHash signature of a set: for each of minHashes
+// hash functions, the smallest hash of any item. Two sets have the same
+// value at a position with a probability equal to their Jaccard
+// similarity.
+func minHash(items []uint64) [minHashes]uint64 {
+	var signature [minHashes]uint64
+	for i := range signature {
+		signature[i] = math.MaxUint64
+		seed := mix(uint64(i) + 1)
+		for _, item := range items {
+			signature[i] = min(signature[i], mix(item^seed))
+		}
+	}
+	return signature
+}
+
+// mix scrambles the bits of x, the finalizer of SplitMix64
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// MergeNotes merges the note dropID into the note keepID and deletes it.
+// The kept note gets the other's tags and, unless it already holds it, the
+// other's content after its own. Returns the merged note.
+func (s *Service) MergeNotes(keepID, dropID int) (*models.Note, error) {
+	if keepID == dropID {
+		return nil, fmt.Errorf("can't merge a note into itself")
+	}
+	keep, err := s.notes.GetByID(keepID)
+	if err != nil {
+		return nil, err
+	}
+	drop, err := s.notes.GetByID(dropID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := drop.Content
+	if _, rest, ok := utils.ParseFrontmatter(body); ok {
+		body = rest
+	}
+	body = strings.Trim(body, "\n")
+	switch {
+	case body == "" || strings.Contains(keep.Content, body):
+	case strings.TrimSpace(keep.Content) == "":
+		keep.Content = body + "\n"
+	default:
+		keep.Content = strings.TrimRight(keep.Content, "\n") + "\n\n---\n\n" + body + "\n"
+	}
+
+	var tags []string
+	for _, tag := range append(keep.Tags, drop.Tags...) {
+		if !slices.ContainsFunc(tags, func(name string) bool { return strings.EqualFold(name, tag.Name) }) {
+			tags = append(tags, tag.Name)
+		}
+	}
+	if err := s.SaveNoteWithTags(keep, tags); err != nil {
+		return nil, fmt.Errorf("failed to merge %q into %q: %w", drop.Title, keep.Title, err)
+	}
+	if err := s.notes.Delete(dropID); err != nil {
+		return nil, fmt.Errorf("failed to merge %q into %q: %w", drop.Title, keep.Title, err)
+	}
+	return s.notes.GetByID(keepID)
+}
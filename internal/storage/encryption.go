@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters used for new keys. They're stored with the salt so
+// they can be raised later without breaking existing databases.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	keyLength    = 32 // AES-256
+	saltLength   = 16
+)
+
+// checkPlaintext is encrypted with the key on setup so a passphrase can be
+// verified before any note is decrypted with it
+const checkPlaintext = "tuinotes-key-check"
+
+var (
+	// ErrLocked is returned when encrypted notes are read or written before
+	// the database is unlocked
+	ErrLocked = errors.New("notes are encrypted; unlock with the passphrase first")
+	// ErrWrongPassphrase is returned when a passphrase doesn't match the key
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// encryptionKey holds the stored key derivation parameters
+type encryptionKey struct {
+	salt       []byte
+	timeCost   uint32
+	memoryKiB  uint32
+	threads    uint8
+	checkValue string
+}
+
+// loadEncryptionKey reads the key parameters, returning nil when
+// encryption isn't enabled
+func (db *DB) loadEncryptionKey() (*encryptionKey, error) {
+	key := &encryptionKey{}
+	err := db.QueryRow(`SELECT salt, time_cost, memory_kib, threads, check_value FROM encryption_key WHERE id = 1`).
+		Scan(&key.salt, &key.timeCost, &key.memoryKiB, &key.threads, &key.checkValue)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// deriveCipher derives the AES-GCM cipher for a passphrase
+func (k *encryptionKey) deriveCipher(passphrase string) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), k.salt, k.timeCost, k.memoryKiB, k.threads, keyLength)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptString seals plaintext with a random nonce and returns the nonce
+// and ciphertext base64 encoded
+func encryptString(aead cipher.AEAD, plaintext string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptString reverses encryptString
+func decryptString(aead cipher.AEAD, encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted content: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted content is truncated")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// sealContent returns the content to store for a note and whether it's
+// encrypted. Notes are encrypted whenever encryption is enabled.
+func (db *DB) sealContent(content string) (string, bool, error) {
+	if db.key == nil {
+		return content, false, nil
+	}
+	if db.cipher == nil {
+		return "", false, ErrLocked
+	}
+	sealed, err := encryptString(db.cipher, content)
+	if err != nil {
+		return "", false, err
+	}
+	return sealed, true, nil
+}
+
+// openContent returns the plaintext of stored note content
+func (db *DB) openContent(stored string, encrypted bool) (string, error) {
+	if !encrypted {
+		return stored, nil
+	}
+	if db.cipher == nil {
+		return "", ErrLocked
+	}
+	return decryptString(db.cipher, stored)
+}
+
+// EncryptionEnabled reports whether note content is encrypted at rest
+func (s *Service) EncryptionEnabled() bool {
+	return s.db.key != nil
+}
+
+// Locked reports whether encryption is enabled and no passphrase has been
+// given yet, so notes can't be read
+func (s *Service) Locked() bool {
+	return s.db.key != nil && s.db.cipher == nil
+}
+
+// Unlock verifies the passphrase and keeps the derived key in memory so
+// notes can be read and written
+func (s *Service) Unlock(passphrase string) error {
+	if s.db.key == nil {
+		return nil
+	}
+	aead, err := s.db.key.deriveCipher(passphrase)
+	if err != nil {
+		return err
+	}
+	if check, err := decryptString(aead, s.db.key.checkValue); err != nil || check != checkPlaintext {
+		return ErrWrongPassphrase
+	}
+	s.db.cipher = aead
+	return nil
+}
+
+// Lock forgets the derived key. Notes can't be read until Unlock is called again.
+func (s *Service) Lock() {
+	s.db.cipher = nil
+}
+
+// EnableEncryption derives a key from the passphrase and encrypts the
+// content of every note with it in one transaction
+func (s *Service) EnableEncryption(passphrase string) error {
+	if s.db.key != nil {
+		return fmt.Errorf("encryption is already enabled")
+	}
+	if passphrase == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+
+	key := &encryptionKey{
+		salt:      make([]byte, saltLength),
+		timeCost:  argonTime,
+		memoryKiB: argonMemory,
+		threads:   argonThreads,
+	}
+	if _, err := rand.Read(key.salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := key.deriveCipher(passphrase)
+	if err != nil {
+		return err
+	}
+	if key.checkValue, err = encryptString(aead, checkPlaintext); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO encryption_key (id, salt, time_cost, memory_kib, threads, check_value) VALUES (1, ?, ?, ?, ?, ?)`,
+		key.salt, key.timeCost, key.memoryKiB, key.threads, key.checkValue); err != nil {
+		return fmt.Errorf("failed to store encryption key: %w", err)
+	}
+	if err := recryptNotes(tx, func(content string, encrypted bool) (string, bool, error) {
+		if encrypted {
+			return content, true, nil
+		}
+		sealed, err := encryptString(aead, content)
+		return sealed, true, err
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.key, s.db.cipher = key, aead
+	return nil
+}
+
+// DisableEncryption decrypts every note and removes the key. The database
+// must be unlocked.
+func (s *Service) DisableEncryption() error {
+	if s.db.key == nil {
+		return fmt.Errorf("encryption is not enabled")
+	}
+	if s.db.cipher == nil {
+		return ErrLocked
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := recryptNotes(tx, func(content string, encrypted bool) (string, bool, error) {
+		if !encrypted {
+			return content, false, nil
+		}
+		plaintext, err := decryptString(s.db.cipher, content)
+		return plaintext, false, err
+	}); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM encryption_key`); err != nil {
+		return fmt.Errorf("failed to remove encryption key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.key, s.db.cipher = nil, nil
+	return nil
+}
+
+// recryptNotes rewrites the content of every note with convert
+func recryptNotes(tx *sql.Tx, convert func(content string, encrypted bool) (string, bool, error)) error {
+	rows, err := tx.Query(`SELECT id, content, encrypted FROM notes`)
+	if err != nil {
+		return fmt.Errorf("failed to query notes: %w", err)
+	}
+	type storedNote struct {
+		id        int
+		content   string
+		encrypted bool
+	}
+	var notes []storedNote
+	for rows.Next() {
+		var note storedNote
+		if err := rows.Scan(&note.id, &note.content, &note.encrypted); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	for _, note := range notes {
+		content, encrypted, err := convert(note.content, note.encrypted)
+		if err != nil {
+			return fmt.Errorf("failed to convert note %d: %w", note.id, err)
+		}
+		if _, err := tx.Exec(`UPDATE notes SET content = ?, encrypted = ? WHERE id = ?`, content, encrypted, note.id); err != nil {
+			return fmt.Errorf("failed to update note %d: %w", note.id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// duplicateTitleCondition matches notes whose title, ignoring case and
+// surrounding whitespace, is shared with at least one other note
+const duplicateTitleCondition = `LOWER(TRIM(n.title)) IN (
+		SELECT LOWER(TRIM(title)) FROM notes
+		WHERE TRIM(title) != ''
+		GROUP BY LOWER(TRIM(title))
+		HAVING COUNT(*) > 1)`
+
+// GetVaultHealth gathers the counts behind the vault health summary. Notes
+// not updated within staleAfter count as stale.
+func (s *Service) GetVaultHealth(staleAfter time.Duration) (*models.VaultHealth, error) {
+	health := &models.VaultHealth{}
+	cutoff := time.Now().Add(-staleAfter).UTC().Format("2006-01-02 15:04:05")
+
+	counts := []struct {
+		dest  *int
+		query string
+		args  []any
+	}{
+		{&health.TotalNotes, `SELECT COUNT(*) FROM notes`, nil},
+		{&health.UntaggedNotes, `SELECT COUNT(*) FROM notes n
+			WHERE NOT EXISTS (SELECT 1 FROM note_tags nt WHERE nt.note_id = n.id)`, nil},
+		{&health.TotalTags, `SELECT COUNT(*) FROM tags`, nil},
+		{&health.OrphanTags, `SELECT COUNT(*) FROM tags t
+			WHERE NOT EXISTS (SELECT 1 FROM note_tags nt WHERE nt.tag_id = t.id)`, nil},
+		{&health.StaleNotes, `SELECT COUNT(*) FROM notes n
+			WHERE datetime(n.updated_at) < datetime(?)`, []any{cutoff}},
+		{&health.DuplicateNotes, `SELECT COUNT(*) FROM notes n WHERE ` + duplicateTitleCondition, nil},
+		{&health.PageCount, `PRAGMA page_count`, nil},
+		{&health.FreePages, `PRAGMA freelist_count`, nil},
+	}
+	for _, c := range counts {
+		if err := s.db.QueryRow(c.query, c.args...).Scan(c.dest); err != nil {
+			return nil, fmt.Errorf("failed to compute vault health: %w", err)
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT MIN(title) FROM notes
+		WHERE TRIM(title) != ''
+		GROUP BY LOWER(TRIM(title))
+		HAVING COUNT(*) > 1
+		ORDER BY COUNT(*) DESC, MIN(title)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate title: %w", err)
+		}
+		health.DuplicateTitles = append(health.DuplicateTitles, title)
+	}
+
+	return health, rows.Err()
+}
+
+// PruneOrphanTags deletes tags that aren't attached to any note and
+// returns how many were removed
+func (s *Service) PruneOrphanTags() (int, error) {
+	result, err := s.db.Exec(`DELETE FROM tags
+		WHERE NOT EXISTS (SELECT 1 FROM note_tags nt WHERE nt.tag_id = tags.id)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune orphan tags: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(removed), nil
+}
+
+// Compact rebuilds the database file to reclaim free pages
+func (s *Service) Compact() error {
+	if _, err := s.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	return nil
+}
+
+// CheckIntegrity runs SQLite's integrity check and looks for rows that
+// refer to rows which don't exist. It returns the problems found, none
+// when the database is sound.
+func (s *Service) CheckIntegrity() ([]string, error) {
+	rows, err := s.db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if message != "ok" {
+			problems = append(problems, message)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+
+	// foreign_key_check returns a row per broken reference; they're
+	// summed up per table and the table referred to
+	fkRows, err := s.db.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	defer fkRows.Close()
+
+	counts := map[[2]string]int{}
+	var order [][2]string
+	for fkRows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := fkRows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key check: %w", err)
+		}
+		key := [2]string{table, parent}
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	if err := fkRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	for _, key := range order {
+		if counts[key] == 1 {
+			problems = append(problems, fmt.Sprintf("1 row of %s refers to missing %s", key[0], key[1]))
+		} else {
+			problems = append(problems, fmt.Sprintf("%d rows of %s refer to missing %s", counts[key], key[0], key[1]))
+		}
+	}
+	return problems, nil
+}
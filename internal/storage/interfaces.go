@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"context"
+	"time"
+
 	"markdown-note-taking-app/internal/models"
 )
 
@@ -8,13 +11,28 @@ import (
 type NoteRepository interface {
 	Create(note *models.Note) error
 	GetByID(id int) (*models.Note, error)
+	GetByUUID(uuid string) (*models.Note, error)
 	GetAll(filter models.NoteFilter) ([]*models.Note, error)
+	GetAllContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error)
+	GetSummariesContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error)
+	LoadContent(notes []*models.Note) ([]*models.Note, error)
+	CountContext(ctx context.Context, filter models.NoteFilter) (int, error)
 	Update(note *models.Note) error
+	SaveWithTags(note *models.Note, tagNames []string) error
+	SetTimestamps(id int, createdAt, updatedAt time.Time) error
 	Delete(id int) error
+	DeleteMany(ids []int) error
+	SetNotebook(ids []int, notebook string) error
+	SetPinned(id int, pinned bool) error
+	MovePinned(id, offset int) error
+	SetColor(id int, color string) error
 	Search(query string, limit int) ([]*models.Note, error)
+	SearchContext(ctx context.Context, query string, sort models.NoteFilter) ([]*models.Note, error)
 	GetByTag(tagID int) ([]*models.Note, error)
 	AddTag(noteID, tagID int) error
 	RemoveTag(noteID, tagID int) error
+	AddTagToMany(ids []int, tagID int) error
+	RemoveTagFromMany(ids []int, tagID int) error
 }
 
 // TagRepository defines the interface for tag operations
@@ -24,6 +42,8 @@ type TagRepository interface {
 	GetAll() ([]*models.Tag, error)
 	GetByName(name string) (*models.Tag, error)
 	Update(tag *models.Tag) error
+	SetColor(id int, color string) error
 	Delete(id int) error
 	GetNoteTags(noteID int) ([]*models.Tag, error)
+	GetUsage() ([]*models.TagUsage, error)
 }
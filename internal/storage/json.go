@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// jsonFormatVersion is written to every JSON export and bumped when the
+// format changes incompatibly
+const jsonFormatVersion = 1
+
+// jsonExport is the document written by ExportJSON
+type jsonExport struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Notes      []jsonNote    `json:"notes"`
+	Tags       []models.Tag  `json:"tags"`
+	NoteTags   []jsonNoteTag `json:"note_tags"`
+}
+
+// jsonNote holds the stored fields of a note. Aliases and dates aren't
+// included since they're read from the content's frontmatter.
+type jsonNote struct {
+	ID         int               `json:"id"`
+	UUID       string            `json:"uuid,omitempty"`
+	Title      string            `json:"title"`
+	Content    string            `json:"content"`
+	Notebook   string            `json:"notebook,omitempty"`
+	Pinned     bool              `json:"pinned,omitempty"`
+	SortOrder  int               `json:"sort_order,omitempty"`
+	Color      string            `json:"color,omitempty"`
+	Properties []models.Property `json:"properties,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// jsonNoteTag associates a note with a tag by their exported IDs
+type jsonNoteTag struct {
+	NoteID int `json:"note_id"`
+	TagID  int `json:"tag_id"`
+}
+
+// JSONImportResult counts what ImportJSON added
+type JSONImportResult struct {
+	Notes       int
+	TagsCreated int // tags that didn't exist yet; the rest were merged by name
+}
+
+// ExportJSON writes every note, tag and tag association as one JSON
+// document. Encrypted notes are written decrypted, so the database must be
+// unlocked.
+func (s *Service) ExportJSON(w io.Writer) error {
+	if s.Locked() {
+		return fmt.Errorf("failed to export notes: the database is locked")
+	}
+
+	notes, err := s.notes.GetAll(models.NoteFilter{})
+	if err != nil {
+		return err
+	}
+	tags, err := s.tags.GetAll()
+	if err != nil {
+		return err
+	}
+	properties, err := s.GetAllNoteProperties()
+	if err != nil {
+		return err
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].ID < notes[j].ID })
+
+	doc := jsonExport{
+		Version:    jsonFormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Notes:      make([]jsonNote, len(notes)),
+		Tags:       make([]models.Tag, len(tags)),
+		NoteTags:   []jsonNoteTag{},
+	}
+	for i, tag := range tags {
+		doc.Tags[i] = *tag
+	}
+	for i, note := range notes {
+		doc.Notes[i] = jsonNote{
+			ID:         note.ID,
+			UUID:       note.UUID,
+			Title:      note.Title,
+			Content:    note.Content,
+			Notebook:   note.Notebook,
+			Pinned:     note.Pinned,
+			SortOrder:  note.SortOrder,
+			Color:      note.Color,
+			Properties: properties[note.ID],
+			CreatedAt:  note.CreatedAt,
+			UpdatedAt:  note.UpdatedAt,
+		}
+		for _, tag := range note.Tags {
+			doc.NoteTags = append(doc.NoteTags, jsonNoteTag{NoteID: note.ID, TagID: tag.ID})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write JSON export: %w", err)
+	}
+	return nil
+}
+
+// ImportJSON adds the notes of a document written by ExportJSON. Notes get
+// new IDs but keep their UUIDs, unless a note here already has the UUID
+// and the imported one is a copy. Tags are merged with existing ones of
+// the same name, and the associations are remapped to match. Pinned notes
+// follow the ones already pinned here, in their exported order. The
+// document is checked in full before anything is written.
+func (s *Service) ImportJSON(r io.Reader) (JSONImportResult, error) {
+	var result JSONImportResult
+
+	var doc jsonExport
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return result, fmt.Errorf("failed to parse JSON export: %w", err)
+	}
+	if doc.Version != jsonFormatVersion {
+		return result, fmt.Errorf("unsupported JSON export version %d", doc.Version)
+	}
+
+	tagNames := map[int]string{}
+	for _, tag := range doc.Tags {
+		if tag.Name == "" {
+			return result, fmt.Errorf("tag %d has no name", tag.ID)
+		}
+		tagNames[tag.ID] = tag.Name
+	}
+	noteIDs := map[int]int{} // exported ID -> new ID, filled in below
+	for _, note := range doc.Notes {
+		if _, ok := noteIDs[note.ID]; ok {
+			return result, fmt.Errorf("note %d appears twice", note.ID)
+		}
+		noteIDs[note.ID] = 0
+	}
+	for _, link := range doc.NoteTags {
+		if _, ok := noteIDs[link.NoteID]; !ok {
+			return result, fmt.Errorf("tag association refers to unknown note %d", link.NoteID)
+		}
+		if _, ok := tagNames[link.TagID]; !ok {
+			return result, fmt.Errorf("tag association refers to unknown tag %d", link.TagID)
+		}
+	}
+
+	tagIDs := map[int]int{}
+	for _, tag := range doc.Tags {
+		existing, err := s.tags.GetByName(tag.Name)
+		if err != nil {
+			if existing, err = s.tags.Create(tag.Name); err != nil {
+				return result, err
+			}
+			result.TagsCreated++
+		}
+		tagIDs[tag.ID] = existing.ID
+	}
+
+	var pinnedBase int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(sort_order), 0) FROM notes WHERE pinned = 1`).Scan(&pinnedBase); err != nil {
+		return result, fmt.Errorf("failed to get pinned notes: %w", err)
+	}
+
+	for _, exported := range doc.Notes {
+		note := &models.Note{
+			UUID:      exported.UUID,
+			Title:     exported.Title,
+			Content:   exported.Content,
+			Notebook:  exported.Notebook,
+			Pinned:    exported.Pinned,
+			SortOrder: pinnedBase + exported.SortOrder,
+			Color:     exported.Color,
+			CreatedAt: exported.CreatedAt,
+			UpdatedAt: exported.UpdatedAt,
+		}
+		if _, err := s.notes.GetByUUID(note.UUID); err == nil || !utils.IsUUID(note.UUID) {
+			note.UUID = ""
+		}
+		if err := s.ImportNote(note); err != nil {
+			return result, err
+		}
+		for _, property := range exported.Properties {
+			if err := s.SetNoteProperty(note.ID, property.Key, property.Value); err != nil {
+				return result, err
+			}
+		}
+		noteIDs[exported.ID] = note.ID
+		result.Notes++
+	}
+
+	for _, link := range doc.NoteTags {
+		if err := s.notes.AddTag(noteIDs[link.NoteID], tagIDs[link.TagID]); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// migrationFiles holds the migrations, named NNN_description.sql and
+// applied in the order of their numbers. A migration never changes once
+// released; later changes go in a new file with the next number.
+var migrationFiles, _ = fs.Sub(migrationsFS, "migrations")
+
+// legacySchemaVersion is the last migration from before migrations were
+// recorded, when every file ran on each start. Those files create what
+// they need only if it doesn't exist, so they can run once more on
+// databases from that time.
+const legacySchemaVersion = 7
+
+// schemaMigrationsTable records the migrations applied to the database
+const schemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`
+
+// migration is a numbered migration file
+type migration struct {
+	version int
+	file    string
+}
+
+// loadMigrations returns the migration files in the order they're applied
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		if path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		number, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s is not numbered like 001_name.sql", entry.Name())
+		}
+		migrations = append(migrations, migration{version: version, file: entry.Name()})
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations found")
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("migrations %s and %s have the same number", migrations[i-1].file, migrations[i].file)
+		}
+	}
+	return migrations, nil
+}
+
+// apply runs the migration and records it as applied
+func (m migration) apply(tx *sql.Tx) error {
+	content, err := fs.ReadFile(migrationFiles, m.file)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", m.file, err)
+	}
+	if _, err := tx.Exec(string(content)); err != nil {
+		return fmt.Errorf("failed to execute migration %s: %w", m.file, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, strings.TrimSuffix(m.file, ".sql"), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.file, err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the versions of the migrations applied to the
+// database in ascending order, none before schema_migrations exists
+func (db *DB) appliedMigrations() ([]int, error) {
+	exists, err := hasTable(db, "schema_migrations")
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// largeTableRows is how many notes make a migration slow enough to report
+// its progress
+const largeTableRows = 1000
+
+// MigrationProgress is told about each step of a schema migration, as step
+// of total, before it runs. Step 0 reports the pre-migration backup.
+type MigrationProgress func(step, total int, description string)
+
+var (
+	migrationProgressMu sync.Mutex
+	migrationProgress   MigrationProgress
+)
+
+// SetMigrationProgress sets where the progress of migrations on large
+// databases is reported when they're opened, or stops reporting it when
+// progress is nil
+func SetMigrationProgress(progress MigrationProgress) {
+	migrationProgressMu.Lock()
+	defer migrationProgressMu.Unlock()
+	migrationProgress = progress
+}
+
+// currentMigrationProgress returns where migration progress is reported,
+// if anywhere
+func currentMigrationProgress() MigrationProgress {
+	migrationProgressMu.Lock()
+	defer migrationProgressMu.Unlock()
+	return migrationProgress
+}
+
+// countNotes returns how many notes the database holds, 0 before the notes
+// table is created
+func (db *DB) countNotes() int {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&count); err != nil {
+		return 0
+	}
+	return count
+}
+
+// backupBeforeMigration copies the database next to dbPath before it's
+// migrated, so the notes survive a migration that goes wrong in a way the
+// rollback can't undo. Returns the backup's path, or "" for databases that
+// aren't files.
+func (db *DB) backupBeforeMigration(dbPath string) (string, error) {
+	if dbPath == "" || strings.HasPrefix(dbPath, ":memory:") || strings.HasPrefix(dbPath, "file:") {
+		return "", nil
+	}
+
+	stem := fmt.Sprintf("%s.pre-migration-%s", dbPath, time.Now().Format("20060102-150405"))
+	backup := stem
+	for i := 2; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s-%d", stem, i)
+	}
+	if _, err := db.Exec(`VACUUM INTO ?`, backup); err != nil {
+		return "", fmt.Errorf("failed to back up database before migrating: %w", err)
+	}
+	return backup, nil
+}
+
+// fileExists reports whether something exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,10 @@
+-- Key derivation parameters for optional note encryption. A single row
+-- exists once encryption is enabled.
+CREATE TABLE IF NOT EXISTS encryption_key (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    salt BLOB NOT NULL,
+    time_cost INTEGER NOT NULL,
+    memory_kib INTEGER NOT NULL,
+    threads INTEGER NOT NULL,
+    check_value TEXT NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Last synced revision of each note file on a remote sync server: a hash
+-- of the file's content and the ETag the server gave it
+CREATE TABLE IF NOT EXISTS sync_revisions (
+    path TEXT PRIMARY KEY,
+    hash TEXT NOT NULL,
+    etag TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,14 @@
+-- Files attached to notes. The files live in the attachments directory
+-- under the name in file; name is what the user attached. Rows outlive
+-- deleted notes so undoing a delete, which keeps the note ID, brings the
+-- attachments back; AUTOINCREMENT never hands the ID to another note.
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    note_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    file TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_note_id ON attachments(note_id);
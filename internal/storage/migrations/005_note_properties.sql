@@ -0,0 +1,9 @@
+-- Custom key-value properties of notes, one value per key. Like
+-- attachments, rows outlive deleted notes so undoing a delete brings the
+-- properties back.
+CREATE TABLE IF NOT EXISTS note_properties (
+    note_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (note_id, key)
+);
@@ -0,0 +1,6 @@
+-- Text snippets the editor expands when their trigger is followed by Tab
+CREATE TABLE IF NOT EXISTS snippets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    trigger TEXT NOT NULL UNIQUE,
+    body TEXT NOT NULL DEFAULT ''
+);
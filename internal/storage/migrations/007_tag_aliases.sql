@@ -0,0 +1,7 @@
+-- Other spellings of tags, resolved to the canonical tag when tagging.
+-- Aliases are looked up by their key, the form tags are compared in.
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias_key TEXT PRIMARY KEY,
+    alias TEXT NOT NULL,
+    tag TEXT NOT NULL
+);
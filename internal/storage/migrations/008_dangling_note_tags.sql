@@ -0,0 +1,6 @@
+-- Deleting a note used to leave its tag associations behind, as foreign
+-- keys weren't enforced. Now that they are, those rows would make merging
+-- tags fail, so they go.
+DELETE FROM note_tags
+WHERE note_id NOT IN (SELECT id FROM notes)
+   OR tag_id NOT IN (SELECT id FROM tags);
@@ -0,0 +1,7 @@
+-- Due tasks the reminder daemon has notified about, so each is announced
+-- once, and the time a snoozed reminder is due again
+CREATE TABLE IF NOT EXISTS reminders (
+    key TEXT PRIMARY KEY,
+    notified_at DATETIME NOT NULL,
+    snoozed_until DATETIME
+);
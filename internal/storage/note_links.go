@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// FindNoteByTitle returns the note a link by title points at: the note
+// with that title or alias, ignoring case, or else the one whose title
+// makes the same file name in markdown exports. The most recently updated
+// note wins when several match.
+func (s *Service) FindNoteByTitle(title string) (*models.Note, error) {
+	title = strings.TrimSpace(title)
+	var id int
+	err := s.db.QueryRow(`
+		SELECT id FROM notes
+		WHERE LOWER(TRIM(title)) = LOWER(?)
+			OR (aliases != '' AND INSTR(char(10) || LOWER(aliases) || char(10), char(10) || LOWER(?) || char(10)) > 0)
+		ORDER BY LOWER(TRIM(title)) = LOWER(?) DESC, updated_at DESC
+		LIMIT 1`, title, title, title).Scan(&id)
+	if err == nil {
+		return s.notes.GetByID(id)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to find note %q: %w", title, err)
+	}
+
+	slug := utils.Slugify(title)
+	rows, err := s.db.Query(`SELECT id, title FROM notes ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find note %q: %w", title, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var candidate string
+		if err := rows.Scan(&id, &candidate); err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		if slug != "" && utils.Slugify(candidate) == slug {
+			rows.Close()
+			return s.notes.GetByID(id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find note %q: %w", title, err)
+	}
+	return nil, fmt.Errorf("no note titled %q", title)
+}
+
+// ResolveNoteLink returns the note a link target points at, by UUID or ID
+// for note:// links and by title for relative links
+func (s *Service) ResolveNoteLink(target string) (*models.Note, error) {
+	ref, ok := utils.NoteLinkTarget(target)
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%q is not a link to a note", target)
+	case ref.UUID != "":
+		return s.notes.GetByUUID(ref.UUID)
+	case ref.ID > 0:
+		return s.notes.GetByID(ref.ID)
+	}
+	return s.FindNoteByTitle(ref.Title)
+}
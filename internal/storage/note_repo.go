@@ -1,56 +1,177 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
 )
 
+// NewNoteUUID generates the UUID of each new note. It can be replaced to
+// identify notes another way, e.g. by ULIDs; any unique string works.
+var NewNoteUUID = utils.NewUUID
+
 // noteRepository implements NoteRepository
 type noteRepository struct {
 	db *DB
 }
 
+// noteColumns lists the note columns selected by every note query, in the
+// order expected by scanNote
+const noteColumns = "n.id, n.uuid, n.title, n.content, n.encrypted, n.notebook, n.aliases, n.note_date, n.pinned, n.sort_order, n.color, n.created_at, n.updated_at"
+
+// summaryColumns selects a note like noteColumns, but only the opening of
+// its content, followed by its word count. Encrypted content is selected
+// whole as it can only be cut once decrypted.
+const summaryColumns = "n.id, n.uuid, n.title, CASE WHEN n.encrypted THEN n.content ELSE substr(n.content, 1, 4096) END, n.encrypted, n.notebook, n.aliases, n.note_date, n.pinned, n.sort_order, n.color, n.created_at, n.updated_at, n.word_count"
+
+// summaryExcerptRunes is the length of the excerpt kept in summaries, more
+// than any list layout shows
+const summaryExcerptRunes = 300
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanNote scans a row selected with noteColumns into a note, decrypting
+// its content if needed. Columns selected after them are scanned into extra.
+func (r *noteRepository) scanNote(row rowScanner, extra ...any) (*models.Note, error) {
+	note := &models.Note{}
+	var aliases, createdAt, updatedAt string
+	var noteDate sql.NullString
+	var encrypted bool
+
+	dest := []any{&note.ID, &note.UUID, &note.Title, &note.Content, &encrypted, &note.Notebook, &aliases, &noteDate, &note.Pinned, &note.SortOrder, &note.Color, &createdAt, &updatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.Content, err = r.db.openContent(note.Content, encrypted); err != nil {
+		return nil, err
+	}
+
+	note.Aliases, note.Date = decodeMetadata(aliases, noteDate)
+
+	// Parse timestamps
+	note.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	note.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return note, nil
+}
+
+// scanSummary scans a row selected with summaryColumns into a summary,
+// keeping an excerpt of the content in place of the content
+func (r *noteRepository) scanSummary(row rowScanner) (*models.Note, error) {
+	var wordCount int
+	note, err := r.scanNote(row, &wordCount)
+	if err != nil {
+		return nil, err
+	}
+	note.Summary = true
+	note.Excerpt = utils.Excerpt(note.Content, summaryExcerptRunes)
+	note.WordCount = wordCount
+	note.Content = ""
+	return note, nil
+}
+
+// encodeMetadata converts frontmatter metadata to its column values.
+// Aliases are stored one per line and the date as RFC3339 or NULL.
+func encodeMetadata(meta utils.NoteMetadata) (string, any) {
+	var date any
+	if meta.Date != nil {
+		date = meta.Date.Format(time.RFC3339)
+	}
+	return strings.Join(meta.Aliases, "\n"), date
+}
+
+// decodeMetadata reverses encodeMetadata. An unparsable date is dropped.
+func decodeMetadata(aliases string, noteDate sql.NullString) ([]string, *time.Time) {
+	var list []string
+	if aliases != "" {
+		list = strings.Split(aliases, "\n")
+	}
+	if !noteDate.Valid {
+		return list, nil
+	}
+	date, err := time.Parse(time.RFC3339, noteDate.String)
+	if err != nil {
+		return list, nil
+	}
+	return list, &date
+}
+
 // NewNoteRepository creates a new note repository
 func NewNoteRepository(db *DB) NoteRepository {
 	return &noteRepository{db: db}
 }
 
-// Create inserts a new note into the database
+// Create inserts a new note into the database. A non-zero note ID is kept,
+// e.g. when restoring a backup; otherwise a new ID is assigned.
 func (r *noteRepository) Create(note *models.Note) error {
+	return r.insert(r.db, note)
+}
+
+// insert stores a new note through exec, the database or a transaction
+func (r *noteRepository) insert(exec execer, note *models.Note) error {
 	query := `
-		INSERT INTO notes (title, content, created_at, updated_at)
-		VALUES (?, ?, ?, ?)`
+		INSERT INTO notes (id, uuid, title, content, encrypted, notebook, pinned, sort_order, color, word_count, aliases, note_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, note.Title, note.Content, note.CreatedAt, note.UpdatedAt)
+	var id any
+	if note.ID != 0 {
+		id = note.ID
+	}
+
+	meta := utils.ParseNoteMetadata(note.Content)
+	note.Aliases, note.Date = meta.Aliases, meta.Date
+	aliases, noteDate := encodeMetadata(meta)
+
+	content, encrypted, err := r.db.sealContent(note.Content)
 	if err != nil {
 		return fmt.Errorf("failed to create note: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	if note.UUID == "" {
+		note.UUID = NewNoteUUID()
+	}
+
+	sortOrder := 0
+	if note.Pinned {
+		sortOrder = note.SortOrder
+	}
+	result, err := exec.Exec(query, id, note.UUID, note.Title, content, encrypted, note.Notebook, note.Pinned, sortOrder, note.Color,
+		utils.WordCount(note.Content), aliases, noteDate, note.CreatedAt, note.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+
+	insertedID, err := result.LastInsertId()
 	if err != nil {
 		return fmt.Errorf("failed to get inserted note ID: %w", err)
 	}
 
-	note.ID = int(id)
+	note.ID = int(insertedID)
 	return nil
 }
 
 // GetByID retrieves a note by its ID
 func (r *noteRepository) GetByID(id int) (*models.Note, error) {
-	query := `
-		SELECT id, title, content, created_at, updated_at
-		FROM notes
-		WHERE id = ?`
+	query := `SELECT ` + noteColumns + ` FROM notes n WHERE n.id = ?`
 
-	note := &models.Note{}
-	var createdAt, updatedAt string
-
-	err := r.db.QueryRow(query, id).Scan(
-		&note.ID, &note.Title, &note.Content, &createdAt, &updatedAt)
+	note, err := r.scanNote(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("note with ID %d not found", id)
@@ -58,18 +179,29 @@ func (r *noteRepository) GetByID(id int) (*models.Note, error) {
 		return nil, fmt.Errorf("failed to get note: %w", err)
 	}
 
-	// Parse timestamps
-	note.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	// Load tags
+	tags, err := r.getNoteTags(context.Background(), note.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		return nil, fmt.Errorf("failed to load tags: %w", err)
 	}
-	note.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+	note.Tags = tags
+
+	return note, nil
+}
+
+// GetByUUID retrieves a note by its UUID
+func (r *noteRepository) GetByUUID(uuid string) (*models.Note, error) {
+	query := `SELECT ` + noteColumns + ` FROM notes n WHERE n.uuid = ?`
+
+	note, err := r.scanNote(r.db.QueryRow(query, uuid))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("note with UUID %s not found", uuid)
+		}
+		return nil, fmt.Errorf("failed to get note: %w", err)
 	}
 
-	// Load tags
-	tags, err := r.getNoteTags(note.ID)
+	tags, err := r.getNoteTags(context.Background(), note.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load tags: %w", err)
 	}
@@ -80,28 +212,35 @@ func (r *noteRepository) GetByID(id int) (*models.Note, error) {
 
 // GetAll retrieves all notes with optional filtering
 func (r *noteRepository) GetAll(filter models.NoteFilter) ([]*models.Note, error) {
-	query := `
-		SELECT DISTINCT n.id, n.title, n.content, n.created_at, n.updated_at
-		FROM notes n`
+	return r.GetAllContext(context.Background(), filter)
+}
 
-	args := []any{}
-	conditions := []string{}
+// GetAllContext retrieves notes with optional filtering, aborting when ctx is cancelled
+func (r *noteRepository) GetAllContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error) {
+	return r.getAll(ctx, filter, false)
+}
 
-	// Add search condition
-	if filter.SearchQuery != "" {
-		conditions = append(conditions, "(n.title LIKE ? OR n.content LIKE ?)")
-		searchPattern := "%" + filter.SearchQuery + "%"
-		args = append(args, searchPattern, searchPattern)
+// GetSummariesContext retrieves summaries of the notes matching a filter,
+// which leave out all but an excerpt of the content
+func (r *noteRepository) GetSummariesContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error) {
+	return r.getAll(ctx, filter, true)
+}
+
+// getAll retrieves the notes matching a filter, or their summaries
+func (r *noteRepository) getAll(ctx context.Context, filter models.NoteFilter, summaries bool) ([]*models.Note, error) {
+	columns := noteColumns
+	if summaries {
+		columns = summaryColumns
 	}
+	query := `SELECT DISTINCT ` + columns + ` FROM notes n`
 
-	// Add tag filter
-	if len(filter.TagIDs) > 0 {
-		placeholders := strings.Repeat("?,", len(filter.TagIDs))
-		placeholders = placeholders[:len(placeholders)-1] // Remove trailing comma
-		conditions = append(conditions, fmt.Sprintf("n.id IN (SELECT note_id FROM note_tags WHERE tag_id IN (%s))", placeholders))
-		for _, tagID := range filter.TagIDs {
-			args = append(args, tagID)
-		}
+	conditions, args := filterConditions(filter)
+	if filter.After > 0 {
+		// Page on from the note the previous page ended with, which is
+		// joined as c so the sort values are compared as stored
+		query += ` JOIN notes c ON c.id = ?`
+		args = append([]any{filter.After}, args...)
+		conditions = append(conditions, afterCondition(filter))
 	}
 
 	// Add WHERE clause if we have conditions
@@ -110,19 +249,15 @@ func (r *noteRepository) GetAll(filter models.NoteFilter) ([]*models.Note, error
 	}
 
 	// Add ordering
-	query += " ORDER BY n.updated_at DESC"
+	query += " ORDER BY " + orderClause(filter)
 
 	// Add pagination
 	if filter.Limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, filter.Limit)
-		if filter.Offset > 0 {
-			query += " OFFSET ?"
-			args = append(args, filter.Offset)
-		}
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query notes: %w", err)
 	}
@@ -130,46 +265,270 @@ func (r *noteRepository) GetAll(filter models.NoteFilter) ([]*models.Note, error
 
 	var notes []*models.Note
 	for rows.Next() {
-		note := &models.Note{}
-		var createdAt, updatedAt string
-
-		err := rows.Scan(&note.ID, &note.Title, &note.Content, &createdAt, &updatedAt)
+		var note *models.Note
+		var err error
+		if summaries {
+			note, err = r.scanSummary(rows)
+		} else {
+			note, err = r.scanNote(rows)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan note: %w", err)
 		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query notes: %w", err)
+	}
+	rows.Close()
 
-		// Parse timestamps
-		note.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	// Load the tags of all the notes at once rather than note by note
+	if err := r.loadTags(ctx, notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// CountContext counts the notes matching a filter, ignoring its limit and
+// offset
+func (r *noteRepository) CountContext(ctx context.Context, filter models.NoteFilter) (int, error) {
+	query := `SELECT COUNT(DISTINCT n.id) FROM notes n`
+	conditions, args := filterConditions(filter)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count notes: %w", err)
+	}
+	return count, nil
+}
+
+// textMatch matches a LIKE pattern against the title or the content.
+// Encrypted content can't be searched in SQL, so only titles match there.
+const textMatch = "(n.title LIKE ? OR (n.encrypted = 0 AND n.content LIKE ?))"
+
+// filterConditions translates a NoteFilter into SQL conditions and arguments
+func filterConditions(filter models.NoteFilter) ([]string, []any) {
+	args := []any{}
+	conditions := []string{}
+
+	// Add search condition
+	if filter.SearchQuery != "" {
+		conditions = append(conditions, textMatch)
+		searchPattern := "%" + filter.SearchQuery + "%"
+		args = append(args, searchPattern, searchPattern)
+	}
+
+	// Every term must match the title or content
+	for _, term := range filter.Terms {
+		conditions = append(conditions, textMatch)
+		pattern := "%" + term + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	// Excluded terms may appear in neither
+	for _, term := range filter.ExcludeTerms {
+		conditions = append(conditions, "NOT "+textMatch)
+		pattern := "%" + term + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	for _, term := range filter.TitleTerms {
+		conditions = append(conditions, "n.title LIKE ?")
+		args = append(args, "%"+term+"%")
+	}
+
+	if filter.Notebook != "" {
+		conditions = append(conditions, "n.notebook = ? COLLATE NOCASE")
+		args = append(args, filter.Notebook)
+	}
+
+	switch filter.Color {
+	case "":
+	case models.NoColor:
+		conditions = append(conditions, "n.color = ''")
+	default:
+		conditions = append(conditions, "n.color = ?")
+		args = append(args, filter.Color)
+	}
+
+	// Add tag filter. A parent tag also matches the tags nested under it.
+	if len(filter.TagIDs) > 0 {
+		placeholders := strings.Repeat("?,", len(filter.TagIDs))
+		placeholders = placeholders[:len(placeholders)-1] // Remove trailing comma
+		conditions = append(conditions, fmt.Sprintf(`n.id IN (
+			SELECT nt.note_id FROM note_tags nt
+			JOIN tags t ON t.id = nt.tag_id
+			JOIN tags p ON t.id = p.id OR substr(t.name, 1, length(p.name) + 1) = p.name || '/'
+			WHERE p.id IN (%s))`, placeholders))
+		for _, tagID := range filter.TagIDs {
+			args = append(args, tagID)
 		}
-		note.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	// Tag names are matched case-insensitively; each one is required. A
+	// parent tag such as work also matches the tags nested under it, like
+	// work/project-x.
+	const taggedWith = `n.id IN (
+		SELECT nt.note_id FROM note_tags nt
+		JOIN tags t ON t.id = nt.tag_id
+		WHERE t.name = ? COLLATE NOCASE
+			OR LOWER(substr(t.name, 1, length(?) + 1)) = LOWER(?))`
+	for _, name := range filter.TagNames {
+		name = utils.NormalizeTag(name)
+		conditions = append(conditions, taggedWith)
+		args = append(args, name, name, name+utils.TagSeparator)
+	}
+	for _, name := range filter.ExcludeTagNames {
+		name = utils.NormalizeTag(name)
+		conditions = append(conditions, "NOT "+taggedWith)
+		args = append(args, name, name, name+utils.TagSeparator)
+	}
+
+	if filter.Untagged {
+		conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM note_tags nt WHERE nt.note_id = n.id)")
+	}
+
+	if filter.Duplicates {
+		conditions = append(conditions, duplicateTitleCondition)
+	}
+
+	if filter.Pinned {
+		conditions = append(conditions, "n.pinned = 1")
+	}
+
+	// Date bounds are compared in UTC so stored offsets don't matter
+	addDateBound := func(column, op string, bound *time.Time) {
+		if bound == nil {
+			return
 		}
+		conditions = append(conditions, fmt.Sprintf("datetime(n.%s) %s datetime(?)", column, op))
+		args = append(args, bound.UTC().Format("2006-01-02 15:04:05"))
+	}
+	addDateBound("created_at", ">=", filter.CreatedAfter)
+	addDateBound("created_at", "<", filter.CreatedBefore)
+	addDateBound("updated_at", ">=", filter.UpdatedAfter)
+	addDateBound("updated_at", "<", filter.UpdatedBefore)
 
-		// Load tags for this note
-		tags, err := r.getNoteTags(note.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load tags for note %d: %w", note.ID, err)
+	return conditions, args
+}
+
+// orderTerm is one expression notes are ordered by
+type orderTerm struct {
+	column    string
+	ascending bool
+}
+
+// orderTerms returns what notes are ordered by for the filter, with the
+// notes table named alias. The note ID is always a tie-breaker so notes
+// sharing a timestamp keep the same relative order between refreshes.
+func orderTerms(filter models.NoteFilter, alias string) []orderTerm {
+	primary := filter.SortBy
+	if primary == "" {
+		primary = models.SortByUpdated
+	}
+	secondary := filter.SecondarySort
+	if secondary == "" {
+		secondary = models.SortByID
+	}
+
+	var terms []orderTerm
+	if filter.PinnedFirst {
+		terms = append(terms, orderTerm{alias + ".pinned", false}, orderTerm{alias + ".sort_order", true})
+	}
+	terms = append(terms, sortTerm(primary, filter.Reverse, alias))
+	if secondary != primary {
+		terms = append(terms, sortTerm(secondary, false, alias))
+	}
+	if primary != models.SortByID && secondary != models.SortByID {
+		terms = append(terms, sortTerm(models.SortByID, false, alias))
+	}
+	return terms
+}
+
+// orderClause builds a deterministic ORDER BY clause for the filter
+func orderClause(filter models.NoteFilter) string {
+	var clause []string
+	for _, term := range orderTerms(filter, "n") {
+		if term.ascending {
+			clause = append(clause, term.column+" ASC")
+		} else {
+			clause = append(clause, term.column+" DESC")
 		}
-		note.Tags = tags
+	}
+	return strings.Join(clause, ", ")
+}
 
-		notes = append(notes, note)
+// afterCondition matches the notes n that sort after the note c: those
+// ordered after it by the first term they differ in
+func afterCondition(filter models.NoteFilter) string {
+	notes, cursor := orderTerms(filter, "n"), orderTerms(filter, "c")
+	var alternatives []string
+	for i := range notes {
+		var parts []string
+		for j := range i {
+			parts = append(parts, notes[j].column+" = "+cursor[j].column)
+		}
+		op := " < "
+		if notes[i].ascending {
+			op = " > "
+		}
+		parts = append(parts, notes[i].column+op+cursor[i].column)
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
 	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
 
-	return notes, rows.Err()
+// sortTerm returns the ordering by a single sort field of the notes table
+// named alias. Titles sort A-Z in the configured locale and everything
+// else largest or newest first unless reversed.
+func sortTerm(field models.SortField, reverse bool, alias string) orderTerm {
+	var column string
+	ascending := false
+	switch field {
+	case models.SortByTitle:
+		column = alias + ".title COLLATE " + localeCollation
+		ascending = true
+	case models.SortByCreated:
+		column = alias + ".created_at"
+	case models.SortByID:
+		column = alias + ".id"
+	case models.SortByTags:
+		column = "(SELECT COUNT(*) FROM note_tags nt WHERE nt.note_id = " + alias + ".id)"
+	case models.SortByWords:
+		column = alias + ".word_count"
+	default:
+		column = alias + ".updated_at"
+	}
+	return orderTerm{column: column, ascending: ascending != reverse}
 }
 
 // Update modifies an existing note
 func (r *noteRepository) Update(note *models.Note) error {
+	return r.update(r.db, note)
+}
+
+// update stores a changed note through exec, the database or a transaction
+func (r *noteRepository) update(exec execer, note *models.Note) error {
 	query := `
 		UPDATE notes
-		SET title = ?, content = ?, updated_at = ?
+		SET title = ?, content = ?, encrypted = ?, notebook = ?, word_count = ?, aliases = ?, note_date = ?, updated_at = ?
 		WHERE id = ?`
 
+	meta := utils.ParseNoteMetadata(note.Content)
+	note.Aliases, note.Date = meta.Aliases, meta.Date
+	aliases, noteDate := encodeMetadata(meta)
+
+	content, encrypted, err := r.db.sealContent(note.Content)
+	if err != nil {
+		return fmt.Errorf("failed to update note: %w", err)
+	}
+
 	note.UpdatedAt = time.Now()
-	result, err := r.db.Exec(query, note.Title, note.Content, note.UpdatedAt, note.ID)
+	result, err := exec.Exec(query, note.Title, content, encrypted, note.Notebook,
+		utils.WordCount(note.Content), aliases, noteDate, note.UpdatedAt, note.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update note: %w", err)
 	}
@@ -186,6 +545,78 @@ func (r *noteRepository) Update(note *models.Note) error {
 	return nil
 }
 
+// SaveWithTags creates the note when it has no ID yet, or else updates
+// it, and replaces its tags with the named ones, creating tags that don't
+// exist. Either all of it is stored or, on error, none of it.
+func (r *noteRepository) SaveWithTags(note *models.Note, tagNames []string) error {
+	id, updatedAt := note.ID, note.UpdatedAt
+	if err := r.saveWithTags(note, tagNames); err != nil {
+		// Nothing was stored, so the note keeps its ID and date
+		note.ID, note.UpdatedAt = id, updatedAt
+		return err
+	}
+	return nil
+}
+
+// saveWithTags stores the note and its tags in one transaction
+func (r *noteRepository) saveWithTags(note *models.Note, tagNames []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if note.ID == 0 {
+		err = r.insert(tx, note)
+	} else {
+		err = r.update(tx, note)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM note_tags WHERE note_id = ?`, note.ID); err != nil {
+		return fmt.Errorf("failed to clear note tags: %w", err)
+	}
+	for _, name := range tagNames {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO note_tags (note_id, tag_id)
+			SELECT ?, id FROM tags WHERE name = ?`, note.ID, name)
+		if err != nil {
+			return fmt.Errorf("failed to add tag %q to note: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetTimestamps sets a note's created_at and updated_at explicitly
+func (r *noteRepository) SetTimestamps(id int, createdAt, updatedAt time.Time) error {
+	query := `UPDATE notes SET created_at = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, createdAt, updatedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to set note timestamps: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note with ID %d not found", id)
+	}
+
+	return nil
+}
+
 // Delete removes a note from the database
 func (r *noteRepository) Delete(id int) error {
 	query := `DELETE FROM notes WHERE id = ?`
@@ -207,13 +638,173 @@ func (r *noteRepository) Delete(id int) error {
 	return nil
 }
 
-// Search performs a full-text search on notes
-func (r *noteRepository) Search(query string, limit int) ([]*models.Note, error) {
-	filter := models.NoteFilter{
-		SearchQuery: query,
-		Limit:       limit,
+// DeleteMany removes several notes and their tag associations in one transaction
+func (r *noteRepository) DeleteMany(ids []int) error {
+	if len(ids) == 0 {
+		return nil
 	}
-	return r.GetAll(filter)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	placeholders, args := inClause(ids)
+	if _, err := tx.Exec("DELETE FROM note_tags WHERE note_id IN ("+placeholders+")", args...); err != nil {
+		return fmt.Errorf("failed to delete note tags: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM notes WHERE id IN ("+placeholders+")", args...); err != nil {
+		return fmt.Errorf("failed to delete notes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetPinned pins or unpins a note. A newly pinned note goes after the
+// notes pinned before it; pinning a pinned note keeps its place.
+func (r *noteRepository) SetPinned(id int, pinned bool) error {
+	query := `UPDATE notes SET pinned = 0, sort_order = 0 WHERE id = ?`
+	if pinned {
+		query = `
+			UPDATE notes
+			SET sort_order = CASE WHEN pinned = 1 THEN sort_order
+				ELSE (SELECT COALESCE(MAX(sort_order), 0) + 1 FROM notes WHERE pinned = 1) END,
+				pinned = 1
+			WHERE id = ?`
+	}
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to pin note: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// MovePinned moves a pinned note offset places up (negative) or down among
+// the pinned notes, stopping at either end. The pinned notes are renumbered
+// from 1 so the order stays free of gaps and ties.
+func (r *noteRepository) MovePinned(id, offset int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM notes WHERE pinned = 1 ORDER BY sort_order, id`)
+	if err != nil {
+		return fmt.Errorf("failed to query pinned notes: %w", err)
+	}
+	var ids []int
+	from := -1
+	for rows.Next() {
+		var pinnedID int
+		if err := rows.Scan(&pinnedID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan pinned note: %w", err)
+		}
+		if pinnedID == id {
+			from = len(ids)
+		}
+		ids = append(ids, pinnedID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query pinned notes: %w", err)
+	}
+	if from < 0 {
+		return fmt.Errorf("note with ID %d is not pinned", id)
+	}
+
+	to := max(0, min(from+offset, len(ids)-1))
+	ids = append(ids[:from], ids[from+1:]...)
+	ids = append(ids[:to], append([]int{id}, ids[to:]...)...)
+
+	for i, pinnedID := range ids {
+		if _, err := tx.Exec(`UPDATE notes SET sort_order = ? WHERE id = ?`, i+1, pinnedID); err != nil {
+			return fmt.Errorf("failed to reorder pinned notes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetColor sets the color label of a note. An empty name removes it.
+func (r *noteRepository) SetColor(id int, color string) error {
+	result, err := r.db.Exec(`UPDATE notes SET color = ? WHERE id = ?`, color, id)
+	if err != nil {
+		return fmt.Errorf("failed to set note color: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// SetNotebook moves several notes into a notebook. An empty name removes
+// them from any notebook.
+func (r *noteRepository) SetNotebook(ids []int, notebook string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(ids)
+	query := "UPDATE notes SET notebook = ? WHERE id IN (" + placeholders + ")"
+	if _, err := r.db.Exec(query, append([]any{notebook}, args...)...); err != nil {
+		return fmt.Errorf("failed to move notes to notebook: %w", err)
+	}
+	return nil
+}
+
+// inClause returns "?,?,..." placeholders and arguments for an IN clause
+func inClause(ids []int) (string, []any) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return placeholders, args
+}
+
+// Search performs a full-text search on notes. The query may use the
+// operator syntax understood by utils.ParseQuery (tag:, title:, -word, ...).
+func (r *noteRepository) Search(query string, limit int) ([]*models.Note, error) {
+	return r.SearchContext(context.Background(), query, models.NoteFilter{Limit: limit})
+}
+
+// SearchContext performs a search that can be cancelled through ctx. The
+// results are ordered and limited as sort asks, like the notes list.
+func (r *noteRepository) SearchContext(ctx context.Context, query string, sort models.NoteFilter) ([]*models.Note, error) {
+	filter := utils.ParseQuery(query)
+	filter.SortBy = sort.SortBy
+	filter.SecondarySort = sort.SecondarySort
+	filter.Reverse = sort.Reverse
+	filter.PinnedFirst = sort.PinnedFirst
+	filter.Limit = sort.Limit
+	return r.GetAllContext(ctx, filter)
 }
 
 // GetByTag retrieves all notes with a specific tag
@@ -259,16 +850,171 @@ func (r *noteRepository) RemoveTag(noteID, tagID int) error {
 	return nil
 }
 
+// AddTagToMany adds a tag to several notes in one statement, skipping
+// notes that already have it
+func (r *noteRepository) AddTagToMany(ids []int, tagID int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(ids)
+	query := "INSERT OR IGNORE INTO note_tags (note_id, tag_id) SELECT id, ? FROM notes WHERE id IN (" + placeholders + ")"
+	if _, err := r.db.Exec(query, append([]any{tagID}, args...)...); err != nil {
+		return fmt.Errorf("failed to add tag to notes: %w", err)
+	}
+	return nil
+}
+
+// RemoveTagFromMany removes a tag from several notes, skipping notes without it
+func (r *noteRepository) RemoveTagFromMany(ids []int, tagID int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(ids)
+	query := "DELETE FROM note_tags WHERE tag_id = ? AND note_id IN (" + placeholders + ")"
+	if _, err := r.db.Exec(query, append([]any{tagID}, args...)...); err != nil {
+		return fmt.Errorf("failed to remove tag from notes: %w", err)
+	}
+	return nil
+}
+
+// LoadContent returns notes with their content, loading it for those that
+// are summaries. Summaries are copied rather than filled in, as they may
+// still be shown.
+func (r *noteRepository) LoadContent(notes []*models.Note) ([]*models.Note, error) {
+	loaded := make([]*models.Note, len(notes))
+	byID := map[int]*models.Note{}
+	var ids []int
+	for i, note := range notes {
+		loaded[i] = note
+		if !note.Summary {
+			continue
+		}
+		copied := *note
+		copied.Summary = false
+		copied.Excerpt = ""
+		copied.Tags = slices.Clone(note.Tags)
+		loaded[i] = &copied
+		byID[note.ID] = &copied
+		ids = append(ids, note.ID)
+	}
+
+	for start := 0; start < len(ids); start += tagBatchSize {
+		placeholders, args := inClause(ids[start:min(start+tagBatchSize, len(ids))])
+		query := `SELECT id, content, encrypted FROM notes WHERE id IN (` + placeholders + `)`
+		if err := r.scanContents(query, args, byID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Whatever is left was deleted since it was listed
+	for id := range byID {
+		return nil, fmt.Errorf("note with ID %d not found", id)
+	}
+	return loaded, nil
+}
+
+// scanContents runs a query of note IDs and their content, setting the
+// content of each note and removing it from byID
+func (r *noteRepository) scanContents(query string, args []any, byID map[int]*models.Note) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query note content: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var content string
+		var encrypted bool
+		if err := rows.Scan(&id, &content, &encrypted); err != nil {
+			return fmt.Errorf("failed to scan note content: %w", err)
+		}
+		note, ok := byID[id]
+		if !ok {
+			continue
+		}
+		if note.Content, err = r.db.openContent(content, encrypted); err != nil {
+			return err
+		}
+		delete(byID, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query note content: %w", err)
+	}
+	return nil
+}
+
+// tagBatchSize caps the note IDs in each query of loadTags, well below
+// SQLite's limit on query parameters
+const tagBatchSize = 500
+
+// loadTags sets the tags of several notes, querying them in batches
+// instead of once per note
+func (r *noteRepository) loadTags(ctx context.Context, notes []*models.Note) error {
+	byID := make(map[int]*models.Note, len(notes))
+	ids := make([]int, 0, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+		ids = append(ids, note.ID)
+	}
+
+	for start := 0; start < len(ids); start += tagBatchSize {
+		placeholders, args := inClause(ids[start:min(start+tagBatchSize, len(ids))])
+		query := `
+			SELECT nt.note_id, t.id, t.name, t.color
+			FROM tags t
+			JOIN note_tags nt ON t.id = nt.tag_id
+			WHERE nt.note_id IN (` + placeholders + `)`
+
+		if err := r.scanNoteTags(ctx, query, args, byID); err != nil {
+			return err
+		}
+	}
+
+	// Sorting each note's few tags here is far cheaper than having SQLite
+	// call back into Go to sort all the tags together
+	for _, note := range notes {
+		slices.SortFunc(note.Tags, func(a, b models.Tag) int {
+			return compareLocale(a.Name, b.Name)
+		})
+	}
+	return nil
+}
+
+// scanNoteTags runs a query of note IDs and their tags, appending each tag
+// to its note
+func (r *noteRepository) scanNoteTags(ctx context.Context, query string, args []any, byID map[int]*models.Note) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query note tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID int
+		var tag models.Tag
+		if err := rows.Scan(&noteID, &tag.ID, &tag.Name, &tag.Color); err != nil {
+			return fmt.Errorf("failed to scan tag: %w", err)
+		}
+		if note := byID[noteID]; note != nil {
+			note.Tags = append(note.Tags, tag)
+		}
+	}
+	return rows.Err()
+}
+
 // getNoteTags retrieves all tags for a specific note
-func (r *noteRepository) getNoteTags(noteID int) ([]models.Tag, error) {
+func (r *noteRepository) getNoteTags(ctx context.Context, noteID int) ([]models.Tag, error) {
 	query := `
-		SELECT t.id, t.name
+		SELECT t.id, t.name, t.color
 		FROM tags t
 		JOIN note_tags nt ON t.id = nt.tag_id
 		WHERE nt.note_id = ?
-		ORDER BY t.name`
+		ORDER BY t.name COLLATE ` + localeCollation
 
-	rows, err := r.db.Query(query, noteID)
+	rows, err := r.db.QueryContext(ctx, query, noteID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query note tags: %w", err)
 	}
@@ -277,7 +1023,7 @@ func (r *noteRepository) getNoteTags(noteID int) ([]models.Tag, error) {
 	var tags []models.Tag
 	for rows.Next() {
 		var tag models.Tag
-		err := rows.Scan(&tag.ID, &tag.Name)
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.Color)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag: %w", err)
 		}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// GetNoteProperties returns the custom properties of a note, sorted by key
+func (s *Service) GetNoteProperties(noteID int) ([]models.Property, error) {
+	rows, err := s.db.Query(`
+		SELECT key, value FROM note_properties
+		WHERE note_id = ? ORDER BY key COLLATE NOCASE`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note properties: %w", err)
+	}
+	defer rows.Close()
+
+	var properties []models.Property
+	for rows.Next() {
+		var property models.Property
+		if err := rows.Scan(&property.Key, &property.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan note property: %w", err)
+		}
+		properties = append(properties, property)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get note properties: %w", err)
+	}
+	return properties, nil
+}
+
+// GetAllNoteProperties returns the custom properties of every note, keyed
+// by note ID and sorted by key. Rows left behind by deleted notes are
+// skipped.
+func (s *Service) GetAllNoteProperties() (map[int][]models.Property, error) {
+	rows, err := s.db.Query(`
+		SELECT p.note_id, p.key, p.value FROM note_properties p
+		JOIN notes n ON n.id = p.note_id
+		ORDER BY p.note_id, p.key COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note properties: %w", err)
+	}
+	defer rows.Close()
+
+	properties := map[int][]models.Property{}
+	for rows.Next() {
+		var noteID int
+		var property models.Property
+		if err := rows.Scan(&noteID, &property.Key, &property.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan note property: %w", err)
+		}
+		properties[noteID] = append(properties[noteID], property)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get note properties: %w", err)
+	}
+	return properties, nil
+}
+
+// SetNoteProperty sets a custom property of a note, replacing any value
+// the key had
+func (s *Service) SetNoteProperty(noteID int, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return fmt.Errorf("property key cannot be empty")
+	}
+	if _, err := s.notes.GetByID(noteID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO note_properties (note_id, key, value) VALUES (?, ?, ?)
+		ON CONFLICT(note_id, key) DO UPDATE SET value = excluded.value`,
+		noteID, key, strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("failed to set note property: %w", err)
+	}
+	return nil
+}
+
+// DeleteNoteProperty removes a custom property from a note
+func (s *Service) DeleteNoteProperty(noteID int, key string) error {
+	result, err := s.db.Exec(`DELETE FROM note_properties WHERE note_id = ? AND key = ?`, noteID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete note property: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note %d has no property %q", noteID, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// GetReminders returns the reminders sent for due tasks, keyed by task key
+func (s *Service) GetReminders() (map[string]*models.Reminder, error) {
+	rows, err := s.db.Query(`SELECT key, notified_at, snoozed_until FROM reminders`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	defer rows.Close()
+
+	reminders := map[string]*models.Reminder{}
+	for rows.Next() {
+		reminder := &models.Reminder{}
+		var snoozed sql.NullTime
+		if err := rows.Scan(&reminder.Key, &reminder.NotifiedAt, &snoozed); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		if snoozed.Valid {
+			reminder.SnoozedUntil = &snoozed.Time
+		}
+		reminders[reminder.Key] = reminder
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// MarkReminded records that the task with key was reminded about at the
+// given time, ending any snooze
+func (s *Service) MarkReminded(key string, at time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO reminders (key, notified_at, snoozed_until) VALUES (?, ?, NULL)
+		ON CONFLICT(key) DO UPDATE SET notified_at = excluded.notified_at, snoozed_until = NULL`,
+		key, at)
+	if err != nil {
+		return fmt.Errorf("failed to record reminder: %w", err)
+	}
+	return nil
+}
+
+// SnoozeReminder has the task with key reminded about again at until
+func (s *Service) SnoozeReminder(key string, until time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO reminders (key, notified_at, snoozed_until) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET snoozed_until = excluded.snoozed_until`,
+		key, time.Now(), until)
+	if err != nil {
+		return fmt.Errorf("failed to snooze reminder: %w", err)
+	}
+	return nil
+}
+
+// DeleteReminders forgets the reminders with the given keys, e.g. those of
+// tasks done or removed since
+func (s *Service) DeleteReminders(keys []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, key := range keys {
+		if _, err := tx.Exec(`DELETE FROM reminders WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("failed to delete reminder: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
 )
 
 // Service provides high-level operations combining repositories
@@ -11,6 +17,9 @@ type Service struct {
 	db    *DB
 	notes NoteRepository
 	tags  TagRepository
+
+	// attachmentsDir overrides where attached files are stored
+	attachmentsDir string
 }
 
 // NewService creates a new storage service
@@ -40,22 +49,188 @@ func (s *Service) CreateNote(title, content string) (*models.Note, error) {
 	if err := s.notes.Create(note); err != nil {
 		return nil, err
 	}
+	if err := s.applyFrontmatterTags(note); err != nil {
+		return nil, err
+	}
 	return note, nil
 }
 
+// ImportNote creates a note keeping its CreatedAt and UpdatedAt so imported
+// notes retain their original dates. Zero timestamps default to now.
+func (s *Service) ImportNote(note *models.Note) error {
+	now := time.Now()
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = now
+	}
+	if note.UpdatedAt.IsZero() {
+		note.UpdatedAt = note.CreatedAt
+	}
+	return s.notes.Create(note)
+}
+
+// SetNoteTimestamps overrides a note's creation and modification times,
+// e.g. to correct the dates of an imported note
+func (s *Service) SetNoteTimestamps(id int, createdAt, updatedAt time.Time) error {
+	if updatedAt.Before(createdAt) {
+		return fmt.Errorf("updated time %s is before created time %s",
+			updatedAt.Format(time.RFC3339), createdAt.Format(time.RFC3339))
+	}
+	return s.notes.SetTimestamps(id, createdAt, updatedAt)
+}
+
 // GetNote retrieves a note by ID
 func (s *Service) GetNote(id int) (*models.Note, error) {
 	return s.notes.GetByID(id)
 }
 
+// GetNoteByUUID retrieves a note by its UUID
+func (s *Service) GetNoteByUUID(uuid string) (*models.Note, error) {
+	return s.notes.GetByUUID(uuid)
+}
+
+// FindNote retrieves a note by its ID or its UUID
+func (s *Service) FindNote(ref string) (*models.Note, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.notes.GetByID(id)
+	}
+	if utils.IsUUID(ref) {
+		return s.notes.GetByUUID(strings.ToLower(ref))
+	}
+	return nil, fmt.Errorf("invalid note ID %q", ref)
+}
+
 // GetAllNotes retrieves all notes with optional filtering
 func (s *Service) GetAllNotes(filter models.NoteFilter) ([]*models.Note, error) {
 	return s.notes.GetAll(filter)
 }
 
+// GetAllNotesContext retrieves notes with optional filtering, stopping when ctx is cancelled
+func (s *Service) GetAllNotesContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error) {
+	return s.notes.GetAllContext(ctx, filter)
+}
+
+// GetAllSummaries retrieves summaries of the notes matching a filter: every
+// field but the content, of which only an excerpt and the word count are
+// kept. Lists of many notes load far less this way; LoadContent or GetNote
+// loads the content when it's needed.
+func (s *Service) GetAllSummaries(filter models.NoteFilter) ([]*models.Note, error) {
+	return s.notes.GetSummariesContext(context.Background(), filter)
+}
+
+// GetAllSummariesContext retrieves note summaries like GetAllSummaries,
+// stopping when ctx is cancelled
+func (s *Service) GetAllSummariesContext(ctx context.Context, filter models.NoteFilter) ([]*models.Note, error) {
+	return s.notes.GetSummariesContext(ctx, filter)
+}
+
+// LoadContent returns notes with their content, loading it for summaries
+func (s *Service) LoadContent(notes []*models.Note) ([]*models.Note, error) {
+	return s.notes.LoadContent(notes)
+}
+
+// CountNotesContext counts the notes matching a filter regardless of its
+// limit, e.g. to tell how many results a limited query left out
+func (s *Service) CountNotesContext(ctx context.Context, filter models.NoteFilter) (int, error) {
+	return s.notes.CountContext(ctx, filter)
+}
+
 // UpdateNote updates an existing note
 func (s *Service) UpdateNote(note *models.Note) error {
-	return s.notes.Update(note)
+	if err := s.notes.Update(note); err != nil {
+		return err
+	}
+	return s.applyFrontmatterTags(note)
+}
+
+// SaveError is returned by SaveNoteWithTags when a note couldn't be saved.
+// Nothing of the save is stored, neither the note nor its tags.
+type SaveError struct {
+	Title string // title of the note being saved
+	Err   error
+}
+
+func (e *SaveError) Error() string {
+	return fmt.Sprintf("failed to save %q: %v", e.Title, e.Err)
+}
+
+func (e *SaveError) Unwrap() error {
+	return e.Err
+}
+
+// SaveNoteWithTags creates a note without an ID or updates one with, and
+// sets its tags to the named ones and those in its frontmatter, all in
+// one transaction. Tags are resolved like GetOrCreateTag. Errors are
+// *SaveError.
+func (s *Service) SaveNoteWithTags(note *models.Note, tagNames []string) error {
+	names, err := s.resolveTagNames(append(tagNames, utils.ParseNoteMetadata(note.Content).Tags...))
+	if err != nil {
+		return &SaveError{Title: note.Title, Err: err}
+	}
+	if err := s.notes.SaveWithTags(note, names); err != nil {
+		return &SaveError{Title: note.Title, Err: err}
+	}
+	return nil
+}
+
+// resolveTagNames normalizes tag names and resolves aliases and other
+// spellings to the tags they stand for, dropping empty names and repeats
+func (s *Service) resolveTagNames(names []string) ([]string, error) {
+	var resolved []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = utils.NormalizeTag(name)
+		if name == "" {
+			continue
+		}
+		if _, err := s.tags.GetByName(name); err != nil {
+			if name, err = s.resolveTagName(name); err != nil {
+				return nil, err
+			}
+		}
+		if !seen[name] {
+			seen[name] = true
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+// applyFrontmatterTags adds the tags listed in a note's frontmatter to it.
+// Tags are only added, so removing one from the frontmatter keeps it on the
+// note until it's removed explicitly.
+func (s *Service) applyFrontmatterTags(note *models.Note) error {
+	for _, name := range utils.ParseNoteMetadata(note.Content).Tags {
+		if err := s.AddTagToNote(note.ID, name); err != nil {
+			return fmt.Errorf("failed to apply frontmatter tag %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DuplicateNote copies a note with its notebook and tags into a new note
+// titled "<title> (copy)"
+func (s *Service) DuplicateNote(id int) (*models.Note, error) {
+	original, err := s.notes.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := s.CreateNote(original.Title+" (copy)", original.Content)
+	if err != nil {
+		return nil, err
+	}
+	if original.Notebook != "" {
+		if err := s.notes.SetNotebook([]int{note.ID}, original.Notebook); err != nil {
+			return nil, err
+		}
+		note.Notebook = original.Notebook
+	}
+	for _, tag := range original.Tags {
+		if err := s.AddTagToNote(note.ID, tag.Name); err != nil {
+			return nil, err
+		}
+	}
+	return s.notes.GetByID(note.ID)
 }
 
 // DeleteNote deletes a note
@@ -63,11 +238,62 @@ func (s *Service) DeleteNote(id int) error {
 	return s.notes.Delete(id)
 }
 
+// RestoreNotes recreates deleted notes from copies taken before deleting
+// them, keeping their IDs, dates, notebooks, colors, tags and pins, in
+// their place among the pinned notes
+func (s *Service) RestoreNotes(notes []*models.Note) error {
+	for _, original := range notes {
+		note := *original
+		note.Tags = nil
+		if err := s.ImportNote(&note); err != nil {
+			return fmt.Errorf("failed to restore %q: %w", original.Title, err)
+		}
+		for _, tag := range original.Tags {
+			if err := s.AddTagToNote(note.ID, tag.Name); err != nil {
+				return fmt.Errorf("failed to restore %q: %w", original.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteNotes deletes several notes at once
+func (s *Service) DeleteNotes(ids []int) error {
+	return s.notes.DeleteMany(ids)
+}
+
+// MoveNotesToNotebook moves several notes into a notebook
+func (s *Service) MoveNotesToNotebook(ids []int, notebook string) error {
+	return s.notes.SetNotebook(ids, strings.TrimSpace(notebook))
+}
+
+// PinNote pins or unpins a note
+func (s *Service) PinNote(id int, pinned bool) error {
+	return s.notes.SetPinned(id, pinned)
+}
+
+// SetNoteColor sets the color label of a note. An empty name removes it.
+func (s *Service) SetNoteColor(id int, color string) error {
+	return s.notes.SetColor(id, strings.TrimSpace(color))
+}
+
+// MovePinnedNote moves a pinned note offset places up (negative) or down
+// among the pinned notes
+func (s *Service) MovePinnedNote(id, offset int) error {
+	return s.notes.MovePinned(id, offset)
+}
+
 // SearchNotes performs a search on notes
 func (s *Service) SearchNotes(query string, limit int) ([]*models.Note, error) {
 	return s.notes.Search(query, limit)
 }
 
+// SearchNotesContext performs a search on notes that stops when ctx is
+// cancelled, ordered and limited by the sort fields and limit of sort
+func (s *Service) SearchNotesContext(ctx context.Context, query string, sort models.NoteFilter) ([]*models.Note, error) {
+	return s.notes.SearchContext(ctx, query, sort)
+}
+
 // Tag operations
 
 // CreateTag creates a new tag
@@ -85,8 +311,66 @@ func (s *Service) GetAllTags() ([]*models.Tag, error) {
 	return s.tags.GetAll()
 }
 
-// GetOrCreateTag gets a tag by name or creates it if it doesn't exist
+// GetTagsByUsage retrieves all tags ranked by how often and how recently
+// they're used, most relevant first
+func (s *Service) GetTagsByUsage() ([]*models.Tag, error) {
+	usage, err := s.tags.GetUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].Score(now) > usage[j].Score(now)
+	})
+
+	tags := make([]*models.Tag, len(usage))
+	for i, u := range usage {
+		tag := u.Tag
+		tags[i] = &tag
+	}
+	return tags, nil
+}
+
+// GetRecentTags retrieves up to limit tags, those on the most recently
+// updated notes first. Tags on no notes are left out.
+func (s *Service) GetRecentTags(limit int) ([]*models.Tag, error) {
+	usage, err := s.tags.GetUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []*models.Tag
+	sort.SliceStable(usage, func(i, j int) bool {
+		a, b := usage[i].LastUsed, usage[j].LastUsed
+		return a != nil && (b == nil || a.After(*b))
+	})
+	for _, u := range usage {
+		if u.LastUsed == nil || len(tags) == limit {
+			break
+		}
+		tag := u.Tag
+		tags = append(tags, &tag)
+	}
+	return tags, nil
+}
+
+// GetOrCreateTag gets a tag by name or creates it if it doesn't exist.
+// Names of nested tags are normalized first, so "work / x" is work/x, and
+// aliases and other spellings of an existing tag resolve to that tag.
 func (s *Service) GetOrCreateTag(name string) (*models.Tag, error) {
+	name = utils.NormalizeTag(name)
+	if name == "" {
+		return nil, fmt.Errorf("tag name is empty")
+	}
+	if tag, err := s.tags.GetByName(name); err == nil {
+		return tag, nil
+	}
+
+	name, err := s.resolveTagName(name)
+	if err != nil {
+		return nil, err
+	}
 	tag, err := s.tags.GetByName(name)
 	if err != nil {
 		// Tag doesn't exist, create it
@@ -103,6 +387,12 @@ func (s *Service) UpdateTag(tag *models.Tag) error {
 	return s.tags.Update(tag)
 }
 
+// SetTagColor sets the color label of a tag, drawn on its badges. An
+// empty name removes it.
+func (s *Service) SetTagColor(id int, color string) error {
+	return s.tags.SetColor(id, strings.TrimSpace(color))
+}
+
 // DeleteTag deletes a tag
 func (s *Service) DeleteTag(id int) error {
 	return s.tags.Delete(id)
@@ -124,6 +414,24 @@ func (s *Service) RemoveTagFromNote(noteID, tagID int) error {
 	return s.notes.RemoveTag(noteID, tagID)
 }
 
+// AddTagToNotes adds a tag to several notes, creating the tag if needed
+func (s *Service) AddTagToNotes(ids []int, tagName string) error {
+	tag, err := s.GetOrCreateTag(tagName)
+	if err != nil {
+		return err
+	}
+	return s.notes.AddTagToMany(ids, tag.ID)
+}
+
+// RemoveTagFromNotes removes a tag from several notes
+func (s *Service) RemoveTagFromNotes(ids []int, tagName string) error {
+	tag, err := s.tags.GetByName(tagName)
+	if err != nil {
+		return err
+	}
+	return s.notes.RemoveTagFromMany(ids, tag.ID)
+}
+
 // GetNotesByTag retrieves all notes with a specific tag
 func (s *Service) GetNotesByTag(tagID int) ([]*models.Note, error) {
 	return s.notes.GetByTag(tagID)
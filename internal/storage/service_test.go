@@ -1,8 +1,24 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
 )
 
 func TestService(t *testing.T) {
@@ -91,3 +107,2385 @@ func TestService(t *testing.T) {
 
 	t.Logf("Storage layer test passed! Created note ID: %d, Tag ID: %d", note.ID, tag.ID)
 }
+
+func TestGetAllStableOrdering(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_order_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	// Insert notes sharing the exact same timestamps, as a bulk import would
+	stamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, title := range []string{"Bravo", "alpha", "Charlie"} {
+		note := &models.Note{Title: title, Content: "", CreatedAt: stamp, UpdatedAt: stamp}
+		if err := service.notes.Create(note); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	// Default tie-breaker is the note ID (newest first)
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list notes: %v", err)
+	}
+	for i := 1; i < len(notes); i++ {
+		if notes[i-1].ID < notes[i].ID {
+			t.Errorf("Expected descending IDs, got %d before %d", notes[i-1].ID, notes[i].ID)
+		}
+	}
+
+	// Title tie-breaker orders case-insensitively
+	notes, err = service.GetAllNotes(models.NoteFilter{SecondarySort: models.SortByTitle})
+	if err != nil {
+		t.Fatalf("Failed to list notes: %v", err)
+	}
+	got := []string{notes[0].Title, notes[1].Title, notes[2].Title}
+	want := []string{"alpha", "Bravo", "Charlie"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected order %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestSearchQuerySyntax(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_query_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	old := time.Date(2023, 6, 1, 9, 0, 0, 0, time.Local)
+	recent := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	fixtures := []struct {
+		title   string
+		content string
+		created time.Time
+		tag     string
+	}{
+		{"Weekly meeting", "budget review", recent, "work"},
+		{"Old meeting", "budget draft", old, "work"},
+		{"Groceries", "milk and eggs", recent, "home"},
+	}
+	for _, f := range fixtures {
+		note := &models.Note{Title: f.title, Content: f.content, CreatedAt: f.created, UpdatedAt: f.created}
+		if err := service.notes.Create(note); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if err := service.AddTagToNote(note.ID, f.tag); err != nil {
+			t.Fatalf("Failed to tag note: %v", err)
+		}
+	}
+
+	cases := []struct {
+		query string
+		want  int
+	}{
+		{"tag:work", 2},
+		{"tag:WORK -draft", 1},
+		{"-tag:work", 1},
+		{"title:meeting budget", 2},
+		{"created:>2024-01-01", 2},
+		{"before:2024-01-01 tag:work", 1},
+		{"created:2024-03-01", 2},
+	}
+	for _, c := range cases {
+		results, err := service.SearchNotes(c.query, 0)
+		if err != nil {
+			t.Fatalf("Search %q failed: %v", c.query, err)
+		}
+		if len(results) != c.want {
+			t.Errorf("Search %q: expected %d results, got %d", c.query, c.want, len(results))
+		}
+
+		// The count ignores the limit, so it tells how many results were cut
+		filter := utils.ParseQuery(c.query)
+		filter.Limit = 1
+		count, err := service.CountNotesContext(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("Count %q failed: %v", c.query, err)
+		}
+		if count != c.want {
+			t.Errorf("Count %q: expected %d, got %d", c.query, c.want, count)
+		}
+	}
+}
+
+func TestSearchSort(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	for _, title := range []string{"Budget beta", "Budget gamma", "Budget alpha"} {
+		if _, err := service.CreateNote(title, "numbers"); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	titles := func(sort models.NoteFilter) string {
+		results, err := service.SearchNotesContext(context.Background(), "budget", sort)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var got []string
+		for _, note := range results {
+			got = append(got, note.Title)
+		}
+		return strings.Join(got, ",")
+	}
+
+	// Results follow the list's sort rather than a fixed order
+	if got := titles(models.NoteFilter{SortBy: models.SortByTitle}); got != "Budget alpha,Budget beta,Budget gamma" {
+		t.Errorf("Expected results by title, got %s", got)
+	}
+	if got := titles(models.NoteFilter{SortBy: models.SortByTitle, Reverse: true, Limit: 2}); got != "Budget gamma,Budget beta" {
+		t.Errorf("Expected the first two results by title reversed, got %s", got)
+	}
+}
+
+func TestGetTagsByUsage(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_tag_usage_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	longAgo := time.Now().AddDate(-1, 0, 0)
+	recent := time.Now().Add(-time.Hour)
+	fixtures := []struct {
+		updated time.Time
+		tag     string
+	}{
+		// "archive" is used often but long ago, "today" once but recently
+		{longAgo, "archive"},
+		{longAgo, "archive"},
+		{longAgo, "archive"},
+		{recent, "today"},
+	}
+	for _, f := range fixtures {
+		note := &models.Note{Title: "Note", Content: "", CreatedAt: f.updated, UpdatedAt: f.updated}
+		if err := service.notes.Create(note); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if err := service.AddTagToNote(note.ID, f.tag); err != nil {
+			t.Fatalf("Failed to tag note: %v", err)
+		}
+	}
+	if _, err := service.CreateTag("unused"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	tags, err := service.GetTagsByUsage()
+	if err != nil {
+		t.Fatalf("Failed to get tags by usage: %v", err)
+	}
+
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	want := []string{"today", "archive", "unused"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected tags %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Expected tags %v, got %v", want, names)
+			break
+		}
+	}
+
+	// Recent tags leave out the unused one, however many are asked for
+	for limit, want := range map[int][]string{1: {"today"}, 5: {"today", "archive"}} {
+		recentTags, err := service.GetRecentTags(limit)
+		if err != nil {
+			t.Fatalf("Failed to get recent tags: %v", err)
+		}
+		var recentNames []string
+		for _, tag := range recentTags {
+			recentNames = append(recentNames, tag.Name)
+		}
+		if strings.Join(recentNames, ",") != strings.Join(want, ",") {
+			t.Errorf("GetRecentTags(%d) = %v, want %v", limit, recentNames, want)
+		}
+	}
+}
+
+// newTestService opens a service on a fresh database that's closed when
+// the test ends
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestBulkOperations(t *testing.T) {
+	service := newTestService(t)
+
+	var ids []int
+	for _, title := range []string{"One", "Two", "Three"} {
+		note, err := service.CreateNote(title, "content")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		ids = append(ids, note.ID)
+	}
+
+	// Tag and move the first two notes
+	if err := service.AddTagToNotes(ids[:2], "project"); err != nil {
+		t.Fatalf("Failed to tag notes: %v", err)
+	}
+	if err := service.MoveNotesToNotebook(ids[:2], "Work"); err != nil {
+		t.Fatalf("Failed to move notes: %v", err)
+	}
+
+	results, err := service.SearchNotes("notebook:work tag:project", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 notes in notebook, got %d", len(results))
+	}
+
+	if err := service.RemoveTagFromNotes(ids, "project"); err != nil {
+		t.Fatalf("Failed to untag notes: %v", err)
+	}
+	results, err = service.SearchNotes("tag:project", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no tagged notes, got %d", len(results))
+	}
+
+	if err := service.DeleteNotes(ids[1:]); err != nil {
+		t.Fatalf("Failed to delete notes: %v", err)
+	}
+	remaining, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Notebook != "Work" {
+		t.Errorf("Expected one remaining note in Work, got %+v", remaining)
+	}
+}
+
+func TestDuplicateNote(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_duplicate_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	original, err := service.CreateNote("Plan", "- ship it")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(original.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.MoveNotesToNotebook([]int{original.ID}, "Projects"); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+
+	copied, err := service.DuplicateNote(original.ID)
+	if err != nil {
+		t.Fatalf("Failed to duplicate note: %v", err)
+	}
+	if copied.ID == original.ID || copied.Title != "Plan (copy)" || copied.Content != "- ship it" {
+		t.Errorf("Expected a new note with the same content, got %+v", copied)
+	}
+	if copied.Notebook != "Projects" || len(copied.Tags) != 1 || copied.Tags[0].Name != "work" {
+		t.Errorf("Expected the notebook and tags copied, got %+v", copied)
+	}
+}
+
+func TestPinnedOrder(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_pinned_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	ids := map[string]int{}
+	for _, title := range []string{"Alpha", "Beta", "Gamma", "Delta"} {
+		note, err := service.CreateNote(title, "")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		ids[title] = note.ID
+	}
+
+	titles := func() []string {
+		notes, err := service.GetAllNotes(models.NoteFilter{SortBy: models.SortByTitle, PinnedFirst: true})
+		if err != nil {
+			t.Fatalf("Failed to get notes: %v", err)
+		}
+		var titles []string
+		for _, note := range notes {
+			titles = append(titles, note.Title)
+		}
+		return titles
+	}
+
+	for _, title := range []string{"Gamma", "Beta", "Delta"} {
+		if err := service.PinNote(ids[title], true); err != nil {
+			t.Fatalf("Failed to pin %s: %v", title, err)
+		}
+	}
+	// Pinning again keeps the note's place
+	if err := service.PinNote(ids["Gamma"], true); err != nil {
+		t.Fatalf("Failed to pin Gamma: %v", err)
+	}
+	if got := strings.Join(titles(), ","); got != "Gamma,Beta,Delta,Alpha" {
+		t.Errorf("Expected pinned notes first in pin order, got %s", got)
+	}
+
+	if err := service.MovePinnedNote(ids["Delta"], -2); err != nil {
+		t.Fatalf("Failed to move Delta: %v", err)
+	}
+	if err := service.MovePinnedNote(ids["Beta"], 5); err != nil {
+		t.Fatalf("Failed to move Beta: %v", err)
+	}
+	if got := strings.Join(titles(), ","); got != "Delta,Gamma,Beta,Alpha" {
+		t.Errorf("Expected the manual order, got %s", got)
+	}
+
+	if err := service.PinNote(ids["Delta"], false); err != nil {
+		t.Fatalf("Failed to unpin Delta: %v", err)
+	}
+	if got := strings.Join(titles(), ","); got != "Gamma,Beta,Alpha,Delta" {
+		t.Errorf("Expected Delta back in title order, got %s", got)
+	}
+	if err := service.MovePinnedNote(ids["Alpha"], 1); err == nil {
+		t.Error("Expected moving an unpinned note to fail")
+	}
+
+	// Without PinnedFirst the manual order is ignored
+	notes, err := service.GetAllNotes(models.NoteFilter{SortBy: models.SortByTitle})
+	if err != nil || notes[0].Title != "Alpha" {
+		t.Errorf("Expected plain title order, got %v", err)
+	}
+}
+
+func TestRestoreNotes(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_restore_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := service.CreateNote("Plan", "- ship it")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(note.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.MoveNotesToNotebook([]int{note.ID}, "Projects"); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+	if err := service.PinNote(note.ID, true); err != nil {
+		t.Fatalf("Failed to pin note: %v", err)
+	}
+	other, err := service.CreateNote("Other", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.PinNote(other.ID, true); err != nil {
+		t.Fatalf("Failed to pin note: %v", err)
+	}
+	deleted, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+
+	if err := service.DeleteNote(note.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if err := service.RestoreNotes([]*models.Note{deleted}); err != nil {
+		t.Fatalf("Failed to restore note: %v", err)
+	}
+
+	restored, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Expected the note back under its ID: %v", err)
+	}
+	if restored.Title != "Plan" || restored.Content != "- ship it" || restored.Notebook != "Projects" || !restored.Pinned {
+		t.Errorf("Expected the note restored as it was, got %+v", restored)
+	}
+	if len(restored.Tags) != 1 || restored.Tags[0].Name != "work" {
+		t.Errorf("Expected the tag restored, got %+v", restored.Tags)
+	}
+	if !restored.CreatedAt.Equal(deleted.CreatedAt) || !restored.UpdatedAt.Equal(deleted.UpdatedAt) {
+		t.Errorf("Expected the dates kept, got %v and %v", restored.CreatedAt, restored.UpdatedAt)
+	}
+
+	// The pin goes back to its place, ahead of the note pinned after it
+	pinned, err := service.GetAllNotes(models.NoteFilter{Pinned: true, PinnedFirst: true})
+	if err != nil || len(pinned) != 2 || pinned[0].ID != note.ID || restored.SortOrder != deleted.SortOrder {
+		t.Errorf("Expected the restored note first among the pinned notes, got %v (%v)", pinned, err)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_attachments_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+	store := t.TempDir()
+	service.SetAttachmentsDir(store)
+
+	note, err := service.CreateNote("Trip", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	source := filepath.Join(t.TempDir(), "boarding pass.pdf")
+	if err := os.WriteFile(source, []byte("%PDF"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	first, err := service.AttachFile(note.ID, source)
+	if err != nil {
+		t.Fatalf("Failed to attach file: %v", err)
+	}
+	second, err := service.AttachFile(note.ID, source)
+	if err != nil {
+		t.Fatalf("Failed to attach file again: %v", err)
+	}
+	if first.Name != "boarding pass.pdf" || first.Size != 4 || filepath.Dir(first.Path) != store {
+		t.Errorf("Unexpected attachment %+v", first)
+	}
+	if second.Path != first.Path {
+		t.Errorf("Expected the same file stored once, got %s and %s", first.Path, second.Path)
+	}
+	if data, err := os.ReadFile(first.Path); err != nil || string(data) != "%PDF" {
+		t.Errorf("Expected the stored copy to match, got %q, %v", data, err)
+	}
+
+	if err := service.RemoveAttachment(first.ID); err != nil {
+		t.Fatalf("Failed to remove attachment: %v", err)
+	}
+	attachments, err := service.GetAttachments(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get attachments: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].ID != second.ID || attachments[0].Path != second.Path {
+		t.Errorf("Expected the second attachment left, got %+v", attachments)
+	}
+
+	if _, err := service.AttachFile(note.ID+1, source); err == nil {
+		t.Error("Expected attaching to a missing note to fail")
+	}
+	if _, err := service.AttachFile(note.ID, store); err == nil {
+		t.Error("Expected attaching a directory to fail")
+	}
+}
+
+func TestNoteTimestamps(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_timestamps_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	created := time.Date(2019, 5, 4, 10, 30, 0, 0, time.UTC)
+	note := &models.Note{Title: "Imported", Content: "old", CreatedAt: created}
+	if err := service.ImportNote(note); err != nil {
+		t.Fatalf("Failed to import note: %v", err)
+	}
+
+	stored, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if !stored.CreatedAt.Equal(created) || !stored.UpdatedAt.Equal(created) {
+		t.Errorf("Expected imported timestamps %v, got %v / %v", created, stored.CreatedAt, stored.UpdatedAt)
+	}
+
+	updated := created.AddDate(1, 0, 0)
+	if err := service.SetNoteTimestamps(note.ID, created, updated); err != nil {
+		t.Fatalf("Failed to set timestamps: %v", err)
+	}
+	stored, err = service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if !stored.UpdatedAt.Equal(updated) {
+		t.Errorf("Expected updated_at %v, got %v", updated, stored.UpdatedAt)
+	}
+
+	if err := service.SetNoteTimestamps(note.ID, updated, created); err == nil {
+		t.Error("Expected an error when updated is before created")
+	}
+}
+
+func TestVaultHealth(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_health_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	var ids []int
+	for _, title := range []string{"Ideas", "ideas ", "Old", "Tagged"} {
+		note, err := service.CreateNote(title, "content")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		ids = append(ids, note.ID)
+	}
+	if err := service.AddTagToNote(ids[3], "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if _, err := service.CreateTag("unused"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	old := time.Now().AddDate(-1, 0, 0)
+	if err := service.SetNoteTimestamps(ids[2], old, old); err != nil {
+		t.Fatalf("Failed to set timestamps: %v", err)
+	}
+
+	health, err := service.GetVaultHealth(90 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to get vault health: %v", err)
+	}
+	if health.TotalNotes != 4 || health.UntaggedNotes != 3 {
+		t.Errorf("Expected 3 of 4 notes untagged, got %d of %d", health.UntaggedNotes, health.TotalNotes)
+	}
+	if health.TotalTags != 2 || health.OrphanTags != 1 {
+		t.Errorf("Expected 1 of 2 tags orphaned, got %d of %d", health.OrphanTags, health.TotalTags)
+	}
+	if health.StaleNotes != 1 {
+		t.Errorf("Expected 1 stale note, got %d", health.StaleNotes)
+	}
+	if health.DuplicateNotes != 2 || len(health.DuplicateTitles) != 1 {
+		t.Errorf("Expected one pair of duplicates, got %d notes %v", health.DuplicateNotes, health.DuplicateTitles)
+	}
+
+	// The same checks are reachable from the search syntax
+	results, err := service.SearchNotes("is:duplicate", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 duplicate notes, got %d", len(results))
+	}
+	results, err = service.SearchNotes("is:untagged", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected 3 untagged notes, got %d", len(results))
+	}
+
+	removed, err := service.PruneOrphanTags()
+	if err != nil {
+		t.Fatalf("Failed to prune tags: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 tag pruned, got %d", removed)
+	}
+	if err := service.Compact(); err != nil {
+		t.Fatalf("Failed to compact database: %v", err)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "notes.db")
+	service, err := NewService(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	for pragma, want := range map[string]string{"journal_mode": "wal", "foreign_keys": "1", "busy_timeout": "5000", "synchronous": "1"} {
+		var got string
+		if err := service.db.QueryRow("PRAGMA " + pragma).Scan(&got); err != nil || got != want {
+			t.Errorf("Expected %s %s, got %q, %v", pragma, want, got, err)
+		}
+	}
+
+	// Deleting a note takes its tag associations with it
+	note, err := service.CreateNote("Plan", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(note.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.DeleteNote(note.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	var count int
+	if err := service.db.QueryRow(`SELECT COUNT(*) FROM note_tags`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected the note's tags to be removed with it, got %d, %v", count, err)
+	}
+
+	problems, err := service.CheckIntegrity()
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+
+	// A connection without foreign keys can still leave rows dangling
+	raw, err := sql.Open(driverName, dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec(`INSERT INTO note_tags (note_id, tag_id) VALUES (?, (SELECT id FROM tags))`, note.ID); err != nil {
+		t.Fatalf("Failed to insert dangling row: %v", err)
+	}
+	problems, err = service.CheckIntegrity()
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if want := []string{"1 row of note_tags refers to missing notes"}; !reflect.DeepEqual(problems, want) {
+		t.Errorf("Expected %v, got %v", want, problems)
+	}
+}
+
+func TestGetNoteCounts(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_counts_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	var ids []int
+	for _, title := range []string{"Plan", "Budget", "Old", "Loose", "Gone"} {
+		note, err := service.CreateNote(title, "content")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		ids = append(ids, note.ID)
+	}
+	if err := service.MoveNotesToNotebook(ids[:2], "Work"); err != nil {
+		t.Fatalf("Failed to move notes: %v", err)
+	}
+	if err := service.MoveNotesToNotebook(ids[2:3], models.ArchiveNotebook); err != nil {
+		t.Fatalf("Failed to archive note: %v", err)
+	}
+	if err := service.MoveNotesToNotebook(ids[4:], models.TrashNotebook); err != nil {
+		t.Fatalf("Failed to trash note: %v", err)
+	}
+	if err := service.PinNote(ids[0], true); err != nil {
+		t.Fatalf("Failed to pin note: %v", err)
+	}
+	for _, id := range ids[:2] {
+		if err := service.AddTagToNote(id, "money"); err != nil {
+			t.Fatalf("Failed to tag note: %v", err)
+		}
+	}
+	if _, err := service.CreateTag("unused"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	counts, err := service.GetNoteCounts()
+	if err != nil {
+		t.Fatalf("Failed to count notes: %v", err)
+	}
+	// Trashed notes are only counted in the trash
+	if counts.All != 4 || counts.Pinned != 1 || counts.Archived != 1 || counts.Trash != 1 {
+		t.Errorf("Expected 4 notes, 1 pinned, 1 archived, 1 trashed, got %+v", counts)
+	}
+	if len(counts.Notebooks) != 1 || counts.Notebooks[0] != (models.NameCount{Name: "Work", Count: 2}) {
+		t.Errorf("Expected only the Work notebook with 2 notes, got %v", counts.Notebooks)
+	}
+	if len(counts.Tags) != 1 || counts.Tags[0] != (models.NameCount{Name: "money", Count: 2}) {
+		t.Errorf("Expected only the money tag with 2 notes, got %v", counts.Tags)
+	}
+
+	results, err := service.SearchNotes("is:pinned", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[0] {
+		t.Errorf("Expected only the pinned note, got %d notes", len(results))
+	}
+}
+
+func TestNestedTags(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_nested_tags_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	tags := []string{"work", " work / project-x ", "work/project-x/notes", "workshop"}
+	var ids []int
+	for _, tag := range tags {
+		note, err := service.CreateNote("Note "+tag, "content")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if err := service.AddTagToNote(note.ID, tag); err != nil {
+			t.Fatalf("Failed to tag note: %v", err)
+		}
+		ids = append(ids, note.ID)
+	}
+
+	tag, err := service.GetOrCreateTag("work/project-x")
+	if err != nil {
+		t.Fatalf("Failed to get tag: %v", err)
+	}
+	if tag.Name != "work/project-x" {
+		t.Errorf("Expected the nested tag name to be normalized, got %q", tag.Name)
+	}
+
+	// A parent tag matches the tags nested under it, but not other tags
+	// sharing its prefix
+	for query, want := range map[string]int{
+		"tag:work":                     3,
+		"tag:Work/Project-X":           2,
+		"tag:work/project-x/notes":     1,
+		"tag:work -tag:work/project-x": 1,
+	} {
+		results, err := service.SearchNotes(query, 0)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != want {
+			t.Errorf("Expected %d notes for %q, got %d", want, query, len(results))
+		}
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{TagIDs: []int{tag.ID}})
+	if err != nil {
+		t.Fatalf("Failed to filter by tag ID: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Errorf("Expected 2 notes under work/project-x, got %d", len(notes))
+	}
+
+	counts, err := service.GetNoteCounts()
+	if err != nil {
+		t.Fatalf("Failed to count notes: %v", err)
+	}
+	want := []models.NameCount{
+		{Name: "work", Count: 3},
+		{Name: "work/project-x", Count: 2},
+		{Name: "work/project-x/notes", Count: 1},
+		{Name: "workshop", Count: 1},
+	}
+	if !reflect.DeepEqual(counts.Tags, want) {
+		t.Errorf("Expected tag tree %v, got %v", want, counts.Tags)
+	}
+}
+
+func TestTagAliases(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_tag_aliases_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	// Other spellings of an existing tag resolve to it
+	golang, err := service.GetOrCreateTag("Golang")
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	for _, name := range []string{"golang", "go-lang", "Golangs"} {
+		tag, err := service.GetOrCreateTag(name)
+		if err != nil {
+			t.Fatalf("Failed to get tag: %v", err)
+		}
+		if tag.ID != golang.ID {
+			t.Errorf("Expected %q to resolve to Golang, got %q", name, tag.Name)
+		}
+	}
+
+	// Aliasing a tag merges it into the canonical one
+	first, _ := service.CreateNote("First", "content")
+	second, _ := service.CreateNote("Second", "content")
+	if err := service.AddTagToNote(first.ID, "golang"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.AddTagToNote(second.ID, "go"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.SetTagAlias("golang", "go"); err != nil {
+		t.Fatalf("Failed to set alias: %v", err)
+	}
+	results, err := service.SearchNotes("tag:go", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected both notes tagged go after merging, got %d", len(results))
+	}
+	if _, err := service.GetTag(golang.ID); err == nil {
+		t.Errorf("Expected the merged tag to be deleted")
+	}
+
+	tag, err := service.GetOrCreateTag("Go-Lang")
+	if err != nil {
+		t.Fatalf("Failed to get tag: %v", err)
+	}
+	if tag.Name != "go" {
+		t.Errorf("Expected the alias to resolve to go, got %q", tag.Name)
+	}
+
+	aliases, err := service.GetTagAliases()
+	if err != nil {
+		t.Fatalf("Failed to get aliases: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0] != (models.TagAlias{Alias: "golang", Tag: "go"}) {
+		t.Errorf("Expected golang -> go, got %v", aliases)
+	}
+	if err := service.SetTagAlias("go", "golang"); err == nil {
+		t.Errorf("Expected aliasing a tag to its own alias to fail")
+	}
+	if err := service.SetTagAlias("rust", "golang"); err == nil {
+		t.Errorf("Expected aliasing to an alias to fail")
+	}
+
+	if err := service.DeleteTagAlias("Golang"); err != nil {
+		t.Fatalf("Failed to delete alias: %v", err)
+	}
+	if tag, _ := service.GetOrCreateTag("golang"); tag == nil || tag.Name != "golang" {
+		t.Errorf("Expected golang to be its own tag once the alias is gone, got %v", tag)
+	}
+}
+
+func TestSaveNoteWithTags(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_save_with_tags_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	tagNames := func(id int) []string {
+		tags, err := service.GetNoteTags(id)
+		if err != nil {
+			t.Fatalf("Failed to get note tags: %v", err)
+		}
+		var names []string
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// A new note is created with its tags, resolved like GetOrCreateTag,
+	// and those in its frontmatter
+	if _, err := service.GetOrCreateTag("Golang"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	note := models.NewNote("Saved", "---\ntags: [draft]\n---\nText")
+	if err := service.SaveNoteWithTags(note, []string{"work", "go-lang", "work"}); err != nil {
+		t.Fatalf("Failed to save note: %v", err)
+	}
+	if note.ID == 0 {
+		t.Fatal("Expected the new note to get an ID")
+	}
+	if got := tagNames(note.ID); !reflect.DeepEqual(got, []string{"Golang", "draft", "work"}) {
+		t.Errorf("Expected tags Golang, draft and work, got %v", got)
+	}
+
+	// Saving again replaces the tags
+	note.Content = "Text"
+	if err := service.SaveNoteWithTags(note, []string{"work"}); err != nil {
+		t.Fatalf("Failed to save note: %v", err)
+	}
+	if got := tagNames(note.ID); !reflect.DeepEqual(got, []string{"work"}) {
+		t.Errorf("Expected only the work tag, got %v", got)
+	}
+
+	// A failed save stores nothing, not even new tags
+	_, err = service.db.Exec(`
+		CREATE TRIGGER fail_tag BEFORE INSERT ON note_tags
+		WHEN NEW.tag_id = (SELECT id FROM tags WHERE name = 'boom')
+		BEGIN SELECT RAISE(ABORT, 'boom'); END`)
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	note.Title = "Renamed"
+	err = service.SaveNoteWithTags(note, []string{"idea", "boom"})
+	var saveErr *SaveError
+	if !errors.As(err, &saveErr) || saveErr.Title != "Renamed" {
+		t.Fatalf("Expected a SaveError for Renamed, got %v", err)
+	}
+	stored, _ := service.GetNote(note.ID)
+	if stored.Title != "Saved" {
+		t.Errorf("Expected the title to stay Saved, got %q", stored.Title)
+	}
+	if got := tagNames(note.ID); !reflect.DeepEqual(got, []string{"work"}) {
+		t.Errorf("Expected the tags to stay as they were, got %v", got)
+	}
+	if _, err := service.tags.GetByName("idea"); err == nil {
+		t.Error("Expected the idea tag not to be created")
+	}
+
+	fresh := models.NewNote("Never saved", "")
+	if err := service.SaveNoteWithTags(fresh, []string{"boom"}); err == nil {
+		t.Fatal("Expected saving a new note to fail")
+	}
+	if fresh.ID != 0 {
+		t.Errorf("Expected the unsaved note to keep no ID, got %d", fresh.ID)
+	}
+	if notes, _ := service.GetAllNotes(models.NoteFilter{}); len(notes) != 1 {
+		t.Errorf("Expected 1 note after the failed save, got %d", len(notes))
+	}
+}
+
+func TestGetAllNotesLoadsTags(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_load_tags_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	// More notes than fit in one batch of tag queries; every third note
+	// has no tags
+	count := tagBatchSize + 10
+	for i := 0; i < count; i++ {
+		var tags []string
+		if i%3 != 0 {
+			tags = []string{"zeta", fmt.Sprintf("note-%d", i), "Alpha"}
+		}
+		if err := service.SaveNoteWithTags(models.NewNote(fmt.Sprintf("Note %d", i), ""), tags); err != nil {
+			t.Fatalf("Failed to save note: %v", err)
+		}
+	}
+
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	if len(notes) != count {
+		t.Fatalf("Expected %d notes, got %d", count, len(notes))
+	}
+	for _, note := range notes {
+		var i int
+		fmt.Sscanf(note.Title, "Note %d", &i)
+		var names []string
+		for _, tag := range note.Tags {
+			names = append(names, tag.Name)
+		}
+		var want []string
+		if i%3 != 0 {
+			want = []string{"Alpha", fmt.Sprintf("note-%d", i), "zeta"}
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Fatalf("Expected %q to have tags %v in order, got %v", note.Title, want, names)
+		}
+	}
+}
+
+func TestTagColors(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_tag_colors_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, _ := service.CreateNote("Tagged", "content")
+	if err := service.AddTagToNote(note.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	tag, err := service.GetOrCreateTag("work")
+	if err != nil {
+		t.Fatalf("Failed to get tag: %v", err)
+	}
+	if tag.Color != "" {
+		t.Errorf("Expected new tags to have no color, got %q", tag.Color)
+	}
+
+	if err := service.SetTagColor(tag.ID, "green"); err != nil {
+		t.Fatalf("Failed to set tag color: %v", err)
+	}
+	tag, _ = service.GetTag(tag.ID)
+	if tag.Color != "green" {
+		t.Errorf("Expected tag color green, got %q", tag.Color)
+	}
+
+	// The color comes along with the note's tags and the tag counts
+	note, _ = service.GetNote(note.ID)
+	if len(note.Tags) != 1 || note.Tags[0].Color != "green" {
+		t.Errorf("Expected the note's tag to be green, got %+v", note.Tags)
+	}
+	counts, err := service.GetNoteCounts()
+	if err != nil {
+		t.Fatalf("Failed to get counts: %v", err)
+	}
+	if len(counts.Tags) != 1 || counts.Tags[0].Color != "green" {
+		t.Errorf("Expected the tag count to be green, got %+v", counts.Tags)
+	}
+
+	if err := service.SetTagColor(tag.ID, ""); err != nil {
+		t.Fatalf("Failed to remove tag color: %v", err)
+	}
+	tag, _ = service.GetTag(tag.ID)
+	if tag.Color != "" {
+		t.Errorf("Expected the color to be removed, got %q", tag.Color)
+	}
+
+	if err := service.SetTagColor(9999, "green"); err == nil {
+		t.Error("Expected an error coloring a missing tag")
+	}
+}
+
+func TestSortByColumns(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_sort_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	short, _ := service.CreateNote("Short", "one")
+	long, _ := service.CreateNote("Long", "one two three four")
+	tagged, _ := service.CreateNote("Tagged", "one two")
+	service.AddTagToNote(tagged.ID, "a")
+	service.AddTagToNote(tagged.ID, "b")
+	service.AddTagToNote(long.ID, "a")
+
+	tests := []struct {
+		sortBy  models.SortField
+		reverse bool
+		want    []int
+	}{
+		{models.SortByWords, false, []int{long.ID, tagged.ID, short.ID}},
+		{models.SortByWords, true, []int{short.ID, tagged.ID, long.ID}},
+		{models.SortByTags, false, []int{tagged.ID, long.ID, short.ID}},
+		{models.SortByTitle, true, []int{tagged.ID, short.ID, long.ID}},
+	}
+	for _, tt := range tests {
+		notes, err := service.GetAllNotes(models.NoteFilter{SortBy: tt.sortBy, Reverse: tt.reverse})
+		if err != nil {
+			t.Fatalf("Failed to get notes: %v", err)
+		}
+		var got []int
+		for _, note := range notes {
+			got = append(got, note.ID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Sort by %s (reverse %v) = %v, want %v", tt.sortBy, tt.reverse, got, tt.want)
+		}
+	}
+}
+
+func TestPaging(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	for i, title := range []string{"b", "A", "c", "a", "B", "d", "C"} {
+		note, _ := service.CreateNote(title, strings.Repeat("word ", i%3))
+		if i%2 == 0 {
+			service.AddTagToNote(note.ID, "even")
+		}
+		if i == 3 || i == 5 {
+			service.PinNote(note.ID, true)
+		}
+	}
+
+	filters := []models.NoteFilter{
+		{},
+		{PinnedFirst: true},
+		{SortBy: models.SortByTitle, PinnedFirst: true},
+		{SortBy: models.SortByTitle, Reverse: true, SecondarySort: models.SortByCreated},
+		{SortBy: models.SortByTags, SecondarySort: models.SortByTitle},
+		{SortBy: models.SortByWords, Reverse: true},
+		{SortBy: models.SortByID, SecondarySort: models.SortByTitle, TagNames: []string{"even"}},
+	}
+	for _, filter := range filters {
+		all, err := service.GetAllNotes(filter)
+		if err != nil {
+			t.Fatalf("Failed to get notes: %v", err)
+		}
+
+		// Pages of two following each other give the same notes in order
+		var paged []*models.Note
+		page := filter
+		page.Limit = 2
+		for {
+			notes, err := service.GetAllNotes(page)
+			if err != nil {
+				t.Fatalf("Failed to get a page of notes: %v", err)
+			}
+			paged = append(paged, notes...)
+			if len(notes) < page.Limit || len(paged) > len(all) {
+				break
+			}
+			page.After = notes[len(notes)-1].ID
+		}
+
+		var want, got []int
+		for _, note := range all {
+			want = append(want, note.ID)
+		}
+		for _, note := range paged {
+			got = append(got, note.ID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Pages of %+v = %v, want %v", filter, got, want)
+		}
+	}
+}
+
+func TestGetAllSummaries(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	long := "# Heading\n\nOpening words " + strings.Repeat("more words ", 1000)
+	note, _ := service.CreateNote("Long", long)
+	service.AddTagToNote(note.ID, "big")
+	secret, _ := service.CreateNote("Secret", "launch codes")
+	if err := service.EnableEncryption("hunter2"); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+
+	summaries, err := service.GetAllSummaries(models.NoteFilter{SortBy: models.SortByTitle})
+	if err != nil {
+		t.Fatalf("Failed to get summaries: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != note.ID {
+		t.Fatalf("Expected the 2 notes by title, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if !summary.Summary || summary.Content != "" {
+		t.Errorf("Expected a summary without content, got %+v", summary)
+	}
+	if summary.Title != "Long" || len(summary.Tags) != 1 || summary.Tags[0].Name != "big" {
+		t.Errorf("Expected the title and tags in the summary, got %q %v", summary.Title, summary.Tags)
+	}
+	if !strings.HasPrefix(summary.Excerpt, "Opening words more words") || len([]rune(summary.Excerpt)) > summaryExcerptRunes {
+		t.Errorf("Unexpected excerpt %q", summary.Excerpt)
+	}
+	if summary.WordCount != utils.WordCount(long) {
+		t.Errorf("Expected %d words, got %d", utils.WordCount(long), summary.WordCount)
+	}
+	if summaries[1].Excerpt != "launch codes" {
+		t.Errorf("Expected the excerpt of encrypted content, got %q", summaries[1].Excerpt)
+	}
+
+	loaded, err := service.LoadContent(summaries)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	if loaded[0].Content != long || loaded[0].Summary || loaded[1].Content != "launch codes" {
+		t.Errorf("Expected the whole notes, got %+v", loaded)
+	}
+	if summary.Content != "" || !summary.Summary {
+		t.Error("Expected the summaries to be left unchanged")
+	}
+
+	// A note deleted since it was listed can't be loaded
+	service.DeleteNote(secret.ID)
+	if _, err := service.LoadContent(summaries); err == nil {
+		t.Error("Expected an error loading a deleted note")
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	plan := "Migrate the billing service to the new queue, then drain the old workers and remove their alerts. "
+	original, _ := service.CreateNote("Billing migration", strings.Repeat(plan, 3))
+	edited, _ := service.CreateNote("Queue work", strings.Repeat(plan, 3)+"Also tell support.")
+	copied, _ := service.CreateNote("Groceries (copy)", "")
+	groceries, _ := service.CreateNote("groceries", "")
+	service.CreateNote("Billing", "Invoices are sent on the first of the month.")
+	service.CreateNote("Reading list", "Books to read this year.")
+	service.SetNoteTimestamps(copied.ID, groceries.CreatedAt.Add(time.Hour), groceries.CreatedAt.Add(time.Hour))
+
+	pairs, err := service.FindDuplicates()
+	if err != nil {
+		t.Fatalf("Failed to find duplicates: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("Expected 2 pairs of duplicates, got %d", len(pairs))
+	}
+	if pairs[0].Older.ID != original.ID || pairs[0].Newer.ID != edited.ID || pairs[0].ContentScore < duplicateContent {
+		t.Errorf("Expected the notes with the same content first, got %q and %q scoring %.2f",
+			pairs[0].Older.Title, pairs[0].Newer.Title, pairs[0].ContentScore)
+	}
+	if pairs[1].Older.ID != groceries.ID || pairs[1].Newer.ID != copied.ID || pairs[1].TitleScore != 1 {
+		t.Errorf("Expected the empty notes with the same title, oldest first, got %q and %q scoring %.2f",
+			pairs[1].Older.Title, pairs[1].Newer.Title, pairs[1].TitleScore)
+	}
+}
+
+func TestMergeNotes(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	keep, _ := service.CreateNote("Trip", "Book the flights.\n")
+	service.AddTagToNote(keep.ID, "travel")
+	drop, _ := service.CreateNote("Trip (copy)", "---\nalias: trip2\n---\nPack the bags.\n")
+	service.AddTagToNote(drop.ID, "Travel")
+	service.AddTagToNote(drop.ID, "todo")
+
+	merged, err := service.MergeNotes(keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("Failed to merge notes: %v", err)
+	}
+	if want := "Book the flights.\n\n---\n\nPack the bags.\n"; merged.Content != want {
+		t.Errorf("Expected merged content %q, got %q", want, merged.Content)
+	}
+	if len(merged.Tags) != 2 {
+		t.Errorf("Expected the tags of both notes once, got %v", merged.Tags)
+	}
+	if _, err := service.GetNote(drop.ID); err == nil {
+		t.Error("Expected the merged note to be deleted")
+	}
+
+	// Content the kept note already holds isn't added again
+	again, _ := service.CreateNote("Trip", "Pack the bags.")
+	if merged, err = service.MergeNotes(keep.ID, again.ID); err != nil {
+		t.Fatalf("Failed to merge notes: %v", err)
+	}
+	if strings.Count(merged.Content, "Pack the bags.") != 1 {
+		t.Errorf("Expected content already there not to be repeated, got %q", merged.Content)
+	}
+	if _, err := service.MergeNotes(keep.ID, keep.ID); err == nil {
+		t.Error("Expected an error merging a note into itself")
+	}
+}
+
+func TestFrontmatterMetadata(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_frontmatter_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	content := "---\ntags: [work, \"#plans\"]\naliases: [Roadmap]\ndate: 2024-03-05\n---\n# Plans"
+	note, err := service.CreateNote("Plans", content)
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	stored, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if len(stored.Tags) != 2 {
+		t.Errorf("Expected 2 frontmatter tags, got %v", stored.Tags)
+	}
+	if len(stored.Aliases) != 1 || stored.Aliases[0] != "Roadmap" {
+		t.Errorf("Expected alias Roadmap, got %q", stored.Aliases)
+	}
+	if stored.Date == nil || stored.Date.Format("2006-01-02") != "2024-03-05" {
+		t.Errorf("Expected date 2024-03-05, got %v", stored.Date)
+	}
+
+	stored.Content = "# Plans without frontmatter"
+	if err := service.UpdateNote(stored); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	stored, err = service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if stored.Aliases != nil || stored.Date != nil {
+		t.Errorf("Expected metadata to be cleared, got %q / %v", stored.Aliases, stored.Date)
+	}
+	if len(stored.Tags) != 2 {
+		t.Errorf("Expected tags to be kept after removing the frontmatter, got %v", stored.Tags)
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_encryption_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	note, err := service.CreateNote("Secret", "launch codes")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.EnableEncryption("hunter2"); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+
+	var stored string
+	if err := service.db.QueryRow(`SELECT content FROM notes WHERE id = ?`, note.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored content: %v", err)
+	}
+	if strings.Contains(stored, "launch") {
+		t.Errorf("Expected content to be encrypted at rest, got %q", stored)
+	}
+	if results, _ := service.SearchNotes("launch", 10); len(results) != 0 {
+		t.Errorf("Expected encrypted content not to be searchable, got %d results", len(results))
+	}
+	service.Close()
+
+	// A reopened database starts locked
+	service, err = NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to reopen service: %v", err)
+	}
+	defer service.Close()
+
+	if !service.Locked() {
+		t.Fatal("Expected reopened database to be locked")
+	}
+	if _, err := service.GetNote(note.ID); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked before unlocking, got %v", err)
+	}
+	if err := service.Unlock("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if err := service.Unlock("hunter2"); err != nil {
+		t.Fatalf("Failed to unlock: %v", err)
+	}
+
+	got, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if got.Content != "launch codes" {
+		t.Errorf("Expected decrypted content, got %q", got.Content)
+	}
+
+	if err := service.DisableEncryption(); err != nil {
+		t.Fatalf("Failed to disable encryption: %v", err)
+	}
+	if err := service.db.QueryRow(`SELECT content FROM notes WHERE id = ?`, note.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored content: %v", err)
+	}
+	if stored != "launch codes" || service.EncryptionEnabled() {
+		t.Errorf("Expected plaintext after disabling encryption, got %q", stored)
+	}
+}
+
+func TestSyncRevisions(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_sync_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	revisions := []*models.SyncRevision{
+		{Path: "https://dav.example/notes/plan.md", Hash: "a", ETag: `"1"`},
+		{Path: "https://dav.example/notes/ideas.md", Hash: "b"},
+		{Path: "https://other.example/notes/plan.md", Hash: "c"},
+	}
+	for _, rev := range revisions {
+		if err := service.SetSyncRevision(rev); err != nil {
+			t.Fatalf("Failed to set sync revision: %v", err)
+		}
+	}
+
+	// Setting a revision again replaces it
+	if err := service.SetSyncRevision(&models.SyncRevision{Path: revisions[0].Path, Hash: "d", ETag: `"2"`}); err != nil {
+		t.Fatalf("Failed to update sync revision: %v", err)
+	}
+	if err := service.DeleteSyncRevision(revisions[1].Path); err != nil {
+		t.Fatalf("Failed to delete sync revision: %v", err)
+	}
+
+	// Only revisions under the prefix are returned
+	got, err := service.GetSyncRevisions("https://dav.example/notes/")
+	if err != nil {
+		t.Fatalf("Failed to get sync revisions: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 sync revision, got %d", len(got))
+	}
+	if rev := got[revisions[0].Path]; rev == nil || rev.Hash != "d" || rev.ETag != `"2"` {
+		t.Errorf("Expected the updated revision, got %+v", rev)
+	}
+}
+
+func TestReminders(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	sent := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, key := range []string{"call", "pay", "send"} {
+		if err := service.MarkReminded(key, sent); err != nil {
+			t.Fatalf("Failed to mark reminded: %v", err)
+		}
+	}
+
+	// A snooze is kept until the reminder is sent again
+	until := sent.Add(time.Hour)
+	if err := service.SnoozeReminder("call", until); err != nil {
+		t.Fatalf("Failed to snooze: %v", err)
+	}
+	if err := service.SnoozeReminder("pay", until); err != nil {
+		t.Fatalf("Failed to snooze: %v", err)
+	}
+	if err := service.MarkReminded("pay", until); err != nil {
+		t.Fatalf("Failed to mark reminded: %v", err)
+	}
+	if err := service.DeleteReminders([]string{"send"}); err != nil {
+		t.Fatalf("Failed to delete reminders: %v", err)
+	}
+
+	reminders, err := service.GetReminders()
+	if err != nil {
+		t.Fatalf("Failed to get reminders: %v", err)
+	}
+	if len(reminders) != 2 {
+		t.Fatalf("Expected 2 reminders, got %d", len(reminders))
+	}
+	if call := reminders["call"]; call == nil || !call.NotifiedAt.Equal(sent) || call.SnoozedUntil == nil || !call.SnoozedUntil.Equal(until) {
+		t.Errorf("Expected the snoozed reminder, got %+v", call)
+	}
+	if pay := reminders["pay"]; pay == nil || !pay.NotifiedAt.Equal(until) || pay.SnoozedUntil != nil {
+		t.Errorf("Expected the reminder sent again without a snooze, got %+v", pay)
+	}
+}
+
+func TestJSONExportImport(t *testing.T) {
+	dir := t.TempDir()
+	source, err := NewService(filepath.Join(dir, "source.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer source.Close()
+
+	// Leave a gap in the IDs so remapping is exercised
+	if _, err := source.CreateNote("Scratch", "gone"); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	plan, err := source.CreateNote("Plan", "---\naliases: Roadmap\n---\n- ship")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := source.DeleteNote(plan.ID - 1); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if err := source.AddTagToNotes([]int{plan.ID}, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := source.MoveNotesToNotebook([]int{plan.ID}, "Projects"); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+	if _, err := source.CreateTag("unused"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := source.PinNote(plan.ID, true); err != nil {
+		t.Fatalf("Failed to pin note: %v", err)
+	}
+	if err := source.SetNoteColor(plan.ID, "green"); err != nil {
+		t.Fatalf("Failed to color note: %v", err)
+	}
+	if err := source.SetNoteProperty(plan.ID, "status", "draft"); err != nil {
+		t.Fatalf("Failed to set property: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := source.ExportJSON(&buf); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// The target already has a "work" tag, which the import merges with
+	target, err := NewService(filepath.Join(dir, "target.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer target.Close()
+	if _, err := target.CreateTag("work"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	result, err := target.ImportJSON(&buf)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result.Notes != 1 || result.TagsCreated != 1 {
+		t.Errorf("Expected 1 note and 1 new tag, got %+v", result)
+	}
+
+	notes, err := target.GetAllNotes(models.NoteFilter{})
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("Expected 1 note, got %d (%v)", len(notes), err)
+	}
+	note := notes[0]
+	if note.Title != "Plan" || note.Notebook != "Projects" || len(note.Aliases) != 1 {
+		t.Errorf("Expected the note with its notebook and aliases, got %+v", note)
+	}
+	if !note.CreatedAt.Equal(plan.CreatedAt) {
+		t.Errorf("Expected creation time %v, got %v", plan.CreatedAt, note.CreatedAt)
+	}
+	if !note.Pinned || note.SortOrder != 1 || note.Color != "green" {
+		t.Errorf("Expected the note pinned first and green, got %+v", note)
+	}
+	properties, err := target.GetNoteProperties(note.ID)
+	if err != nil || len(properties) != 1 || properties[0] != (models.Property{Key: "status", Value: "draft"}) {
+		t.Errorf("Expected the status property, got %+v (%v)", properties, err)
+	}
+	if len(note.Tags) != 1 || note.Tags[0].Name != "work" {
+		t.Errorf("Expected the work tag, got %+v", note.Tags)
+	}
+	tags, _ := target.GetAllTags()
+	if len(tags) != 2 {
+		t.Errorf("Expected work and unused tags, got %d", len(tags))
+	}
+
+	// Broken documents are rejected before anything is written
+	broken := `{"version": 1, "notes": [{"id": 1, "title": "X"}], "tags": [], "note_tags": [{"note_id": 1, "tag_id": 9}]}`
+	if _, err := target.ImportJSON(strings.NewReader(broken)); err == nil {
+		t.Error("Expected an error for an unknown tag")
+	}
+	if notes, _ := target.GetAllNotes(models.NoteFilter{}); len(notes) != 1 {
+		t.Errorf("Expected nothing imported from a broken document, got %d notes", len(notes))
+	}
+}
+
+func TestLocaleSorting(t *testing.T) {
+	service, err := NewService(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+	defer SetLocale("")
+
+	titles := func() []string {
+		notes, err := service.GetAllNotes(models.NoteFilter{SortBy: models.SortByTitle})
+		if err != nil {
+			t.Fatalf("Failed to get notes: %v", err)
+		}
+		var got []string
+		for _, note := range notes {
+			got = append(got, note.Title)
+		}
+		return got
+	}
+
+	// Accented titles sort next to their base letters instead of after z
+	for _, title := range []string{"Zebra", "Ost", "Ölung", "apple", "Äpfel", "Bär"} {
+		if _, err := service.CreateNote(title, ""); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+	if err := SetLocale("de"); err != nil {
+		t.Fatalf("Failed to set locale: %v", err)
+	}
+	want := []string{"Äpfel", "apple", "Bär", "Ölung", "Ost", "Zebra"}
+	if got := titles(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	for _, name := range []string{"zeit", "über", "apfel"} {
+		if _, err := service.CreateTag(name); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	tags, err := service.GetAllTags()
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if len(tags) != 3 || tags[0].Name != "apfel" || tags[1].Name != "über" || tags[2].Name != "zeit" {
+		t.Errorf("Expected apfel, über, zeit, got %+v", tags)
+	}
+
+	// Hiragana and katakana interleave in gojūon order
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	for _, note := range notes {
+		if err := service.DeleteNote(note.ID); err != nil {
+			t.Fatalf("Failed to delete note: %v", err)
+		}
+	}
+	for _, title := range []string{"かさ", "アイス", "いちご", "あめ"} {
+		if _, err := service.CreateNote(title, ""); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+	if err := SetLocale("ja"); err != nil {
+		t.Fatalf("Failed to set locale: %v", err)
+	}
+	want = []string{"アイス", "あめ", "いちご", "かさ"}
+	if got := titles(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if err := SetLocale("not a locale!"); err == nil {
+		t.Error("Expected an error for an invalid locale")
+	}
+}
+
+func TestNoteProperties(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_properties_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := service.CreateNote("Book", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	if err := service.SetNoteProperty(note.ID, " status ", "reading"); err != nil {
+		t.Fatalf("Failed to set property: %v", err)
+	}
+	if err := service.SetNoteProperty(note.ID, "author", "Le Guin"); err != nil {
+		t.Fatalf("Failed to set property: %v", err)
+	}
+	if err := service.SetNoteProperty(note.ID, "status", "done"); err != nil {
+		t.Fatalf("Failed to replace property: %v", err)
+	}
+	if err := service.SetNoteProperty(note.ID, " ", "x"); err == nil {
+		t.Error("Expected an empty key to be rejected")
+	}
+
+	properties, err := service.GetNoteProperties(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get properties: %v", err)
+	}
+	expected := []models.Property{{Key: "author", Value: "Le Guin"}, {Key: "status", Value: "done"}}
+	if !reflect.DeepEqual(properties, expected) {
+		t.Errorf("Expected %v, got %v", expected, properties)
+	}
+
+	if err := service.DeleteNoteProperty(note.ID, "author"); err != nil {
+		t.Fatalf("Failed to delete property: %v", err)
+	}
+	if err := service.DeleteNoteProperty(note.ID, "author"); err == nil {
+		t.Error("Expected deleting a missing property to fail")
+	}
+	if properties, _ := service.GetNoteProperties(note.ID); len(properties) != 1 {
+		t.Errorf("Expected 1 property left, got %v", properties)
+	}
+
+	// The color label is kept with the note, and through undoing a delete
+	if err := service.SetNoteColor(note.ID, "green"); err != nil {
+		t.Fatalf("Failed to set color: %v", err)
+	}
+	note, _ = service.GetNote(note.ID)
+	if err := service.DeleteNote(note.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if err := service.RestoreNotes([]*models.Note{note}); err != nil {
+		t.Fatalf("Failed to restore note: %v", err)
+	}
+	restored, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get restored note: %v", err)
+	}
+	if restored.Color != "green" {
+		t.Errorf("Expected color green, got %q", restored.Color)
+	}
+	if properties, _ := service.GetNoteProperties(note.ID); len(properties) != 1 {
+		t.Errorf("Expected the property to survive the delete, got %v", properties)
+	}
+}
+
+func TestNoteUUIDs(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_uuid_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	first, err := service.CreateNote("First", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	copied, err := service.DuplicateNote(first.ID)
+	if err != nil {
+		t.Fatalf("Failed to duplicate note: %v", err)
+	}
+	if !utils.IsUUID(first.UUID) || first.UUID == copied.UUID {
+		t.Errorf("Expected distinct UUIDs, got %q and %q", first.UUID, copied.UUID)
+	}
+
+	for _, ref := range []string{strconv.Itoa(first.ID), first.UUID, strings.ToUpper(first.UUID)} {
+		if note, err := service.FindNote(ref); err != nil || note.ID != first.ID {
+			t.Errorf("Expected %q to find note %d, got %v", ref, first.ID, err)
+		}
+	}
+	if _, err := service.FindNote("first"); err == nil {
+		t.Error("Expected an invalid reference to fail")
+	}
+
+	// A JSON import keeps UUIDs unless they're taken here
+	var buf bytes.Buffer
+	if err := service.ExportJSON(&buf); err != nil {
+		t.Fatalf("Failed to export JSON: %v", err)
+	}
+	if err := service.DeleteNote(copied.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if _, err := service.ImportJSON(&buf); err != nil {
+		t.Fatalf("Failed to import JSON: %v", err)
+	}
+	if note, err := service.GetNoteByUUID(copied.UUID); err != nil || note.ID == copied.ID {
+		t.Errorf("Expected the deleted note back under its UUID, got %v", err)
+	}
+	notes, _ := service.GetAllNotes(models.NoteFilter{})
+	if len(notes) != 3 {
+		t.Fatalf("Expected 3 notes, got %d", len(notes))
+	}
+}
+
+func TestBackfillUUIDs(t *testing.T) {
+	// The same note in two databases gets the same UUID
+	var uuids []string
+	for i := 0; i < 2; i++ {
+		db, err := NewDB(filepath.Join(t.TempDir(), "notes.db"))
+		if err != nil {
+			t.Fatalf("Failed to create database: %v", err)
+		}
+		// As before notes had UUIDs
+		if _, err := db.Exec(`DROP INDEX idx_notes_uuid`); err != nil {
+			t.Fatalf("Failed to drop index: %v", err)
+		}
+		created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC).Add(time.Duration(i) * time.Millisecond)
+		for range 2 {
+			if _, err := db.Exec(`INSERT INTO notes (title, content, created_at, updated_at) VALUES ('Plan', '', ?, ?)`, created, created); err != nil {
+				t.Fatalf("Failed to insert note: %v", err)
+			}
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin: %v", err)
+		}
+		if err := backfillUUIDs(tx); err != nil {
+			t.Fatalf("Failed to backfill: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+
+		rows, err := db.Query(`SELECT uuid FROM notes ORDER BY id`)
+		if err != nil {
+			t.Fatalf("Failed to read UUIDs: %v", err)
+		}
+		var got []string
+		for rows.Next() {
+			var uuid string
+			rows.Scan(&uuid)
+			got = append(got, uuid)
+		}
+		rows.Close()
+		db.Close()
+
+		if len(got) != 2 || got[0] == got[1] {
+			t.Fatalf("Expected two distinct UUIDs, got %v", got)
+		}
+		uuids = append(uuids, got[0])
+	}
+	if uuids[0] != uuids[1] {
+		t.Errorf("Expected the same UUID in both databases, got %v", uuids)
+	}
+}
+
+func TestCreateDigest(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_digest_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	var notes []*models.Note
+	for _, n := range []struct {
+		title, content string
+		created        time.Time
+	}{
+		{"Thursday", "# Done\nshipped", time.Date(2024, 3, 7, 18, 0, 0, 0, time.Local)},
+		{"Morning", "coffee", time.Date(2024, 3, 5, 8, 0, 0, 0, time.Local)},
+		{"Evening", "---\nmood: ok\n---\nread", time.Date(2024, 3, 5, 21, 0, 0, 0, time.Local)},
+	} {
+		note := &models.Note{Title: n.title, Content: n.content, Notebook: "Journal", CreatedAt: n.created}
+		if err := service.ImportNote(note); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		notes = append(notes, note)
+	}
+
+	digest, err := service.CreateDigest(notes, "")
+	if err != nil {
+		t.Fatalf("Failed to create digest: %v", err)
+	}
+	expected := "Digest of 3 notes, 2024-03-05 to 2024-03-07.\n" +
+		"\n## 2024-03-05\n\n### Morning\n\ncoffee\n\n### Evening\n\nread\n" +
+		"\n## 2024-03-07\n\n### Thursday\n\n#### Done\nshipped\n"
+	if digest.Content != expected {
+		t.Errorf("Expected content %q, got %q", expected, digest.Content)
+	}
+	if digest.Title != "Digest 2024-03-05 to 2024-03-07" || digest.Notebook != "Journal" {
+		t.Errorf("Unexpected digest %q in %q", digest.Title, digest.Notebook)
+	}
+	if len(digest.Tags) != 1 || digest.Tags[0].Name != DigestTag {
+		t.Errorf("Expected the digest tag, got %v", digest.Tags)
+	}
+
+	for _, original := range notes {
+		note, err := service.GetNote(original.ID)
+		if err != nil {
+			t.Fatalf("Failed to get note: %v", err)
+		}
+		if !note.Archived() {
+			t.Errorf("Expected %q archived, got notebook %q", note.Title, note.Notebook)
+		}
+	}
+}
+
+func TestColorLabelFilter(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_color_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	for _, title := range []string{"Green one", "Green two", "Plain"} {
+		note, err := service.CreateNote(title, "")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if strings.HasPrefix(title, "Green") {
+			if err := service.SetNoteColor(note.ID, "green"); err != nil {
+				t.Fatalf("Failed to set color: %v", err)
+			}
+		}
+	}
+
+	for query, expected := range map[string]int{"color:green": 2, "color:none": 1, "color:green two": 1, "color:purple": 0} {
+		notes, err := service.SearchNotes(query, 0)
+		if err != nil {
+			t.Fatalf("Failed to search %q: %v", query, err)
+		}
+		if len(notes) != expected {
+			t.Errorf("Expected %d notes for %q, got %d", expected, query, len(notes))
+		}
+	}
+}
+
+func TestMigrationRollback(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "notes.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO notes (title, content, created_at, updated_at) VALUES ('Plan', '', ?, ?)`, time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to insert note: %v", err)
+	}
+	// As before notes had UUIDs
+	if _, err := db.Exec(`DROP INDEX idx_notes_uuid`); err != nil {
+		t.Fatalf("Failed to drop index: %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE notes DROP COLUMN uuid`); err != nil {
+		t.Fatalf("Failed to drop column: %v", err)
+	}
+	db.Close()
+
+	// A later column whose backfill fails undoes the whole migration
+	saved := columnAdditions
+	defer func() { columnAdditions = saved }()
+	columnAdditions = append(columnAdditions[:len(columnAdditions):len(columnAdditions)], struct {
+		table      string
+		column     string
+		definition string
+		backfill   func(tx *sql.Tx) error
+	}{"notes", "broken", "TEXT", func(tx *sql.Tx) error { return errors.New("backfill failed") }})
+
+	if _, err := NewDB(dbPath); err == nil || !strings.Contains(err.Error(), "backfill failed") {
+		t.Fatalf("Expected the migration to fail, got %v", err)
+	}
+
+	backups, _ := filepath.Glob(dbPath + ".pre-migration-*")
+	if len(backups) != 1 {
+		t.Fatalf("Expected a pre-migration backup, got %v", backups)
+	}
+
+	raw, err := sql.Open(driverName, dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for _, column := range []string{"uuid", "broken"} {
+		if exists, err := hasColumn(raw, "notes", column); err != nil || exists {
+			t.Errorf("Expected notes.%s to be rolled back, got %v, %v", column, exists, err)
+		}
+	}
+	raw.Close()
+
+	// Once the problem is fixed the migration goes through
+	columnAdditions = saved
+	service, err := NewService(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	defer service.Close()
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil || len(notes) != 1 || !utils.IsUUID(notes[0].UUID) {
+		t.Errorf("Expected the note to be kept with a UUID, got %v, %v", notes, err)
+	}
+}
+
+func TestSchemaMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "notes.db")
+	versions := func(db *DB) []int {
+		t.Helper()
+		applied, err := db.appliedMigrations()
+		if err != nil {
+			t.Fatalf("Failed to read applied migrations: %v", err)
+		}
+		return applied
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	var want []int
+	for _, m := range migrations {
+		want = append(want, m.version)
+	}
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if got := versions(db); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected migrations %v, got %v", want, got)
+	}
+	if _, err := db.Exec(`INSERT INTO notes (title, content, created_at, updated_at) VALUES ('Plan', '', ?, ?)`, time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to insert note: %v", err)
+	}
+	db.Close()
+
+	// Reopening applies nothing, so nothing is backed up
+	if db, err = NewDB(dbPath); err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	if got := versions(db); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected migrations %v after reopening, got %v", want, got)
+	}
+	if backups, _ := filepath.Glob(dbPath + ".pre-migration-*"); len(backups) != 0 {
+		t.Errorf("Expected no backup without pending migrations, got %v", backups)
+	}
+
+	// Databases from before migrations were recorded get them recorded
+	if _, err := db.Exec(`DROP TABLE schema_migrations`); err != nil {
+		t.Fatalf("Failed to drop schema_migrations: %v", err)
+	}
+	db.Close()
+	if db, err = NewDB(dbPath); err != nil {
+		t.Fatalf("Failed to migrate an unversioned database: %v", err)
+	}
+	if got := versions(db); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected migrations %v recorded, got %v", want, got)
+	}
+	db.Close()
+
+	// A new migration runs once, so it may alter tables
+	saved := migrationFiles
+	defer func() { migrationFiles = saved }()
+	next := fmt.Sprintf("%03d_note_summary.sql", want[len(want)-1]+1)
+	files := fstest.MapFS{next: {Data: []byte(`ALTER TABLE notes ADD COLUMN summary TEXT NOT NULL DEFAULT '';`)}}
+	entries, _ := fs.ReadDir(saved, ".")
+	for _, entry := range entries {
+		data, _ := fs.ReadFile(saved, entry.Name())
+		files[entry.Name()] = &fstest.MapFile{Data: data}
+	}
+	migrationFiles = files
+	for range 2 {
+		if db, err = NewDB(dbPath); err != nil {
+			t.Fatalf("Failed to apply the new migration: %v", err)
+		}
+		if exists, _ := hasColumn(db, "notes", "summary"); !exists {
+			t.Error("Expected the new migration to add notes.summary")
+		}
+		db.Close()
+	}
+
+	// Databases migrated by a newer version aren't opened
+	migrationFiles = saved
+	if _, err := NewDB(dbPath); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected a database ahead of the migrations to be refused, got %v", err)
+	}
+}
+
+func TestGetActivity(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_activity_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.Local) }
+	for _, times := range [][2]time.Time{
+		{day(1), day(1)}, // never edited
+		{day(1), day(3)}, // edited later
+		{day(2), day(3)},
+		{day(3).AddDate(0, -1, 0), day(2)}, // created before the range
+	} {
+		note, err := service.CreateNote("Note", "")
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		if _, err := service.db.Exec(`UPDATE notes SET created_at = ?, updated_at = ? WHERE id = ?`, times[0], times[1], note.ID); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+	}
+
+	days, err := service.GetActivity("2024-05-01")
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	expected := []models.ActivityDay{
+		{Date: "2024-05-01", Created: 2},
+		{Date: "2024-05-02", Created: 1, Updated: 1},
+		{Date: "2024-05-03", Updated: 2},
+	}
+	if !reflect.DeepEqual(days, expected) {
+		t.Errorf("Expected %v, got %v", expected, days)
+	}
+}
+
+func TestResolveNoteLink(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_links_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	meeting, err := service.CreateNote("Meeting Notes", "")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	reading, err := service.CreateNote("Reading list", "---\naliases: [Books]\n---\n")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	for target, expected := range map[string]int{
+		fmt.Sprintf("note://%d", meeting.ID): meeting.ID,
+		"note://" + reading.UUID:             reading.ID,
+		"Meeting%20notes.md":                 meeting.ID,
+		"./meeting-notes.md":                 meeting.ID,
+		"books":                              reading.ID,
+		"<Reading list>":                     reading.ID,
+	} {
+		note, err := service.ResolveNoteLink(target)
+		if err != nil {
+			t.Errorf("Failed to resolve %q: %v", target, err)
+			continue
+		}
+		if note.ID != expected {
+			t.Errorf("Expected %q to resolve to note %d, got %d", target, expected, note.ID)
+		}
+	}
+
+	for _, target := range []string{"Missing.md", "note://999", "note://" + NewNoteUUID(), "https://x.io"} {
+		if _, err := service.ResolveNoteLink(target); err == nil {
+			t.Errorf("Expected %q not to resolve", target)
+		}
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_snippets_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	sig := &models.Snippet{Trigger: " ;sig ", Body: "Cheers,\nSam"}
+	if err := service.SaveSnippet(sig); err != nil {
+		t.Fatalf("Failed to save snippet: %v", err)
+	}
+	if sig.ID == 0 || sig.Trigger != ";sig" {
+		t.Errorf("Expected saved snippet with trimmed trigger, got %+v", sig)
+	}
+	meeting := &models.Snippet{Trigger: "/meeting", Body: "## {{date}}\n{{cursor}}"}
+	if err := service.SaveSnippet(meeting); err != nil {
+		t.Fatalf("Failed to save snippet: %v", err)
+	}
+
+	for _, invalid := range []*models.Snippet{
+		{Trigger: ""},
+		{Trigger: "two words"},
+		{Trigger: ";sig"},
+		{ID: meeting.ID, Trigger: ";sig"},
+	} {
+		if err := service.SaveSnippet(invalid); err == nil {
+			t.Errorf("Expected saving %+v to fail", invalid)
+		}
+	}
+
+	sig.Body = "Best,\nSam"
+	if err := service.SaveSnippet(sig); err != nil {
+		t.Fatalf("Failed to update snippet: %v", err)
+	}
+	if err := service.DeleteSnippet(meeting.ID); err != nil {
+		t.Fatalf("Failed to delete snippet: %v", err)
+	}
+	if err := service.DeleteSnippet(meeting.ID); err == nil {
+		t.Error("Expected deleting a missing snippet to fail")
+	}
+
+	snippets, err := service.GetSnippets()
+	if err != nil {
+		t.Fatalf("Failed to get snippets: %v", err)
+	}
+	if len(snippets) != 1 || snippets[0].Trigger != ";sig" || snippets[0].Body != "Best,\nSam" {
+		t.Errorf("Expected the updated ;sig snippet only, got %+v", snippets)
+	}
+}
+
+func TestSplitNote(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "notes_split_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	service, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	note, err := service.CreateNote("Trip", "Packing list first.\n\n# Day one\nMuseum\n\n## Evening\nDinner")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := service.AddTagToNote(note.ID, "travel"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if err := service.MoveNotesToNotebook([]int{note.ID}, "Personal"); err != nil {
+		t.Fatalf("Failed to move note: %v", err)
+	}
+
+	parts, err := service.SplitNote(note.ID, true)
+	if err != nil {
+		t.Fatalf("Failed to split note: %v", err)
+	}
+	if len(parts) != 2 || parts[0].Title != "Day one" || parts[1].Title != "Evening" {
+		t.Fatalf("Expected notes for Day one and Evening, got %+v", parts)
+	}
+	backLink := fmt.Sprintf("Part of [Trip](note://%s)\n\n", note.UUID)
+	if parts[0].Content != backLink+"Museum" || parts[1].Content != backLink+"Dinner" {
+		t.Errorf("Unexpected contents %q and %q", parts[0].Content, parts[1].Content)
+	}
+	for _, part := range parts {
+		if part.Notebook != "Personal" || len(part.Tags) != 1 || part.Tags[0].Name != "travel" {
+			t.Errorf("Expected %q to keep the notebook and tags, got %q and %+v", part.Title, part.Notebook, part.Tags)
+		}
+	}
+
+	parent, err := service.GetNote(note.ID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	expected := fmt.Sprintf("Packing list first.\n\n- [Day one](note://%s)\n- [Evening](note://%s)\n", parts[0].UUID, parts[1].UUID)
+	if parent.Content != expected {
+		t.Errorf("Expected the note to link to its parts, got %q", parent.Content)
+	}
+
+	if _, err := service.SplitNote(parts[0].ID, false); err == nil {
+		t.Error("Expected splitting a note without headings to fail")
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// GetSnippets returns the stored snippets, sorted by trigger
+func (s *Service) GetSnippets() ([]*models.Snippet, error) {
+	rows, err := s.db.Query(`SELECT id, trigger, body FROM snippets ORDER BY trigger COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var snippets []*models.Snippet
+	for rows.Next() {
+		snippet := &models.Snippet{}
+		if err := rows.Scan(&snippet.ID, &snippet.Trigger, &snippet.Body); err != nil {
+			return nil, fmt.Errorf("failed to scan snippet: %w", err)
+		}
+		snippets = append(snippets, snippet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get snippets: %w", err)
+	}
+	return snippets, nil
+}
+
+// SaveSnippet stores a snippet, adding it when it has no ID yet. Triggers
+// are single words and unique.
+func (s *Service) SaveSnippet(snippet *models.Snippet) error {
+	snippet.Trigger = strings.TrimSpace(snippet.Trigger)
+	if snippet.Trigger == "" {
+		return fmt.Errorf("snippet trigger cannot be empty")
+	}
+	if strings.ContainsAny(snippet.Trigger, " \t\n") {
+		return fmt.Errorf("snippet trigger cannot contain spaces")
+	}
+
+	var existing int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM snippets WHERE trigger = ? AND id != ?`,
+		snippet.Trigger, snippet.ID).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("failed to save snippet: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("a snippet with trigger %q already exists", snippet.Trigger)
+	}
+
+	if snippet.ID == 0 {
+		result, err := s.db.Exec(`INSERT INTO snippets (trigger, body) VALUES (?, ?)`, snippet.Trigger, snippet.Body)
+		if err != nil {
+			return fmt.Errorf("failed to save snippet: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get snippet ID: %w", err)
+		}
+		snippet.ID = int(id)
+		return nil
+	}
+
+	result, err := s.db.Exec(`UPDATE snippets SET trigger = ?, body = ? WHERE id = ?`, snippet.Trigger, snippet.Body, snippet.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save snippet: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("snippet with ID %d not found", snippet.ID)
+	}
+	return nil
+}
+
+// DeleteSnippet removes a snippet
+func (s *Service) DeleteSnippet(id int) error {
+	result, err := s.db.Exec(`DELETE FROM snippets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete snippet: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("snippet with ID %d not found", id)
+	}
+
+	return nil
+}
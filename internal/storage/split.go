@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// SplitNote moves each section of a note under a level 1 or 2 heading into
+// a note of its own, titled after the heading, with the note's notebook and
+// tags. The note keeps what came before the first heading, followed by
+// links to the new notes. With linkBack, each new note starts with a link
+// back to it. Returns the new notes in order.
+func (s *Service) SplitNote(id int, linkBack bool) ([]*models.Note, error) {
+	parent, err := s.notes.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	preamble, sections := utils.SplitSections(parent.Content)
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("%q has no level 1 or 2 headings to split at", parent.Title)
+	}
+
+	var parts []*models.Note
+	for _, section := range sections {
+		content := section.Body
+		if linkBack {
+			content = "Part of " + noteLink(parent) + "\n\n" + content
+		}
+		title := section.Title
+		if title == "" {
+			title = "Untitled"
+		}
+
+		part, err := s.CreateNote(title, content)
+		if err != nil {
+			return parts, fmt.Errorf("failed to split %q: %w", parent.Title, err)
+		}
+		if parent.Notebook != "" {
+			if err := s.notes.SetNotebook([]int{part.ID}, parent.Notebook); err != nil {
+				return parts, err
+			}
+		}
+		for _, tag := range parent.Tags {
+			if err := s.AddTagToNote(part.ID, tag.Name); err != nil {
+				return parts, err
+			}
+		}
+		if part, err = s.notes.GetByID(part.ID); err != nil {
+			return parts, err
+		}
+		parts = append(parts, part)
+	}
+
+	var b strings.Builder
+	if preamble = strings.TrimRight(preamble, "\n"); preamble != "" {
+		b.WriteString(preamble + "\n\n")
+	}
+	for _, part := range parts {
+		b.WriteString("- " + noteLink(part) + "\n")
+	}
+	parent.Content = b.String()
+	if err := s.UpdateNote(parent); err != nil {
+		return parts, fmt.Errorf("failed to split %q: %w", parent.Title, err)
+	}
+	return parts, nil
+}
+
+// noteLink returns a markdown link to a note by its UUID, which keeps
+// working once the notes sync to another machine
+func noteLink(note *models.Note) string {
+	title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(note.Title)
+	return fmt.Sprintf("[%s](%s%s)", title, utils.NoteLinkScheme, note.UUID)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// GetSyncRevisions returns the last synced revision of every note file
+// whose path starts with prefix, keyed by path
+func (s *Service) GetSyncRevisions(prefix string) (map[string]*models.SyncRevision, error) {
+	rows, err := s.db.Query(`
+		SELECT path, hash, etag FROM sync_revisions
+		WHERE substr(path, 1, length(?)) = ?`, prefix, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := map[string]*models.SyncRevision{}
+	for rows.Next() {
+		rev := &models.SyncRevision{}
+		if err := rows.Scan(&rev.Path, &rev.Hash, &rev.ETag); err != nil {
+			return nil, fmt.Errorf("failed to scan sync revision: %w", err)
+		}
+		revisions[rev.Path] = rev
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sync revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// SetSyncRevision records the synced revision of a note file
+func (s *Service) SetSyncRevision(rev *models.SyncRevision) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sync_revisions (path, hash, etag) VALUES (?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET hash = excluded.hash, etag = excluded.etag`,
+		rev.Path, rev.Hash, rev.ETag)
+	if err != nil {
+		return fmt.Errorf("failed to set sync revision: %w", err)
+	}
+	return nil
+}
+
+// DeleteSyncRevision forgets a note file that no longer exists on either side
+func (s *Service) DeleteSyncRevision(path string) error {
+	if _, err := s.db.Exec(`DELETE FROM sync_revisions WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("failed to delete sync revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// resolveTagName returns the tag a name stands for: the canonical tag of
+// an alias, or an existing tag spelled differently, like Golang for
+// go-lang. Names matching neither are returned as they are.
+func (s *Service) resolveTagName(name string) (string, error) {
+	var canonical string
+	err := s.db.QueryRow(`SELECT tag FROM tag_aliases WHERE alias_key = ?`, utils.TagKey(name)).Scan(&canonical)
+	switch {
+	case err == nil:
+		name = canonical
+	case err != sql.ErrNoRows:
+		return "", fmt.Errorf("failed to look up tag alias: %w", err)
+	}
+
+	tags, err := s.tags.GetAll()
+	if err != nil {
+		return "", err
+	}
+	key := utils.TagKey(name)
+	for _, tag := range tags {
+		if tag.Name == name {
+			return name, nil
+		}
+	}
+	for _, tag := range tags {
+		if utils.TagKey(tag.Name) == key {
+			return tag.Name, nil
+		}
+	}
+	return name, nil
+}
+
+// GetTagAliases returns the tag aliases, sorted by alias
+func (s *Service) GetTagAliases() ([]models.TagAlias, error) {
+	rows, err := s.db.Query(`SELECT alias, tag FROM tag_aliases ORDER BY alias COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []models.TagAlias
+	for rows.Next() {
+		var alias models.TagAlias
+		if err := rows.Scan(&alias.Alias, &alias.Tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tag aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// SetTagAlias makes alias another spelling of tag, replacing what it stood
+// for before. Tags already spelled like the alias are merged into tag,
+// and aliases of the alias move to tag too.
+func (s *Service) SetTagAlias(alias, tag string) error {
+	alias = utils.NormalizeTag(alias)
+	tag = utils.NormalizeTag(tag)
+	if alias == "" || tag == "" {
+		return fmt.Errorf("alias and tag cannot be empty")
+	}
+	aliasKey := utils.TagKey(alias)
+	if aliasKey == utils.TagKey(tag) {
+		return fmt.Errorf("%q and %q are already the same tag", alias, tag)
+	}
+
+	var target string
+	err := s.db.QueryRow(`SELECT tag FROM tag_aliases WHERE alias_key = ?`, utils.TagKey(tag)).Scan(&target)
+	if err == nil {
+		return fmt.Errorf("%q is itself an alias of %q", tag, target)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up tag alias: %w", err)
+	}
+
+	canonical, err := s.GetOrCreateTag(tag)
+	if err != nil {
+		return err
+	}
+	tags, err := s.tags.GetAll()
+	if err != nil {
+		return err
+	}
+	aliases, err := s.GetTagAliases()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO tag_aliases (alias_key, alias, tag) VALUES (?, ?, ?)
+		ON CONFLICT (alias_key) DO UPDATE SET alias = excluded.alias, tag = excluded.tag`,
+		aliasKey, alias, canonical.Name)
+	if err != nil {
+		return fmt.Errorf("failed to save tag alias: %w", err)
+	}
+	for _, other := range aliases {
+		if utils.TagKey(other.Tag) == aliasKey {
+			if _, err := tx.Exec(`UPDATE tag_aliases SET tag = ? WHERE alias_key = ?`, canonical.Name, utils.TagKey(other.Alias)); err != nil {
+				return fmt.Errorf("failed to save tag alias: %w", err)
+			}
+		}
+	}
+
+	for _, merged := range tags {
+		if merged.ID == canonical.ID || utils.TagKey(merged.Name) != aliasKey {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO note_tags (note_id, tag_id)
+			SELECT note_id, ? FROM note_tags WHERE tag_id = ?`, canonical.ID, merged.ID); err != nil {
+			return fmt.Errorf("failed to merge tag %q: %w", merged.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM note_tags WHERE tag_id = ?`, merged.ID); err != nil {
+			return fmt.Errorf("failed to merge tag %q: %w", merged.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, merged.ID); err != nil {
+			return fmt.Errorf("failed to merge tag %q: %w", merged.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteTagAlias removes an alias. Notes tagged through it keep the
+// canonical tag.
+func (s *Service) DeleteTagAlias(alias string) error {
+	result, err := s.db.Exec(`DELETE FROM tag_aliases WHERE alias_key = ?`, utils.TagKey(alias))
+	if err != nil {
+		return fmt.Errorf("failed to delete tag alias: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag alias %q not found", alias)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"markdown-note-taking-app/internal/models"
 )
@@ -36,10 +37,10 @@ func (r *tagRepository) Create(name string) (*models.Tag, error) {
 
 // GetByID retrieves a tag by its ID
 func (r *tagRepository) GetByID(id int) (*models.Tag, error) {
-	query := `SELECT id, name FROM tags WHERE id = ?`
+	query := `SELECT id, name, color FROM tags WHERE id = ?`
 
 	tag := &models.Tag{}
-	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.Name)
+	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.Name, &tag.Color)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tag with ID %d not found", id)
@@ -52,7 +53,7 @@ func (r *tagRepository) GetByID(id int) (*models.Tag, error) {
 
 // GetAll retrieves all tags
 func (r *tagRepository) GetAll() ([]*models.Tag, error) {
-	query := `SELECT id, name FROM tags ORDER BY name`
+	query := `SELECT id, name, color FROM tags ORDER BY name COLLATE ` + localeCollation
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -63,7 +64,7 @@ func (r *tagRepository) GetAll() ([]*models.Tag, error) {
 	var tags []*models.Tag
 	for rows.Next() {
 		tag := &models.Tag{}
-		err := rows.Scan(&tag.ID, &tag.Name)
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.Color)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag: %w", err)
 		}
@@ -75,10 +76,10 @@ func (r *tagRepository) GetAll() ([]*models.Tag, error) {
 
 // GetByName retrieves a tag by its name
 func (r *tagRepository) GetByName(name string) (*models.Tag, error) {
-	query := `SELECT id, name FROM tags WHERE name = ?`
+	query := `SELECT id, name, color FROM tags WHERE name = ?`
 
 	tag := &models.Tag{}
-	err := r.db.QueryRow(query, name).Scan(&tag.ID, &tag.Name)
+	err := r.db.QueryRow(query, name).Scan(&tag.ID, &tag.Name, &tag.Color)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tag with name '%s' not found", name)
@@ -110,6 +111,25 @@ func (r *tagRepository) Update(tag *models.Tag) error {
 	return nil
 }
 
+// SetColor sets the color label of a tag. An empty name removes it.
+func (r *tagRepository) SetColor(id int, color string) error {
+	result, err := r.db.Exec(`UPDATE tags SET color = ? WHERE id = ?`, color, id)
+	if err != nil {
+		return fmt.Errorf("failed to set tag color: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag with ID %d not found", id)
+	}
+
+	return nil
+}
+
 // Delete removes a tag from the database
 func (r *tagRepository) Delete(id int) error {
 	query := `DELETE FROM tags WHERE id = ?`
@@ -134,11 +154,11 @@ func (r *tagRepository) Delete(id int) error {
 // GetNoteTags retrieves all tags for a specific note
 func (r *tagRepository) GetNoteTags(noteID int) ([]*models.Tag, error) {
 	query := `
-		SELECT t.id, t.name
+		SELECT t.id, t.name, t.color
 		FROM tags t
 		JOIN note_tags nt ON t.id = nt.tag_id
 		WHERE nt.note_id = ?
-		ORDER BY t.name`
+		ORDER BY t.name COLLATE ` + localeCollation
 
 	rows, err := r.db.Query(query, noteID)
 	if err != nil {
@@ -149,7 +169,7 @@ func (r *tagRepository) GetNoteTags(noteID int) ([]*models.Tag, error) {
 	var tags []*models.Tag
 	for rows.Next() {
 		tag := &models.Tag{}
-		err := rows.Scan(&tag.ID, &tag.Name)
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.Color)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag: %w", err)
 		}
@@ -158,3 +178,38 @@ func (r *tagRepository) GetNoteTags(noteID int) ([]*models.Tag, error) {
 
 	return tags, rows.Err()
 }
+
+// GetUsage retrieves every tag with its note count and most recent use
+func (r *tagRepository) GetUsage() ([]*models.TagUsage, error) {
+	query := `
+		SELECT t.id, t.name, t.color, COUNT(nt.note_id),
+			MAX(CAST(strftime('%s', n.updated_at) AS INTEGER))
+		FROM tags t
+		LEFT JOIN note_tags nt ON t.id = nt.tag_id
+		LEFT JOIN notes n ON n.id = nt.note_id
+		GROUP BY t.id, t.name
+		ORDER BY t.name COLLATE ` + localeCollation
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*models.TagUsage
+	for rows.Next() {
+		u := &models.TagUsage{}
+		var lastUsed sql.NullInt64
+		err := rows.Scan(&u.Tag.ID, &u.Tag.Name, &u.Tag.Color, &u.NoteCount, &lastUsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag usage: %w", err)
+		}
+		if lastUsed.Valid {
+			t := time.Unix(lastUsed.Int64, 0)
+			u.LastUsed = &t
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"markdown-note-taking-app/internal/storage"
+)
+
+// dirStore keeps note files in a directory on this machine
+type dirStore struct {
+	dir string
+}
+
+// NewDir creates a provider that mirrors notes into dir, one markdown file
+// per note, so they can be edited with other programs. The directory is
+// created on the first sync if missing.
+func NewDir(service *storage.Service, dir string) (*Mirror, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	return newMirror(service, &dirStore{dir: abs}), nil
+}
+
+// location returns the directory as a file URL
+func (d *dirStore) location() string {
+	return "file://" + filepath.ToSlash(d.dir) + "/"
+}
+
+// fileETag derives an ETag from a file's size and modification time, which
+// any program writing the file changes
+func fileETag(info fs.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
+
+// noteFile reports whether a directory entry is a note file. Hidden files
+// are left out, which skips the lock files and backups editors leave
+// beside the files they edit.
+func noteFile(entry fs.DirEntry) bool {
+	name := entry.Name()
+	return entry.Type().IsRegular() && !strings.HasPrefix(name, ".") && strings.EqualFold(filepath.Ext(name), ".md")
+}
+
+// list returns the ETag of every markdown file in the directory
+func (d *dirStore) list() (map[string]string, bool, error) {
+	entries, err := os.ReadDir(d.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(d.dir, 0755); err != nil {
+			return nil, false, fmt.Errorf("failed to create %s: %w", d.dir, err)
+		}
+		return map[string]string{}, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", d.dir, err)
+	}
+
+	files := map[string]string{}
+	for _, entry := range entries {
+		if !noteFile(entry) {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = fileETag(info)
+	}
+	return files, false, nil
+}
+
+// get reads a file and its ETag
+func (d *dirStore) get(name string) (string, string, error) {
+	path := filepath.Join(d.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return string(data), fileETag(info), nil
+}
+
+// current returns the ETag a file has now, and whether it exists
+func (d *dirStore) current(name string) (string, bool, error) {
+	info, err := os.Stat(filepath.Join(d.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return fileETag(info), true, nil
+}
+
+// put writes a file unless it changed since it had etag. The content is
+// written to a hidden file first and renamed over the note, so programs
+// watching the directory never see half a note.
+func (d *dirStore) put(name, content string, exists bool, etag string) (string, error) {
+	current, found, err := d.current(name)
+	if err != nil {
+		return "", err
+	}
+	if found != exists || (exists && etag != "" && current != etag) {
+		return "", ErrRemoteChanged
+	}
+
+	temp, err := os.CreateTemp(d.dir, "."+name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.WriteString(content); err != nil {
+		temp.Close()
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := temp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	path := filepath.Join(d.dir, name)
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return fileETag(info), nil
+}
+
+// remove deletes a file unless it changed since it had etag
+func (d *dirStore) remove(name, etag string) error {
+	current, found, err := d.current(name)
+	if err != nil || !found {
+		return err
+	}
+	if etag != "" && current != etag {
+		return ErrRemoteChanged
+	}
+	if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	return nil
+}
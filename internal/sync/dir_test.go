@@ -0,0 +1,177 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+func TestDirMirror(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "notes")
+	service, err := storage.NewService(filepath.Join(tmp, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	defer service.Close()
+	mirror, err := NewDir(service, dir)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// The first sync creates the directory and writes the note there
+	if _, err := service.CreateNote("Groceries", "- milk"); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	result, err := Run(mirror)
+	if err != nil || result.Uploaded != 1 {
+		t.Fatalf("Failed to sync: %v (%+v)", err, result)
+	}
+	path := filepath.Join(dir, "groceries.md")
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "- milk") {
+		t.Fatalf("Expected the note in the directory, got %q (%v)", data, err)
+	}
+
+	// An edit made by another program is pulled into the note
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), "- milk", "- milk\n- eggs", 1)), 0644); err != nil {
+		t.Fatalf("Failed to edit file: %v", err)
+	}
+	if result, err = Run(mirror); err != nil || result.Imported.Updated != 1 || result.Uploaded != 0 {
+		t.Fatalf("Failed to pull the edit: %v (%+v)", err, result)
+	}
+	note := findNote(t, service, "Groceries")
+	if note == nil || note.Content != "- milk\n- eggs" {
+		t.Fatalf("Expected the edit in the note, got %+v", note)
+	}
+
+	// A file created by hand becomes a note, renamed after its title
+	if err := os.WriteFile(filepath.Join(dir, "Ideas.md"), []byte("A garden shed"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if result, err = Run(mirror); err != nil || result.Imported.Created != 1 {
+		t.Fatalf("Failed to pull the new file: %v (%+v)", err, result)
+	}
+	if findNote(t, service, "Ideas") == nil {
+		t.Errorf("Expected a note for the new file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ideas.md")); err != nil {
+		t.Errorf("Expected the file renamed to ideas.md: %v", err)
+	}
+
+	// Files other programs leave behind are skipped
+	if err := os.WriteFile(filepath.Join(dir, ".#groceries.md"), []byte("lock"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if result, err = Run(mirror); err != nil || result.Imported.Created != 0 {
+		t.Errorf("Expected hidden files to be skipped, got %+v (%v)", result, err)
+	}
+
+	// Changing the file and the note both is a conflict
+	note.Content = "- oat milk"
+	if err := service.UpdateNote(note); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if err := os.WriteFile(path, []byte(string(data)+"\n- bread"), 0644); err != nil {
+		t.Fatalf("Failed to edit file: %v", err)
+	}
+	if result, err = Run(mirror); err != nil || len(result.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v (%v)", result, err)
+	}
+	if _, err := mirror.Resolve(result.Conflicts[0], KeepBoth); err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if _, err := Run(mirror); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "oat milk") || !strings.Contains(string(data), "bread") {
+		t.Errorf("Expected both versions in the file, got %q", data)
+	}
+
+	// Deleting the file moves the note to the trash, which isn't mirrored
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	if result, err = Run(mirror); err != nil || result.Deleted != 1 {
+		t.Fatalf("Failed to sync: %v (%+v)", err, result)
+	}
+	if note := findNote(t, service, "Groceries"); note == nil || note.Notebook != models.TrashNotebook {
+		t.Errorf("Expected the note in the trash, got %+v", note)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the trashed note's file to stay deleted: %v", err)
+	}
+
+	// A directory emptied all at once changes nothing
+	if _, err := service.CreateNote("Errands", "- post office"); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if _, err := Run(mirror); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	for _, name := range []string{"ideas.md", "errands.md"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Failed to delete file: %v", err)
+		}
+	}
+	if _, err := Run(mirror); !errors.Is(err, ErrAllMissing) {
+		t.Errorf("Expected the pull refused, got %v", err)
+	}
+	for _, title := range []string{"Ideas", "Errands"} {
+		if note := findNote(t, service, title); note == nil || note.Notebook != "" {
+			t.Errorf("Expected %s left alone, got %+v", title, note)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "notes")
+	watcher, err := Watch(dir)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	changed := func() bool {
+		select {
+		case <-watcher.Changes():
+			return true
+		case <-time.After(2 * time.Second):
+			return false
+		}
+	}
+
+	// Several writes settle into one change
+	for i := range 3 {
+		if err := os.WriteFile(filepath.Join(dir, "plan.md"), []byte(strings.Repeat("x", i)), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	if !changed() {
+		t.Fatalf("Expected a change after writing a note")
+	}
+	select {
+	case <-watcher.Changes():
+		t.Errorf("Expected the writes reported as one change")
+	case <-time.After(2 * watchSettle):
+	}
+
+	watcher.Trigger()
+	if !changed() {
+		t.Errorf("Expected a change after a trigger")
+	}
+
+	if err := watcher.Close(); err != nil {
+		t.Errorf("Failed to close watcher: %v", err)
+	}
+	if _, ok := <-watcher.Changes(); ok {
+		t.Errorf("Expected the changes closed with the watcher")
+	}
+}
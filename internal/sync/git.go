@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// remoteName is the git remote notes are pulled from and pushed to
+const remoteName = "origin"
+
+// Repo is a git working tree holding exported notes
+type Repo struct {
+	dir string
+}
+
+// OpenRepo opens the git repository in dir, creating the directory and
+// initializing a repository when needed
+func OpenRepo(dir string) (*Repo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sync directory: %w", err)
+	}
+
+	repo := &Repo{dir: dir}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := repo.git("init"); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// Dir returns the working tree directory
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// git runs a git command in the working tree and returns its trimmed output
+func (r *Repo) git(args ...string) (string, error) {
+	out, err := r.gitRaw(args...)
+	return strings.TrimSpace(out), err
+}
+
+// gitRaw runs a git command and returns its output unchanged
+func (r *Repo) gitRaw(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, msg)
+	}
+	return stdout.String(), nil
+}
+
+// identity returns config flags naming the committer when the user hasn't
+// configured git, which would otherwise refuse to commit
+func (r *Repo) identity() []string {
+	if email, err := r.git("config", "--get", "user.email"); err == nil && email != "" {
+		return nil
+	}
+	return []string{"-c", "user.name=TuiNotes", "-c", "user.email=tuinotes@localhost"}
+}
+
+// Commit stages every change and commits it. Returns false when there was
+// nothing to commit.
+func (r *Repo) Commit(message string) (bool, error) {
+	if _, err := r.git("add", "-A"); err != nil {
+		return false, err
+	}
+	if status, err := r.git("status", "--porcelain"); err != nil || status == "" {
+		return false, err
+	}
+
+	args := append(r.identity(), "commit", "-q", "-m", message)
+	if _, err := r.git(args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HasRemote reports whether the repository has a remote to sync with
+func (r *Repo) HasRemote() bool {
+	remotes, err := r.git("remote")
+	if err != nil {
+		return false
+	}
+	for _, remote := range strings.Fields(remotes) {
+		if remote == remoteName {
+			return true
+		}
+	}
+	return false
+}
+
+// branch returns the current branch name
+func (r *Repo) branch() (string, error) {
+	return r.git("symbolic-ref", "--short", "HEAD")
+}
+
+// Merging reports whether a merge is waiting for conflicts to be resolved
+func (r *Repo) Merging() bool {
+	_, err := r.git("rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return err == nil
+}
+
+// Pull merges the remote branch into the current one. Conflicts aren't an
+// error; they're returned for resolution and the merge is left in progress.
+func (r *Repo) Pull() ([]Conflict, error) {
+	branch, err := r.branch()
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing to pull before the branch has been pushed once
+	if _, err := r.git("ls-remote", "--exit-code", "--heads", remoteName, branch); err != nil {
+		return nil, nil
+	}
+
+	args := append(r.identity(), "pull", "--no-rebase", "--no-edit", "--allow-unrelated-histories", remoteName, branch)
+	if _, pullErr := r.git(args...); pullErr != nil {
+		conflicts, err := r.Conflicts()
+		if err != nil || len(conflicts) == 0 {
+			return nil, pullErr
+		}
+		return conflicts, nil
+	}
+	return nil, nil
+}
+
+// Push pushes the current branch, setting it up to track the remote
+func (r *Repo) Push() error {
+	branch, err := r.branch()
+	if err != nil {
+		return err
+	}
+	_, err = r.git("push", "-q", "-u", remoteName, branch)
+	return err
+}
+
+// Conflicts returns the files left unmerged by the last pull
+func (r *Repo) Conflicts() ([]Conflict, error) {
+	out, err := r.git("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []Conflict
+	for _, path := range strings.Split(out, "\n") {
+		if path == "" {
+			continue
+		}
+		conflict := Conflict{Path: path}
+		// Index stage 2 holds our version and stage 3 theirs; a missing
+		// stage means that side deleted the file
+		if local, err := r.gitRaw("show", ":2:"+path); err == nil {
+			conflict.Local = local
+		} else {
+			conflict.LocalDeleted = true
+		}
+		if remote, err := r.gitRaw("show", ":3:"+path); err == nil {
+			conflict.Remote = remote
+		} else {
+			conflict.RemoteDeleted = true
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// resolve replaces a conflicted file with content, or removes it when
+// deleted, and marks it resolved
+func (r *Repo) resolve(path, content string, deleted bool) error {
+	if deleted {
+		_, err := r.git("rm", "-q", "--", path)
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, path), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	_, err := r.git("add", "--", path)
+	return err
+}
+
+// finishMerge commits a merge once every conflict is resolved
+func (r *Repo) finishMerge() error {
+	args := append(r.identity(), "commit", "-q", "--no-edit")
+	_, err := r.git(args...)
+	return err
+}
@@ -0,0 +1,341 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+
+	"markdown-note-taking-app/internal/importer"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// Git syncs notes through a git repository of markdown files, which is
+// pulled from and pushed to the remote named origin when there is one
+type Git struct {
+	mu      gosync.Mutex // git can't run two commands on one repository at once
+	repo    *Repo
+	service *storage.Service
+}
+
+// NewGit opens or creates the sync repository in dir
+func NewGit(service *storage.Service, dir string) (*Git, error) {
+	repo, err := OpenRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Git{repo: repo, service: service}, nil
+}
+
+// Dir returns the repository directory
+func (g *Git) Dir() string {
+	return g.repo.Dir()
+}
+
+// CommitNotes exports every note and commits the changes. It does nothing
+// while conflicts are being resolved so the conflicted files stay intact.
+func (g *Git) CommitNotes(message string) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.service.EncryptionEnabled() {
+		return false, ErrEncrypted
+	}
+	if g.repo.Merging() {
+		return false, nil
+	}
+	if err := g.adopt(); err != nil {
+		return false, err
+	}
+	if err := g.export(); err != nil {
+		return false, err
+	}
+	return g.repo.Commit(message)
+}
+
+// Pull commits local changes, merges the remote branch and imports what
+// changed there. When the merge conflicts, the merge is left in progress
+// and the conflicts are returned.
+func (g *Git) Pull() (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var result Result
+	if g.service.EncryptionEnabled() {
+		return result, ErrEncrypted
+	}
+	if g.repo.Merging() {
+		return g.continueMerge()
+	}
+
+	if err := g.adopt(); err != nil {
+		return result, err
+	}
+	if err := g.export(); err != nil {
+		return result, err
+	}
+	committed, err := g.repo.Commit("Sync notes")
+	if err != nil {
+		return result, err
+	}
+	result.Committed = committed
+
+	if !g.repo.HasRemote() {
+		return result, nil
+	}
+
+	// An empty repository has no HEAD yet, and nothing to delete either
+	base, _ := g.repo.git("rev-parse", "-q", "--verify", "HEAD")
+	conflicts, err := g.repo.Pull()
+	if err != nil {
+		return result, err
+	}
+	if len(conflicts) > 0 {
+		result.Conflicts = conflicts
+		return result, nil
+	}
+	return g.applyMerge(result, base)
+}
+
+// Push commits any changes and pushes them to the remote
+func (g *Git) Push() (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var result Result
+	if g.service.EncryptionEnabled() {
+		return result, ErrEncrypted
+	}
+	if g.repo.Merging() {
+		return result, fmt.Errorf("failed to push: sync conflicts aren't resolved")
+	}
+
+	if err := g.export(); err != nil {
+		return result, err
+	}
+	committed, err := g.repo.Commit("Sync notes")
+	if err != nil {
+		return result, err
+	}
+	result.Committed = committed
+
+	if !g.repo.HasRemote() {
+		return result, nil
+	}
+	if err := g.repo.Push(); err != nil {
+		return result, err
+	}
+	result.Pushed = true
+	return result, nil
+}
+
+// Resolve settles one conflict with the chosen version. Once the last one
+// is resolved the merge is completed and imported.
+func (g *Git) Resolve(conflict Conflict, choice Choice) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	content, deleted := conflict.Local, conflict.LocalDeleted
+	switch choice {
+	case KeepRemote:
+		content, deleted = conflict.Remote, conflict.RemoteDeleted
+	case KeepBoth:
+		content, deleted = mergeBoth(conflict), false
+	}
+	if err := g.repo.resolve(conflict.Path, content, deleted); err != nil {
+		return Result{}, err
+	}
+	return g.continueMerge()
+}
+
+// continueMerge returns the conflicts left, or completes the merge when
+// none are
+func (g *Git) continueMerge() (Result, error) {
+	conflicts, err := g.repo.Conflicts()
+	if err != nil || len(conflicts) > 0 {
+		return Result{Conflicts: conflicts}, err
+	}
+	if err := g.repo.finishMerge(); err != nil {
+		return Result{}, err
+	}
+	// The merge commit's first parent is the local side
+	return g.applyMerge(Result{Committed: true}, "HEAD^1")
+}
+
+// applyMerge applies the files merged on top of base to storage, then
+// exports the notes again so the repository matches storage
+func (g *Git) applyMerge(result Result, base string) (Result, error) {
+	deleted, err := g.deleteRemoved(base)
+	if err != nil {
+		return result, err
+	}
+	result.Deleted = deleted
+	imported, err := importer.MarkdownDir(g.service, g.repo.Dir())
+	if err != nil {
+		return result, err
+	}
+	result.Imported = imported
+
+	if err := g.export(); err != nil {
+		return result, err
+	}
+	committed, err := g.repo.Commit("Sync notes")
+	if err != nil {
+		return result, err
+	}
+	result.Committed = result.Committed || committed
+	return result, nil
+}
+
+// adoptedKey is set in the repository's git config once its files have
+// been imported
+const adoptedKey = "tuinotes.adopted"
+
+// adopt imports the notes already in the repository the first time it's
+// used, e.g. after cloning it on a new machine, so the first export
+// doesn't remove them as stale
+func (g *Git) adopt() error {
+	if value, err := g.repo.git("config", "--get", adoptedKey); err == nil && value == "true" {
+		return nil
+	}
+	if _, err := importer.MarkdownDir(g.service, g.repo.Dir()); err != nil {
+		return err
+	}
+	_, err := g.repo.git("config", adoptedKey, "true")
+	return err
+}
+
+// export writes every note to the repository and removes note files that
+// weren't written, such as those of deleted or renamed notes. Files added
+// elsewhere have been imported as notes by then and are written again.
+func (g *Git) export() error {
+	files, err := localFiles(g.service)
+	if err != nil {
+		return err
+	}
+
+	for name, note := range files {
+		path := filepath.Join(g.repo.Dir(), name)
+		data := []byte(noteMarkdown(note))
+		// Leave unchanged files alone so their modification time stays put
+		if existing, err := os.ReadFile(path); err != nil || !bytes.Equal(existing, data) {
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(g.repo.Dir())
+	if err != nil {
+		return fmt.Errorf("failed to read sync directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ".md") || files[name] != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(g.repo.Dir(), name)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deleteRemoved moves the notes whose files were removed between base and
+// HEAD to the trash, which is how deletions made elsewhere arrive, and
+// returns how many it moved. Files match their notes by UUID, as on import,
+// so a note whose file changed name, because it was renamed or another
+// note sharing its title went, is only moved when no file holds its UUID
+// any more. Files synced before notes had UUIDs match by slug. Trashed
+// notes aren't exported, so their files stay removed.
+func (g *Git) deleteRemoved(base string) (int, error) {
+	if base == "" {
+		return 0, nil
+	}
+	out, err := g.repo.git("diff", "--name-status", "--no-renames", base, "HEAD")
+	if err != nil || out == "" {
+		return 0, err
+	}
+
+	var removed, slugs []string
+	kept := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok || !strings.EqualFold(filepath.Ext(path), ".md") {
+			continue
+		}
+		if status != "A" {
+			old, err := g.repo.gitRaw("show", base+":"+path)
+			if err != nil {
+				return 0, err
+			}
+			uuid, slug := fileIdentity(old)
+			if uuid != "" {
+				removed = append(removed, uuid)
+			} else if status == "D" && slug != "" {
+				slugs = append(slugs, slug)
+			}
+		}
+		if status != "D" {
+			current, err := g.repo.gitRaw("show", "HEAD:"+path)
+			if err != nil {
+				return 0, err
+			}
+			if uuid, _ := fileIdentity(current); uuid != "" {
+				kept[uuid] = true
+			}
+		}
+	}
+
+	var ids []int
+	for _, uuid := range removed {
+		if kept[uuid] {
+			continue
+		}
+		kept[uuid] = true
+		note, err := g.service.GetNoteByUUID(uuid)
+		if err != nil || note.Notebook == models.TrashNotebook {
+			continue
+		}
+		ids = append(ids, note.ID)
+	}
+	if len(slugs) > 0 {
+		files, err := localFiles(g.service)
+		if err != nil {
+			return 0, err
+		}
+		bySlug := map[string]int{}
+		for _, note := range files {
+			bySlug[utils.Slugify(note.Title)] = note.ID
+		}
+		for _, slug := range slugs {
+			if id, ok := bySlug[slug]; ok {
+				ids = append(ids, id)
+				delete(bySlug, slug)
+			}
+		}
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		if err := g.service.MoveNotesToNotebook([]int{id}, models.TrashNotebook); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// fileIdentity returns the UUID and slug a synced file's frontmatter holds
+func fileIdentity(data string) (uuid, slug string) {
+	fm, _, _ := utils.ParseFrontmatter(data)
+	if id, _ := fm.Get("uuid"); utils.IsUUID(id) {
+		uuid = strings.ToLower(id)
+	}
+	slug, _ = fm.Get("slug")
+	return uuid, slug
+}
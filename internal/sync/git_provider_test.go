@@ -0,0 +1,194 @@
+package sync
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+// newMachine creates a service and git provider in dir that sync through remote
+func newMachine(t *testing.T, dir, remote string) (*storage.Service, *Git) {
+	t.Helper()
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	provider, err := NewGit(service, filepath.Join(dir, "notes"))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.repo.git("remote", "add", remoteName, remote); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	return service, provider
+}
+
+// findNote returns the note titled title, or nil
+func findNote(t *testing.T, service *storage.Service, title string) *models.Note {
+	t.Helper()
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	for _, note := range notes {
+		if note.Title == title {
+			return note
+		}
+	}
+	return nil
+}
+
+func TestGitSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create remote: %v: %s", err, out)
+	}
+
+	laptop, laptopSync := newMachine(t, filepath.Join(dir, "laptop"), remote)
+	desktop, desktopSync := newMachine(t, filepath.Join(dir, "desktop"), remote)
+
+	// A note written on the laptop reaches the desktop with its tags
+	plan, err := laptop.CreateNote("Weekly plan", "- ship sync")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := laptop.AddTagToNote(plan.ID, "work"); err != nil {
+		t.Fatalf("Failed to tag note: %v", err)
+	}
+	if _, err := laptopSync.CommitNotes("Update Weekly plan"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	result, err := Run(laptopSync)
+	if err != nil || !result.Pushed {
+		t.Fatalf("Failed to sync laptop: %v (%+v)", err, result)
+	}
+
+	if result, err = Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if result.Imported.Created != 1 {
+		t.Errorf("Expected 1 imported note, got %+v", result.Imported)
+	}
+	synced := findNote(t, desktop, "Weekly plan")
+	if synced == nil || synced.Content != "- ship sync" {
+		t.Fatalf("Expected the note on the desktop, got %+v", synced)
+	}
+	if len(synced.Tags) != 1 || synced.Tags[0].Name != "work" {
+		t.Errorf("Expected the work tag on the desktop, got %+v", synced.Tags)
+	}
+
+	// Syncing again with nothing changed commits nothing
+	if result, err = Run(laptopSync); err != nil || result.Committed {
+		t.Errorf("Expected a no-op sync, got %+v (%v)", result, err)
+	}
+
+	// Both sides edit the same line: the sync stops with a conflict
+	plan.Content = "- ship sync on Monday"
+	if err := laptop.UpdateNote(plan); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	synced.Content = "- ship sync on Friday"
+	if err := desktop.UpdateNote(synced); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if result, err = Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v", result.Conflicts)
+	}
+	conflict := result.Conflicts[0]
+	if !strings.Contains(conflict.Local, "Friday") || !strings.Contains(conflict.Remote, "Monday") {
+		t.Errorf("Expected both versions in the conflict, got %+v", conflict)
+	}
+
+	// Saving during the merge leaves the conflicted file alone
+	if committed, err := desktopSync.CommitNotes("Update Weekly plan"); err != nil || committed {
+		t.Errorf("Expected no commit while merging, got %v (%v)", committed, err)
+	}
+
+	if result, err = desktopSync.Resolve(conflict, KeepBoth); err != nil || len(result.Conflicts) != 0 {
+		t.Fatalf("Failed to resolve: %v (%+v)", err, result)
+	}
+	if result, err = desktopSync.Push(); err != nil || !result.Pushed {
+		t.Fatalf("Failed to push: %v (%+v)", err, result)
+	}
+	merged := findNote(t, desktop, "Weekly plan")
+	if merged == nil || !strings.Contains(merged.Content, "Friday") || !strings.Contains(merged.Content, "Monday") {
+		t.Errorf("Expected both versions kept, got %+v", merged)
+	}
+
+	// A note deleted on the desktop moves to the trash on the laptop
+	if err := desktop.DeleteNote(merged.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if _, err := Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	if note := findNote(t, laptop, "Weekly plan"); note == nil || note.Notebook != models.TrashNotebook {
+		t.Errorf("Expected the note trashed on the laptop, got %+v", note)
+	}
+
+	// Of two notes sharing a title, only the one deleted is trashed
+	first, err := laptop.CreateNote("Ideas", "- first")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	second, err := laptop.CreateNote("Ideas", "- second")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	if _, err := Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	for _, note := range []*models.Note{first, second} {
+		if _, err := desktop.GetNoteByUUID(note.UUID); err != nil {
+			t.Fatalf("Failed to get synced note: %v", err)
+		}
+	}
+	deleted, err := desktop.GetNoteByUUID(first.UUID)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+	if err := desktop.DeleteNote(deleted.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if _, err := Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	for _, tc := range []struct {
+		note    *models.Note
+		trashed bool
+	}{{first, true}, {second, false}} {
+		note, err := laptop.GetNoteByUUID(tc.note.UUID)
+		if err != nil {
+			t.Fatalf("Failed to get note: %v", err)
+		}
+		if (note.Notebook == models.TrashNotebook) != tc.trashed {
+			t.Errorf("Expected %q trashed: %v, got notebook %q", note.Content, tc.trashed, note.Notebook)
+		}
+	}
+}
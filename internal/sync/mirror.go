@@ -0,0 +1,375 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+// ErrRemoteChanged is returned when a note file changed in the collection
+// while it was being pushed; syncing again pulls the change first
+var ErrRemoteChanged = errors.New("a note file changed during the sync")
+
+// ErrAllMissing is returned by a pull that would trash every mirrored note,
+// as when the collection's directory is unmounted or emptied. Nothing is
+// applied.
+var ErrAllMissing = errors.New("every note file is missing")
+
+// contentHash returns the revision hash of a note file
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// remoteFile is a note file as read from the collection
+type remoteFile struct {
+	content string
+	hash    string
+	etag    string
+	deleted bool
+}
+
+// fileStore is a flat collection of markdown files a Mirror syncs with.
+// Every file has an ETag that changes whenever the file does.
+type fileStore interface {
+	// location identifies the collection, without credentials
+	location() string
+	// list returns the ETag of every markdown file in the collection. When
+	// the collection doesn't exist yet it's created and created is true.
+	list() (files map[string]string, created bool, err error)
+	// get returns the content of a file and its ETag
+	get(name string) (string, string, error)
+	// put writes a file and returns its new ETag, which may be empty. The
+	// write only succeeds if the file still has etag, or doesn't exist when
+	// exists is false.
+	put(name, content string, exists bool, etag string) (string, error)
+	// remove deletes a file unless it changed since it had etag. A file
+	// that's already gone isn't an error.
+	remove(name, etag string) error
+}
+
+// Mirror syncs notes with a collection of files, one markdown file per
+// note. The hash and ETag of every file at the last sync are kept in
+// storage, so a file counts as changed on a side when its hash or ETag
+// differs there. A file deleted from the collection moves its note to the
+// trash notebook, whose notes aren't mirrored.
+type Mirror struct {
+	mu      gosync.Mutex
+	store   fileStore
+	service *storage.Service
+
+	// Conflicts found by the last pull and the remote files behind them
+	pending map[string]pendingConflict
+}
+
+// pendingConflict is a conflict waiting to be resolved
+type pendingConflict struct {
+	conflict Conflict
+	remote   remoteFile
+}
+
+// newMirror creates a provider for the files in store
+func newMirror(service *storage.Service, store fileStore) *Mirror {
+	return &Mirror{store: store, service: service, pending: map[string]pendingConflict{}}
+}
+
+// Pull reads the files changed in the collection since the last sync and
+// applies them to storage
+func (w *Mirror) Pull() (Result, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var result Result
+	if w.service.EncryptionEnabled() {
+		return result, ErrEncrypted
+	}
+
+	remote, created, err := w.store.list()
+	if err != nil {
+		return result, err
+	}
+	revisions, err := w.revisions()
+	if err != nil {
+		return result, err
+	}
+	if created {
+		// The server lost the collection; upload every note again rather
+		// than taking the missing files as deleted
+		for name := range revisions {
+			if err := w.forget(name); err != nil {
+				return result, err
+			}
+		}
+		revisions = map[string]*models.SyncRevision{}
+	}
+	local, err := localFiles(w.service)
+	if err != nil {
+		return result, err
+	}
+
+	w.pending = map[string]pendingConflict{}
+	incoming := map[string]remoteFile{}
+
+	for name, etag := range remote {
+		rev := revisions[name]
+		if rev != nil && etag != "" && etag == rev.ETag {
+			continue
+		}
+
+		content, getETag, err := w.store.get(name)
+		if err != nil {
+			return result, err
+		}
+		if getETag != "" {
+			etag = getETag
+		}
+		file := remoteFile{content: content, hash: contentHash(content), etag: etag}
+
+		if rev != nil && file.hash == rev.Hash {
+			// Only the ETag changed, e.g. the file was rewritten as it was
+			if err := w.record(name, file); err != nil {
+				return result, err
+			}
+			continue
+		}
+		incoming[name] = file
+	}
+
+	missing := 0
+	for name, rev := range revisions {
+		if _, ok := remote[name]; !ok {
+			incoming[name] = remoteFile{deleted: true, hash: rev.Hash}
+			if local[name] != nil {
+				missing++
+			}
+		}
+	}
+	if missing > 1 && missing == len(local) {
+		// Far more likely a directory that's unmounted or was emptied, or
+		// a checkout in it, than every note deleted on purpose
+		return result, fmt.Errorf("%w from %s; not moving all %d notes to the trash", ErrAllMissing, w.store.location(), missing)
+	}
+
+	imports := map[string]string{}
+	for name, file := range incoming {
+		note := local[name]
+		localContent := ""
+		if note != nil {
+			localContent = noteMarkdown(note)
+		}
+		rev := revisions[name]
+		localChanged := rev == nil || contentHash(localContent) != rev.Hash
+		if note == nil {
+			// Deleted locally, unless it never existed here
+			localChanged = rev != nil
+		}
+
+		switch {
+		case file.deleted && note == nil:
+			// Deleted on both sides
+			if err := w.forget(name); err != nil {
+				return result, err
+			}
+		case !file.deleted && note != nil && localContent == file.content:
+			// The same change was made on both sides
+			if err := w.record(name, file); err != nil {
+				return result, err
+			}
+		case localChanged:
+			w.pending[name] = pendingConflict{
+				conflict: Conflict{
+					Path:          name,
+					Local:         localContent,
+					Remote:        file.content,
+					LocalDeleted:  note == nil,
+					RemoteDeleted: file.deleted,
+				},
+				remote: file,
+			}
+		case file.deleted:
+			if err := w.service.MoveNotesToNotebook([]int{note.ID}, models.TrashNotebook); err != nil {
+				return result, err
+			}
+			if err := w.forget(name); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		default:
+			imports[name] = file.content
+		}
+	}
+
+	if result.Imported, err = importFiles(w.service, imports); err != nil {
+		return result, err
+	}
+	for name := range imports {
+		if err := w.record(name, incoming[name]); err != nil {
+			return result, err
+		}
+	}
+	result.Conflicts = w.conflicts()
+	return result, nil
+}
+
+// revisions returns the last synced revision of every file in the
+// collection, keyed by file name. Revisions are stored under the file's
+// location so pointing the provider at another collection starts afresh
+// instead of taking every file as deleted.
+func (w *Mirror) revisions() (map[string]*models.SyncRevision, error) {
+	prefix := w.store.location()
+	stored, err := w.service.GetSyncRevisions(prefix)
+	if err != nil {
+		return nil, err
+	}
+	revisions := map[string]*models.SyncRevision{}
+	for path, rev := range stored {
+		revisions[strings.TrimPrefix(path, prefix)] = rev
+	}
+	return revisions, nil
+}
+
+// record stores a version of a file as its last synced revision
+func (w *Mirror) record(name string, file remoteFile) error {
+	return w.service.SetSyncRevision(&models.SyncRevision{
+		Path: w.store.location() + name,
+		Hash: file.hash,
+		ETag: file.etag,
+	})
+}
+
+// forget drops the revision of a file that's gone from the collection
+func (w *Mirror) forget(name string) error {
+	return w.service.DeleteSyncRevision(w.store.location() + name)
+}
+
+// Push writes the note files changed locally since the last sync and
+// deletes the files of notes deleted or renamed locally
+func (w *Mirror) Push() (Result, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var result Result
+	if w.service.EncryptionEnabled() {
+		return result, ErrEncrypted
+	}
+
+	revisions, err := w.revisions()
+	if err != nil {
+		return result, err
+	}
+	local, err := localFiles(w.service)
+	if err != nil {
+		return result, err
+	}
+
+	for name, note := range local {
+		content := noteMarkdown(note)
+		hash := contentHash(content)
+		rev := revisions[name]
+		if rev != nil && rev.Hash == hash {
+			continue
+		}
+
+		etag := ""
+		if rev != nil {
+			etag = rev.ETag
+		}
+		newETag, err := w.store.put(name, content, rev != nil, etag)
+		if err != nil {
+			return result, err
+		}
+		if err := w.record(name, remoteFile{hash: hash, etag: newETag}); err != nil {
+			return result, err
+		}
+		result.Uploaded++
+	}
+
+	for name, rev := range revisions {
+		if local[name] != nil {
+			continue
+		}
+		if err := w.store.remove(name, rev.ETag); err != nil {
+			return result, err
+		}
+		if err := w.forget(name); err != nil {
+			return result, err
+		}
+		result.Uploaded++
+	}
+
+	result.Pushed = true
+	return result, nil
+}
+
+// Resolve settles one conflict. The remote version becomes the last synced
+// revision, so a kept local version is written by the next push.
+func (w *Mirror) Resolve(conflict Conflict, choice Choice) (Result, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var result Result
+	pending, ok := w.pending[conflict.Path]
+	if !ok {
+		result.Conflicts = w.conflicts()
+		return result, fmt.Errorf("no conflict pending for %s", conflict.Path)
+	}
+	file := pending.remote
+
+	switch {
+	case choice == KeepRemote && file.deleted:
+		files, err := localFiles(w.service)
+		if err != nil {
+			return result, err
+		}
+		if note := files[conflict.Path]; note != nil {
+			if err := w.service.MoveNotesToNotebook([]int{note.ID}, models.TrashNotebook); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		}
+	case choice == KeepRemote:
+		imported, err := importFiles(w.service, map[string]string{conflict.Path: file.content})
+		if err != nil {
+			return result, err
+		}
+		result.Imported = imported
+	case choice == KeepBoth:
+		imported, err := importFiles(w.service, map[string]string{conflict.Path: mergeBoth(conflict)})
+		if err != nil {
+			return result, err
+		}
+		result.Imported = imported
+	}
+
+	var err error
+	if file.deleted {
+		// Whatever was kept locally is written as a new file
+		err = w.forget(conflict.Path)
+	} else {
+		err = w.record(conflict.Path, file)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	delete(w.pending, conflict.Path)
+	result.Conflicts = w.conflicts()
+	return result, nil
+}
+
+// conflicts returns the conflicts still pending, ordered by path
+func (w *Mirror) conflicts() []Conflict {
+	var conflicts []Conflict
+	for _, pending := range w.pending {
+		conflicts = append(conflicts, pending.conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts
+}
@@ -0,0 +1,180 @@
+// Package sync keeps notes in step with a remote copy so they can be
+// shared between machines. Notes travel as one markdown file per note,
+// through a git repository or a WebDAV server, or mirrored into a
+// directory that other programs edit.
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/importer"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+)
+
+// ErrEncrypted is returned when syncing encrypted notes, which would be
+// written to the remote in plain text
+var ErrEncrypted = errors.New("sync isn't available for encrypted notes")
+
+// Conflict is a note file both sides changed since the last sync
+type Conflict struct {
+	Path          string // file name relative to the synced directory
+	Local         string
+	Remote        string
+	LocalDeleted  bool
+	RemoteDeleted bool
+}
+
+// Choice picks the version that resolves a conflict
+type Choice int
+
+const (
+	KeepLocal Choice = iota
+	KeepRemote
+	KeepBoth // the local note followed by the body of the remote one
+)
+
+// Result describes what a sync did
+type Result struct {
+	Imported  importer.Result
+	Deleted   int  // notes deleted, or moved to the trash, because their file was deleted remotely
+	Committed bool // local changes were committed to the git repository
+	Uploaded  int  // note files written to or deleted from a WebDAV server or directory
+	Pushed    bool
+	Conflicts []Conflict // non-empty when the sync stopped to resolve conflicts
+}
+
+// Provider is a remote that notes are synced with
+type Provider interface {
+	// Pull applies the changes made remotely since the last sync to
+	// storage. Notes changed on both sides are left alone and returned as
+	// conflicts.
+	Pull() (Result, error)
+
+	// Push sends local changes to the remote. Only call it once every
+	// conflict is resolved.
+	Push() (Result, error)
+
+	// Resolve settles one conflict with the chosen version and returns
+	// the conflicts left
+	Resolve(conflict Conflict, choice Choice) (Result, error)
+}
+
+// Committer is implemented by providers that record each saved note
+// locally before it's pushed
+type Committer interface {
+	CommitNotes(message string) (bool, error)
+}
+
+// Run pulls and, unless that stopped at conflicts, pushes
+func Run(provider Provider) (Result, error) {
+	result, err := provider.Pull()
+	if err != nil || len(result.Conflicts) > 0 {
+		return result, err
+	}
+
+	pushed, err := provider.Push()
+	result.Committed = result.Committed || pushed.Committed
+	result.Uploaded += pushed.Uploaded
+	result.Pushed = pushed.Pushed
+	return result, err
+}
+
+// noteFiles names the file of every note after its slug. Notes sharing a
+// slug get numeric suffixes in ID order.
+func noteFiles(notes []*models.Note) map[string]*models.Note {
+	sorted := append([]*models.Note(nil), notes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	files := map[string]*models.Note{}
+	for _, note := range sorted {
+		stem := utils.Slugify(note.Title)
+		name := stem + ".md"
+		for i := 2; files[name] != nil; i++ {
+			name = fmt.Sprintf("%s-%d.md", stem, i)
+		}
+		files[name] = note
+	}
+	return files
+}
+
+// localFiles loads the notes that are synced, every note but those in the
+// trash, and names their files
+func localFiles(service *storage.Service) (map[string]*models.Note, error) {
+	notes, err := service.GetAllNotes(models.NoteFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	var synced []*models.Note
+	for _, note := range notes {
+		if note.Notebook != models.TrashNotebook {
+			synced = append(synced, note)
+		}
+	}
+	return noteFiles(synced), nil
+}
+
+// noteMarkdown renders a note for syncing. Unlike a plain export it
+// leaves out note and tag IDs, which differ between machines, so the
+// importer matches synced files by UUID, or by slug for files synced
+// before notes had UUIDs. The update time is left out too;
+// importing a change sets it anew on every machine, which would otherwise
+// be synced back and forth.
+func noteMarkdown(note *models.Note) string {
+	var fm utils.Frontmatter
+	fm.Set("uuid", note.UUID)
+	fm.Set("slug", utils.Slugify(note.Title))
+	fm.Set("title", note.Title)
+	if note.Notebook != "" {
+		fm.Set("notebook", note.Notebook)
+	}
+	fm.Set("created", note.CreatedAt.Format(time.RFC3339))
+
+	tags := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		tags[i] = tag.Name
+	}
+	fm.SetList("tags", tags)
+
+	return fm.String() + note.Content
+}
+
+// importFiles imports note files held in memory, keyed by file name
+func importFiles(service *storage.Service, files map[string]string) (importer.Result, error) {
+	if len(files) == 0 {
+		return importer.Result{}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "tuinotes-sync-*")
+	if err != nil {
+		return importer.Result{}, fmt.Errorf("failed to create import directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return importer.Result{}, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return importer.MarkdownDir(service, dir)
+}
+
+// mergeBoth keeps the local file and appends the remote note's body under
+// a rule, so nothing written on either side is lost
+func mergeBoth(conflict Conflict) string {
+	if conflict.LocalDeleted {
+		return conflict.Remote
+	}
+	if conflict.RemoteDeleted {
+		return conflict.Local
+	}
+	_, remoteBody, _ := utils.ParseFrontmatter(conflict.Remote)
+	return strings.TrimRight(conflict.Local, "\n") + "\n\n---\n\n" + remoteBody
+}
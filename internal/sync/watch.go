@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long the files must stay untouched before a change is
+// reported, so an editor saving a file in several steps causes one sync
+const watchSettle = 300 * time.Millisecond
+
+// Watcher reports changes to the markdown files in a directory
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	changes chan struct{}
+	trigger chan struct{}
+	done    chan struct{}
+}
+
+// Watch starts watching dir, creating it if missing
+func Watch(dir string) (*Watcher, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	w := &Watcher{
+		watcher: watcher,
+		changes: make(chan struct{}, 1),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Changes receives a value once the markdown files in the directory have
+// changed and settled. Changes that happen before the value is received
+// are reported together. The channel is closed when the watcher is.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Trigger reports a change as if a file had changed, e.g. so a note saved
+// in the app is written to the directory by the same sync
+func (w *Watcher) Trigger() {
+	select {
+	case w.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+// run waits for the files to settle after each change and reports it
+func (w *Watcher) run() {
+	defer close(w.done)
+	defer close(w.changes)
+
+	settle := time.NewTimer(watchSettle)
+	settle.Stop()
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Base(event.Name)
+			if strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), ".md") || event.Op == fsnotify.Chmod {
+				continue
+			}
+			settle.Reset(watchSettle)
+		case <-w.trigger:
+			settle.Reset(watchSettle)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been dropped, so sync in case they mattered
+			slog.Warn("failed to watch notes directory", "err", err)
+			settle.Reset(watchSettle)
+		case <-settle.C:
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
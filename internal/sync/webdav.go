@@ -0,0 +1,228 @@
+package sync
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/storage"
+)
+
+// davClient talks to one collection on a WebDAV server
+type davClient struct {
+	base     *url.URL // collection URL, ending in a slash
+	user     string
+	password string
+	http     *http.Client
+}
+
+// newDavClient creates a client for the collection at rawURL
+func newDavClient(rawURL, user, password string) (*davClient, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid WebDAV URL %q", rawURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	return &davClient{
+		base:     base,
+		user:     user,
+		password: password,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// do sends a request for a file in the collection, or the collection
+// itself when name is empty
+func (c *davClient) do(method, name string, body io.Reader, header map[string]string) (*http.Response, error) {
+	target := c.base.JoinPath(name)
+	req, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	if c.user != "" || c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, target.Redacted(), err)
+	}
+	return resp, nil
+}
+
+// location returns the collection URL without credentials
+func (c *davClient) location() string {
+	location := *c.base
+	location.User = nil
+	return location.String()
+}
+
+// statusError describes an unexpected response
+func statusError(method, name string, resp *http.Response) error {
+	return fmt.Errorf("failed to %s %s: server returned %s", method, name, resp.Status)
+}
+
+// multistatus is the body of a PROPFIND response
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Prop struct {
+				ETag         string `xml:"DAV: getetag"`
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/><d:resourcetype/></d:prop></d:propfind>`
+
+// list returns the ETag of every markdown file in the collection. When the
+// collection doesn't exist yet it's created and created is true.
+func (c *davClient) list() (files map[string]string, created bool, err error) {
+	resp, err := c.do("PROPFIND", "", strings.NewReader(propfindBody), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		mkcol, err := c.do("MKCOL", "", nil, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		mkcol.Body.Close()
+		if mkcol.StatusCode != http.StatusCreated {
+			return nil, false, statusError("MKCOL", c.base.Path, mkcol)
+		}
+		return map[string]string{}, true, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, false, statusError("PROPFIND", c.base.Path, resp)
+	}
+
+	var status multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, false, fmt.Errorf("failed to parse PROPFIND response: %w", err)
+	}
+
+	files = map[string]string{}
+	for _, r := range status.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		name := path.Base(href.Path)
+		if strings.HasSuffix(href.Path, "/") || !strings.EqualFold(path.Ext(name), ".md") {
+			continue
+		}
+		files[name] = ""
+		for _, propstat := range r.Propstat {
+			if propstat.Prop.ResourceType.Collection != nil {
+				delete(files, name)
+				break
+			}
+			if propstat.Prop.ETag != "" {
+				files[name] = propstat.Prop.ETag
+			}
+		}
+	}
+	return files, false, nil
+}
+
+// get downloads a file and its ETag
+func (c *davClient) get(name string) (string, string, error) {
+	resp, err := c.do(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", statusError("GET", name, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return string(data), resp.Header.Get("ETag"), nil
+}
+
+// put uploads a file and returns its new ETag, which is empty when the
+// server doesn't send one. The upload only succeeds if the file still has
+// etag, or doesn't exist when exists is false.
+func (c *davClient) put(name, content string, exists bool, etag string) (string, error) {
+	header := map[string]string{"Content-Type": "text/markdown; charset=utf-8"}
+	switch {
+	case !exists:
+		header["If-None-Match"] = "*"
+	case etag != "":
+		header["If-Match"] = etag
+	}
+
+	resp, err := c.do(http.MethodPut, name, strings.NewReader(content), header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return resp.Header.Get("ETag"), nil
+	case http.StatusPreconditionFailed:
+		return "", ErrRemoteChanged
+	default:
+		return "", statusError("PUT", name, resp)
+	}
+}
+
+// remove deletes a file unless it changed since it had etag. A file that's
+// already gone isn't an error.
+func (c *davClient) remove(name, etag string) error {
+	var header map[string]string
+	if etag != "" {
+		header = map[string]string{"If-Match": etag}
+	}
+
+	resp, err := c.do(http.MethodDelete, name, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrRemoteChanged
+	default:
+		return statusError("DELETE", name, resp)
+	}
+}
+
+// NewWebDAV creates a provider that syncs with the collection at rawURL on
+// a WebDAV server
+func NewWebDAV(service *storage.Service, rawURL, user, password string) (*Mirror, error) {
+	client, err := newDavClient(rawURL, user, password)
+	if err != nil {
+		return nil, err
+	}
+	return newMirror(service, client), nil
+}
@@ -0,0 +1,202 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"markdown-note-taking-app/internal/importer"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+)
+
+// davServer is a minimal WebDAV server holding one collection in memory
+type davServer struct {
+	mu      gosync.Mutex
+	files   map[string]string
+	version int
+	etags   map[string]string
+	created bool
+}
+
+func (s *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := path.Base(r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") {
+		name = ""
+	}
+	etag, exists := s.etags[name]
+
+	switch r.Method {
+	case "MKCOL":
+		s.created = true
+		w.WriteHeader(http.StatusCreated)
+	case "PROPFIND":
+		if !s.created {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+		fmt.Fprint(w, `<d:response><d:href>/notes/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop></d:propstat></d:response>`)
+		for file, tag := range s.etags {
+			fmt.Fprintf(w, `<d:response><d:href>/notes/%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag><d:resourcetype/></d:prop></d:propstat></d:response>`, file, tag)
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, s.files[name])
+	case http.MethodPut:
+		if match := r.Header.Get("If-Match"); match != "" && match != etag ||
+			r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("ETag", s.write(name, string(data)))
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if match := r.Header.Get("If-Match"); match != "" && match != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(s.files, name)
+		delete(s.etags, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// write stores a file and returns its new ETag
+func (s *davServer) write(name, content string) string {
+	s.version++
+	s.files[name] = content
+	s.etags[name] = fmt.Sprintf(`"v%d"`, s.version)
+	return s.etags[name]
+}
+
+// newDavMachine creates a service and WebDAV provider in dir that sync
+// through the server at url
+func newDavMachine(t *testing.T, dir, url string) (*storage.Service, *Mirror) {
+	t.Helper()
+	service, err := storage.NewService(filepath.Join(dir, "notes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	provider, err := NewWebDAV(service, url, "me", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return service, provider
+}
+
+func TestWebDAVSync(t *testing.T) {
+	dav := &davServer{files: map[string]string{}, etags: map[string]string{}}
+	server := httptest.NewServer(dav)
+	defer server.Close()
+
+	dir := t.TempDir()
+	laptop, laptopSync := newDavMachine(t, filepath.Join(dir, "laptop"), server.URL+"/notes")
+	desktop, desktopSync := newDavMachine(t, filepath.Join(dir, "desktop"), server.URL+"/notes")
+
+	// The first sync creates the collection and uploads the laptop's note
+	plan, err := laptop.CreateNote("Weekly plan", "- ship sync")
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	result, err := Run(laptopSync)
+	if err != nil || result.Uploaded != 1 || !dav.created {
+		t.Fatalf("Failed to sync laptop: %v (%+v)", err, result)
+	}
+
+	if result, err = Run(desktopSync); err != nil || result.Imported.Created != 1 || result.Uploaded != 0 {
+		t.Fatalf("Failed to sync desktop: %v (%+v)", err, result)
+	}
+	synced := findNote(t, desktop, "Weekly plan")
+	if synced == nil || synced.Content != "- ship sync" {
+		t.Fatalf("Expected the note on the desktop, got %+v", synced)
+	}
+
+	// Nothing changed, so nothing is downloaded or uploaded
+	if result, err = Run(laptopSync); err != nil || result.Uploaded != 0 || result.Imported != (importer.Result{}) {
+		t.Errorf("Expected a no-op sync, got %+v (%v)", result, err)
+	}
+
+	// A change on one side is pulled by the other
+	synced.Content = "- ship sync\n- write docs"
+	if err := desktop.UpdateNote(synced); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if _, err := Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if result, err = Run(laptopSync); err != nil || result.Imported.Updated != 1 {
+		t.Fatalf("Failed to pull the change: %v (%+v)", err, result)
+	}
+	if note := findNote(t, laptop, "Weekly plan"); note == nil || !strings.Contains(note.Content, "write docs") {
+		t.Errorf("Expected the desktop's change on the laptop, got %+v", note)
+	}
+
+	// Both sides change the note: the revision hashes reveal a conflict
+	plan = findNote(t, laptop, "Weekly plan")
+	plan.Content = "- ship on Monday"
+	if err := laptop.UpdateNote(plan); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	synced = findNote(t, desktop, "Weekly plan")
+	synced.Content = "- ship on Friday"
+	if err := desktop.UpdateNote(synced); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+	if result, err = Run(desktopSync); err != nil || len(result.Conflicts) != 1 || result.Pushed {
+		t.Fatalf("Expected 1 conflict and no push, got %+v (%v)", result, err)
+	}
+	conflict := result.Conflicts[0]
+	if !strings.Contains(conflict.Local, "Friday") || !strings.Contains(conflict.Remote, "Monday") {
+		t.Errorf("Expected both versions in the conflict, got %+v", conflict)
+	}
+
+	// Keeping the local version uploads it over the remote one
+	if result, err = desktopSync.Resolve(conflict, KeepLocal); err != nil || len(result.Conflicts) != 0 {
+		t.Fatalf("Failed to resolve: %v (%+v)", err, result)
+	}
+	if result, err = Run(desktopSync); err != nil || result.Uploaded != 1 {
+		t.Fatalf("Failed to push the resolution: %v (%+v)", err, result)
+	}
+	if !strings.Contains(dav.files["weekly-plan.md"], "Friday") {
+		t.Errorf("Expected the local version on the server, got %q", dav.files["weekly-plan.md"])
+	}
+
+	// A note deleted on the desktop is moved to the trash on the laptop
+	if err := desktop.DeleteNote(synced.ID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	if _, err := Run(desktopSync); err != nil {
+		t.Fatalf("Failed to sync desktop: %v", err)
+	}
+	if _, err := Run(laptopSync); err != nil {
+		t.Fatalf("Failed to sync laptop: %v", err)
+	}
+	if note := findNote(t, laptop, "Weekly plan"); note == nil || note.Notebook != models.TrashNotebook {
+		t.Errorf("Expected the note in the trash on the laptop, got %+v", note)
+	}
+	if len(dav.files) != 0 {
+		t.Errorf("Expected no files left on the server, got %v", dav.files)
+	}
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"markdown-note-taking-app/internal/export"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// noteAction is one entry of the action menu
+type noteAction struct {
+	key   string // shortcut inside the menu
+	label string
+	run   func(m *NotesListModel, note *models.Note) tea.Cmd
+}
+
+// noteActions lists everything that can be done to a single note. The
+// action menu is built from it, so add new note actions here.
+var noteActions = []noteAction{
+	{"e", "Edit", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		m.selectedNote = note
+		return m.app.SwitchToView(ViewNoteEditor)
+	}},
+	{"p", "Preview", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		m.peek.visible = true
+		return nil
+	}},
+	{"y", "Duplicate", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.duplicateNote(note)
+	}},
+	{"t", "Edit tags", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.openTagEditor()
+	}},
+	{"P", "Pin / unpin", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.togglePin(note)
+	}},
+	{"m", "Move to notebook", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.startNoteAction(bulkMove, note)
+	}},
+	{"x", "Export", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.startNoteAction(bulkExport, note)
+	}},
+	{"h", "Export as HTML", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.exportHTML(note)
+	}},
+	{"s", "Split at headings", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.splitNote(note, false)
+	}},
+	{"S", "Split, linking back", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.splitNote(note, true)
+	}},
+	{"d", "Delete", func(m *NotesListModel, note *models.Note) tea.Cmd {
+		return m.startNoteAction(bulkDelete, note)
+	}},
+}
+
+// actionMenu is the popup listing the actions for the note under the cursor
+type actionMenu struct {
+	active bool
+	cursor int
+	note   *models.Note
+}
+
+// openActionMenu shows the action menu for the note under the cursor
+func (m *NotesListModel) openActionMenu() {
+	if len(m.filteredNotes) == 0 {
+		return
+	}
+	m.statusMsg = ""
+	m.menu = actionMenu{active: true, note: m.filteredNotes[m.cursor]}
+}
+
+// handleMenuKey handles keys while the action menu is open
+func (m *NotesListModel) handleMenuKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q":
+		m.menu.active = false
+	case "up", "k":
+		m.menu.cursor = max(m.menu.cursor-1, 0)
+	case "down", "j":
+		m.menu.cursor = min(m.menu.cursor+1, len(noteActions)-1)
+	case "enter":
+		return m.runMenuAction(noteActions[m.menu.cursor])
+	default:
+		for _, action := range noteActions {
+			if msg.String() == action.key {
+				return m.runMenuAction(action)
+			}
+		}
+	}
+	return nil
+}
+
+// runMenuAction closes the menu and runs action on its note
+func (m *NotesListModel) runMenuAction(action noteAction) tea.Cmd {
+	m.menu.active = false
+	return action.run(m, m.menu.note)
+}
+
+// startNoteAction opens a bulk action prompt that applies to note alone,
+// leaving the selection untouched
+func (m *NotesListModel) startNoteAction(action bulkAction, note *models.Note) tea.Cmd {
+	cmd := m.startBulkAction(action)
+	m.bulkTarget = note
+	return cmd
+}
+
+// duplicateNote copies note with its notebook and tags
+func (m *NotesListModel) duplicateNote(note *models.Note) tea.Cmd {
+	return func() tea.Msg {
+		copied, err := m.app.GetStorage().DuplicateNote(note.ID)
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		return bulkDoneMsg{status: fmt.Sprintf("Created %q", copied.Title)}
+	}
+}
+
+// splitNote splits note into a note per level 1 or 2 heading, which can be
+// undone by deleting them and restoring the note
+func (m *NotesListModel) splitNote(note *models.Note, linkBack bool) tea.Cmd {
+	return func() tea.Msg {
+		// Keep the note as stored, content and all, for undoing
+		original, err := m.app.GetStorage().GetNote(note.ID)
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		parts, err := m.app.GetStorage().SplitNote(note.ID, linkBack)
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+
+		undo := func(s *storage.Service) error {
+			for _, part := range parts {
+				if err := s.DeleteNote(part.ID); err != nil {
+					return err
+				}
+			}
+			return s.UpdateNote(original)
+		}
+		return bulkDoneMsg{status: fmt.Sprintf("Split %q into %d notes", note.Title, len(parts)), undo: undo}
+	}
+}
+
+// exportHTML writes the note of a summary as a themed HTML page to the
+// default export directory
+func (m *NotesListModel) exportHTML(summary *models.Note) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := m.app.GetStorage().LoadContent([]*models.Note{summary})
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		note := notes[0]
+		dir, err := export.ExpandHome(defaultExportDir)
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return bulkDoneMsg{err: fmt.Errorf("failed to create export directory: %w", err)}
+		}
+		name := utils.Slugify(note.Title) + ".html"
+		cfg := m.app.GetConfig()
+		opts := export.HTMLOptions{
+			Theme:      cfg.HTMLTheme,
+			Stylesheet: cfg.HTMLStylesheet,
+			Autolinks:  cfg.AutolinkRules(),
+		}
+		if err := export.HTMLFile(note, filepath.Join(dir, name), opts); err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		return bulkDoneMsg{status: fmt.Sprintf("Exported %q to %s/%s", note.Title, defaultExportDir, name)}
+	}
+}
+
+// renderActionMenu renders the action menu as a bordered popup
+func (m *NotesListModel) renderActionMenu() string {
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Render(ansi.Truncate(m.menu.note.Title, 28, "…"))
+
+	lines := []string{title, ""}
+	for i, action := range noteActions {
+		label := fmt.Sprintf(" %-18s", action.label)
+		if i == m.menu.cursor {
+			label = selectedStyle.Render(label)
+		} else {
+			label = labelStyle.Render(label)
+		}
+		lines = append(lines, keyStyle.Render(action.key)+" "+label)
+	}
+	lines = append(lines, "", lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#64748B")).
+		Render("enter: run • esc: close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Background(lipgloss.Color("#0F172A")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// overlay draws popup over the middle of background, which is width
+// columns wide
+func overlay(background, popup string, width int) string {
+	bgLines := strings.Split(background, "\n")
+	popupLines := strings.Split(popup, "\n")
+	popupWidth := lipgloss.Width(popup)
+
+	x := max((width-popupWidth)/2, 0)
+	y := max((len(bgLines)-len(popupLines))/2, 0)
+	for i, line := range popupLines {
+		if y+i >= len(bgLines) {
+			break
+		}
+		bg := bgLines[y+i]
+		left := ansi.Truncate(bg, x, "")
+		left += strings.Repeat(" ", max(x-ansi.StringWidth(left), 0))
+		right := ansi.TruncateLeft(bg, x+popupWidth, "")
+		bgLines[y+i] = left + line + right
+	}
+	return strings.Join(bgLines, "\n")
+}
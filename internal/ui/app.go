@@ -2,8 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"log/slog"
+	"time"
 
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/instance"
+	"markdown-note-taking-app/internal/models"
 	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -15,74 +21,387 @@ const (
 	ViewNotesList View = iota
 	ViewNoteEditor
 	ViewHelp
+	ViewTasks
+	ViewStats
+	ViewUnlock
+	ViewCompare
+	ViewConflicts
+	ViewVaults
+	ViewSnippets
+	ViewTags
+	ViewCalendar
+	ViewDuplicates
 )
 
+// viewNames names the views in the log
+var viewNames = []string{"notes", "editor", "help", "tasks", "stats", "unlock", "compare", "conflicts", "vaults", "snippets", "tags", "calendar", "duplicates"}
+
+// String returns the name of the view
+func (v View) String() string {
+	if int(v) < len(viewNames) {
+		return viewNames[v]
+	}
+	return fmt.Sprintf("view %d", int(v))
+}
+
 // App represents the main application
 type App struct {
 	storage     *storage.Service
+	config      *config.Config
+	vault       config.Vault // the vault storage was opened from
 	currentView View
 	notesList   *NotesListModel
-	noteEditor  *NoteEditorModel
-	help        *HelpModel
 	width       int
 	height      int
+
+	// Secondary views are created lazily on first use so the notes list
+	// can render its first frame as quickly as possible
+	help  *HelpModel
+	tasks *TasksModel
+	stats *StatsModel
+
+	compare   *CompareModel
+	conflicts *ConflictModel
+	vaults    *VaultsModel
+
+	snippetManager *SnippetsModel
+	tagManager     *TagsModel
+	calendar       *CalendarModel
+	duplicates     *DuplicatesModel
+
+	// Remote notes are synced with, opened on first use, and the outcome
+	// of the last sync for the status bar
+	syncer    sync.Provider
+	syncState syncState
+	syncedAt  time.Time
+
+	// Directory the notes are mirrored into and watched, and whether a
+	// mirror is running or another is wanted once it finishes
+	mirror      *sync.Mirror
+	watcher     *sync.Watcher
+	mirroring   bool
+	mirrorAgain bool
+
+	// Open notes, each in its own editor tab
+	editors      []*NoteEditorModel
+	activeEditor int
+
+	crashed bool // a command panicked and the app quit
+
+	// The claim on the open vault's database that keeps a second instance
+	// out, and a note asked for on start
+	instance   *instance.Server
+	startTitle string
+
+	// Places visited before the current one, most recent last, and the
+	// places left by going back
+	backStack    []place
+	forwardStack []place
+
+	// Tags are streamed in after startup and shared with the editor,
+	// along with the few used most recently
+	tags       []*models.Tag
+	recentTags []*models.Tag
+
+	// Snippets the editor expands, loaded like tags
+	snippets []*models.Snippet
+
+	// Passphrase prompt for encrypted notes, the view to return to after
+	// unlocking and the time of the last input for the idle lock
+	unlockView *UnlockModel
+	lockedView View
+	lastInput  time.Time
 }
 
-// NewApp creates a new application instance
-func NewApp(dbPath string) (*App, error) {
+// NewApp creates a new application instance showing the notes in vault
+func NewApp(vault config.Vault, cfg *config.Config) (*App, error) {
 	// Initialize storage
-	storageService, err := storage.NewService(dbPath)
+	storageService, err := storage.NewService(vault.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
-	app := &App{
-		storage:     storageService,
-		currentView: ViewNotesList,
-	}
+	app := &App{config: cfg}
+	app.open(storageService, vault)
+	return app, nil
+}
 
-	// Initialize view models
-	app.notesList = NewNotesListModel(app)
-	app.noteEditor = NewNoteEditorModel(app)
-	app.help = NewHelpModel(app)
+// open shows the notes of a freshly opened storage service, starting over
+// with an empty notes list and no open tabs
+func (a *App) open(service *storage.Service, vault config.Vault) {
+	a.stopWatching()
+	a.storage = service
+	a.vault = vault
+	a.currentView = ViewNotesList
+	a.tags = []*models.Tag{}
+	a.recentTags = nil
+	a.snippets = nil
 
-	return app, nil
+	a.editors = nil
+	a.activeEditor = 0
+	a.backStack = nil
+	a.forwardStack = nil
+	a.syncer = nil
+	a.syncState = syncIdle
+	a.syncedAt = time.Time{}
+
+	// Views holding notes or stats of the previous vault are created again
+	a.tasks = nil
+	a.stats = nil
+	a.compare = nil
+	a.conflicts = nil
+	a.snippetManager = nil
+	a.tagManager = nil
+	a.calendar = nil
+	a.duplicates = nil
+
+	// Only the notes list is needed for the first frame
+	a.notesList = NewNotesListModel(a)
+	a.notesList.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+
+	// Encrypted notes can't be shown until the passphrase is entered
+	a.unlockView = NewUnlockModel(a)
+	a.unlockView.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	a.lastInput = time.Now()
+	if service.Locked() {
+		a.currentView = ViewUnlock
+		a.lockedView = ViewNotesList
+	}
 }
 
 // Close closes the application and cleans up resources
 func (a *App) Close() error {
+	a.stopWatching()
 	return a.storage.Close()
 }
 
-// Init initializes the application
+// Init initializes the application. Notes and tags load in the background
+// while the list renders a skeleton.
 func (a *App) Init() tea.Cmd {
-	return a.notesList.Init()
+	if a.storage.Locked() {
+		return guard(a.unlockView.Init())
+	}
+	return guard(tea.Batch(a.notesList.Init(), a.loadTags(), a.loadSnippets(), a.idleCheck(), a.openStartNote(), a.startWatching()))
 }
 
-// Update handles application-wide updates and view switching
+// loadTags loads all tags, ranked by usage, from storage in the background
+func (a *App) loadTags() tea.Cmd {
+	return func() tea.Msg {
+		return loadTagLists(a.storage)
+	}
+}
+
+// editor returns the editor of the active tab, opening a tab if none is open
+func (a *App) editor() *NoteEditorModel {
+	if len(a.editors) == 0 {
+		a.editors = []*NoteEditorModel{a.newEditor()}
+		a.activeEditor = 0
+	}
+	return a.editors[a.activeEditor]
+}
+
+// helpView returns the help view, creating it on first use
+func (a *App) helpView() *HelpModel {
+	if a.help == nil {
+		a.help = NewHelpModel(a)
+		a.help.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.help
+}
+
+// tasksView returns the task dashboard, creating it on first use
+func (a *App) tasksView() *TasksModel {
+	if a.tasks == nil {
+		a.tasks = NewTasksModel(a)
+		a.tasks.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.tasks
+}
+
+// statsView returns the stats view, creating it on first use
+func (a *App) statsView() *StatsModel {
+	if a.stats == nil {
+		a.stats = NewStatsModel(a)
+		a.stats.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.stats
+}
+
+// compareView returns the compare view, creating it on first use
+func (a *App) compareView() *CompareModel {
+	if a.compare == nil {
+		a.compare = NewCompareModel(a)
+		a.compare.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.compare
+}
+
+// openCompare shows two notes side by side
+func (a *App) openCompare(left, right *models.Note) tea.Cmd {
+	a.compareView().SetNotes(left, right)
+	return a.SwitchToView(ViewCompare)
+}
+
+// Update handles application-wide updates and view switching, logging
+// each switch. Panics are logged, and the commands returned are guarded
+// so a panic in one quits the app cleanly.
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer recoverPanic()
+	if _, ok := msg.(crashMsg); ok {
+		a.crashed = true
+		return a, tea.Quit
+	}
+
+	from := a.currentView
+	model, cmd := a.update(msg)
+	if a.currentView != from {
+		slog.Debug("switched view", "from", from, "to", a.currentView)
+	}
+	return model, guard(cmd)
+}
+
+// update handles a message for Update
+func (a *App) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
-		// Update all views with new dimensions
+		// Update all created views with new dimensions
 		a.notesList.Update(msg)
-		a.noteEditor.Update(msg)
-		a.help.Update(msg)
+		for _, editor := range a.editors {
+			editor.Update(a.editorSize())
+		}
+		if a.help != nil {
+			a.help.Update(msg)
+		}
+		if a.tasks != nil {
+			a.tasks.Update(msg)
+		}
+		if a.stats != nil {
+			a.stats.Update(msg)
+		}
+		if a.compare != nil {
+			a.compare.Update(msg)
+		}
+		if a.conflicts != nil {
+			a.conflicts.Update(msg)
+		}
+		if a.vaults != nil {
+			a.vaults.Update(msg)
+		}
+		if a.snippetManager != nil {
+			a.snippetManager.Update(msg)
+		}
+		if a.tagManager != nil {
+			a.tagManager.Update(msg)
+		}
+		if a.calendar != nil {
+			a.calendar.Update(msg)
+		}
+		if a.duplicates != nil {
+			a.duplicates.Update(msg)
+		}
+		a.unlockView.Update(msg)
+		return a, nil
+
+	case openRequestMsg:
+		return a, a.answerOpenRequest(msg)
+
+	case requestedNoteMsg:
+		return a, a.openRequestedNote(msg)
+
+	case capturedMsg:
+		// Handled here so the text lands where the hook's key was pressed
+		return a, a.captured(msg)
+
+	case idleCheckMsg:
+		if a.currentView == ViewUnlock {
+			// Unlocking starts the checks again
+			return a, nil
+		}
+		if lockAfter := a.lockAfter(); lockAfter > 0 && time.Since(a.lastInput) >= lockAfter {
+			return a, a.lock()
+		}
+		return a, a.idleCheck()
+
+	case tea.MouseMsg:
+		a.lastInput = time.Now()
+
+	case syncDoneMsg:
+		// Handled here so a sync finishes whichever view is open
+		return a, a.syncFinished(msg)
+
+	case watchChangedMsg:
+		if msg.watcher != a.watcher {
+			// Left over from a vault that was closed
+			return a, nil
+		}
+		return a, tea.Batch(a.runMirror(), waitForChanges(msg.watcher))
+
+	case mirrorDoneMsg:
+		return a, a.mirrorFinished(msg)
+
+	case notesLoadedMsg:
+		// Most changes made in the app reload the list, so mirror them
+		a.notesChanged()
+
+	case vaultOpenedMsg:
+		// Handled here so leaving the switcher early can't strand the vault
+		return a, a.vaultOpened(msg)
+
+	case historyNoteMsg:
+		return a, a.showNote(msg)
+
+	case tagsLoadedMsg:
+		// Cache tags app-wide so a lazily created editor starts with them
+		a.tags = msg.tags
+		a.recentTags = msg.recent
+		for _, editor := range a.editors {
+			editor.Update(msg)
+		}
+		return a, nil
+
+	case snippetsLoadedMsg:
+		a.snippets = msg.snippets
+		if a.snippetManager != nil {
+			a.snippetManager.cursor = max(min(a.snippetManager.cursor, len(a.snippets)-1), 0)
+		}
 		return a, nil
 
 	case tea.KeyMsg:
+		a.lastInput = time.Now()
 		switch msg.String() {
 		case "ctrl+c", "ctrl+q":
 			return a, tea.Quit
+		}
+		if a.currentView == ViewUnlock {
+			// Nothing else is reachable until the notes are unlocked
+			return a.unlockView.Update(msg)
+		}
+		switch msg.String() {
 		case "?":
-			a.currentView = ViewHelp
-			return a, nil
+			if a.currentView != ViewHelp && !a.formOpen() {
+				return a, a.SwitchToView(ViewHelp)
+			}
 		case "esc":
-			// Go back to notes list from any view
-			if a.currentView != ViewNotesList {
-				a.currentView = ViewNotesList
-				return a, a.notesList.Init()
+			// Go back from any view but the list, unless the editor has
+			// a panel or suggestions to close first
+			if a.currentView != ViewNotesList && !(a.currentView == ViewNoteEditor && a.editor().capturesEsc()) && !a.formOpen() {
+				return a, a.back()
+			}
+		case "ctrl+o", "alt+left":
+			return a, a.back()
+		case "alt+right":
+			return a, a.forward()
+		case "backspace", "tab":
+			// Views without text input take Backspace to go back and
+			// Ctrl+I, which terminals send as Tab, to go forward
+			switch a.currentView {
+			case ViewHelp, ViewTasks, ViewStats, ViewCompare, ViewDuplicates:
+				if msg.String() == "backspace" {
+					return a, a.back()
+				}
+				return a, a.forward()
 			}
 		}
 	}
@@ -92,9 +411,29 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ViewNotesList:
 		return a.notesList.Update(msg)
 	case ViewNoteEditor:
-		return a.noteEditor.Update(msg)
+		return a.editor().Update(msg)
 	case ViewHelp:
-		return a.help.Update(msg)
+		return a.helpView().Update(msg)
+	case ViewTasks:
+		return a.tasksView().Update(msg)
+	case ViewStats:
+		return a.statsView().Update(msg)
+	case ViewUnlock:
+		return a.unlockView.Update(msg)
+	case ViewCompare:
+		return a.compareView().Update(msg)
+	case ViewConflicts:
+		return a.conflictView().Update(msg)
+	case ViewVaults:
+		return a.vaultView().Update(msg)
+	case ViewSnippets:
+		return a.snippetsView().Update(msg)
+	case ViewTags:
+		return a.tagsView().Update(msg)
+	case ViewCalendar:
+		return a.calendarView().Update(msg)
+	case ViewDuplicates:
+		return a.duplicatesView().Update(msg)
 	default:
 		return a, nil
 	}
@@ -102,28 +441,78 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the current view
 func (a *App) View() string {
+	defer recoverPanic()
 	switch a.currentView {
 	case ViewNotesList:
 		return a.notesList.View()
 	case ViewNoteEditor:
-		return a.noteEditor.View()
+		return a.editor().View()
 	case ViewHelp:
-		return a.help.View()
+		return a.helpView().View()
+	case ViewTasks:
+		return a.tasksView().View()
+	case ViewStats:
+		return a.statsView().View()
+	case ViewUnlock:
+		return a.unlockView.View()
+	case ViewCompare:
+		return a.compareView().View()
+	case ViewConflicts:
+		return a.conflictView().View()
+	case ViewVaults:
+		return a.vaultView().View()
+	case ViewSnippets:
+		return a.snippetsView().View()
+	case ViewTags:
+		return a.tagsView().View()
+	case ViewCalendar:
+		return a.calendarView().View()
+	case ViewDuplicates:
+		return a.duplicatesView().View()
 	default:
 		return "Unknown view"
 	}
 }
 
-// SwitchToView switches to a different view
+// SwitchToView switches to a different view, recording the move in the
+// navigation history
 func (a *App) SwitchToView(view View) tea.Cmd {
+	from := a.here()
+	cmd := a.showView(view)
+	a.visited(from)
+	return cmd
+}
+
+// showView switches to a different view
+func (a *App) showView(view View) tea.Cmd {
+	if note := a.notesList.selectedNote; view == ViewNoteEditor && note != nil && note.Summary {
+		// Notes picked from the list open once they're loaded whole
+		return a.openNote(note)
+	}
 	a.currentView = view
 	switch view {
 	case ViewNotesList:
 		return a.notesList.Init()
 	case ViewNoteEditor:
-		return a.noteEditor.Init(a.notesList.selectedNote)
+		return a.openEditor(a.notesList.selectedNote)
 	case ViewHelp:
-		return a.help.Init()
+		return a.helpView().Init()
+	case ViewTasks:
+		return a.tasksView().Init()
+	case ViewStats:
+		return a.statsView().Init()
+	case ViewCompare:
+		return a.compareView().Init()
+	case ViewVaults:
+		return a.vaultView().Init()
+	case ViewSnippets:
+		return a.snippetsView().Init()
+	case ViewTags:
+		return a.tagsView().Init()
+	case ViewCalendar:
+		return a.calendarView().Init()
+	case ViewDuplicates:
+		return a.duplicatesView().Init()
 	default:
 		return nil
 	}
@@ -133,3 +522,21 @@ func (a *App) SwitchToView(view View) tea.Cmd {
 func (a *App) GetStorage() *storage.Service {
 	return a.storage
 }
+
+// saveConfig writes the config file in the background
+func (a *App) saveConfig() tea.Cmd {
+	// Save a copy so later changes can't race with the write
+	saved := *a.config
+	return func() tea.Msg {
+		if err := saved.Save(); err != nil {
+			slog.Warn("failed to save config", "err", err)
+			return nil
+		}
+		return nil
+	}
+}
+
+// GetConfig returns the user configuration
+func (a *App) GetConfig() *config.Config {
+	return a.config
+}
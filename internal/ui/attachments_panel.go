@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"markdown-note-taking-app/internal/export"
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// imageExtensions are linked as images when inserted into a note
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+}
+
+// attachmentsPanel lists the files attached to the note and attaches more
+type attachmentsPanel struct {
+	visible bool
+	items   []*models.Attachment
+	cursor  int
+	adding  bool            // the path input has focus
+	input   textinput.Model // path of the file to attach
+	err     string
+}
+
+// newAttachmentsPanel creates the attachments panel
+func newAttachmentsPanel() attachmentsPanel {
+	input := textinput.New()
+	input.Placeholder = "~/path/to/file"
+	input.Prompt = "Attach: "
+	input.Width = 50
+	return attachmentsPanel{input: input}
+}
+
+// openAttachmentsPanel shows the panel and loads the note's attachments.
+// Files can only be attached to saved notes.
+func (m *NoteEditorModel) openAttachmentsPanel() tea.Cmd {
+	m.attachments.visible = true
+	m.attachments.adding = false
+	m.attachments.err = ""
+	if m.note == nil {
+		m.attachments.items = nil
+		m.attachments.err = "Save the note before attaching files"
+		return nil
+	}
+	return m.loadAttachments()
+}
+
+// loadAttachments loads the files attached to the note
+func (m *NoteEditorModel) loadAttachments() tea.Cmd {
+	noteID := m.note.ID
+	return func() tea.Msg {
+		items, err := m.app.GetStorage().GetAttachments(noteID)
+		return attachmentsLoadedMsg{noteID: noteID, items: items, err: err}
+	}
+}
+
+// handleAttachmentsKey handles keys while the attachments panel is open
+func (m *NoteEditorModel) handleAttachmentsKey(msg tea.KeyMsg) tea.Cmd {
+	panel := &m.attachments
+	if panel.adding {
+		switch msg.String() {
+		case "esc":
+			panel.adding = false
+			panel.input.Blur()
+			return nil
+		case "enter":
+			path := strings.TrimSpace(panel.input.Value())
+			if path == "" {
+				return nil
+			}
+			panel.adding = false
+			panel.input.Blur()
+			return m.attachFile(path)
+		}
+		return updateInput(&panel.input, msg)
+	}
+
+	switch msg.String() {
+	case "esc", "alt+a":
+		panel.visible = false
+	case "up", "k":
+		panel.cursor = max(panel.cursor-1, 0)
+	case "down", "j":
+		panel.cursor = max(min(panel.cursor+1, len(panel.items)-1), 0)
+	case "a":
+		if m.note != nil {
+			panel.adding = true
+			panel.err = ""
+			panel.input.SetValue("")
+			return panel.input.Focus()
+		}
+	case "enter", "o":
+		if attachment := panel.selected(); attachment != nil {
+			if err := openFile(attachment.Path); err != nil {
+				panel.err = err.Error()
+			}
+		}
+	case "i":
+		if attachment := panel.selected(); attachment != nil {
+			m.insertAttachmentLink(attachment)
+			panel.visible = false
+		}
+	case "d":
+		if attachment := panel.selected(); attachment != nil {
+			return m.removeAttachment(attachment)
+		}
+	}
+	return nil
+}
+
+// selected returns the attachment under the cursor, if any
+func (p *attachmentsPanel) selected() *models.Attachment {
+	if p.cursor < 0 || p.cursor >= len(p.items) {
+		return nil
+	}
+	return p.items[p.cursor]
+}
+
+// attachFile attaches the file at path to the note
+func (m *NoteEditorModel) attachFile(path string) tea.Cmd {
+	noteID := m.note.ID
+	return func() tea.Msg {
+		expanded, err := export.ExpandHome(path)
+		if err != nil {
+			return attachmentChangedMsg{err: err}
+		}
+		attachment, err := m.app.GetStorage().AttachFile(noteID, expanded)
+		return attachmentChangedMsg{attachment: attachment, err: err}
+	}
+}
+
+// removeAttachment detaches a file from the note
+func (m *NoteEditorModel) removeAttachment(attachment *models.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.app.GetStorage().RemoveAttachment(attachment.ID); err != nil {
+			return attachmentChangedMsg{err: err}
+		}
+		return m.loadAttachments()()
+	}
+}
+
+// insertAttachmentLink inserts a markdown link to an attachment at the
+// cursor, as an image when it is one so the preview shows it
+func (m *NoteEditorModel) insertAttachmentLink(attachment *models.Attachment) {
+	target := attachment.Path
+	if strings.ContainsAny(target, " \t") {
+		target = "<" + target + ">"
+	}
+	link := fmt.Sprintf("[%s](%s)", attachment.Name, target)
+	if imageExtensions[strings.ToLower(filepath.Ext(attachment.Name))] {
+		link = "!" + link
+	}
+	m.contentInput.InsertString(link)
+	if m.splitPane {
+		m.UpdatePreview()
+	}
+}
+
+// openFile opens path with the desktop's default application
+func openFile(path string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	cmd := exec.Command(opener, path)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// renderAttachmentsPanel renders the attachments as a centered dialog
+func (m *NoteEditorModel) renderAttachmentsPanel() string {
+	panel := m.attachments
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	sizeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Attachments") + "\n\n"
+
+	if len(panel.items) == 0 && panel.err == "" {
+		s += hintStyle.Render("No files attached yet") + "\n"
+	}
+	for i, attachment := range panel.items {
+		name := fmt.Sprintf(" %-36s", ansi.Truncate(attachment.Name, 36, "…"))
+		if i == panel.cursor {
+			name = selectedStyle.Render(name)
+		}
+		s += name + sizeStyle.Render(fmt.Sprintf(" %8s", formatSize(attachment.Size))) + "\n"
+	}
+
+	if panel.adding {
+		s += "\n" + panel.input.View() + "\n"
+	}
+	if panel.err != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(panel.err) + "\n"
+	}
+
+	hint := "a: Attach • Enter: Open • i: Insert link • d: Remove • Esc: Close"
+	if panel.adding {
+		hint = "Enter: Attach and insert link • Esc: Cancel"
+	}
+	s += "\n" + hintStyle.Render(hint)
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Render(s)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// formatSize formats a file size in bytes for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// Messages
+
+// attachmentsLoadedMsg carries the files attached to a note
+type attachmentsLoadedMsg struct {
+	noteID int
+	items  []*models.Attachment
+	err    error
+}
+
+// attachmentChangedMsg reports a file attached to the note, or why
+// attaching or removing one failed
+type attachmentChangedMsg struct {
+	attachment *models.Attachment
+	err        error
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/ui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// browserPane is the two-pane layout used on large terminals: the notes
+// list on the left and a live preview of the selected note on the right
+type browserPane struct {
+	preview *MarkdownPreviewModel
+	focused bool   // true while keys scroll the preview instead of the list
+	noteKey string // note shown in the preview, so unchanged notes aren't re-rendered
+}
+
+// browserActive reports whether the list is shown with the preview pane
+func (m *NotesListModel) browserActive() bool {
+	return m.app.GetConfig().TwoPane && theme.NewResponsive(m.width, m.height).IsLarge()
+}
+
+// toggleBrowser turns the two-pane layout on or off and saves the choice
+func (m *NotesListModel) toggleBrowser() tea.Cmd {
+	cfg := m.app.GetConfig()
+	cfg.TwoPane = !cfg.TwoPane
+	m.browser.focused = false
+
+	switch {
+	case !cfg.TwoPane:
+		m.statusMsg = "Two-pane layout off"
+	case m.browserActive():
+		m.statusMsg = "Two-pane layout on (Tab to focus the preview)"
+	default:
+		m.statusMsg = "Two-pane layout on; widen the terminal to see it"
+	}
+	return m.app.saveConfig()
+}
+
+// browserPreview returns the preview model of the pane, creating it on first use
+func (m *NotesListModel) browserPreview() *MarkdownPreviewModel {
+	if m.browser.preview == nil {
+		m.browser.preview = NewMarkdownPreviewModel(NewRenderer(m.app.GetConfig()))
+		m.browser.preview.ShowPreview(true)
+	}
+	return m.browser.preview
+}
+
+// handleBrowserKey scrolls the preview while it has focus. Keys it doesn't
+// handle fall through to the list.
+func (m *NotesListModel) handleBrowserKey(msg tea.KeyMsg) bool {
+	preview := m.browserPreview()
+
+	switch msg.String() {
+	case "up", "k":
+		preview.ScrollUp()
+	case "down", "j":
+		preview.ScrollDown()
+	case "pgup":
+		for i := 0; i < preview.getMaxVisibleLines(); i++ {
+			preview.ScrollUp()
+		}
+	case "pgdown", "ctrl+f":
+		for i := 0; i < preview.getMaxVisibleLines(); i++ {
+			preview.ScrollDown()
+		}
+	case "home", "g":
+		preview.ScrollToTop()
+	case "end", "G":
+		preview.ScrollToBottom()
+	case "esc":
+		m.browser.focused = false
+	default:
+		return false
+	}
+	return true
+}
+
+// renderBrowserPane renders the preview of the note under the cursor in a
+// bordered pane of the given outer size
+func (m *NotesListModel) renderBrowserPane(width, height int) string {
+	preview := m.browserPreview()
+
+	// Border takes 2 columns and the preview's own padding and margin 3
+	preview.SetSize(max(width-5, 10), height)
+
+	key, content := "", ""
+	if note := m.cursorNote(); note != nil {
+		key = fmt.Sprintf("%d|%d", note.ID, note.UpdatedAt.UnixNano())
+		content = note.Content
+	}
+	if key != m.browser.noteKey {
+		// A different note starts at the top
+		m.browser.noteKey = key
+		preview.SetContent(content)
+		preview.ScrollToTop()
+	}
+
+	// Clip instead of wrapping so the scroll indicator can't add a line
+	lines := strings.Split(preview.View(), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width-2, "")
+	}
+
+	borderColor := "#334155"
+	if m.browser.focused {
+		borderColor = "#EA580C"
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(borderColor)).
+		Width(width - 2).
+		Height(height - 2).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
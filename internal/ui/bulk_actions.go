@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/export"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// bulkAction identifies a bulk operation waiting for confirmation or input
+type bulkAction int
+
+const (
+	bulkNone bulkAction = iota
+	bulkDelete
+	bulkAddTag
+	bulkRemoveTag
+	bulkMove
+	bulkExport
+)
+
+// defaultExportDir is suggested when exporting selected notes
+const defaultExportDir = "~/tuinotes-export"
+
+// newBulkInput creates the text input used by bulk action prompts
+func newBulkInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 40
+	return ti
+}
+
+// toggleSelection selects or deselects the note under the cursor
+func (m *NotesListModel) toggleSelection() {
+	if len(m.filteredNotes) == 0 {
+		return
+	}
+	id := m.filteredNotes[m.cursor].ID
+	if m.selected[id] {
+		delete(m.selected, id)
+	} else {
+		m.selected[id] = true
+	}
+	m.selectAnchor = m.cursor
+}
+
+// selectRange selects every note between the last toggled note and the cursor
+func (m *NotesListModel) selectRange() {
+	if len(m.filteredNotes) == 0 {
+		return
+	}
+	from, to := m.selectAnchor, m.cursor
+	if from > to {
+		from, to = to, from
+	}
+	to = min(to, len(m.filteredNotes)-1)
+	for i := max(from, 0); i <= to; i++ {
+		m.selected[m.filteredNotes[i].ID] = true
+	}
+	m.selectAnchor = m.cursor
+}
+
+// clearSelection deselects all notes
+func (m *NotesListModel) clearSelection() {
+	m.selected = map[int]bool{}
+	m.selectAnchor = 0
+}
+
+// selectedNotes returns the selected notes in list order
+func (m *NotesListModel) selectedNotes() []*models.Note {
+	var notes []*models.Note
+	for _, note := range m.filteredNotes {
+		if m.selected[note.ID] {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
+
+// bulkNotes returns the notes a bulk action applies to: the note it was
+// started on from the action menu, or else the selected notes
+func (m *NotesListModel) bulkNotes() []*models.Note {
+	if m.bulkTarget != nil {
+		return []*models.Note{m.bulkTarget}
+	}
+	return m.selectedNotes()
+}
+
+// noteCount formats a number of notes, e.g. "1 note" or "3 notes"
+func noteCount(n int) string {
+	if n == 1 {
+		return "1 note"
+	}
+	return fmt.Sprintf("%d notes", n)
+}
+
+// startBulkAction opens the confirmation or input prompt for an action
+func (m *NotesListModel) startBulkAction(action bulkAction) tea.Cmd {
+	m.bulkAction = action
+	m.statusMsg = ""
+	if action == bulkDelete {
+		return nil
+	}
+
+	m.bulkInput.SetValue("")
+	switch action {
+	case bulkAddTag, bulkRemoveTag:
+		m.bulkInput.Placeholder = "tag name"
+	case bulkMove:
+		m.bulkInput.Placeholder = "notebook (empty for none)"
+	case bulkExport:
+		m.bulkInput.Placeholder = "directory"
+		m.bulkInput.SetValue(defaultExportDir)
+	}
+	m.bulkInput.CursorEnd()
+	return m.bulkInput.Focus()
+}
+
+// cancelBulkAction closes the bulk action prompt
+func (m *NotesListModel) cancelBulkAction() {
+	m.bulkAction = bulkNone
+	m.bulkTarget = nil
+	m.bulkInput.Blur()
+}
+
+// handleBulkKey handles keys while a bulk action prompt is open
+func (m *NotesListModel) handleBulkKey(msg tea.KeyMsg) tea.Cmd {
+	if m.bulkAction == bulkDelete {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return m.runBulkAction("")
+		case "n", "N", "esc":
+			m.cancelBulkAction()
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.cancelBulkAction()
+		return nil
+	case "enter":
+		value := strings.TrimSpace(m.bulkInput.Value())
+		if value == "" && m.bulkAction != bulkMove {
+			return nil
+		}
+		return m.runBulkAction(value)
+	}
+
+	return updateInput(&m.bulkInput, msg)
+}
+
+// runBulkAction applies the pending action to the selected notes
+func (m *NotesListModel) runBulkAction(value string) tea.Cmd {
+	action := m.bulkAction
+	notes := m.bulkNotes()
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		ids[i] = note.ID
+	}
+	m.cancelBulkAction()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	revert := inverseOf(action, notes, value)
+
+	return func() tea.Msg {
+		storage := m.app.GetStorage()
+		var err error
+		var status string
+
+		if action == bulkDelete || action == bulkExport {
+			// The list holds summaries; undoing a delete and exporting
+			// need the whole notes
+			if notes, err = storage.LoadContent(notes); err != nil {
+				return bulkDoneMsg{err: err}
+			}
+		}
+
+		switch action {
+		case bulkDelete:
+			revert = restoreNotes(notes)
+			err = storage.DeleteNotes(ids)
+			status = fmt.Sprintf("Deleted %s", noteCount(len(ids)))
+		case bulkAddTag:
+			err = storage.AddTagToNotes(ids, value)
+			status = fmt.Sprintf("Tagged %s with %q", noteCount(len(ids)), value)
+		case bulkRemoveTag:
+			err = storage.RemoveTagFromNotes(ids, value)
+			status = fmt.Sprintf("Removed %q from %s", value, noteCount(len(ids)))
+		case bulkMove:
+			err = storage.MoveNotesToNotebook(ids, value)
+			if value == "" {
+				status = fmt.Sprintf("Removed %s from their notebook", noteCount(len(ids)))
+			} else {
+				status = fmt.Sprintf("Moved %s to %q", noteCount(len(ids)), value)
+			}
+		case bulkExport:
+			var dir string
+			dir, err = export.ExpandHome(value)
+			if err == nil {
+				_, err = export.MarkdownDir(notes, dir)
+			}
+			status = fmt.Sprintf("Exported %s to %s", noteCount(len(ids)), value)
+		}
+
+		return bulkDoneMsg{status: status, err: err, undo: revert}
+	}
+}
+
+// renderSelectionBar renders the selection count, or the note picked from
+// the action menu, and the bulk action prompt
+func (m *NotesListModel) renderSelectionBar() string {
+	accent := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Bold(true)
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	count := accent.Render(fmt.Sprintf("● %d selected", len(m.selected)))
+	if m.bulkTarget != nil {
+		count = accent.Render("● " + ansi.Truncate(m.bulkTarget.Title, 30, "…"))
+	}
+
+	switch m.bulkAction {
+	case bulkDelete:
+		return count + " " + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F43F5E")).
+			Bold(true).
+			Render(fmt.Sprintf("Delete %s? (y/n)", noteCount(len(m.bulkNotes()))))
+	case bulkAddTag:
+		return count + " Add tag: " + m.bulkInput.View()
+	case bulkRemoveTag:
+		return count + " Remove tag: " + m.bulkInput.View()
+	case bulkMove:
+		return count + " Move to notebook: " + m.bulkInput.View()
+	case bulkExport:
+		return count + " Export to: " + m.bulkInput.View()
+	}
+
+	if len(m.selected) == 2 {
+		return count + hint.Render(" • c: compare • d: delete • +/-: tag/untag • m: move • x: export • esc: clear")
+	}
+	return count + hint.Render(" • d: delete • +/-: tag/untag • m: move • x: export • esc: clear")
+}
+
+// Messages
+type bulkDoneMsg struct {
+	status string
+	err    error
+	undo   func(s *storage.Service) error // reverts the action, nil if it can't be undone
+}
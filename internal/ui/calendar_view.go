@@ -0,0 +1,452 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dayFormat keys the calendar's days and titles daily notes
+const dayFormat = "2006-01-02"
+
+// calendarDay holds what happened on one day of the calendar
+type calendarDay struct {
+	daily   *models.Note // the note titled with the date, if any
+	created []*models.Note
+	edited  []*models.Note // edited that day but created earlier
+	due     []taskGroup    // open tasks due that day, by note
+}
+
+// calendarRow is a note listed for the selected day
+type calendarRow struct {
+	note  *models.Note
+	label string
+	task  *utils.Task // the task due, for rows of due tasks
+}
+
+// CalendarModel shows a month at a time with the days that have notes,
+// a daily note or tasks due marked. A daily note is the note titled with
+// its date, as in 2024-03-01.
+type CalendarModel struct {
+	app      *App
+	days     map[string]*calendarDay
+	selected time.Time // midnight of the selected day
+	loaded   bool
+	creating bool // the selected day's daily note is being created
+	status   string
+	width    int
+	height   int
+
+	// The notes of the selected day, focused with Tab
+	listFocused bool
+	rows        []calendarRow
+	cursor      int
+}
+
+// NewCalendarModel creates a new calendar model showing this month
+func NewCalendarModel(app *App) *CalendarModel {
+	return &CalendarModel{
+		app:      app,
+		days:     map[string]*calendarDay{},
+		selected: startOfDay(time.Now()),
+	}
+}
+
+// Init loads the notes the calendar marks
+func (m *CalendarModel) Init() tea.Cmd {
+	m.status = ""
+	return func() tea.Msg {
+		notes, err := m.app.GetStorage().GetAllNotes(models.NoteFilter{})
+		return calendarLoadedMsg{notes: notes, err: err}
+	}
+}
+
+// startOfDay returns midnight of t's day in local time
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// day returns the calendar day for key, adding it when missing
+func (m *CalendarModel) day(key string) *calendarDay {
+	day := m.days[key]
+	if day == nil {
+		day = &calendarDay{}
+		m.days[key] = day
+	}
+	return day
+}
+
+// buildDays sorts the notes into the days they belong to
+func (m *CalendarModel) buildDays(notes []*models.Note) {
+	m.days = map[string]*calendarDay{}
+	for _, note := range notes {
+		created := note.CreatedAt.Local().Format(dayFormat)
+		m.day(created).created = append(m.day(created).created, note)
+		if updated := note.UpdatedAt.Local().Format(dayFormat); updated != created {
+			m.day(updated).edited = append(m.day(updated).edited, note)
+		}
+
+		title := strings.TrimSpace(note.Title)
+		if _, err := time.Parse(dayFormat, title); err == nil {
+			day := m.day(title)
+			// The most recently edited note wins when several share a date
+			if day.daily == nil || note.UpdatedAt.After(day.daily.UpdatedAt) {
+				day.daily = note
+			}
+		}
+
+		if note.Archived() {
+			continue
+		}
+		due := map[string][]utils.Task{}
+		for _, task := range utils.ParseTasks(note.Content) {
+			if !task.Done && task.Due != nil {
+				key := task.Due.Format(dayFormat)
+				due[key] = append(due[key], task)
+			}
+		}
+		for key, tasks := range due {
+			m.day(key).due = append(m.day(key).due, taskGroup{note: note, tasks: tasks})
+		}
+	}
+	m.buildRows()
+}
+
+// buildRows lists the notes of the selected day
+func (m *CalendarModel) buildRows() {
+	m.rows = nil
+	day := m.days[m.selected.Format(dayFormat)]
+	if day != nil {
+		for _, note := range day.created {
+			m.rows = append(m.rows, calendarRow{note: note, label: "created"})
+		}
+		for _, note := range day.edited {
+			m.rows = append(m.rows, calendarRow{note: note, label: "edited"})
+		}
+		for _, group := range day.due {
+			for i := range group.tasks {
+				m.rows = append(m.rows, calendarRow{note: group.note, label: "due", task: &group.tasks[i]})
+			}
+		}
+	}
+	if m.cursor >= len(m.rows) {
+		m.cursor = max(len(m.rows)-1, 0)
+	}
+	if len(m.rows) == 0 {
+		m.listFocused = false
+	}
+}
+
+// selectDay moves the selection to day, keeping the list cursor only
+// when the day stays the same
+func (m *CalendarModel) selectDay(day time.Time) {
+	if day.Equal(m.selected) {
+		return
+	}
+	m.selected = day
+	m.cursor = 0
+	m.buildRows()
+}
+
+// moveMonths moves the selection by months, keeping the day of the month
+// where the new month has it
+func (m *CalendarModel) moveMonths(months int) {
+	first := time.Date(m.selected.Year(), m.selected.Month()+time.Month(months), 1, 0, 0, 0, 0, time.Local)
+	last := first.AddDate(0, 1, -1).Day()
+	m.selectDay(first.AddDate(0, 0, min(m.selected.Day(), last)-1))
+}
+
+// Update handles updates for the calendar
+func (m *CalendarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case calendarLoadedMsg:
+		if msg.err != nil {
+			slog.Warn("failed to load notes for the calendar", "err", msg.err)
+			m.status = "Error: " + msg.err.Error()
+			return m.app, nil
+		}
+		m.buildDays(msg.notes)
+		m.loaded = true
+		return m.app, nil
+
+	case dailyNoteMsg:
+		m.creating = false
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m.app, nil
+		}
+		m.app.notesChanged()
+		return m.app, m.openNote(msg.note)
+
+	case tea.KeyMsg:
+		if m.listFocused {
+			return m.app, m.handleListKey(msg)
+		}
+		switch msg.String() {
+		case "left", "h":
+			m.selectDay(m.selected.AddDate(0, 0, -1))
+		case "right", "l":
+			m.selectDay(m.selected.AddDate(0, 0, 1))
+		case "up", "k":
+			m.selectDay(m.selected.AddDate(0, 0, -7))
+		case "down", "j":
+			m.selectDay(m.selected.AddDate(0, 0, 7))
+		case "[", "pgup":
+			m.moveMonths(-1)
+		case "]", "pgdown":
+			m.moveMonths(1)
+		case "t":
+			m.selectDay(startOfDay(time.Now()))
+		case "enter", "e":
+			return m.app, m.openDailyNote()
+		case "tab":
+			if len(m.rows) > 0 {
+				m.listFocused = true
+			}
+		case "r":
+			return m.app, m.Init()
+		case "q":
+			return m.app, m.app.SwitchToView(ViewNotesList)
+		}
+	}
+	return m.app, nil
+}
+
+// handleListKey handles keys while the selected day's notes have focus
+func (m *CalendarModel) handleListKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "enter", "e":
+		if m.cursor < len(m.rows) {
+			return m.openNote(m.rows[m.cursor].note)
+		}
+	case "tab", "q":
+		m.listFocused = false
+	}
+	return nil
+}
+
+// openNote opens a note in the editor
+func (m *CalendarModel) openNote(note *models.Note) tea.Cmd {
+	m.app.notesList.selectedNote = note
+	return m.app.SwitchToView(ViewNoteEditor)
+}
+
+// openDailyNote opens the selected day's daily note, creating it first
+// when the day has none
+func (m *CalendarModel) openDailyNote() tea.Cmd {
+	key := m.selected.Format(dayFormat)
+	if day := m.days[key]; day != nil && day.daily != nil {
+		return m.openNote(day.daily)
+	}
+	if m.creating || !m.loaded {
+		return nil
+	}
+	m.creating = true
+	return func() tea.Msg {
+		note, err := m.app.GetStorage().CreateNote(key, "")
+		return dailyNoteMsg{note: note, err: err}
+	}
+}
+
+// View renders the month grid above the selected day's notes
+func (m *CalendarModel) View() string {
+	if !m.loaded && m.status == "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Bold(true).
+			Render("Loading calendar...")
+	}
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1).
+		Render("Calendar") + "\n\n"
+
+	s += m.renderMonth() + "\n"
+	legend := func(symbol, color, label string) string {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(symbol) + hintStyle.Render(" "+label+"  ")
+	}
+	s += legend("●", "#EA580C", "daily note") + legend("•", "#38BDF8", "notes") + legend("!", "#F59E0B", "tasks due") + "\n\n"
+	s += m.renderDay() + "\n"
+
+	switch {
+	case m.status != "":
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(m.status) + "\n\n"
+	case m.creating:
+		s += hintStyle.Render("Creating the daily note...") + "\n\n"
+	}
+	return s + m.renderControls()
+}
+
+// renderMonth renders the selected month as a grid of weeks starting on
+// Monday
+func (m *CalendarModel) renderMonth() string {
+	first := time.Date(m.selected.Year(), m.selected.Month(), 1, 0, 0, 0, 0, time.Local)
+	today := startOfDay(time.Now())
+
+	monthStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9")).Bold(true)
+	weekdayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	lines := []string{monthStyle.Render(first.Format("January 2006"))}
+	var header []string
+	for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		header = append(header, weekdayStyle.Render(fmt.Sprintf("%-6s", name)))
+	}
+	lines = append(lines, strings.Join(header, ""))
+
+	// Go back to the Monday on or before the first of the month
+	offset := (int(first.Weekday()) + 6) % 7
+	day := first.AddDate(0, 0, -offset)
+	for day.Month() == first.Month() || day.Before(first) {
+		var week string
+		for range 7 {
+			if day.Month() != first.Month() {
+				week += strings.Repeat(" ", 6)
+			} else {
+				week += m.renderCell(day, today)
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		lines = append(lines, week)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCell renders one day of the grid: its number and a mark for each
+// of a daily note, other notes and open tasks due
+func (m *CalendarModel) renderCell(day, today time.Time) string {
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+	if day.Equal(today) {
+		numberStyle = numberStyle.Foreground(lipgloss.Color("#EA580C")).Bold(true).Underline(true)
+	}
+	if day.Equal(m.selected) {
+		numberStyle = numberStyle.Foreground(lipgloss.Color("#F1F5F9")).Background(lipgloss.Color("#EA580C")).Bold(true)
+	}
+
+	marks := "   "
+	if info := m.days[day.Format(dayFormat)]; info != nil {
+		mark := func(show bool, symbol, color string) string {
+			if !show {
+				return " "
+			}
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(symbol)
+		}
+		dueColor := "#F59E0B" // Amber for due today or later
+		if day.Before(today) {
+			dueColor = "#F43F5E" // Rose for overdue
+		}
+		marks = mark(info.daily != nil, "●", "#EA580C") +
+			mark(len(info.created)+len(info.edited) > 0, "•", "#38BDF8") +
+			mark(len(info.due) > 0, "!", dueColor)
+	}
+	return numberStyle.Render(fmt.Sprintf("%2d", day.Day())) + marks + " "
+}
+
+// renderDay renders the selected day's daily note and the notes created,
+// edited or with tasks due that day
+func (m *CalendarModel) renderDay() string {
+	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#38BDF8")).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+
+	s := dateStyle.Render(m.selected.Format("Monday, January 2, 2006")) + "\n"
+	day := m.days[m.selected.Format(dayFormat)]
+	if day != nil && day.daily != nil {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#EA580C")).Render("● Daily note: "+day.daily.Title) + "\n"
+	} else {
+		s += mutedStyle.Render("No daily note yet, Enter creates one") + "\n"
+	}
+
+	if len(m.rows) == 0 {
+		return s + mutedStyle.Render("Nothing created, edited or due this day") + "\n"
+	}
+
+	// Keep the cursor within the space left under the grid
+	maxRows := max(m.height-22, 3)
+	start := 0
+	if m.cursor >= maxRows {
+		start = m.cursor - maxRows + 1
+	}
+	end := min(start+maxRows, len(m.rows))
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Width(9)
+	for i := start; i < end; i++ {
+		row := m.rows[i]
+		cursor := "  "
+		titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		if m.listFocused && i == m.cursor {
+			cursor = lipgloss.NewStyle().Foreground(lipgloss.Color("#EA580C")).Bold(true).Render("▶ ")
+			titleStyle = titleStyle.Bold(true)
+		}
+		line := cursor + labelStyle.Render(row.label) + titleStyle.Render(row.note.Title)
+		if row.task != nil {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Render(": " + row.task.Text)
+		}
+		s += line + "\n"
+	}
+	if end < len(m.rows) {
+		s += mutedStyle.Render(fmt.Sprintf("  and %d more", len(m.rows)-end)) + "\n"
+	}
+	return s
+}
+
+// renderControls renders the key hints for the calendar
+func (m *CalendarModel) renderControls() string {
+	controls := "←→↑↓: Day • [ ]: Month • t: Today • Enter: Daily note • Tab: Notes of the day • Esc: Back"
+	if m.listFocused {
+		controls = "↑↓: Navigate • Enter: Open note • Tab: Back to the month • Esc: Back"
+	} else if m.width < 100 {
+		controls = "←→↑↓: Day • [ ]: Month • t: Today • Enter: Daily • Tab: Notes • Esc: Back"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render(controls)
+}
+
+// calendarView returns the calendar, creating it on first use
+func (a *App) calendarView() *CalendarModel {
+	if a.calendar == nil {
+		a.calendar = NewCalendarModel(a)
+		a.calendar.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.calendar
+}
+
+// Messages
+
+// calendarLoadedMsg carries the notes the calendar marks
+type calendarLoadedMsg struct {
+	notes []*models.Note
+	err   error
+}
+
+// dailyNoteMsg carries a daily note just created, or why creating it
+// failed
+type dailyNoteMsg struct {
+	note *models.Note
+	err  error
+}
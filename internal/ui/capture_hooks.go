@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+
+	"markdown-note-taking-app/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Capture hooks run a command configured for a key, such as a
+// speech-to-text tool, and take what it prints as note text. The command
+// gets the terminal while it runs, to show its progress or ask for input,
+// and only its standard output is captured.
+
+// captureHook returns the capture hook bound to the key pressed
+func (a *App) captureHook(msg tea.KeyMsg) (config.CaptureHook, bool) {
+	for _, hook := range a.GetConfig().CaptureHooks {
+		if hook.Key == msg.String() && strings.TrimSpace(hook.Command) != "" {
+			return hook, true
+		}
+	}
+	return config.CaptureHook{}, false
+}
+
+// runCapture runs a capture hook. Its output goes into editor, or into a
+// new note when editor is nil.
+func runCapture(hook config.CaptureHook, editor *NoteEditorModel) tea.Cmd {
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", hook.Command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", hook.Command)
+	}
+	cmd.Stdout = &out
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return capturedMsg{editor: editor, err: fmt.Errorf("capture command %q failed: %w", hook.Command, err)}
+		}
+		return capturedMsg{editor: editor, text: strings.TrimSpace(out.String())}
+	})
+}
+
+// captured puts a capture hook's output where its key was pressed
+func (a *App) captured(msg capturedMsg) tea.Cmd {
+	if msg.editor == nil {
+		switch {
+		case msg.err != nil:
+			a.notesList.statusMsg = "Error: " + msg.err.Error()
+		case msg.text == "":
+			a.notesList.statusMsg = "Nothing was captured"
+		case a.currentView == ViewNotesList:
+			return a.notesList.newNoteWith(msg.text)
+		}
+		return nil
+	}
+
+	// The editor may have been closed meanwhile
+	if !slices.Contains(a.editors, msg.editor) {
+		return nil
+	}
+	msg.editor.insertCaptured(msg)
+	return nil
+}
+
+// insertCaptured inserts a capture hook's output at the cursor of the
+// content
+func (m *NoteEditorModel) insertCaptured(msg capturedMsg) {
+	switch {
+	case msg.err != nil:
+		m.saveErr = msg.err.Error()
+		return
+	case msg.text == "":
+		m.saveErr = "Nothing was captured"
+		return
+	}
+	m.saveErr = ""
+
+	if m.focused != 2 {
+		m.focused = 2
+		m.updateFocus()
+	}
+	// Keep the text from running into a word before the cursor
+	text := msg.text
+	ta := &m.contentInput
+	line := []rune(strings.Split(ta.Value(), "\n")[ta.Line()])
+	if col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset; col > 0 && col <= len(line) && !unicode.IsSpace(line[col-1]) {
+		text = " " + text
+	}
+	ta.InsertString(text)
+	if m.zen {
+		m.growZenTextarea()
+	}
+	if m.splitPane {
+		m.UpdatePreview()
+	}
+}
+
+// Messages
+
+// capturedMsg carries the output of a capture hook
+type capturedMsg struct {
+	editor *NoteEditorModel // nil when captured from the notes list
+	text   string
+	err    error
+}
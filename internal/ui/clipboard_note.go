@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/importer"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newNoteFromClipboard reads the system clipboard in the background for a
+// new note, converting HTML to markdown
+func (m *NotesListModel) newNoteFromClipboard() tea.Cmd {
+	return func() tea.Msg {
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			return clipboardNoteMsg{err: fmt.Errorf("failed to read the clipboard: %w", err)}
+		}
+		content, _, err := importer.PastedMarkdown(text)
+		return clipboardNoteMsg{content: content, err: err}
+	}
+}
+
+// openClipboardNote opens the editor on a new note holding what was read
+// from the clipboard
+func (m *NotesListModel) openClipboardNote(msg clipboardNoteMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		m.statusMsg = "Error: " + msg.err.Error()
+		return nil
+	case msg.content == "":
+		m.statusMsg = "The clipboard is empty"
+		return nil
+	}
+	return m.newNoteWith(msg.content)
+}
+
+// newNoteWith opens the editor on a new note holding content, titled after
+// its first heading or line
+func (m *NotesListModel) newNoteWith(content string) tea.Cmd {
+	m.selectedNote = nil
+	cmd := m.app.SwitchToView(ViewNoteEditor)
+	editor := m.app.editor()
+	editor.contentInput.SetValue(content)
+	editor.suggestTitle()
+	if editor.splitPane {
+		editor.UpdatePreview()
+	}
+	return cmd
+}
+
+// Messages
+
+// clipboardNoteMsg carries the clipboard's text as markdown for a new note
+type clipboardNoteMsg struct {
+	content string
+	err     error
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/ui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// nextColorLabel returns the color label direction steps from current,
+// cycling through no label and then each of theme.TagColors
+func nextColorLabel(current string, direction int) string {
+	names := []string{""}
+	for _, color := range theme.TagColors {
+		names = append(names, color.Name)
+	}
+	i := 0
+	for j, name := range names {
+		if name == current {
+			i = j
+		}
+	}
+	return names[(i+direction+len(names))%len(names)]
+}
+
+// colorLabelStyle returns the style a color label is shown in
+func colorLabelStyle(name string) lipgloss.Style {
+	if color, ok := colorLabel(name); ok {
+		return lipgloss.NewStyle().Foreground(color)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+}
+
+// colorLabel returns the color of a label, if it's one of theme.TagColors
+func colorLabel(name string) (lipgloss.Color, bool) {
+	for _, color := range theme.TagColors {
+		if color.Name == name {
+			return color.Border, true
+		}
+	}
+	return "", false
+}
+
+// tagColor returns the color a tag's badges are drawn in: its color
+// label, or cyan for tags without one
+func tagColor(tag models.Tag) lipgloss.Color {
+	for _, color := range theme.TagColors {
+		if color.Name == tag.Color {
+			return color.Foreground
+		}
+	}
+	return theme.TagColors[0].Foreground
+}
+
+// colorMarker renders the one column wide marker of a note's color label
+// in list rows, blank for notes without one
+func colorMarker(note *models.Note) string {
+	if _, ok := colorLabel(note.Color); !ok {
+		return " "
+	}
+	return colorLabelStyle(note.Color).Render("●")
+}
+
+// cycleColor gives the note under the cursor the next color label
+func (m *NotesListModel) cycleColor(note *models.Note) tea.Cmd {
+	color := nextColorLabel(note.Color, 1)
+	return func() tea.Msg {
+		if err := m.app.GetStorage().SetNoteColor(note.ID, color); err != nil {
+			return colorSetMsg{err: err}
+		}
+		if color == "" {
+			return colorSetMsg{status: fmt.Sprintf("Removed the color label of %q", note.Title)}
+		}
+		return colorSetMsg{status: fmt.Sprintf("Labeled %q %s", note.Title, color)}
+	}
+}
+
+// Messages
+
+// colorSetMsg reports the outcome of changing a note's color label
+type colorSetMsg struct {
+	status string
+	err    error
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// CompareModel manages the compare view, which shows two notes side by
+// side with a shared scroll position so matching parts stay level
+type CompareModel struct {
+	app      *App
+	notes    [2]*models.Note
+	raw      bool // show the markdown source instead of the rendered preview
+	renderer Renderer
+	lines    [2][]string
+	linesKey string // width and mode the lines were built for
+	scroll   int
+	width    int
+	height   int
+}
+
+// NewCompareModel creates a new compare view model
+func NewCompareModel(app *App) *CompareModel {
+	return &CompareModel{app: app, renderer: NewRenderer(app.GetConfig())}
+}
+
+// SetNotes chooses the notes to compare and scrolls back to the top
+func (m *CompareModel) SetNotes(left, right *models.Note) {
+	m.notes = [2]*models.Note{left, right}
+	m.linesKey = ""
+	m.scroll = 0
+}
+
+// Init initializes the compare view
+func (m *CompareModel) Init() tea.Cmd {
+	return nil
+}
+
+// paneWidth returns the inner width of each pane, without border and padding
+func (m *CompareModel) paneWidth() int {
+	return max((m.width-1)/2-4, 10)
+}
+
+// bodyHeight returns how many content lines fit in a pane below its title
+func (m *CompareModel) bodyHeight() int {
+	// Footer line, pane border and pane title
+	return max(m.height-4, 1)
+}
+
+// paneLines returns the lines of both panes, building them when the width
+// or mode changed
+func (m *CompareModel) paneLines() [2][]string {
+	width := m.paneWidth()
+	key := fmt.Sprintf("%d|%v", width, m.raw)
+	if key == m.linesKey || m.notes[0] == nil || m.notes[1] == nil {
+		return m.lines
+	}
+
+	if m.raw {
+		m.lines[0] = rawCompareLines(m.notes[0].Content, m.notes[1].Content, width)
+		m.lines[1] = rawCompareLines(m.notes[1].Content, m.notes[0].Content, width)
+	} else {
+		for i, note := range m.notes {
+			rendered, _ := renderWithFrontmatter(m.renderer, note.Content, width, false)
+			m.lines[i] = strings.Split(rendered, "\n")
+		}
+	}
+	m.linesKey = key
+	return m.lines
+}
+
+// rawCompareLines wraps the source of a note to width. Lines that don't
+// appear anywhere in the other note are marked in the gutter.
+func rawCompareLines(content, other string, width int) []string {
+	otherLines := map[string]bool{}
+	for _, line := range strings.Split(other, "\n") {
+		otherLines[strings.TrimSpace(line)] = true
+	}
+
+	marker := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Render("▌ ")
+	changed := lipgloss.NewStyle().Foreground(lipgloss.Color("#FCD34D"))
+
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		unique := !otherLines[strings.TrimSpace(line)]
+		for _, part := range strings.Split(ansi.Wrap(line, max(width-2, 8), ""), "\n") {
+			if unique {
+				lines = append(lines, marker+changed.Render(part))
+			} else {
+				lines = append(lines, "  "+part)
+			}
+		}
+	}
+	return lines
+}
+
+// maxScroll returns the furthest scroll position, where the longer pane
+// shows its last line
+func (m *CompareModel) maxScroll() int {
+	lines := m.paneLines()
+	return max(max(len(lines[0]), len(lines[1]))-m.bodyHeight(), 0)
+}
+
+// scrollBy moves both panes together, staying within the longer note
+func (m *CompareModel) scrollBy(delta int) {
+	m.scroll = min(max(m.scroll+delta, 0), m.maxScroll())
+}
+
+// Update handles updates for the compare view
+func (m *CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.scrollBy(0)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			m.scrollBy(-1)
+		case "down", "j":
+			m.scrollBy(1)
+		case "pgup", "ctrl+b":
+			m.scrollBy(-m.bodyHeight())
+		case "pgdown", "ctrl+f", " ":
+			m.scrollBy(m.bodyHeight())
+		case "home", "g":
+			m.scroll = 0
+		case "end", "G":
+			m.scroll = m.maxScroll()
+		case "r":
+			// Switch between the rendered preview and the markdown source
+			m.raw = !m.raw
+			m.scroll = 0
+		case "x":
+			// Swap the panes
+			m.notes[0], m.notes[1] = m.notes[1], m.notes[0]
+			m.lines[0], m.lines[1] = m.lines[1], m.lines[0]
+			if m.raw {
+				m.linesKey = ""
+			}
+		}
+	}
+	return m.app, nil
+}
+
+// View renders both notes side by side
+func (m *CompareModel) View() string {
+	if m.notes[0] == nil || m.notes[1] == nil {
+		return "No notes to compare"
+	}
+
+	lines := m.paneLines()
+	width := m.paneWidth()
+	height := m.bodyHeight()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Bold(true)
+	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	panes := make([]string, 2)
+	for i, note := range m.notes {
+		date := dateStyle.Render(" · " + note.UpdatedAt.Format("Jan 2, 2006 15:04"))
+		title := ansi.Truncate(note.Title, max(width-lipgloss.Width(date), 1), "…")
+
+		body := make([]string, 0, height)
+		for j := m.scroll; j < min(m.scroll+height, len(lines[i])); j++ {
+			body = append(body, ansi.Truncate(lines[i][j], width, ""))
+		}
+		if m.scroll >= len(lines[i]) {
+			body = append(body, dateStyle.Italic(true).Render("(end of note)"))
+		}
+
+		panes[i] = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#475569")).
+			Padding(0, 1).
+			Width(width + 2).
+			Height(height + 1).
+			Render(titleStyle.Render(title) + date + "\n" + strings.Join(body, "\n"))
+	}
+
+	mode := "rendered"
+	if m.raw {
+		mode = "source, ▌ lines missing from the other note"
+	}
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#64748B")).
+		Render(fmt.Sprintf("%s • ↑/↓ scroll both • r: rendered/source • x: swap • esc: back", mode))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, panes[0], " ", panes[1]) + "\n" +
+		ansi.Truncate(footer, m.width, "…")
+}
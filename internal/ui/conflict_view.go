@@ -0,0 +1,361 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ConflictModel manages the conflict view, which shows the local and remote
+// versions of each note a sync couldn't merge and lets the user pick one
+type ConflictModel struct {
+	app       *App
+	conflicts []sync.Conflict
+	index     int
+	scroll    int
+	resolving bool // true while a resolution is being applied
+	err       string
+	width     int
+	height    int
+}
+
+// NewConflictModel creates a new conflict view model
+func NewConflictModel(app *App) *ConflictModel {
+	return &ConflictModel{app: app}
+}
+
+// SetConflicts replaces the conflicts left to resolve
+func (m *ConflictModel) SetConflicts(conflicts []sync.Conflict) {
+	m.conflicts = conflicts
+	m.index = min(m.index, max(len(conflicts)-1, 0))
+	m.scroll = 0
+	m.resolving = false
+	m.err = ""
+}
+
+// Init initializes the conflict view
+func (m *ConflictModel) Init() tea.Cmd {
+	return nil
+}
+
+// paneWidth returns the inner width of each pane, without border and padding
+func (m *ConflictModel) paneWidth() int {
+	return max((m.width-1)/2-4, 10)
+}
+
+// bodyHeight returns how many content lines fit in a pane below its title
+func (m *ConflictModel) bodyHeight() int {
+	// Header, footer, pane border and pane title
+	return max(m.height-5, 1)
+}
+
+// paneLines returns the local and remote lines of the current conflict
+func (m *ConflictModel) paneLines() [2][]string {
+	conflict := m.conflicts[m.index]
+	width := m.paneWidth()
+	deleted := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true).Render("(deleted)")
+
+	var lines [2][]string
+	switch {
+	case conflict.LocalDeleted:
+		lines[0] = []string{deleted}
+		lines[1] = rawCompareLines(conflict.Remote, "", width)
+	case conflict.RemoteDeleted:
+		lines[0] = rawCompareLines(conflict.Local, "", width)
+		lines[1] = []string{deleted}
+	default:
+		lines[0] = rawCompareLines(conflict.Local, conflict.Remote, width)
+		lines[1] = rawCompareLines(conflict.Remote, conflict.Local, width)
+	}
+	return lines
+}
+
+// scrollBy moves both panes together, staying within the longer version
+func (m *ConflictModel) scrollBy(delta int) {
+	lines := m.paneLines()
+	maxScroll := max(max(len(lines[0]), len(lines[1]))-m.bodyHeight(), 0)
+	m.scroll = min(max(m.scroll+delta, 0), maxScroll)
+}
+
+// resolve applies the choice to the current conflict in the background.
+// Once the last conflict is resolved the result is pushed.
+func (m *ConflictModel) resolve(choice sync.Choice) tea.Cmd {
+	provider := m.app.syncProvider()
+	if provider == nil {
+		return nil
+	}
+	conflict := m.conflicts[m.index]
+	m.resolving = true
+	m.err = ""
+	return func() tea.Msg {
+		result, err := provider.Resolve(conflict, choice)
+		if err != nil || len(result.Conflicts) > 0 {
+			return syncDoneMsg{result: result, err: err}
+		}
+
+		pushed, err := provider.Push()
+		result.Committed = result.Committed || pushed.Committed
+		result.Uploaded += pushed.Uploaded
+		result.Pushed = pushed.Pushed
+		return syncDoneMsg{result: result, err: err}
+	}
+}
+
+// Update handles updates for the conflict view
+func (m *ConflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.resolving || len(m.conflicts) == 0 {
+			return m.app, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			m.scrollBy(-1)
+		case "down", "j":
+			m.scrollBy(1)
+		case "pgup", "ctrl+b":
+			m.scrollBy(-m.bodyHeight())
+		case "pgdown", "ctrl+f", " ":
+			m.scrollBy(m.bodyHeight())
+		case "n", "tab":
+			m.index = (m.index + 1) % len(m.conflicts)
+			m.scroll = 0
+		case "p", "shift+tab":
+			m.index = (m.index + len(m.conflicts) - 1) % len(m.conflicts)
+			m.scroll = 0
+		case "l":
+			return m.app, m.resolve(sync.KeepLocal)
+		case "r":
+			return m.app, m.resolve(sync.KeepRemote)
+		case "b":
+			return m.app, m.resolve(sync.KeepBoth)
+		}
+	}
+	return m.app, nil
+}
+
+// View renders the local and remote versions side by side
+func (m *ConflictModel) View() string {
+	if len(m.conflicts) == 0 {
+		return "No conflicts to resolve"
+	}
+
+	conflict := m.conflicts[m.index]
+	lines := m.paneLines()
+	width := m.paneWidth()
+	height := m.bodyHeight()
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#DC2626")).
+		Bold(true).
+		Padding(0, 1)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	header := headerStyle.Render(fmt.Sprintf("⚠ Sync conflict %d of %d", m.index+1, len(m.conflicts))) +
+		" " + hintStyle.Render(conflict.Path)
+
+	panes := make([]string, 2)
+	for i, title := range []string{"Local (l)", "Remote (r)"} {
+		body := make([]string, 0, height)
+		for j := m.scroll; j < min(m.scroll+height, len(lines[i])); j++ {
+			body = append(body, ansi.Truncate(lines[i][j], width, ""))
+		}
+
+		panes[i] = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#475569")).
+			Padding(0, 1).
+			Width(width + 2).
+			Height(height + 1).
+			Render(titleStyle.Render(title) + "\n" + strings.Join(body, "\n"))
+	}
+
+	var footer string
+	switch {
+	case m.resolving:
+		footer = hintStyle.Render("Resolving...")
+	case m.err != "":
+		footer = lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(m.err)
+	default:
+		footer = hintStyle.Render("l: keep local • r: keep remote • b: keep both • n/p: next/previous • ↑/↓ scroll • esc: later")
+	}
+
+	return ansi.Truncate(header, m.width, "…") + "\n" +
+		lipgloss.JoinHorizontal(lipgloss.Top, panes[0], " ", panes[1]) + "\n" +
+		ansi.Truncate(footer, m.width, "…")
+}
+
+// syncConfigured reports whether the open vault has a sync provider set up
+func (a *App) syncConfigured() bool {
+	if a.config.SyncProvider == config.SyncWebDAV {
+		return a.vault.WebDAVURL != ""
+	}
+	return a.vault.SyncDir != ""
+}
+
+// syncProvider returns the configured sync provider, opening it on first
+// use, or nil when sync isn't set up
+func (a *App) syncProvider() sync.Provider {
+	if a.syncer != nil || !a.syncConfigured() {
+		return a.syncer
+	}
+
+	var provider sync.Provider
+	var err error
+	if a.config.SyncProvider == config.SyncWebDAV {
+		password := a.config.WebDAVPassword
+		if password == "" {
+			password = os.Getenv("TUINOTES_WEBDAV_PASSWORD")
+		}
+		provider, err = sync.NewWebDAV(a.storage, a.vault.WebDAVURL, a.config.WebDAVUser, password)
+	} else {
+		provider, err = sync.NewGit(a.storage, a.vault.SyncDir)
+	}
+	if err != nil {
+		// The next sync tries again
+		slog.Warn("failed to set up sync", "err", err)
+		return nil
+	}
+	a.syncer = provider
+	return provider
+}
+
+// conflictView returns the conflict view, creating it on first use
+func (a *App) conflictView() *ConflictModel {
+	if a.conflicts == nil {
+		a.conflicts = NewConflictModel(a)
+		a.conflicts.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.conflicts
+}
+
+// startSync pulls and pushes the notes in the background
+func (a *App) startSync() tea.Cmd {
+	if !a.syncConfigured() {
+		a.notesList.statusMsg = "Set sync_dir or webdav_url in the config file to sync notes"
+		return nil
+	}
+	if a.syncState == syncRunning {
+		return nil
+	}
+	provider := a.syncProvider()
+	if provider == nil {
+		a.notesList.statusMsg = "Error: couldn't set up sync, check the sync settings in the config file"
+		return nil
+	}
+
+	a.syncState = syncRunning
+	a.notesList.statusMsg = ""
+	return func() tea.Msg {
+		result, err := sync.Run(provider)
+		return syncDoneMsg{result: result, err: err}
+	}
+}
+
+// syncFinished shows the outcome of a sync, or the conflicts it stopped at
+func (a *App) syncFinished(msg syncDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		a.syncState = syncFailed
+		if a.currentView == ViewConflicts {
+			a.conflictView().resolving = false
+			a.conflictView().err = "Error: " + msg.err.Error()
+			return nil
+		}
+		a.notesList.statusMsg = "Sync failed: " + msg.err.Error()
+		return nil
+	}
+
+	if len(msg.result.Conflicts) > 0 {
+		a.syncState = syncConflicted
+		a.conflictView().SetConflicts(msg.result.Conflicts)
+		a.currentView = ViewConflicts
+		return nil
+	}
+
+	a.syncState = syncDone
+	a.syncedAt = time.Now()
+	a.notesList.statusMsg = syncStatus(msg.result)
+	if a.currentView == ViewConflicts {
+		a.currentView = ViewNotesList
+	}
+	// Notes pulled from the remote show up in the list
+	return tea.Batch(a.notesList.Init(), a.loadTags())
+}
+
+// syncStatus summarizes a completed sync for the status line
+func syncStatus(result sync.Result) string {
+	imported := result.Imported
+	if imported.Created == 0 && imported.Updated == 0 && result.Deleted == 0 && !result.Committed && result.Uploaded == 0 {
+		return "Sync complete: already up to date"
+	}
+
+	status := fmt.Sprintf("Sync complete: %d new, %d updated", imported.Created, imported.Updated)
+	if result.Deleted > 0 {
+		status += fmt.Sprintf(", %d deleted", result.Deleted)
+	}
+	switch {
+	case result.Uploaded > 0:
+		status += fmt.Sprintf(", %d uploaded", result.Uploaded)
+	case result.Pushed:
+		status += ", pushed"
+	case result.Committed:
+		status += ", committed (no remote)"
+	}
+	return status
+}
+
+// syncState is the outcome of the last sync, shown in the status bar
+type syncState int
+
+const (
+	syncIdle syncState = iota // no sync since startup
+	syncRunning
+	syncDone
+	syncConflicted
+	syncFailed
+)
+
+// syncIndicator renders the sync state for the status bar, or nothing when
+// sync isn't set up
+func (a *App) syncIndicator() string {
+	if !a.syncConfigured() {
+		return ""
+	}
+
+	label, color := "⇅ not synced", "#64748B"
+	switch a.syncState {
+	case syncRunning:
+		label, color = "⇅ syncing…", "#38BDF8"
+	case syncDone:
+		label, color = "✓ synced "+a.syncedAt.Format("15:04"), "#22C55E"
+	case syncConflicted:
+		label, color = "⚠ sync conflicts", "#F59E0B"
+	case syncFailed:
+		label, color = "✗ sync failed", "#F43F5E"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(label)
+}
+
+// Messages
+
+type syncDoneMsg struct {
+	result sync.Result
+	err    error
+}
@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DuplicatesModel lists the pairs of notes that look like duplicates for
+// review: each pair can be compared side by side, merged into one note or
+// have a note deleted
+type DuplicatesModel struct {
+	app      *App
+	pairs    []models.DuplicatePair
+	skipped  map[[2]int]bool // pairs dismissed as not duplicates, by note IDs
+	cursor   int
+	scanning bool
+	err      error
+	status   string
+	pending  *duplicateAction // action waiting to be confirmed
+	width    int
+	height   int
+}
+
+// duplicateAction is a merge or delete asked for and waiting for y
+type duplicateAction struct {
+	prompt string
+	run    tea.Cmd
+}
+
+// NewDuplicatesModel creates the duplicates review
+func NewDuplicatesModel(app *App) *DuplicatesModel {
+	return &DuplicatesModel{app: app, skipped: map[[2]int]bool{}}
+}
+
+// Init looks for duplicates again
+func (m *DuplicatesModel) Init() tea.Cmd {
+	m.pending = nil
+	m.status = ""
+	return m.scan()
+}
+
+// scan compares the notes in the background
+func (m *DuplicatesModel) scan() tea.Cmd {
+	m.scanning = true
+	return func() tea.Msg {
+		pairs, err := m.app.GetStorage().FindDuplicates()
+		return duplicatesFoundMsg{pairs: pairs, err: err}
+	}
+}
+
+// shown returns the pairs found, less those skipped
+func (m *DuplicatesModel) shown() []models.DuplicatePair {
+	var pairs []models.DuplicatePair
+	for _, pair := range m.pairs {
+		if !m.skipped[[2]int{pair.Older.ID, pair.Newer.ID}] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// Update handles updates for the duplicates review
+func (m *DuplicatesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case duplicatesFoundMsg:
+		m.scanning = false
+		m.pairs = msg.pairs
+		m.err = msg.err
+		m.cursor = min(m.cursor, max(len(m.shown())-1, 0))
+		return m.app, nil
+
+	case duplicateResolvedMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.status = msg.status
+			m.app.notesChanged()
+		}
+		return m.app, m.scan()
+
+	case tea.KeyMsg:
+		if m.pending != nil {
+			action := m.pending
+			m.pending = nil
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m.app, action.run
+			}
+			m.status = "Cancelled"
+			return m.app, nil
+		}
+
+		pairs := m.shown()
+		switch msg.String() {
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, max(len(pairs)-1, 0))
+		case "r":
+			m.status = ""
+			return m.app, m.scan()
+		case "q":
+			return m.app, m.app.SwitchToView(ViewStats)
+		}
+		if len(pairs) == 0 || m.scanning {
+			return m.app, nil
+		}
+
+		pair := pairs[m.cursor]
+		switch msg.String() {
+		case "enter", "c":
+			return m.app, m.app.openCompare(pair.Older, pair.Newer)
+		case "m":
+			m.askMerge(pair.Older, pair.Newer)
+		case "M":
+			m.askMerge(pair.Newer, pair.Older)
+		case "d":
+			m.askDelete(pair.Newer)
+		case "D":
+			m.askDelete(pair.Older)
+		case "s":
+			m.skipped[[2]int{pair.Older.ID, pair.Newer.ID}] = true
+			m.cursor = min(m.cursor, max(len(pairs)-2, 0))
+			m.status = "Skipped"
+		}
+	}
+	return m.app, nil
+}
+
+// askMerge asks to merge drop into keep
+func (m *DuplicatesModel) askMerge(keep, drop *models.Note) {
+	m.pending = &duplicateAction{
+		prompt: fmt.Sprintf("Merge %q into %q and delete it? (y/n)", drop.Title, keep.Title),
+		run: func() tea.Msg {
+			merged, err := m.app.GetStorage().MergeNotes(keep.ID, drop.ID)
+			if err != nil {
+				return duplicateResolvedMsg{err: err}
+			}
+			return duplicateResolvedMsg{status: fmt.Sprintf("Merged %q into %q", drop.Title, merged.Title)}
+		},
+	}
+}
+
+// askDelete asks to delete note
+func (m *DuplicatesModel) askDelete(note *models.Note) {
+	m.pending = &duplicateAction{
+		prompt: fmt.Sprintf("Delete %q? (y/n)", note.Title),
+		run: func() tea.Msg {
+			if err := m.app.GetStorage().DeleteNote(note.ID); err != nil {
+				return duplicateResolvedMsg{err: err}
+			}
+			return duplicateResolvedMsg{status: fmt.Sprintf("Deleted %q", note.Title)}
+		},
+	}
+}
+
+// View renders the duplicates review
+func (m *DuplicatesModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#0D9488")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	metaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E"))
+
+	s := titleStyle.Render("Duplicates") + "\n\n"
+	pairs := m.shown()
+	switch {
+	case m.err != nil:
+		s += errStyle.Render("Error: "+m.err.Error()) + "\n"
+	case m.scanning && len(pairs) == 0:
+		s += hintStyle.Bold(true).Render("Looking for duplicates...") + "\n"
+	case len(pairs) == 0:
+		s += "  " + hintStyle.Italic(true).Render("No likely duplicates found.") + "\n"
+	}
+
+	// Each pair takes two lines; keep the cursor in view on long lists
+	height := max((m.height-10)/2, 3)
+	offset := max(m.cursor-height+1, 0)
+	for i := offset; i < min(offset+height, len(pairs)); i++ {
+		pair := pairs[i]
+		cursor := "  "
+		nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		if i == m.cursor {
+			cursor = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EA580C")).
+				Bold(true).
+				Render("▶ ")
+			nameStyle = nameStyle.Bold(true)
+		}
+
+		titles := nameStyle.Render(pair.Older.Title) + metaStyle.Render("  ⇄  ") + nameStyle.Render(pair.Newer.Title)
+		details := fmt.Sprintf("content %d%% · title %d%% · created %s and %s",
+			percentOf(pair.ContentScore), percentOf(pair.TitleScore),
+			pair.Older.CreatedAt.Format("Jan 2, 2006"), pair.Newer.CreatedAt.Format("Jan 2, 2006"))
+		s += ansi.Truncate("  "+cursor+titles, m.width, "…") + "\n"
+		s += ansi.Truncate("      "+metaStyle.Render(details), m.width, "…") + "\n"
+	}
+
+	s += "\n"
+	switch {
+	case m.pending != nil:
+		s += errStyle.Bold(true).Render(m.pending.prompt) + "\n\n"
+	case m.status != "":
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#4ADE80")).Render(m.status) + "\n\n"
+	case len(pairs) > 0:
+		s += hintStyle.Italic(true).Render("The first note of each pair is the older one.") + "\n\n"
+	}
+	return s + m.renderControls()
+}
+
+// percentOf formats a similarity from 0 to 1 as a whole percentage
+func percentOf(score float64) int {
+	return int(score*100 + 0.5)
+}
+
+// renderControls renders the key hints for the duplicates review
+func (m *DuplicatesModel) renderControls() string {
+	controls := "↑↓: Navigate • Enter: Compare • m/M: Merge into older/newer • d/D: Delete newer/older • s: Skip • r: Rescan • Esc: Back"
+	if m.width < 120 {
+		controls = "Enter: Compare • m/M: Merge • d/D: Delete • s: Skip • r: Rescan • Esc: Back"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render(controls)
+}
+
+// duplicatesView returns the duplicates review, creating it on first use
+func (a *App) duplicatesView() *DuplicatesModel {
+	if a.duplicates == nil {
+		a.duplicates = NewDuplicatesModel(a)
+		a.duplicates.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.duplicates
+}
+
+// Messages
+
+// duplicatesFoundMsg carries the pairs of likely duplicates
+type duplicatesFoundMsg struct {
+	pairs []models.DuplicatePair
+	err   error
+}
+
+// duplicateResolvedMsg reports the outcome of merging or deleting a
+// duplicate
+type duplicateResolvedMsg struct {
+	status string
+	err    error
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxTabTitle is the longest title shown in a tab before truncation
+const maxTabTitle = 20
+
+// editorSize is the size given to editors, leaving a line for the tab bar
+func (a *App) editorSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: a.width, Height: max(a.height-1, 1)}
+}
+
+// newEditor creates an editor for a new tab
+func (a *App) newEditor() *NoteEditorModel {
+	editor := NewNoteEditorModel(a)
+	editor.availableTags = a.tags
+	editor.recentTags = a.recentTags
+	editor.Update(a.editorSize())
+	return editor
+}
+
+// openEditor switches to the tab editing note, opening a new tab when the
+// note isn't open yet. A nil note always opens a new tab for a new note.
+func (a *App) openEditor(note *models.Note) tea.Cmd {
+	if note != nil {
+		for i, editor := range a.editors {
+			if editor.note != nil && editor.note.ID == note.ID {
+				a.activeEditor = i
+				if editor.dirty() {
+					// Keep unsaved work exactly as it was left
+					return nil
+				}
+				return editor.Init(note)
+			}
+		}
+	}
+
+	editor := a.newEditor()
+	a.editors = append(a.editors, editor)
+	a.activeEditor = len(a.editors) - 1
+	return editor.Init(note)
+}
+
+// closeEditor closes the active tab, returning to the list after the last one
+func (a *App) closeEditor() tea.Cmd {
+	if len(a.editors) == 0 {
+		return nil
+	}
+	a.editors = append(a.editors[:a.activeEditor], a.editors[a.activeEditor+1:]...)
+	if len(a.editors) == 0 {
+		a.activeEditor = 0
+		return a.SwitchToView(ViewNotesList)
+	}
+	a.activeEditor = min(a.activeEditor, len(a.editors)-1)
+	return nil
+}
+
+// handleTabKey handles the keys that switch between and close tabs. It
+// reports whether the key was one of them.
+func (a *App) handleTabKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if len(a.editors) == 0 {
+		return nil, false
+	}
+	active := a.editors[a.activeEditor]
+
+	key := msg.String()
+	if key != "alt+w" {
+		active.confirmClose = false
+	}
+
+	switch key {
+	case "alt+]":
+		a.activeEditor = (a.activeEditor + 1) % len(a.editors)
+	case "alt+[":
+		a.activeEditor = (a.activeEditor + len(a.editors) - 1) % len(a.editors)
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		if index := int(key[len(key)-1] - '1'); index < len(a.editors) {
+			a.activeEditor = index
+		}
+	case "alt+w":
+		// Unsaved changes need a second press to be discarded
+		if active.dirty() && !active.confirmClose {
+			active.confirmClose = true
+			return nil, true
+		}
+		return a.closeEditor(), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// renderTabBar renders the open notes as numbered tabs, marking unsaved ones
+func (a *App) renderTabBar() string {
+	activeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1)
+	inactiveStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Background(lipgloss.Color("#1F2937")).
+		Padding(0, 1)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	var tabs []string
+	for i, editor := range a.editors {
+		title := strings.TrimSpace(editor.titleInput.Value())
+		if title == "" {
+			title = "Untitled"
+		}
+		label := fmt.Sprintf("%d %s", i+1, ansi.Truncate(title, maxTabTitle, "…"))
+		if editor.dirty() {
+			label += " •"
+		}
+
+		if i == a.activeEditor {
+			tabs = append(tabs, activeStyle.Render(label))
+		} else {
+			tabs = append(tabs, inactiveStyle.Render(label))
+		}
+	}
+	bar := strings.Join(tabs, " ")
+
+	hint := "alt+[ ]: switch • alt+w: close"
+	if a.editors[a.activeEditor].confirmClose {
+		hint = "Unsaved changes; alt+w again to discard"
+		hintStyle = hintStyle.Foreground(lipgloss.Color("#F43F5E"))
+	}
+	if lipgloss.Width(bar)+lipgloss.Width(hint)+2 <= a.width {
+		bar += "  " + hintStyle.Render(hint)
+	}
+	return ansi.Truncate(bar, a.width, "…")
+}
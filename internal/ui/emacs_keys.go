@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/config"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyPreset is the key preset of text fields chosen in the config
+var keyPreset = config.KeymapDefault
+
+// SetKeymap selects the key preset of text fields, "default" or "emacs".
+// Text fields already move and delete with Ctrl+A, E, K, W and U; the
+// emacs preset also keeps what those delete for Ctrl+Y, as readline does.
+func SetKeymap(preset string) {
+	keyPreset = preset
+}
+
+// emacsKeys reports whether text fields use the emacs preset
+func emacsKeys() bool {
+	return keyPreset == config.KeymapEmacs
+}
+
+// Keys that kill text forward or backward of the cursor
+var (
+	killForwardKeys  = map[string]bool{"ctrl+k": true, "alt+d": true, "alt+delete": true}
+	killBackwardKeys = map[string]bool{"ctrl+u": true, "ctrl+w": true, "alt+backspace": true}
+)
+
+// killBuffer holds the text killed last, shared by every text field
+var killBuffer struct {
+	text      string
+	appending bool // the last key killed text, so another kill adds to it
+}
+
+// killed records the text a key removed from a field. Kills in a row
+// build up one piece of text, as in readline.
+func killed(key, before, after string) {
+	forward, backward := killForwardKeys[key], killBackwardKeys[key]
+	if !forward && !backward {
+		killBuffer.appending = false
+		return
+	}
+	text := removedText(before, after)
+	if text == "" {
+		return
+	}
+	switch {
+	case !killBuffer.appending:
+		killBuffer.text = text
+	case forward:
+		killBuffer.text += text
+	default:
+		killBuffer.text = text + killBuffer.text
+	}
+	killBuffer.appending = true
+}
+
+// removedText returns the text deleted from before to leave after
+func removedText(before, after string) string {
+	b, a := []rune(before), []rune(after)
+	if len(a) >= len(b) {
+		return ""
+	}
+	prefix := 0
+	for prefix < len(a) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return string(b[prefix : len(b)-suffix])
+}
+
+// updateInput passes msg to a text input. With the emacs preset, Ctrl+Y
+// inserts the killed text and kills are recorded. Hidden input such as a
+// passphrase is never recorded.
+func updateInput(input *textinput.Model, msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !emacsKeys() || input.EchoMode != textinput.EchoNormal {
+		var cmd tea.Cmd
+		*input, cmd = input.Update(msg)
+		return cmd
+	}
+
+	if key.String() == "ctrl+y" {
+		killBuffer.appending = false
+		// Text inputs hold one line
+		text := []rune(strings.ReplaceAll(killBuffer.text, "\n", " "))
+		value := []rune(input.Value())
+		pos := input.Position()
+		input.SetValue(string(value[:pos]) + string(text) + string(value[pos:]))
+		input.SetCursor(pos + len(text))
+		return nil
+	}
+
+	before := input.Value()
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	killed(key.String(), before, input.Value())
+	return cmd
+}
+
+// updateTextArea passes msg to a text area, handling kills and Ctrl+Y
+// like updateInput
+func updateTextArea(area *textarea.Model, msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !emacsKeys() {
+		var cmd tea.Cmd
+		*area, cmd = area.Update(msg)
+		return cmd
+	}
+
+	if key.String() == "ctrl+y" {
+		killBuffer.appending = false
+		area.InsertString(killBuffer.text)
+		return nil
+	}
+
+	before := area.Value()
+	var cmd tea.Cmd
+	*area, cmd = area.Update(msg)
+	killed(key.String(), before, area.Value())
+	return cmd
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"markdown-note-taking-app/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAltDKillsWordInTitle(t *testing.T) {
+	app, err := NewApp(config.Vault{Path: filepath.Join(t.TempDir(), "notes.db")}, config.Default())
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	t.Cleanup(func() { app.Close() })
+
+	SetKeymap(config.KeymapEmacs)
+	t.Cleanup(func() { SetKeymap(config.KeymapDefault) })
+
+	editor := NewNoteEditorModel(app)
+	editor.titleInput.SetValue("weekly plan notes")
+	editor.titleInput.SetCursor(len("weekly "))
+
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d"), Alt: true})
+
+	if editor.metadata.visible {
+		t.Error("Expected Alt+D to stay in the title field, not open the dates panel")
+	}
+	if got := editor.titleInput.Value(); got != "weekly  notes" {
+		t.Errorf("Expected the next word killed, got %q", got)
+	}
+	if killBuffer.text != "plan" {
+		t.Errorf("Expected the kill buffer to hold %q, got %q", "plan", killBuffer.text)
+	}
+}
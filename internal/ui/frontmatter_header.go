@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// renderWithFrontmatter renders content with its frontmatter replaced by a
+// metadata header: a one-line summary, or every field when expanded. The
+// line map still points into the original content, header lines to the
+// opening delimiter.
+func renderWithFrontmatter(renderer Renderer, content string, width int, expanded bool) (string, LineMap) {
+	fm, body, ok := utils.ParseFrontmatter(content)
+	if !ok || !strings.HasSuffix(content, body) {
+		return renderer.RenderMarkdown(content, width)
+	}
+	offset := strings.Count(content[:len(content)-len(body)], "\n")
+
+	header := renderFrontmatterHeader(fm, width, expanded)
+	if strings.TrimSpace(body) == "" {
+		return strings.Join(header, "\n"), make(LineMap, len(header))
+	}
+
+	rendered, bodyMap := renderer.RenderMarkdown(body, width)
+	lineMap := make(LineMap, len(header), len(header)+len(bodyMap))
+	for _, src := range bodyMap {
+		lineMap = append(lineMap, src+offset)
+	}
+	return strings.Join(header, "\n") + "\n" + rendered, lineMap
+}
+
+// renderFrontmatterHeader renders the frontmatter fields followed by a blank line
+func renderFrontmatterHeader(fm utils.Frontmatter, width int, expanded bool) []string {
+	markerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#EA580C"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	keys := fm.Keys()
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = strings.Join(fm.List(key), ", ")
+	}
+
+	if !expanded {
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = keyStyle.Render(key+":") + " " + valueStyle.Render(values[i])
+		}
+		summary := markerStyle.Render("▸ ") + strings.Join(fields, valueStyle.Render(" · "))
+		return []string{ansi.Truncate(summary, width, "…"), ""}
+	}
+
+	keyWidth := 0
+	for _, key := range keys {
+		keyWidth = max(keyWidth, lipgloss.Width(key)+1)
+	}
+
+	lines := []string{markerStyle.Render("▾ ") + keyStyle.Render("Frontmatter")}
+	for i, key := range keys {
+		// Values wrap under themselves, past the aligned keys
+		label := "  " + keyStyle.Render(key+":") + strings.Repeat(" ", keyWidth-lipgloss.Width(key+":")+1)
+		wrapped := strings.Split(ansi.Wrap(values[i], max(width-keyWidth-3, 10), ""), "\n")
+		for j, part := range wrapped {
+			if j > 0 {
+				label = strings.Repeat(" ", keyWidth+3)
+			}
+			lines = append(lines, label+valueStyle.Render(part))
+		}
+	}
+	return append(lines, "")
+}
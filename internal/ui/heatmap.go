@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatmapWeeks is how many weeks the activity heatmap covers, a year
+const heatmapWeeks = 53
+
+// heatmapColors shade the heatmap's cells from no activity to the most
+var heatmapColors = []string{"#1E293B", "#14532D", "#15803D", "#22C55E", "#4ADE80"}
+
+// heatmapStart returns the Sunday the heatmap's first column starts on, so
+// the last column holds today
+func heatmapStart(today time.Time, weeks int) time.Time {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	return today.AddDate(0, 0, -int(today.Weekday())-7*(weeks-1))
+}
+
+// heatmapLevel maps a day's activity to one of the heatmapColors,
+// relative to the busiest day
+func heatmapLevel(count, busiest int) int {
+	if count <= 0 || busiest <= 0 {
+		return 0
+	}
+	return min((count*(len(heatmapColors)-1)+busiest-1)/busiest, len(heatmapColors)-1)
+}
+
+// renderHeatmap draws activity as a grid with a column per week and a row
+// per weekday, like a contribution graph. weeks is how many weeks up to
+// today are shown.
+func renderHeatmap(activity []models.ActivityDay, today time.Time, weeks int) string {
+	counts := map[string]int{}
+	busiest, total := 0, 0
+	for _, day := range activity {
+		counts[day.Date] = day.Total()
+		busiest = max(busiest, day.Total())
+		total += day.Total()
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	cells := make([]lipgloss.Style, len(heatmapColors))
+	for i, color := range heatmapColors {
+		cells[i] = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	}
+
+	start := heatmapStart(today, weeks)
+	today = start.AddDate(0, 0, 7*(weeks-1)+int(today.Weekday()))
+
+	// Month names above the first week of each month, unless the previous
+	// name is in the way
+	months := ""
+	for week := 0; week < weeks; week++ {
+		day := start.AddDate(0, 0, 7*week)
+		if (week == 0 || day.Day() <= 7) && len(months) <= 2*week {
+			months += strings.Repeat(" ", 2*week-len(months)) + day.Format("Jan")
+		}
+	}
+	s := "    " + labelStyle.Render(months) + "\n"
+
+	weekdays := []string{"", "Mon", "", "Wed", "", "Fri", ""}
+	for weekday := 0; weekday < 7; weekday++ {
+		s += labelStyle.Render(fmt.Sprintf("%-4s", weekdays[weekday]))
+		for week := 0; week < weeks; week++ {
+			day := start.AddDate(0, 0, 7*week+weekday)
+			if day.After(today) {
+				break
+			}
+			s += cells[heatmapLevel(counts[day.Format("2006-01-02")], busiest)].Render("■") + " "
+		}
+		s += "\n"
+	}
+
+	s += "    " + labelStyle.Render(fmt.Sprintf("%d notes created or edited  •  Less ", total))
+	for _, cell := range cells {
+		s += cell.Render("■") + " "
+	}
+	s += labelStyle.Render("More") + "\n"
+	return s
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHistory is how many places back the navigation history reaches
+const maxHistory = 50
+
+// place is a spot in the navigation history: a view, and for the editor
+// the note open in it, 0 for a new note
+type place struct {
+	view   View
+	noteID int
+}
+
+// here returns the place currently shown
+func (a *App) here() place {
+	p := place{view: a.currentView}
+	if p.view == ViewNoteEditor && len(a.editors) > 0 {
+		if note := a.editors[a.activeEditor].note; note != nil {
+			p.noteID = note.ID
+		}
+	}
+	return p
+}
+
+// visited records leaving from for somewhere else, dropping the places
+// ahead of it
+func (a *App) visited(from place) {
+	if from == a.here() || from.view == ViewUnlock || from.view == ViewVaults {
+		return
+	}
+	a.backStack = append(a.backStack, from)
+	if len(a.backStack) > maxHistory {
+		a.backStack = a.backStack[len(a.backStack)-maxHistory:]
+	}
+	a.forwardStack = nil
+}
+
+// back returns to the previous place, or to the notes list when there's
+// none
+func (a *App) back() tea.Cmd {
+	if len(a.backStack) == 0 {
+		if a.currentView == ViewNotesList {
+			return nil
+		}
+		a.currentView = ViewNotesList
+		return a.notesList.Init()
+	}
+	to := a.backStack[len(a.backStack)-1]
+	a.backStack = a.backStack[:len(a.backStack)-1]
+	a.forwardStack = append(a.forwardStack, a.here())
+	return a.show(to)
+}
+
+// forward returns to the place left by going back
+func (a *App) forward() tea.Cmd {
+	if len(a.forwardStack) == 0 {
+		return nil
+	}
+	to := a.forwardStack[len(a.forwardStack)-1]
+	a.forwardStack = a.forwardStack[:len(a.forwardStack)-1]
+	a.backStack = append(a.backStack, a.here())
+	return a.show(to)
+}
+
+// show shows a place from the history without recording the move. Notes
+// are loaded again, since they may have changed since.
+func (a *App) show(to place) tea.Cmd {
+	if to.view != ViewNoteEditor {
+		return a.showView(to.view)
+	}
+	if to.noteID > 0 {
+		return func() tea.Msg {
+			note, err := a.GetStorage().GetNote(to.noteID)
+			return historyNoteMsg{note: note, err: err}
+		}
+	}
+
+	// A new note is only still there if its tab wasn't closed
+	for i, editor := range a.editors {
+		if editor.note == nil {
+			a.activeEditor = i
+			a.currentView = ViewNoteEditor
+			return nil
+		}
+	}
+	return a.showView(ViewNotesList)
+}
+
+// showNote opens a note loaded to return to it, or the notes list if it
+// was deleted in the meantime
+func (a *App) showNote(msg historyNoteMsg) tea.Cmd {
+	if msg.err != nil {
+		return a.showView(ViewNotesList)
+	}
+	a.currentView = ViewNoteEditor
+	return a.openEditor(msg.note)
+}
+
+// followLink opens the note a link was followed to, so going back
+// returns to the note with the link
+func (a *App) followLink(note *models.Note) tea.Cmd {
+	from := a.here()
+	cmd := a.openEditor(note)
+	a.visited(from)
+	return cmd
+}
+
+// Messages
+
+// historyNoteMsg carries a note being returned to in the history
+type historyNoteMsg struct {
+	note *models.Note
+	err  error
+}
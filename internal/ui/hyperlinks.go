@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"markdown-note-taking-app/internal/config"
+)
+
+// hyperlink wraps text in an OSC 8 escape sequence so supporting terminals
+// make it clickable
+func hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// hyperlinksEnabled resolves the hyperlinks config setting, detecting
+// terminal support from the environment in auto mode
+func hyperlinksEnabled(mode string) bool {
+	switch mode {
+	case config.HyperlinksAlways:
+		return true
+	case config.HyperlinksNever:
+		return false
+	}
+	return terminalSupportsHyperlinks()
+}
+
+// terminalSupportsHyperlinks reports whether the terminal is known to
+// understand OSC 8. Unknown terminals get the plain URL display.
+func terminalSupportsHyperlinks() bool {
+	// Multiplexers swallow or mangle OSC 8 unless specially configured
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return false
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby":
+		return true
+	}
+
+	if os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" {
+		return true
+	}
+
+	// GNOME Terminal and other VTE terminals support OSC 8 since 0.50
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	term := os.Getenv("TERM")
+	for _, name := range []string{"kitty", "alacritty", "foot", "ghostty", "wezterm"} {
+		if strings.Contains(term, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,338 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	_ "image/gif"  // Register the GIF decoder
+	_ "image/jpeg" // Register the JPEG decoder
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"markdown-note-taking-app/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// imageProtocol is a terminal graphics protocol used to draw images
+type imageProtocol int
+
+const (
+	imageNone imageProtocol = iota // Alt-text placeholder only
+	imageKitty
+	imageITerm2
+	imageSixel
+)
+
+// Terminal cells are assumed to be this many pixels when sizing images,
+// which matches common fonts closely enough to keep the aspect ratio
+const (
+	cellPixelWidth  = 10
+	cellPixelHeight = 20
+	maxImageRows    = 20
+	maxImageBytes   = 10 << 20 // Larger files are shown as placeholders
+)
+
+// imageLineRegex matches a markdown image on a line of its own
+var imageLineRegex = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*<?([^)>\s]+)>?(?:\s+"[^"]*")?\s*\)$`)
+
+// imageProtocolFor resolves the images config setting, detecting terminal
+// support from the environment in auto mode
+func imageProtocolFor(mode string) imageProtocol {
+	switch mode {
+	case config.ImagesKitty:
+		return imageKitty
+	case config.ImagesITerm2:
+		return imageITerm2
+	case config.ImagesSixel:
+		return imageSixel
+	case config.ImagesAuto:
+		return detectImageProtocol()
+	}
+	return imageNone
+}
+
+// detectImageProtocol picks a graphics protocol the terminal is known to
+// understand. Unknown terminals get placeholders.
+func detectImageProtocol() imageProtocol {
+	// Multiplexers don't pass graphics through without extra configuration
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return imageNone
+	}
+
+	if os.Getenv("KITTY_WINDOW_ID") != "" || strings.Contains(os.Getenv("TERM"), "kitty") {
+		return imageKitty
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "ghostty", "WezTerm":
+		return imageKitty
+	case "iTerm.app":
+		return imageITerm2
+	}
+
+	term := os.Getenv("TERM")
+	for _, name := range []string{"foot", "mlterm", "contour"} {
+		if strings.Contains(term, name) {
+			return imageSixel
+		}
+	}
+	return imageNone
+}
+
+// parseImageLine reports whether a trimmed line is just an image and returns
+// its alt text and source
+func parseImageLine(trimmed string) (string, string, bool) {
+	match := imageLineRegex.FindStringSubmatch(trimmed)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// renderImage renders a block image, drawing it with the configured
+// graphics protocol when possible and as a placeholder box otherwise
+func (r *nativeRenderer) renderImage(alt, src string) []string {
+	if r.images != imageNone {
+		if lines, err := r.drawImage(src); err == nil {
+			return lines
+		}
+	}
+	return r.imagePlaceholder(alt, src)
+}
+
+// imagePlaceholder draws a box with the image's alt text and source
+func (r *nativeRenderer) imagePlaceholder(alt, src string) []string {
+	label := "Image"
+	if alt != "" {
+		label += ": " + alt
+	}
+
+	inner := min(max(lipgloss.Width(label), lipgloss.Width(src)), max(r.width-4, 6))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#CBD5E1")).Bold(true)
+	srcStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#64748B")).
+		Padding(0, 1)
+
+	content := labelStyle.Render(ansi.Truncate(label, inner, "…")) + "\n" +
+		srcStyle.Render(ansi.Truncate(src, inner, "…"))
+	return strings.Split(box.Render(content), "\n")
+}
+
+// drawImage encodes a local image for the configured protocol. The escape
+// sequence goes on the first line and blank lines reserve the rows the
+// image covers. Encoded images are cached until the file or width changes.
+func (r *nativeRenderer) drawImage(src string) ([]string, error) {
+	path, err := resolveImagePath(src)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.Size() > maxImageBytes {
+		return nil, fmt.Errorf("image %s is too large to display", path)
+	}
+
+	key := fmt.Sprintf("%s|%d|%d|%d", path, info.ModTime().UnixNano(), r.width, r.images)
+	if lines, ok := r.imageCache[key]; ok {
+		return lines, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	cols, rows := imageCells(img.Bounds(), r.width)
+
+	var sequence string
+	switch r.images {
+	case imageKitty:
+		// Kitty only accepts PNG data directly
+		if format != "png" {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				return nil, fmt.Errorf("failed to encode image: %w", err)
+			}
+			data = buf.Bytes()
+		}
+		sequence = kittyImage(imageID(path), data, cols, rows)
+	case imageITerm2:
+		sequence = iterm2Image(data, cols, rows)
+	case imageSixel:
+		sequence = sixelImage(img, cols*cellPixelWidth, rows*cellPixelHeight)
+	}
+
+	lines := make([]string, rows)
+	lines[0] = sequence
+	if r.imageCache == nil {
+		r.imageCache = make(map[string][]string)
+	}
+	r.imageCache[key] = lines
+	return lines, nil
+}
+
+// resolveImagePath turns an image source into a local file path. Relative
+// paths are resolved against the working directory.
+func resolveImagePath(src string) (string, error) {
+	if strings.Contains(src, "://") {
+		return "", fmt.Errorf("remote images are not supported")
+	}
+	if strings.HasPrefix(src, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		src = filepath.Join(homeDir, src[2:])
+	}
+	return filepath.Abs(src)
+}
+
+// imageCells sizes an image in terminal cells, fitting it to the pane width
+// and maxImageRows while keeping its aspect ratio
+func imageCells(bounds image.Rectangle, width int) (int, int) {
+	w, h := max(bounds.Dx(), 1), max(bounds.Dy(), 1)
+
+	cols := min(max(width, 1), (w+cellPixelWidth-1)/cellPixelWidth)
+	rows := (h*cols*cellPixelWidth/w + cellPixelHeight - 1) / cellPixelHeight
+	if rows > maxImageRows {
+		rows = maxImageRows
+		cols = w * rows * cellPixelHeight / (h * cellPixelWidth)
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// imageID derives a stable kitty image ID from the file path so redraws
+// replace the image instead of stacking copies
+func imageID(path string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return h.Sum32()%0xFFFFFF + 1
+}
+
+// kittyImage builds the kitty graphics sequence that places PNG data over
+// cols×rows cells without moving the cursor
+func kittyImage(id uint32, data []byte, cols, rows int) string {
+	const chunkSize = 4096
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+	for i := 0; i < len(encoded); i += chunkSize {
+		end := min(i+chunkSize, len(encoded))
+		more := 0
+		if end < len(encoded) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,i=%d,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", id, cols, rows, more, encoded[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, encoded[i:end])
+		}
+	}
+	return b.String()
+}
+
+// iterm2Image builds the iTerm2 inline image sequence for a file's data
+func iterm2Image(data []byte, cols, rows int) string {
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixelImage scales an image to fit width×height pixels, reduces it to a
+// 256-color palette and encodes it as sixel graphics
+func sixelImage(img image.Image, width, height int) string {
+	bounds := img.Bounds()
+	scale := math.Min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	w := max(int(float64(bounds.Dx())*scale), 1)
+	h := max(int(float64(bounds.Dy())*scale), 1)
+
+	// Nearest-neighbour scaling is plenty for a preview
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/w, bounds.Min.Y+y*bounds.Dy()/h))
+		}
+	}
+
+	paletted := image.NewPaletted(scaled.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), scaled, image.Point{})
+	return encodeSixel(paletted)
+}
+
+// encodeSixel encodes a paletted image as a sixel DCS sequence
+func encodeSixel(img *image.Paletted) string {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for i, c := range img.Palette {
+		red, green, blue, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, red*100/0xFFFF, green*100/0xFFFF, blue*100/0xFFFF)
+	}
+
+	row := make([]byte, w)
+	for y := 0; y < h; y += 6 {
+		// Each color used in this six-pixel band is drawn in its own pass
+		var colors []uint8
+		seen := make(map[uint8]bool)
+		for dy := 0; dy < 6 && y+dy < h; dy++ {
+			for x := 0; x < w; x++ {
+				if c := img.ColorIndexAt(x, y+dy); !seen[c] {
+					seen[c] = true
+					colors = append(colors, c)
+				}
+			}
+		}
+
+		for _, c := range colors {
+			for x := 0; x < w; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && y+dy < h; dy++ {
+					if img.ColorIndexAt(x, y+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = byte(63 + bits)
+			}
+			fmt.Fprintf(&b, "#%d", c)
+			writeSixelRow(&b, row)
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRow writes sixel characters using run-length encoding
+func writeSixelRow(b *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+		i = j
+	}
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inlineTagEditor edits the tags of the note under the cursor without
+// leaving the list. Every change is saved right away.
+type inlineTagEditor struct {
+	active           bool
+	note             *models.Note
+	input            textinput.Model
+	suggestions      []string
+	suggestionCursor int
+}
+
+// newInlineTagEditor creates a closed inline tag editor
+func newInlineTagEditor() inlineTagEditor {
+	ti := textinput.New()
+	ti.Placeholder = "add tag"
+	ti.CharLimit = 50
+	ti.Width = 20
+	return inlineTagEditor{input: ti}
+}
+
+// openTagEditor starts editing the tags of the note under the cursor
+func (m *NotesListModel) openTagEditor() tea.Cmd {
+	if len(m.filteredNotes) == 0 {
+		return nil
+	}
+	m.statusMsg = ""
+	m.tagEditor.active = true
+	m.tagEditor.note = m.filteredNotes[m.cursor]
+	m.tagEditor.input.SetValue("")
+	m.tagEditor.suggestions = nil
+	return m.tagEditor.input.Focus()
+}
+
+// closeTagEditor closes the inline tag editor and reloads the list so
+// sorting and filters reflect the new tags
+func (m *NotesListModel) closeTagEditor() tea.Cmd {
+	m.tagEditor.active = false
+	m.tagEditor.note = nil
+	m.tagEditor.input.Blur()
+	return tea.Batch(m.loadNotes(), m.app.loadTags())
+}
+
+// handleTagEditorKey handles keys while the inline tag editor is open
+func (m *NotesListModel) handleTagEditorKey(msg tea.KeyMsg) tea.Cmd {
+	e := &m.tagEditor
+
+	switch msg.String() {
+	case "esc":
+		return m.closeTagEditor()
+	case "up", "shift+tab":
+		if e.suggestionCursor > 0 {
+			e.suggestionCursor--
+		}
+		return nil
+	case "down", "tab":
+		if e.suggestionCursor < len(e.suggestions)-1 {
+			e.suggestionCursor++
+		}
+		return nil
+	case "enter", " ":
+		name := strings.TrimSpace(e.input.Value())
+		if len(e.suggestions) > 0 && msg.String() == "enter" {
+			name = e.suggestions[e.suggestionCursor]
+		}
+		if name == "" {
+			if msg.String() == "enter" {
+				// Enter on an empty input finishes editing
+				return m.closeTagEditor()
+			}
+			return nil
+		}
+		return m.addInlineTag(name)
+	case "backspace":
+		if e.input.Value() == "" {
+			return m.removeLastInlineTag()
+		}
+	}
+
+	cmd := updateInput(&e.input, msg)
+	e.suggestions = suggestTags(m.app.tags, e.input.Value(), e.note.Tags)
+	e.suggestionCursor = 0
+	return cmd
+}
+
+// addInlineTag tags the edited note and saves it immediately
+func (m *NotesListModel) addInlineTag(name string) tea.Cmd {
+	e := &m.tagEditor
+	e.input.SetValue("")
+	e.suggestions = nil
+
+	for _, tag := range e.note.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return nil // Tag already added
+		}
+	}
+	e.note.Tags = append(e.note.Tags, models.Tag{Name: name})
+
+	noteID := e.note.ID
+	return func() tea.Msg {
+		err := m.app.GetStorage().AddTagToNote(noteID, name)
+		return inlineTagSavedMsg{err: err}
+	}
+}
+
+// removeLastInlineTag removes the edited note's last tag and saves it immediately
+func (m *NotesListModel) removeLastInlineTag() tea.Cmd {
+	e := &m.tagEditor
+	if len(e.note.Tags) == 0 {
+		return nil
+	}
+	name := e.note.Tags[len(e.note.Tags)-1].Name
+	e.note.Tags = e.note.Tags[:len(e.note.Tags)-1]
+
+	noteID := e.note.ID
+	return func() tea.Msg {
+		err := m.app.GetStorage().RemoveTagFromNotes([]int{noteID}, name)
+		return inlineTagSavedMsg{err: err}
+	}
+}
+
+// renderTagEditor renders the inline tag editor in place of the status line
+func (m *NotesListModel) renderTagEditor() string {
+	e := &m.tagEditor
+	accent := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Bold(true)
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	s := accent.Render("Tags:")
+	for _, tag := range e.note.Tags {
+		s += lipgloss.NewStyle().Foreground(tagColor(tag)).Render(" #" + tag.Name)
+	}
+	s += " " + e.input.View()
+
+	if len(e.suggestions) > 0 {
+		shown := e.suggestions[:min(len(e.suggestions), 5)]
+		parts := make([]string, len(shown))
+		for i, name := range shown {
+			if i == e.suggestionCursor {
+				parts[i] = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#0F172A")).
+					Background(lipgloss.Color("#F59E0B")).
+					Render(name)
+			} else {
+				parts[i] = hint.Render(name)
+			}
+		}
+		s += "\n" + hint.Render("↑↓ ") + strings.Join(parts, hint.Render(" · "))
+	} else {
+		s += "\n" + hint.Render("enter/space: add • backspace: remove last • esc: done")
+	}
+	return s
+}
+
+// Messages
+type inlineTagSavedMsg struct {
+	err error
+}
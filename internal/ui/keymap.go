@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyHelp documents one key binding
+type keyHelp struct {
+	keys  string // keys as shown to the user, alternatives separated by ", "
+	short string // description on narrow terminals; empty hides the binding there
+	long  string // full description
+}
+
+// keySection groups the bindings of one view
+type keySection struct {
+	icon  string
+	title string
+	keys  []keyHelp
+}
+
+// keyReference lists every key binding by view. The help screen and the
+// exported cheat sheet are both generated from it, so add new bindings here.
+var keyReference = []keySection{
+	{"📝", "Notes List", []keyHelp{
+		{"n", "New note", "Create new note"},
+		{"Ctrl+V", "Note from clipboard", "Create a note from the clipboard, converting HTML to markdown"},
+		{"e, Enter", "Edit note", "Edit selected note"},
+		{"d", "Delete note", "Delete selected note"},
+		{"m", "Note actions", "Menu of every action for the note under the cursor"},
+		{"Ctrl+S", "Search mode", "Toggle search mode"},
+		{"t", "Task dashboard", "Open task dashboard"},
+		{"C", "Calendar", "Open the calendar of notes, daily notes and due tasks"},
+		{"T", "Edit tags inline", "Edit tags of the note under the cursor"},
+		{"s", "Vault health", "Open stats and vault health"},
+		{"L", "Cycle layout", "Cycle compact, detailed, card and table layouts"},
+		{"p", "Toggle preview", "Preview the selected note (beside the list on wide terminals)"},
+		{"b", "Two-pane layout", "Toggle the list + preview layout on large terminals"},
+		{"Tab", "", "Focus the list or the preview pane"},
+		{"B", "Sidebar", "Show or hide the notebooks, tags and views sidebar on large terminals"},
+		{"Ctrl+B", "Focus sidebar", "Move focus to the sidebar (j/k, Enter to show notes, a/d to save or remove a search), again to go back"},
+		{"]", "Open notes", "Return to the notes open in tabs"},
+		{"Ctrl+G", "Sync", "Sync notes with the git repository or WebDAV server"},
+		{"v", "Switch vault", "Open another vault (database) from the config file"},
+		{"S", "Snippets", "Add, edit and delete text snippets"},
+		{"#", "Tags", "Set tag colors and add or delete other spellings of tags"},
+		{"Ctrl+Z", "Undo", "Undo the last delete, retag or move within 10 seconds"},
+		{"P", "Pin/unpin note", "Pin the note to the top of the list, or unpin it"},
+		{"Shift+↑, ↓", "Reorder pinned", "Move a pinned note up or down among the pinned notes"},
+		{"c", "Color label", "Cycle the color label of the note (compares two selected notes)"},
+		{"1-5", "Sort table column", "Table layout: sort by column, again to reverse"},
+		{"o", "Secondary sort", "Cycle secondary sort (id/title/created)"},
+		{"↑, k", "Move up", "Move cursor up"},
+		{"↓, j", "Move down", "Move cursor down"},
+		{"PgUp, PgDn", "Page up/down", "Scroll a page up or down"},
+		{"gg, G", "First/last note", "Jump to first or last note"},
+		{"A", "Load all", "Load the remaining notes or search results now rather than page by page"},
+		{"?", "Help", "Show this help"},
+	}},
+	{"☰", "Note Actions", []keyHelp{
+		{"↑, ↓", "Move", "Move between actions"},
+		{"Enter", "Run action", "Run the highlighted action"},
+		{"e, p, y", "Edit/preview/copy", "Edit, preview or duplicate the note"},
+		{"t, m, x, d", "Tags/move/export/del", "Edit tags, move to a notebook, export or delete (asks to confirm)"},
+		{"P", "Pin/unpin", "Pin the note to the top of the list, or unpin it"},
+		{"h", "Export as HTML", "Write the note as a themed HTML page to ~/tuinotes-export"},
+		{"s, S", "Split at headings", "Make a note of each H1/H2 section, with tags (S: each links back)"},
+		{"Esc", "Close menu", "Close the menu"},
+	}},
+	{"☑", "Selection", []keyHelp{
+		{"Space", "Select note", "Select or deselect note"},
+		{"V", "Select range", "Select from last selected note to cursor"},
+		{"d", "Delete selected", "Delete selected notes (asks to confirm)"},
+		{"+, -", "Tag/untag selected", "Add or remove a tag on selected notes"},
+		{"m", "Move to notebook", "Move selected notes to a notebook"},
+		{"x", "Export selected", "Export selected notes as markdown files"},
+		{"c", "Compare two selected", "Compare the two selected notes side by side"},
+		{"Esc", "Clear selection", "Clear selection"},
+	}},
+	{"🔍", "Search Mode", []keyHelp{
+		{"Ctrl+S", "Enter/exit search", "Enter/exit search mode"},
+		{"Type", "Live search", "Search notes as you type"},
+		{"tag:work", "Filter by tag", "Only notes tagged work or work/... (-tag: excludes)"},
+		{"title:x", "", "Title contains x"},
+		{"notebook:x", "", "Only notes in notebook x"},
+		{"is:untagged", "", "Notes without tags (is:duplicate, is:pinned)"},
+		{"color:green", "", "Notes with a color label (color:none for none)"},
+		{"-word", "Exclude word", "Exclude notes containing word"},
+		{"created:>", "", "Date filters: created:, updated:, before:, after:"},
+		{"Enter", "Confirm search", "Confirm search"},
+		{"Esc", "Cancel search", "Cancel search"},
+		{"Backspace", "Delete char", "Delete search character"},
+	}},
+	{"✏️", "Note Editor", []keyHelp{
+		{"Tab", "Switch fields", "Switch between title/content/tags (indents the list item on the cursor line)"},
+		{"Shift+Tab", "Outdent item", "Move the list item on the cursor line one level out"},
+		{"Enter", "Continue list", "In a list item, start the next item; on an empty item, end the list"},
+		{"trigger Tab", "Expand snippet", "Replace a snippet trigger before the cursor, such as /date, with its text"},
+		{"Ctrl+S", "Save note", "Save note (an empty title is taken from the first heading or line)"},
+		{"Ctrl+S", "Suggested tags", "Space picks tags suggested from the note, Enter adds them, Esc saves without"},
+		{"Alt+T", "Suggest title", "Set the title from the first heading or line of the content"},
+		{"Ctrl+P", "Toggle preview", "Toggle preview"},
+		{"Ctrl+G", "Frontmatter", "Expand/collapse frontmatter in the preview"},
+		{"Alt+V", "Focus preview", "Move focus to the split-pane preview to scroll it (j/k, PgUp/PgDn), again to go back"},
+		{"Alt+S", "Scroll sync", "Keep the preview aligned with the cursor line"},
+		{"Alt+O", "Outline", "Jump to a heading (←→: collapse/expand sections)"},
+		{"Alt+Z", "Zen mode", "Write with only the content on screen, the cursor line centered (Esc: leave)"},
+		{"Ctrl+T", "Toggle task", "Toggle task checkbox on current line"},
+		{"Ctrl+R", "Renumber list", "Renumber ordered list on current line"},
+		{"Alt+M", "Edit dates", "Edit created/updated dates (applied on save)"},
+		{"Alt+A", "Attachments", "List, open, link and attach files (a: attach, Enter: open, i: insert link)"},
+		{"Alt+P", "Properties", "Show notebook, pin, archive, color and custom properties beside the editor"},
+		{"Alt+L", "Links", "List the note's links; Enter opens a web link in the browser or follows a link to a note, Backspace goes back"},
+		{"Alt+[, Alt+]", "Switch tabs", "Previous / next open note"},
+		{"Alt+1-9", "", "Jump to open note by number"},
+		{"Alt+W", "Close tab", "Close the current tab"},
+		{"Esc", "Back", "Leave the editor for the previous view or note"},
+		{"Enter", "New line / Confirm", "New line (in content) / Confirm tag"},
+		{"Space", "Separate tags", "Separate tags"},
+	}},
+	{"🏷️", "Tag Management", []keyHelp{
+		{"Tab to Tags", "Switch to tags", "Switch to tag input field"},
+		{"Type", "Add tags", "Add new tags (auto-suggests existing)"},
+		{"Space/Enter", "Confirm tag", "Confirm tag addition"},
+		{"↓ then 1-5", "Toggle recent tag", "From the empty input, move to the recently used tags (1-5, Space or Enter: add or remove, Esc: back)"},
+		{"Backspace", "Remove last tag", "Remove the last tag when the input is empty"},
+		{"←/→", "Select tag", "Move over the tags (Del: remove, Enter: rename, Esc: back to input)"},
+		{"↑/↓", "Navigate suggestions", "Navigate tag suggestions"},
+		{"Esc", "Close suggestions", "Close tag suggestions"},
+	}},
+	{"☑", "Tasks", []keyHelp{
+		{"Space, x", "Toggle task", "Toggle selected task"},
+		{"Enter", "Open note", "Open the note containing the task"},
+		{"t", "Filter tag", "Cycle tag filter"},
+		{"c", "Hide done", "Show/hide completed tasks"},
+	}},
+	{"▦", "Calendar", []keyHelp{
+		{"←, →, ↑, ↓", "Move day", "Move to the previous or next day or week (h/l/k/j too)"},
+		{"[, ]", "Month", "Go to the previous or next month (PgUp/PgDn too)"},
+		{"t", "Today", "Go back to today"},
+		{"Enter", "Daily note", "Open the day's daily note, the note titled with its date, creating it if needed"},
+		{"Tab", "Notes of the day", "Move between the month and the notes created, edited or due that day"},
+	}},
+	{"⧉", "Duplicates", []keyHelp{
+		{"Enter", "Compare", "Show the pair of notes side by side"},
+		{"m, M", "Merge", "Merge the newer note into the older one, or the older into the newer, and delete it"},
+		{"d, D", "Delete", "Delete the newer or the older note of the pair"},
+		{"s", "Skip", "Hide a pair that isn't a duplicate until the app restarts"},
+		{"r", "Rescan", "Look for duplicates again"},
+	}},
+	{"⇆", "Compare", []keyHelp{
+		{"↑, ↓", "Scroll both", "Scroll both notes together"},
+		{"r", "Rendered/source", "Switch between the rendered notes and their markdown source"},
+		{"x", "Swap panes", "Swap the left and right notes"},
+	}},
+	{"⚠", "Sync Conflicts", []keyHelp{
+		{"l, r", "Keep local/remote", "Resolve the conflict with the local or remote version"},
+		{"b", "Keep both", "Keep the local note with the remote body appended"},
+		{"n, p", "Next/previous", "Go to the next or previous conflict"},
+		{"Esc", "", "Resolve later; Ctrl+G in the list returns here"},
+	}},
+	{"▣", "Vaults", []keyHelp{
+		{"↑, ↓", "Move", "Move between vaults"},
+		{"Enter", "Open vault", "Close the open vault and open the selected one"},
+	}},
+	{"✂", "Snippets", []keyHelp{
+		{"a", "Add snippet", "Add a snippet; {{date}}, {{time}}, {{weekday}} and {{cursor}} are filled in"},
+		{"Enter", "Edit snippet", "Edit the trigger and text (Tab: switch field, Ctrl+S: save)"},
+		{"d d", "Delete snippet", "Delete the snippet under the cursor"},
+	}},
+	{"#", "Tags", []keyHelp{
+		{"c, C", "Tag color", "Cycle the color of the tag under the cursor"},
+		{"a", "Add alias", "Make a spelling stand for a tag (Tab: switch field, Enter: save)"},
+		{"d d", "Delete alias", "Delete the alias under the cursor"},
+	}},
+	{"📊", "Vault Health", []keyHelp{
+		{"u, s, d", "Untagged/stale/dupes", "Show untagged, stale or duplicate notes"},
+		{"D", "Review duplicates", "Find notes with similar titles or content to compare, merge or delete"},
+		{"o", "Prune unused tags", "Delete tags no note uses (asks to confirm)"},
+		{"c", "Compact database", "Compact the database file"},
+		{"i", "Check integrity", "Check the database file and the references between its tables"},
+	}},
+	{"⚙️", "General", []keyHelp{
+		{"Esc", "Back", "Go back to the previous view or note (from any view but the list)"},
+		{"Ctrl+O, Alt+←", "Back", "Go back to the previous view or note"},
+		{"Ctrl+I, Alt+→", "Forward", "Go forward again after going back (Ctrl+I is Tab, so only outside the list and editor)"},
+		{"Backspace", "", "Go back from help, tasks, stats, duplicates and compare"},
+		{"Ctrl+A, Ctrl+E", "", "Text fields: go to the start or end of the line"},
+		{"Ctrl+K, Ctrl+U", "", "Text fields: delete to the end or start of the line"},
+		{"Ctrl+W", "", "Text fields: delete the word before the cursor"},
+		{"Ctrl+Y", "", "Emacs keymap: paste the text last deleted with Ctrl+K, Ctrl+U, Ctrl+W or Alt+D"},
+		{"q, Ctrl+C", "Quit application", "Quit application"},
+	}},
+}
+
+// KeyReferenceMarkdown renders the key bindings as a markdown cheat sheet
+func KeyReferenceMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# TuiNotes keyboard shortcuts\n")
+	for _, section := range keyReference {
+		fmt.Fprintf(&b, "\n## %s\n\n| Key | Action |\n| --- | --- |\n", section.title)
+		for _, binding := range section.keys {
+			keys := strings.Split(binding.keys, ", ")
+			for i, key := range keys {
+				keys[i] = "`" + strings.ReplaceAll(key, "|", `\|`) + "`"
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", strings.Join(keys, ", "), strings.ReplaceAll(binding.long, "|", `\|`))
+		}
+	}
+	return b.String()
+}
+
+// KeyReferenceText renders the key bindings as plain text for printing
+func KeyReferenceText() string {
+	keyWidth := 0
+	for _, section := range keyReference {
+		for _, binding := range section.keys {
+			keyWidth = max(keyWidth, lipgloss.Width(binding.keys))
+		}
+	}
+
+	var b strings.Builder
+	title := "TuiNotes keyboard shortcuts"
+	b.WriteString(title + "\n" + strings.Repeat("=", len(title)) + "\n")
+	for _, section := range keyReference {
+		fmt.Fprintf(&b, "\n%s\n%s\n", section.title, strings.Repeat("-", lipgloss.Width(section.title)))
+		for _, binding := range section.keys {
+			padding := strings.Repeat(" ", keyWidth-lipgloss.Width(binding.keys))
+			fmt.Fprintf(&b, "  %s%s  %s\n", binding.keys, padding, binding.long)
+		}
+	}
+	return b.String()
+}
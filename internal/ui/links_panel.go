@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// linksPanel lists the web links in the note, numbered as in the preview,
+// followed by the links to other notes. Web links open in the browser and
+// note links open the note.
+type linksPanel struct {
+	visible bool
+	urls    []string // web links
+	notes   []string // targets of links to other notes
+	cursor  int
+	err     string
+}
+
+// openLinksPanel shows the panel with the links in the note as it's being
+// edited
+func (m *NoteEditorModel) openLinksPanel() {
+	content := m.contentInput.Value()
+	m.links = linksPanel{
+		visible: true,
+		urls:    webLinks(content, m.app.GetConfig().AutolinkRules()),
+		notes:   utils.NoteLinks(content),
+	}
+}
+
+// webLinks returns the web links in content in the order the preview
+// numbers them: frontmatter isn't rendered, and autolinks are added first
+func webLinks(content string, rules []utils.AutolinkRule) []string {
+	if _, body, ok := utils.ParseFrontmatter(content); ok {
+		content = body
+	}
+	_, urls := utils.NumberWebLinks(utils.Autolink(content, rules))
+	return urls
+}
+
+// handleLinksKey handles keys while the links panel is open
+func (m *NoteEditorModel) handleLinksKey(msg tea.KeyMsg) tea.Cmd {
+	panel := &m.links
+	switch key := msg.String(); key {
+	case "esc", "alt+l":
+		panel.visible = false
+	case "up", "k":
+		panel.cursor = max(panel.cursor-1, 0)
+	case "down", "j":
+		panel.cursor = max(min(panel.cursor+1, len(panel.urls)+len(panel.notes)-1), 0)
+	case "enter", "o":
+		if i := panel.cursor - len(panel.urls); i >= 0 && i < len(panel.notes) {
+			return m.followNoteLink(panel.notes[i])
+		}
+		panel.open(panel.cursor)
+	case "backspace":
+		panel.visible = false
+		return m.app.back()
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		number, _ := strconv.Atoi(key)
+		if number <= len(panel.urls) {
+			panel.cursor = number - 1
+			panel.open(number - 1)
+		}
+	}
+	return nil
+}
+
+// followNoteLink opens the note a link points at
+func (m *NoteEditorModel) followNoteLink(target string) tea.Cmd {
+	return func() tea.Msg {
+		note, err := m.app.GetStorage().ResolveNoteLink(target)
+		return noteLinkMsg{note: note, err: err}
+	}
+}
+
+// open opens the link at index i in the default browser
+func (p *linksPanel) open(i int) {
+	if i < 0 || i >= len(p.urls) {
+		return
+	}
+	if err := openFile(p.urls[i]); err != nil {
+		p.err = err.Error()
+		return
+	}
+	p.err = ""
+}
+
+// renderLinksPanel renders the links as a centered dialog
+func (m *NoteEditorModel) renderLinksPanel() string {
+	panel := m.links
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+	width := max(min(m.width-16, 80), 20)
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Links") + "\n\n"
+
+	if len(panel.urls)+len(panel.notes) == 0 {
+		s += hintStyle.Render("No links in this note") + "\n"
+	}
+	row := func(i int, marker, target string) {
+		line := fmt.Sprintf(" %-*s", width, ansi.Truncate(target, width, "…"))
+		if i == panel.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s += numberStyle.Render(fmt.Sprintf("%4s", marker)) + line + "\n"
+	}
+	for i, url := range panel.urls {
+		row(i, fmt.Sprintf("[%d]", i+1), url)
+	}
+	if len(panel.urls) > 0 && len(panel.notes) > 0 {
+		s += "\n"
+	}
+	for i, target := range panel.notes {
+		row(len(panel.urls)+i, "→", target)
+	}
+
+	if panel.err != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(panel.err) + "\n"
+	}
+	hint := "Enter: Open • 1-9: Open by number • Esc: Close"
+	if len(m.app.backStack) > 0 {
+		hint = "Enter: Open • 1-9: Open by number • Backspace: Back • Esc: Close"
+	}
+	s += "\n" + hintStyle.Render(hint)
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Render(s)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// Messages
+
+// noteLinkMsg carries the note a link points at, or why it can't be opened
+type noteLinkMsg struct {
+	note *models.Note
+	err  error
+}
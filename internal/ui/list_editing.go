@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/utils"
+)
+
+// continueList handles Enter on a list item in the content: the new line
+// starts with the next marker, or an empty item is ended by removing its
+// marker. Reports false, changing nothing, when the cursor isn't after the
+// marker of a list item.
+func (m *NoteEditorModel) continueList() bool {
+	ta := &m.contentInput
+	lines := strings.Split(ta.Value(), "\n")
+	row := ta.Line()
+	if row >= len(lines) {
+		return false
+	}
+	line := lines[row]
+	col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset
+
+	prefix, empty, ok := utils.ListContinuation(line)
+	if !ok || col < len([]rune(line[:utils.ListMarkerEnd(line)])) {
+		return false
+	}
+
+	if empty && strings.TrimSpace(string([]rune(line)[min(col, len([]rune(line))):])) == "" {
+		lines[row] = ""
+		setTextareaValue(ta, strings.Join(lines, "\n"), row, 0)
+	} else {
+		ta.InsertString("\n" + prefix)
+	}
+	m.contentChanged()
+	return true
+}
+
+// indentListItem moves the list item on the cursor line one level in, or
+// out when outdent is set. Reports false when the line isn't a list item.
+func (m *NoteEditorModel) indentListItem(outdent bool) bool {
+	ta := &m.contentInput
+	lines := strings.Split(ta.Value(), "\n")
+	row := ta.Line()
+	if row >= len(lines) {
+		return false
+	}
+	if _, ok := utils.ParseListItem(lines[row]); !ok {
+		return false
+	}
+
+	line, delta := utils.IndentListItem(lines[row], outdent)
+	if delta != 0 {
+		col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset
+		lines[row] = line
+		setTextareaValue(ta, strings.Join(lines, "\n"), row, max(col+delta, 0))
+		m.contentChanged()
+	}
+	return true
+}
+
+// contentChanged brings the textarea's scroll position and the preview up
+// to date after the content was changed other than by typing
+func (m *NoteEditorModel) contentChanged() {
+	// The textarea scrolls to the cursor when it updates
+	m.contentInput, _ = m.contentInput.Update(nil)
+	if m.splitPane {
+		m.UpdatePreview()
+		m.syncPreviewScroll()
+	}
+}
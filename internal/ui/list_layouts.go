@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/ui/theme"
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// listLayout renders notes in one of the notes list layouts
+type listLayout interface {
+	// rowHeight is the number of lines one rendered note takes
+	rowHeight() int
+	// render renders the visible notes to fit in width columns. cursor is
+	// the index of the note under the cursor within notes.
+	render(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time) string
+}
+
+// listLayouts maps layout names from the config to their renderers
+var listLayouts = map[string]listLayout{
+	config.LayoutCompact:  compactLayout{},
+	config.LayoutDetailed: detailedLayout{},
+	config.LayoutCard:     cardLayout{},
+	config.LayoutTable:    tableLayout{},
+}
+
+// listLayoutOrder is the order layouts are cycled through
+var listLayoutOrder = []string{
+	config.LayoutCompact,
+	config.LayoutDetailed,
+	config.LayoutCard,
+	config.LayoutTable,
+}
+
+// renderRows renders notes one below the other using renderNote, marking the
+// note under the cursor. Multi-line notes only carry the marker on their
+// first line.
+func renderRows(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time,
+	renderNote func(m *NotesListModel, note *models.Note, isCursor bool, width int, now time.Time) string) string {
+	s := ""
+	for i, note := range notes {
+		marker := "  "
+		if i == cursor {
+			marker = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EA580C")).
+				Bold(true).
+				Render("▶ ")
+		}
+
+		for j, line := range strings.Split(renderNote(m, note, i == cursor, width, now), "\n") {
+			if j > 0 {
+				marker = "  "
+			}
+			s += marker + line + "\n"
+		}
+	}
+	return s
+}
+
+// rowStyles holds the styles for one note row, which depend on whether the
+// note is under the cursor or selected for a bulk operation
+type rowStyles struct {
+	base  lipgloss.Style
+	title lipgloss.Style
+	meta  lipgloss.Style
+	tag   lipgloss.Style
+	// colorTags draws tags in their own colors rather than the tag style's
+	colorTags bool
+	// accent colors the card border
+	accent lipgloss.Color
+}
+
+// noteRowStyles picks the row colors for a note
+func (m *NotesListModel) noteRowStyles(note *models.Note, isCursor bool) rowStyles {
+	// Colors for the cursor row, rows picked for bulk operations and the rest
+	bg, fg, dim, tagColor, accent := "#1F2937", "#F1F5F9", "#64748B", "#38BDF8", "#334155"
+	switch {
+	case isCursor:
+		bg, fg, dim, tagColor, accent = "#EA580C", "#0F172A", "#431407", "#0F172A", "#EA580C"
+	case m.selected[note.ID]:
+		bg, fg, dim, tagColor, accent = "#78350F", "#FCD34D", "#D97706", "#FDE68A", "#D97706"
+	}
+
+	base := lipgloss.NewStyle().Background(lipgloss.Color(bg))
+	return rowStyles{
+		base:      base,
+		title:     base.Foreground(lipgloss.Color(fg)).Bold(isCursor),
+		meta:      base.Foreground(lipgloss.Color(dim)),
+		tag:       base.Foreground(lipgloss.Color(tagColor)),
+		colorTags: !isCursor && !m.selected[note.ID],
+		accent:    lipgloss.Color(accent),
+	}
+}
+
+// renderTagBadges renders up to maxTags "#tag" badges followed by a "+N"
+// marker for the rest. Returns the rendered badges and their width.
+func renderTagBadges(tags []models.Tag, maxTags int, styles rowStyles) (string, int) {
+	out := ""
+	width := 0
+	for i, tag := range tags {
+		if maxTags == 0 {
+			break
+		}
+		if i == maxTags {
+			more := fmt.Sprintf(" +%d", len(tags)-maxTags)
+			out += styles.meta.Render(more)
+			width += lipgloss.Width(more)
+			break
+		}
+		badge := " #" + tag.Name
+		style := styles.tag
+		if styles.colorTags {
+			style = style.Foreground(tagColor(tag))
+		}
+		out += style.Render(badge)
+		width += lipgloss.Width(badge)
+	}
+	return out, width
+}
+
+// noteTitle returns the title shown for a note, marked when selected or
+// pinned
+func (m *NotesListModel) noteTitle(note *models.Note) string {
+	title := note.Title
+	if note.Pinned {
+		title = "⚑ " + title
+	}
+	if m.selected[note.ID] {
+		return "✓ " + title
+	}
+	return title
+}
+
+// spreadLine renders left and right parts with the gap between them filled
+// so the line spans width columns
+func spreadLine(left, right string, width int, styles rowStyles) string {
+	gap := max(width-lipgloss.Width(left)-lipgloss.Width(right), 1)
+	return left + styles.base.Render(strings.Repeat(" ", gap)) + right
+}
+
+// compactLayout renders each note on a single line
+type compactLayout struct{}
+
+func (compactLayout) rowHeight() int { return 1 }
+
+func (l compactLayout) render(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time) string {
+	return renderRows(m, notes, cursor, width, now, l.renderNote)
+}
+
+// renderNote renders a note's title followed by its tags, word count and
+// last edit time. Small terminals only get the edit time.
+func (compactLayout) renderNote(m *NotesListModel, note *models.Note, isCursor bool, width int, now time.Time) string {
+	styles := m.noteRowStyles(note, isCursor)
+
+	// Metadata segments, most detailed on large terminals
+	edited := utils.RelativeTime(note.UpdatedAt, now)
+	maxTags := 0
+	var details []string
+	switch theme.NewResponsive(m.width, m.height).GetBreakpoint() {
+	case theme.BreakpointSmall:
+		details = []string{edited}
+	case theme.BreakpointMedium:
+		maxTags = 2
+		details = []string{fmt.Sprintf("%dw", noteWords(note)), edited}
+	default:
+		maxTags = 3
+		details = []string{fmt.Sprintf("%d words", noteWords(note)), "edited " + edited}
+	}
+
+	meta, metaWidth := renderTagBadges(note.Tags, maxTags, styles)
+	detailText := " · " + strings.Join(details, " · ")
+	if metaWidth == 0 {
+		detailText = " " + strings.Join(details, " · ")
+	}
+	meta += styles.meta.Render(detailText + " ")
+	metaWidth += lipgloss.Width(detailText + " ")
+
+	// The title gets whatever room the metadata leaves
+	titleWidth := max(min(m.maxTitleLength(), width-metaWidth-3), 10)
+	title := ansi.Truncate(m.noteTitle(note), titleWidth, "...")
+
+	return colorMarker(note) + spreadLine(styles.title.Render(" "+title), meta, width-1, styles)
+}
+
+// detailedLayout renders the title and tags on one line and an excerpt with
+// dates below, with a blank line between notes
+type detailedLayout struct{}
+
+func (detailedLayout) rowHeight() int { return 3 }
+
+func (l detailedLayout) render(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time) string {
+	return renderRows(m, notes, cursor, width, now, l.renderNote)
+}
+
+// renderNote renders a note as a title line and an excerpt line
+func (detailedLayout) renderNote(m *NotesListModel, note *models.Note, isCursor bool, width int, now time.Time) string {
+	styles := m.noteRowStyles(note, isCursor)
+	inner := width - 1
+
+	maxTags := 3
+	if theme.NewResponsive(m.width, m.height).GetBreakpoint() == theme.BreakpointSmall {
+		maxTags = 1
+	}
+	tags, tagsWidth := renderTagBadges(note.Tags, maxTags, styles)
+	tags += styles.base.Render(" ")
+	title := ansi.Truncate(m.noteTitle(note), max(inner-tagsWidth-3, 10), "...")
+	first := spreadLine(styles.title.Render(" "+title), tags, inner, styles)
+
+	date := fmt.Sprintf(" %s · %d words ", utils.RelativeTime(note.UpdatedAt, now), noteWords(note))
+	excerpt := noteExcerpt(note, max(inner-lipgloss.Width(date)-3, 0))
+	if excerpt == "" {
+		excerpt = "No content"
+	}
+	second := spreadLine(styles.meta.Render(" "+excerpt), styles.meta.Render(date), inner, styles)
+
+	return colorMarker(note) + first + "\n  " + second + "\n"
+}
+
+// cardLayout renders each note in a bordered card
+type cardLayout struct{}
+
+func (cardLayout) rowHeight() int { return 5 }
+
+func (l cardLayout) render(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time) string {
+	return renderRows(m, notes, cursor, width, now, l.renderNote)
+}
+
+// renderNote renders a note as a card with its title, excerpt and details
+func (cardLayout) renderNote(m *NotesListModel, note *models.Note, isCursor bool, width int, now time.Time) string {
+	styles := m.noteRowStyles(note, isCursor)
+	inner := width - 4 // border and padding
+
+	tags, tagsWidth := renderTagBadges(note.Tags, 3, styles)
+	title := ansi.Truncate(m.noteTitle(note), max(inner-tagsWidth-1, 10), "...")
+	titleLine := spreadLine(styles.title.Render(title), tags, inner, styles)
+
+	// Color labels color the card border, except under the cursor
+	accent := styles.accent
+	if color, ok := colorLabel(note.Color); ok && !isCursor {
+		accent = color
+	}
+
+	excerpt := noteExcerpt(note, inner)
+	if excerpt == "" {
+		excerpt = "No content"
+	}
+	excerptLine := spreadLine(styles.meta.Render(excerpt), "", inner, styles)
+
+	details := []string{
+		fmt.Sprintf("%d words", noteWords(note)),
+		"created " + note.CreatedAt.Format("Jan 2, 2006"),
+		"edited " + utils.RelativeTime(note.UpdatedAt, now),
+	}
+	if note.Notebook != "" {
+		details = append([]string{"📓 " + note.Notebook}, details...)
+	}
+	detailLine := spreadLine(styles.meta.Render(ansi.Truncate(strings.Join(details, " · "), inner, "...")), "", inner, styles)
+
+	card := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accent).
+		Background(styles.base.GetBackground()).
+		Padding(0, 1).
+		Render(titleLine + "\n" + excerptLine + "\n" + detailLine)
+	return card
+}
+
+// layout returns the active list layout
+func (m *NotesListModel) layout() listLayout {
+	if layout, ok := listLayouts[m.app.GetConfig().ListLayout]; ok {
+		return layout
+	}
+	return compactLayout{}
+}
+
+// cycleLayout switches to the next list layout and remembers it in the config
+func (m *NotesListModel) cycleLayout() tea.Cmd {
+	cfg := m.app.GetConfig()
+	next := listLayoutOrder[0]
+	for i, name := range listLayoutOrder {
+		if name == cfg.ListLayout {
+			next = listLayoutOrder[(i+1)%len(listLayoutOrder)]
+			break
+		}
+	}
+	cfg.ListLayout = next
+	m.statusMsg = "Layout: " + next
+	return m.app.saveConfig()
+}
+
+// tableColumns lists the table layout columns with the key that sorts by each
+var tableColumns = []struct {
+	key   string
+	title string
+	field models.SortField
+}{
+	{"1", "Title", models.SortByTitle},
+	{"2", "Updated", models.SortByUpdated},
+	{"3", "Created", models.SortByCreated},
+	{"4", "Tags", models.SortByTags},
+	{"5", "Words", models.SortByWords},
+}
+
+// tableSortField returns the sort field for a table column key
+func tableSortField(key string) (models.SortField, bool) {
+	for _, col := range tableColumns {
+		if col.key == key {
+			return col.field, true
+		}
+	}
+	return "", false
+}
+
+// tableLayout renders notes as a table with sortable columns
+type tableLayout struct{}
+
+func (tableLayout) rowHeight() int { return 1 }
+
+// render renders the visible notes with a bubbles table. The list keeps its
+// own cursor and scrolling, so the table only ever holds the visible rows.
+func (tableLayout) render(m *NotesListModel, notes []*models.Note, cursor, width int, now time.Time) string {
+	width += 2 // no cursor marker column
+
+	// Fixed columns are dropped on narrow terminals to leave room for titles
+	widths := map[models.SortField]int{
+		models.SortByUpdated: 11,
+		models.SortByCreated: 13,
+		models.SortByTags:    16,
+		models.SortByWords:   9,
+	}
+	switch theme.NewResponsive(m.width, m.height).GetBreakpoint() {
+	case theme.BreakpointSmall:
+		widths[models.SortByCreated] = 0
+		widths[models.SortByTags] = 0
+	case theme.BreakpointMedium:
+		widths[models.SortByCreated] = 0
+	}
+	titleWidth := width - 2 // cell padding
+	for _, w := range widths {
+		if w > 0 {
+			titleWidth -= w + 2
+		}
+	}
+	widths[models.SortByTitle] = max(titleWidth, 10)
+
+	columns := make([]table.Column, len(tableColumns))
+	for i, col := range tableColumns {
+		title := col.key + " " + col.title
+		if m.sortBy == col.field {
+			// Titles sort ascending by default, everything else descending
+			ascending := (col.field == models.SortByTitle) != m.sortReverse
+			if ascending {
+				title += " ▲"
+			} else {
+				title += " ▼"
+			}
+		}
+		columns[i] = table.Column{Title: title, Width: widths[col.field]}
+	}
+
+	rows := make([]table.Row, len(notes))
+	for i, note := range notes {
+		tags := make([]string, len(note.Tags))
+		for j, tag := range note.Tags {
+			tags[j] = "#" + tag.Name
+		}
+		rows[i] = table.Row{
+			m.noteTitle(note),
+			utils.RelativeTime(note.UpdatedAt, now),
+			note.CreatedAt.Format("Jan 2, 2006"),
+			strings.Join(tags, " "),
+			fmt.Sprintf("%d", noteWords(note)),
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithHeight(len(rows)+1),
+		table.WithWidth(width),
+		table.WithStyles(table.Styles{
+			Header: lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F59E0B")).
+				Bold(true).
+				Padding(0, 1),
+			Cell: lipgloss.NewStyle().Padding(0, 1),
+			Selected: lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#0F172A")).
+				Background(lipgloss.Color("#EA580C")).
+				Bold(true),
+		}),
+	)
+	t.SetCursor(cursor)
+	return t.View() + "\n"
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"log/slog"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pagePrefetch is how close the cursor gets to the last loaded note before
+// the next page of notes loads
+const pagePrefetch = 20
+
+// pageLimit returns how many notes a reload of the list fetches: a page of
+// the configured size, or as many as were already paged in so the cursor
+// keeps its place. 0 fetches every note.
+func (m *NotesListModel) pageLimit(configured, loaded int) int {
+	limit := m.limit(configured)
+	if limit == 0 {
+		return 0
+	}
+	return max(limit, loaded)
+}
+
+// loadMore fetches the page of notes after the last one loaded when the
+// cursor nears the end of the list and more notes match. Pages continue
+// from the last note's place in the sort order rather than an offset, so
+// notes added or removed meanwhile don't shift them.
+func (m *NotesListModel) loadMore() tea.Cmd {
+	notes := m.filteredNotes
+	if m.loadingMore || m.searching || len(notes) == 0 || len(notes) >= m.resultTotal() ||
+		m.cursor < len(notes)-pagePrefetch {
+		return nil
+	}
+
+	var filter models.NoteFilter
+	if m.searchQuery != "" {
+		filter = utils.ParseQuery(m.searchQuery)
+		filter.Limit = m.limit(m.app.GetConfig().SearchLimit)
+	} else {
+		filter.Limit = m.limit(m.app.GetConfig().ListLimit)
+		filter.PinnedFirst = true
+	}
+	if filter.Limit == 0 {
+		return nil
+	}
+	filter.SortBy = m.sortBy
+	filter.SecondarySort = m.secondarySort
+	filter.Reverse = m.sortReverse
+	filter.After = notes[len(notes)-1].ID
+
+	m.loadingMore = true
+	msg := notesPageMsg{seq: m.searchSeq, query: m.searchQuery, after: filter.After}
+	return func() tea.Msg {
+		msg.notes, msg.err = m.app.GetStorage().GetAllSummaries(filter)
+		return msg
+	}
+}
+
+// addPage appends a page of notes to the list or the search results,
+// unless the list changed since it was asked for
+func (m *NotesListModel) addPage(msg notesPageMsg) {
+	m.loadingMore = false
+	notes := m.filteredNotes
+	if msg.seq != m.searchSeq || msg.query != m.searchQuery || len(notes) == 0 || notes[len(notes)-1].ID != msg.after {
+		return
+	}
+	if msg.err != nil {
+		slog.Warn("failed to load more notes", "err", msg.err)
+		m.statusMsg = "Error: " + msg.err.Error()
+		return
+	}
+
+	m.filteredNotes = append(m.filteredNotes, msg.notes...)
+	if m.searchQuery != "" {
+		if len(msg.notes) == 0 {
+			// Notes were deleted since they were counted
+			m.searchTotal = len(m.filteredNotes)
+		}
+		return
+	}
+	m.allNotes = append(m.allNotes, msg.notes...)
+	if len(msg.notes) == 0 {
+		m.allTotal = len(m.allNotes)
+	}
+}
+
+// Messages
+
+// notesPageMsg carries the page of notes after the note with ID after, for
+// the search query at sequence number seq
+type notesPageMsg struct {
+	seq   int
+	query string
+	after int
+	notes []*models.Note
+	err   error
+}
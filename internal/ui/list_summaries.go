@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"log/slog"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The list loads note summaries, which leave out the content, so large
+// vaults start quickly. Whatever needs the content loads the whole note
+// first: the editor, the previews, comparing, exporting and deleting.
+
+// loadedNote is the whole note under the cursor, loaded for the previews
+type loadedNote struct {
+	note    *models.Note
+	loading *models.Note // summary the note was last asked for, not asked again after failing
+}
+
+// noteWords returns the word count of a note or summary
+func noteWords(note *models.Note) int {
+	if note.Summary {
+		return note.WordCount
+	}
+	return utils.WordCount(note.Content)
+}
+
+// noteExcerpt returns the opening text of a note or summary in at most
+// maxRunes runes
+func noteExcerpt(note *models.Note, maxRunes int) string {
+	if note.Summary {
+		return utils.ShortenExcerpt(note.Excerpt, maxRunes)
+	}
+	return utils.Excerpt(note.Content, maxRunes)
+}
+
+// cursorNote returns the whole note under the cursor, or nil while it's
+// still loading
+func (m *NotesListModel) cursorNote() *models.Note {
+	if len(m.filteredNotes) == 0 {
+		return nil
+	}
+	note := m.filteredNotes[m.cursor]
+	if !note.Summary {
+		return note
+	}
+	if whole := m.whole.note; whole != nil && whole.ID == note.ID && whole.UpdatedAt.Equal(note.UpdatedAt) {
+		return whole
+	}
+	return nil
+}
+
+// loadCursorNote loads the whole note under the cursor when a preview
+// shows it
+func (m *NotesListModel) loadCursorNote() tea.Cmd {
+	if len(m.filteredNotes) == 0 || !m.peek.visible && !m.browserActive() || m.cursorNote() != nil {
+		return nil
+	}
+	summary := m.filteredNotes[m.cursor]
+	if m.whole.loading == summary {
+		return nil
+	}
+	m.whole.loading = summary
+	return func() tea.Msg {
+		notes, err := m.app.GetStorage().LoadContent([]*models.Note{summary})
+		if err != nil {
+			return cursorNoteMsg{summary: summary, err: err}
+		}
+		return cursorNoteMsg{summary: summary, note: notes[0]}
+	}
+}
+
+// setCursorNote keeps a note loaded for the previews
+func (m *NotesListModel) setCursorNote(msg cursorNoteMsg) {
+	if msg.err != nil {
+		slog.Warn("failed to load note", "id", msg.summary.ID, "err", msg.err)
+		m.statusMsg = "Error: " + msg.err.Error()
+		return
+	}
+	m.whole.note = msg.note
+}
+
+// compareNotes loads two notes whole and shows them side by side
+func (m *NotesListModel) compareNotes(left, right *models.Note) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := m.app.GetStorage().LoadContent([]*models.Note{left, right})
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		return compareNotesMsg{left: notes[0], right: notes[1]}
+	}
+}
+
+// openNote loads a note listed as a summary and opens it in the editor
+func (a *App) openNote(summary *models.Note) tea.Cmd {
+	return func() tea.Msg {
+		note, err := a.GetStorage().GetNote(summary.ID)
+		return requestedNoteMsg{note: note, err: err}
+	}
+}
+
+// Messages
+
+// cursorNoteMsg carries the whole note of the summary under the cursor
+type cursorNoteMsg struct {
+	summary *models.Note
+	note    *models.Note
+	err     error
+}
+
+// compareNotesMsg carries two notes loaded whole to compare
+type compareNotesMsg struct {
+	left, right *models.Note
+}
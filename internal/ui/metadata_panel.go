@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// metadataTimeLayout is the format used to edit note timestamps
+const metadataTimeLayout = "2006-01-02 15:04"
+
+// metadataPanel lets the user correct a note's created and updated times
+type metadataPanel struct {
+	visible bool
+	inputs  []textinput.Model // 0 = created, 1 = updated
+	focus   int
+	err     string
+}
+
+// newMetadataPanel creates the timestamp editing panel
+func newMetadataPanel() metadataPanel {
+	inputs := make([]textinput.Model, 2)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = metadataTimeLayout
+		inputs[i].CharLimit = len(metadataTimeLayout)
+		inputs[i].Width = len(metadataTimeLayout) + 1
+	}
+	return metadataPanel{inputs: inputs}
+}
+
+// openMetadataPanel shows the panel prefilled with the note's current times
+func (m *NoteEditorModel) openMetadataPanel() tea.Cmd {
+	created, updated := time.Now(), time.Now()
+	if m.pendingCreated != nil {
+		created, updated = *m.pendingCreated, *m.pendingUpdated
+	} else if m.note != nil {
+		created, updated = m.note.CreatedAt, m.note.UpdatedAt
+	}
+
+	m.metadata.visible = true
+	m.metadata.err = ""
+	m.metadata.focus = 0
+	m.metadata.inputs[0].SetValue(created.Local().Format(metadataTimeLayout))
+	m.metadata.inputs[1].SetValue(updated.Local().Format(metadataTimeLayout))
+	m.metadata.inputs[1].Blur()
+	return m.metadata.inputs[0].Focus()
+}
+
+// handleMetadataKey handles keys while the metadata panel is open
+func (m *NoteEditorModel) handleMetadataKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "alt+m":
+		m.metadata.visible = false
+		return nil
+	case "tab", "shift+tab", "up", "down":
+		m.metadata.inputs[m.metadata.focus].Blur()
+		m.metadata.focus = 1 - m.metadata.focus
+		return m.metadata.inputs[m.metadata.focus].Focus()
+	case "enter":
+		m.applyMetadata()
+		return nil
+	}
+
+	return updateInput(&m.metadata.inputs[m.metadata.focus], msg)
+}
+
+// applyMetadata validates the entered times and keeps them for the next save
+func (m *NoteEditorModel) applyMetadata() {
+	created, err := time.ParseInLocation(metadataTimeLayout, m.metadata.inputs[0].Value(), time.Local)
+	if err != nil {
+		m.metadata.err = "Created: expected " + metadataTimeLayout
+		return
+	}
+	updated, err := time.ParseInLocation(metadataTimeLayout, m.metadata.inputs[1].Value(), time.Local)
+	if err != nil {
+		m.metadata.err = "Updated: expected " + metadataTimeLayout
+		return
+	}
+	if updated.Before(created) {
+		m.metadata.err = "Updated can't be earlier than created"
+		return
+	}
+
+	m.pendingCreated = &created
+	m.pendingUpdated = &updated
+	m.metadata.visible = false
+}
+
+// renderMetadataPanel renders the timestamp editing panel as a centered dialog
+func (m *NoteEditorModel) renderMetadataPanel() string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Width(10)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Note Metadata") + "\n\n"
+
+	labels := []string{"Created:", "Updated:"}
+	for i, input := range m.metadata.inputs {
+		s += labelStyle.Render(labels[i]) + input.View() + "\n"
+	}
+
+	if m.metadata.err != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(m.metadata.err) + "\n"
+	}
+
+	s += "\n" + hintStyle.Render(fmt.Sprintf("Format %s • Tab: Switch • Enter: Apply on save • Esc: Close", metadataTimeLayout))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Render(s)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,615 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"markdown-note-taking-app/internal/ui/theme"
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// bulletGlyphs are the bullet markers used for each list nesting depth
+var bulletGlyphs = []string{"•", "◦", "▪", "▫"}
+
+// blockquoteBarColors color the quote bars by nesting depth
+var blockquoteBarColors = []lipgloss.Color{
+	lipgloss.Color("#64748B"),
+	lipgloss.Color("#38BDF8"),
+	lipgloss.Color("#C084FC"),
+}
+
+// nativeRenderer is the built-in line-based markdown renderer
+type nativeRenderer struct {
+	width      int
+	hyperlinks bool          // emit OSC 8 hyperlinks instead of bracketed URLs
+	images     imageProtocol // how block images are drawn
+
+	imageCache map[string][]string // encoded images by file, mtime and width
+
+	// Per-render state used for spacing decisions
+	atTop       bool  // true until the first non-blank line is rendered
+	prevBlank   bool  // true when the last rendered line was blank
+	listIndents []int // indent widths of the enclosing list items
+	listNumbers []int // running ordered-list number per depth, -1 when unset
+	listHangs   []int // rendered column where each enclosing item's text starts
+
+	footnoteNumbers map[string]int // display number of each footnote label
+	footnoteKey     string         // footnoteNumbers as text, for the line cache
+
+	// Output of the source lines of this render and the last one
+	lineCache     map[string]renderedLine
+	lastLineCache map[string]renderedLine
+}
+
+// newNativeRenderer creates the built-in renderer
+func newNativeRenderer() *nativeRenderer {
+	return &nativeRenderer{width: 80}
+}
+
+// RenderMarkdown renders markdown line by line, recording the source line of every output line
+func (r *nativeRenderer) RenderMarkdown(content string, width int) (string, LineMap) {
+	r.width = width
+
+	// For now, use the enhanced native markdown processing
+	// This is more stable and provides better terminal formatting
+	lines := strings.Split(content, "\n")
+	var renderedLines []string
+	var lineMap LineMap
+	r.atTop = true
+	r.prevBlank = false
+	r.endList()
+
+	// Footnote definitions are pulled out of the text and listed at the end
+	footnotes := utils.ParseFootnotes(content)
+	r.footnoteNumbers = make(map[string]int, len(footnotes))
+	definitionLines := make(map[int]bool)
+	for _, note := range footnotes {
+		if _, ok := r.footnoteNumbers[note.Label]; !ok {
+			r.footnoteNumbers[note.Label] = note.Number
+		}
+		for line := note.StartLine; line <= note.EndLine; line++ {
+			definitionLines[line] = true
+		}
+	}
+	r.startLineCache()
+
+	afterDefinition := false
+	for i, line := range lines {
+		if definitionLines[i] {
+			afterDefinition = true
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// Don't leave a double gap where definitions were removed
+			if afterDefinition && r.prevBlank {
+				continue
+			}
+			renderedLines = append(renderedLines, "")
+			lineMap = append(lineMap, i)
+			r.prevBlank = true
+			continue
+		}
+
+		afterDefinition = false
+
+		// Process each line with enhanced markdown formatting
+		processedLines := r.renderLine(line)
+		renderedLines = append(renderedLines, processedLines...)
+		for range processedLines {
+			lineMap = append(lineMap, i)
+		}
+		if len(processedLines) > 0 {
+			r.atTop = false
+			r.prevBlank = processedLines[len(processedLines)-1] == ""
+		}
+	}
+
+	if len(footnotes) > 0 {
+		footnoteLines, sources := r.renderFootnotes(footnotes)
+		renderedLines = append(renderedLines, footnoteLines...)
+		lineMap = append(lineMap, sources...)
+	}
+
+	return strings.Join(renderedLines, "\n"), lineMap
+}
+
+// processEnhancedLine processes a line with inline formatting
+func (r *nativeRenderer) processEnhancedLine(line string) []string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return []string{""}
+	}
+
+	// Handle headings
+	if strings.HasPrefix(trimmed, "#") {
+		return r.processHeading(trimmed)
+	}
+
+	// Handle thematic breaks before lists so "* * *" isn't read as a bullet
+	if isThematicBreak(trimmed) {
+		r.endList()
+		return []string{r.styleThematicBreak()}
+	}
+
+	// Images on a line of their own render as a block
+	if alt, src, ok := parseImageLine(trimmed); ok {
+		r.endList()
+		return r.renderImage(alt, src)
+	}
+
+	// Handle task list items before regular lists
+	if task, ok := utils.ParseTaskLine(line); ok {
+		depth := r.listDepth(utils.IndentWidth(task.Indent))
+		box, text := r.taskParts(task)
+		return r.wrapListText(listIndent(depth)+box, depth, depth*2+lipgloss.Width(box), text)
+	}
+
+	// Handle lists, keeping their nesting depth
+	if item, ok := utils.ParseListItem(line); ok {
+		depth := r.listDepth(utils.IndentWidth(item.Indent))
+		return r.styleListItem(item, depth)
+	}
+
+	// Handle blockquotes, including nested ones (>> or > >)
+	if strings.HasPrefix(trimmed, ">") {
+		r.endList()
+		return r.styleBlockquote(trimmed)
+	}
+
+	// Indented lines under a list item continue the deepest item they're
+	// indented past, and so does an unindented line right after an item
+	if len(r.listIndents) > 0 {
+		indent := utils.IndentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+		if indent > 0 || !r.prevBlank {
+			depth := len(r.listIndents) - 1
+			for indent > 0 && depth > 0 && indent <= r.listIndents[depth] {
+				depth--
+			}
+			hang := r.listHangs[depth]
+			return r.wrapListText(strings.Repeat(" ", hang), depth, hang, r.processInlineFormatting(trimmed))
+		}
+	}
+
+	// Any other line ends the current list
+	r.endList()
+
+	// Regular paragraph with inline formatting, wrapped to the pane
+	return r.wrapHanging("", "", r.processInlineFormatting(trimmed))
+}
+
+// isThematicBreak reports whether a trimmed line is a thematic break (---, ***, * * *)
+func isThematicBreak(trimmed string) bool {
+	compact := strings.ReplaceAll(trimmed, " ", "")
+	if len(compact) < 3 {
+		return false
+	}
+	for _, marker := range []string{"-", "*", "_"} {
+		if strings.Trim(compact, marker) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// endList forgets the enclosing list items
+func (r *nativeRenderer) endList() {
+	r.listIndents = nil
+	r.listNumbers = nil
+	r.listHangs = nil
+}
+
+// listDepth returns the nesting depth of a list item from its indent width.
+// Indents are tracked relative to earlier items so both 2- and 4-space
+// indentation styles nest one level per step.
+func (r *nativeRenderer) listDepth(indent int) int {
+	for len(r.listIndents) > 0 && r.listIndents[len(r.listIndents)-1] > indent {
+		r.listIndents = r.listIndents[:len(r.listIndents)-1]
+		r.listNumbers = r.listNumbers[:len(r.listNumbers)-1]
+		r.listHangs = r.listHangs[:len(r.listHangs)-1]
+	}
+	if len(r.listIndents) == 0 || r.listIndents[len(r.listIndents)-1] < indent {
+		r.listIndents = append(r.listIndents, indent)
+		r.listNumbers = append(r.listNumbers, -1)
+		r.listHangs = append(r.listHangs, len(r.listIndents)*2)
+	}
+	return len(r.listIndents) - 1
+}
+
+// nextListNumber returns the display number for an ordered item. Like
+// CommonMark, only the first item's number matters; later items count up.
+func (r *nativeRenderer) nextListNumber(item utils.ListItem, depth int) int {
+	if depth >= len(r.listNumbers) {
+		return item.Number
+	}
+	if r.listNumbers[depth] < 0 {
+		r.listNumbers[depth] = item.Number
+	} else {
+		r.listNumbers[depth]++
+	}
+	return r.listNumbers[depth]
+}
+
+// listIndent returns the rendered indentation for a list depth
+func listIndent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// wrapListText wraps list item text to the preview width. The first line
+// starts with prefix (indent and marker); later lines hang at the column
+// where the text starts so wrapped text lines up under it. The hang is also
+// remembered for continuation lines of the item at depth.
+func (r *nativeRenderer) wrapListText(prefix string, depth, hang int, text string) []string {
+	if depth < len(r.listHangs) {
+		r.listHangs[depth] = hang
+	}
+	return r.wrapHanging(prefix, strings.Repeat(" ", hang), text)
+}
+
+// wrapHanging soft-wraps text to the preview width. The first line starts
+// with prefix and later lines with padding; both must be the same width.
+func (r *nativeRenderer) wrapHanging(prefix, padding, text string) []string {
+	lines := r.wrap(text, lipgloss.Width(prefix))
+	for i := range lines {
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = padding + lines[i]
+		}
+	}
+	return lines
+}
+
+// wrap soft-wraps text to the preview width left after used columns,
+// breaking words that are too long to fit on a line of their own
+func (r *nativeRenderer) wrap(text string, used int) []string {
+	return strings.Split(ansi.Wrap(text, max(r.width-used, 10), ""), "\n")
+}
+
+// processInlineFormatting handles inline markdown elements
+func (r *nativeRenderer) processInlineFormatting(text string) string {
+	// Process inline code spans first
+	text = r.processInlineCode(text)
+
+	// Process bold text
+	text = r.processBoldText(text)
+
+	// Process italic text
+	text = r.processItalicText(text)
+
+	// Process links
+	text = r.processLinks(text)
+
+	// Footnote references become superscript numbers
+	text = r.processFootnoteRefs(text)
+
+	// Apply base style
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+	return style.Render(text)
+}
+
+// processInlineCode handles `code` spans
+func (r *nativeRenderer) processInlineCode(text string) string {
+	// Simple regex-like approach for inline code
+	result := text
+	for {
+		start := strings.Index(result, "`")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(result[start+1:], "`")
+		if end == -1 {
+			break
+		}
+		end = start + 1 + end
+
+		codeContent := result[start+1 : end]
+		style := lipgloss.NewStyle().
+			Background(lipgloss.Color("#374151")).
+			Foreground(lipgloss.Color("#10B981"))
+
+		result = result[:start] + style.Render(codeContent) + result[end+1:]
+	}
+	return result
+}
+
+// processBoldText handles **bold** text
+func (r *nativeRenderer) processBoldText(text string) string {
+	result := text
+	for {
+		start := strings.Index(result, "**")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(result[start+2:], "**")
+		if end == -1 {
+			break
+		}
+		end = start + 2 + end
+
+		boldContent := result[start+2 : end]
+		style := lipgloss.NewStyle().Bold(true)
+
+		result = result[:start] + style.Render(boldContent) + result[end+2:]
+	}
+	return result
+}
+
+// processItalicText handles *italic* text
+func (r *nativeRenderer) processItalicText(text string) string {
+	result := text
+	for {
+		start := strings.Index(result, "*")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(result[start+1:], "*")
+		if end == -1 {
+			break
+		}
+		end = start + 1 + end
+
+		// Skip if this is actually bold (already processed)
+		if start > 0 && result[start-1] == '*' {
+			start++
+			continue
+		}
+		if end < len(result)-1 && result[end+1] == '*' {
+			continue
+		}
+
+		italicContent := result[start+1 : end]
+		style := lipgloss.NewStyle().Italic(true)
+
+		result = result[:start] + style.Render(italicContent) + result[end+1:]
+	}
+	return result
+}
+
+// processLinks handles [text](url) links
+func (r *nativeRenderer) processLinks(text string) string {
+	result := text
+	from := 0
+	for {
+		start := strings.Index(result[from:], "[")
+		if start == -1 {
+			break
+		}
+		start += from
+		mid := strings.Index(result[start+1:], "]")
+		if mid == -1 {
+			break
+		}
+		mid = start + 1 + mid
+
+		// Brackets that aren't followed by a URL are plain text
+		if mid+1 >= len(result) || result[mid+1] != '(' {
+			from = start + 1
+			continue
+		}
+		end := strings.Index(result[mid+2:], ")")
+		if end == -1 {
+			break
+		}
+		end = mid + 2 + end
+
+		linkText := result[start+1 : mid]
+		linkURL := result[mid+2 : end]
+
+		// Images inside a line of text show their alt text
+		if start > 0 && result[start-1] == '!' {
+			image := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#C084FC")).
+				Render("[image: " + linkText + "]")
+			result = result[:start-1] + image + result[end+1:]
+			from = start - 1 + len(image)
+			continue
+		}
+
+		style := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#38BDF8")).
+			Underline(true)
+
+		var link string
+		if r.hyperlinks {
+			// Clickable link; the URL is carried by the escape sequence
+			link = hyperlink(linkURL, style.Render(linkText))
+		} else {
+			link = style.Render(linkText) + lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#64748B")).Render(" ["+linkURL+"]")
+		}
+
+		result = result[:start] + link + result[end+1:]
+		from = start + len(link)
+	}
+	return result
+}
+
+// processFootnoteRefs replaces [^label] references to defined footnotes
+// with superscript numbers
+func (r *nativeRenderer) processFootnoteRefs(text string) string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#38BDF8"))
+	return utils.ReplaceFootnoteRefs(text, func(label string) (string, bool) {
+		number, ok := r.footnoteNumbers[label]
+		if !ok {
+			return "", false
+		}
+		return style.Render(utils.Superscript(number)), true
+	})
+}
+
+// renderFootnotes renders the numbered footnote section shown below the
+// note, along with the source line of each rendered line
+func (r *nativeRenderer) renderFootnotes(footnotes []utils.Footnote) ([]string, []int) {
+	ruleStyle := lipgloss.NewStyle().Foreground(theme.SectionSeparatorColor)
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#38BDF8"))
+
+	var lines []string
+	var sources []int
+	if !r.prevBlank {
+		lines = append(lines, "")
+		sources = append(sources, footnotes[0].StartLine)
+	}
+	lines = append(lines, ruleStyle.Render(strings.Repeat(theme.SectionSeparator, min(20, r.ruleWidth()))))
+	sources = append(sources, footnotes[0].StartLine)
+
+	// Numbers are right-aligned so the texts line up
+	numberWidth := len(strconv.Itoa(len(footnotes))) + 1
+	for _, note := range footnotes {
+		marker := fmt.Sprintf("%*s ", numberWidth, strconv.Itoa(note.Number)+".")
+		wrapped := r.wrapHanging(numberStyle.Render(marker), strings.Repeat(" ", len(marker)),
+			r.processInlineFormatting(note.Text))
+		lines = append(lines, wrapped...)
+		for range wrapped {
+			sources = append(sources, note.StartLine)
+		}
+	}
+	return lines, sources
+}
+
+// styleThematicBreak styles thematic breaks
+func (r *nativeRenderer) styleThematicBreak() string {
+	style := lipgloss.NewStyle().Foreground(theme.SectionSeparatorColor)
+	return style.Render(strings.Repeat(theme.SectionSeparator, r.ruleWidth()))
+}
+
+// processHeading processes heading lines
+func (r *nativeRenderer) processHeading(line string) []string {
+	level := 0
+	for i, char := range line {
+		if char == '#' {
+			level = i + 1
+		} else {
+			break
+		}
+	}
+
+	text := strings.TrimSpace(line[level:])
+
+	var color string
+	switch level {
+	case 1:
+		color = "#38BDF8" // Bright blue
+	case 2:
+		color = "#4ADE80" // Bright green
+	case 3:
+		color = "#F59E0B" // Bright yellow
+	default:
+		color = "#C084FC" // Bright purple
+	}
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
+	decoration := theme.HeadingDecorationFor(level)
+
+	var lines []string
+
+	// Separate sections with spacing, except at the top of the note
+	if !r.atTop {
+		spacing := decoration.SpaceBefore
+		if r.prevBlank {
+			spacing--
+		}
+		for i := 0; i < spacing; i++ {
+			lines = append(lines, "")
+		}
+	}
+
+	if decoration.ShowPrefix {
+		text = strings.Repeat("#", level) + " " + text
+	}
+	for _, line := range r.wrapHanging("", "", text) {
+		lines = append(lines, style.Render(line))
+	}
+
+	// Underline the heading across the full pane width
+	if decoration.Rule != "" {
+		ruleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		lines = append(lines, ruleStyle.Render(strings.Repeat(decoration.Rule, r.ruleWidth())))
+	}
+
+	for i := 0; i < decoration.SpaceAfter; i++ {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// ruleWidth returns the width used for heading rules and separators
+func (r *nativeRenderer) ruleWidth() int {
+	return max(r.width, 10)
+}
+
+// styleListItem styles a list item, wrapping long items under their text
+func (r *nativeRenderer) styleListItem(item utils.ListItem, depth int) []string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+
+	marker := bulletGlyphs[depth%len(bulletGlyphs)]
+	if item.Ordered {
+		marker = strconv.Itoa(r.nextListNumber(item, depth)) + item.Delim
+	} else if depth < len(r.listNumbers) {
+		// A bullet at this depth ends any ordered run
+		r.listNumbers[depth] = -1
+	}
+	return r.wrapListText(listIndent(depth)+style.Render(marker+" "), depth,
+		depth*2+lipgloss.Width(marker)+1, r.processInlineFormatting(item.Text))
+}
+
+// taskParts styles a task's checkbox and text separately
+func (r *nativeRenderer) taskParts(task utils.TaskLine) (string, string) {
+	if task.Done {
+		boxStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#4ADE80")).Bold(true)
+		textStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#64748B")).
+			Strikethrough(true)
+		return boxStyle.Render("☑ "), textStyle.Render(task.Text)
+	}
+
+	boxStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Bold(true)
+	return boxStyle.Render("☐ "), r.processInlineFormatting(task.Text)
+}
+
+// styleBlockquote styles a blockquote with one bar per nesting level,
+// repeating the bars on every wrapped line
+func (r *nativeRenderer) styleBlockquote(line string) []string {
+	depth, content := utils.BlockquoteDepth(line)
+
+	bars := ""
+	for i := 0; i < depth; i++ {
+		barStyle := lipgloss.NewStyle().Foreground(blockquoteBarColors[i%len(blockquoteBarColors)])
+		bars += barStyle.Render("│ ")
+	}
+
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9CA3AF")).
+		Italic(true)
+
+	// Lists inside quotes keep their marker and hang under their text
+	if task, ok := utils.ParseTaskLine(content); ok {
+		box, text := r.taskParts(task)
+		return r.wrapHanging(bars+box, bars+strings.Repeat(" ", lipgloss.Width(box)), text)
+	}
+	if item, ok := utils.ParseListItem(content); ok {
+		marker := "•"
+		if item.Ordered {
+			marker = item.Marker
+		}
+		hang := lipgloss.Width(marker) + 1
+		lines := r.wrap(item.Text, depth*2+hang)
+		for i, l := range lines {
+			if i == 0 {
+				lines[i] = bars + style.Render(marker+" "+l)
+			} else {
+				lines[i] = bars + strings.Repeat(" ", hang) + style.Render(l)
+			}
+		}
+		return lines
+	}
+
+	lines := r.wrap(content, depth*2)
+	for i, l := range lines {
+		lines[i] = bars + style.Render(l)
+	}
+	return lines
+}
@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
+	"markdown-note-taking-app/internal/mdformat"
 	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/sync"
+	"markdown-note-taking-app/internal/utils"
 
 	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -19,7 +25,7 @@ import (
 type NoteEditorModel struct {
 	app     *App
 	note    *models.Note
-	focused int    // 0=title, 1=tags, 2=content
+	focused int    // 0=title, 1=tags, 2=content, 3=preview (focusPreview)
 	mode    string // "create" or "edit"
 	width   int
 	height  int
@@ -32,6 +38,8 @@ type NoteEditorModel struct {
 	// Tag management
 	tags             []models.Tag
 	availableTags    []*models.Tag
+	recentTags       []*models.Tag // offered as chips under the tag input
+	recentTagIndex   int           // -1 = chip row not focused, 0+ = chip under the cursor
 	tagSuggestions   []string
 	showSuggestions  bool
 	suggestionCursor int
@@ -42,8 +50,40 @@ type NoteEditorModel struct {
 	editingTagName  string // temporary storage for edited tag name
 
 	// Markdown preview
-	preview   *MarkdownPreviewModel
-	splitPane bool // true when showing split-pane view
+	preview    *MarkdownPreviewModel
+	splitPane  bool // true when showing split-pane view
+	scrollSync bool // keep the preview aligned with the cursor line
+
+	// Distraction-free writing
+	zen      bool
+	zenSaved zenSettings
+
+	// Timestamp corrections, applied when the note is saved
+	metadata       metadataPanel
+	pendingCreated *time.Time
+	pendingUpdated *time.Time
+
+	// Files attached to the note
+	attachments attachmentsPanel
+
+	// Structured metadata shown beside the editor
+	properties propertiesPanel
+
+	// Web links in the note, to open in the browser
+	links linksPanel
+
+	// Headings of the note, to jump between sections
+	outline outlinePanel
+
+	// Tags suggested from the content when saving
+	tagPrompt tagPrompt
+
+	// confirmClose is set after closing a tab with unsaved changes was
+	// requested once
+	confirmClose bool
+
+	// saveErr tells why the last save, or capture hook, failed
+	saveErr string
 }
 
 // NewNoteEditorModel creates a new note editor model
@@ -90,15 +130,22 @@ func NewNoteEditorModel(app *App) *NoteEditorModel {
 		showSuggestions:  false,
 		suggestionCursor: 0,
 		selectedTagIndex: -1, // No tag selected initially
+		recentTagIndex:   -1,
 		tagEditMode:      false,
 		editingTagName:   "",
-		preview:          NewMarkdownPreviewModel(),
+		preview:          NewMarkdownPreviewModel(NewRenderer(app.GetConfig())),
 		splitPane:        false,
+		metadata:         newMetadataPanel(),
+		attachments:      newAttachmentsPanel(),
+		properties:       newPropertiesPanel(),
 	}
 }
 
 // Init initializes the note editor
 func (m *NoteEditorModel) Init(selectedNote *models.Note) tea.Cmd {
+	if m.zen {
+		m.toggleZen()
+	}
 	if selectedNote != nil {
 		m.SetNote(selectedNote)
 	} else {
@@ -116,23 +163,33 @@ func (m *NoteEditorModel) Init(selectedNote *models.Note) tea.Cmd {
 		m.tagInput.Blur()
 	}
 
+	m.attachments.visible = false
+	m.properties.visible = false
+	m.links.visible = false
+	m.outline.visible = false
+	m.tagPrompt = tagPrompt{declined: map[string]bool{}}
+	m.saveErr = ""
+	m.resizePreview()
+
+	// Reset timestamp corrections
+	m.metadata.visible = false
+	m.pendingCreated = nil
+	m.pendingUpdated = nil
+
 	// Reset tag suggestions and tag editing state
 	m.showSuggestions = false
 	m.suggestionCursor = 0
 	m.selectedTagIndex = -1
+	m.recentTagIndex = -1
 	m.tagEditMode = false
 	m.editingTagName = ""
 	return m.loadAvailableTags()
 }
 
-// loadAvailableTags loads all available tags from storage
+// loadAvailableTags loads all available tags from storage, most used first
 func (m *NoteEditorModel) loadAvailableTags() tea.Cmd {
 	return func() tea.Msg {
-		tags, err := m.app.GetStorage().GetAllTags()
-		if err != nil {
-			return tagsLoadedMsg{tags: []*models.Tag{}}
-		}
-		return tagsLoadedMsg{tags: tags}
+		return loadTagLists(m.app.GetStorage())
 	}
 }
 
@@ -159,28 +216,206 @@ func (m *NoteEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		if m.preview != nil {
-			m.preview.Update(msg)
+			m.resizePreview()
 		}
 
 	case tagsLoadedMsg:
 		m.availableTags = msg.tags
+		m.recentTags = msg.recent
+		return m.app, nil
+
+	case attachmentsLoadedMsg:
+		if m.note != nil && m.note.ID == msg.noteID {
+			if msg.err != nil {
+				m.attachments.err = msg.err.Error()
+			} else {
+				m.attachments.items = msg.items
+				m.attachments.cursor = min(m.attachments.cursor, max(len(msg.items)-1, 0))
+			}
+		}
+		return m.app, nil
+
+	case attachmentChangedMsg:
+		if msg.err != nil {
+			m.attachments.err = msg.err.Error()
+			return m.app, nil
+		}
+		if m.note == nil || msg.attachment == nil || msg.attachment.NoteID != m.note.ID {
+			return m.app, nil
+		}
+		// A newly attached file is linked where the cursor was
+		m.insertAttachmentLink(msg.attachment)
+		return m.app, m.loadAttachments()
+
+	case propertiesLoadedMsg:
+		m.applyLoadedProperties(msg)
 		return m.app, nil
 
+	case noteSaveFailedMsg:
+		m.saveErr = msg.err.Error()
+		return m.app, nil
+
+	case taskToggledMsg:
+		if msg.err != nil {
+			slog.Warn("failed to save note", "id", msg.note.ID, "err", msg.err)
+			m.saveErr = msg.err.Error()
+			return m.app, nil
+		}
+		if m.note != nil && m.note.ID == msg.note.ID {
+			*m.note = *msg.note
+		}
+		return m.app, nil
+
+	case noteLinkMsg:
+		if msg.err != nil {
+			m.links.err = msg.err.Error()
+			return m.app, nil
+		}
+		m.links.visible = false
+		return m.app, m.app.followLink(msg.note)
+
 	case tea.KeyMsg:
-		// Handle escape key
-		if msg.String() == "esc" {
+		// Suggested tags are answered before anything else, even in zen mode
+		if m.tagPrompt.visible {
+			return m.app, m.handleTagPromptKey(msg)
+		}
+
+		// The metadata panel captures input while open
+		if m.metadata.visible {
+			return m.app, m.handleMetadataKey(msg)
+		}
+
+		// So does the attachments panel
+		if m.attachments.visible {
+			return m.app, m.handleAttachmentsKey(msg)
+		}
+
+		// And the properties panel
+		if m.properties.visible {
+			return m.app, m.handlePropertiesKey(msg)
+		}
+
+		// And the links panel
+		if m.links.visible {
+			return m.app, m.handleLinksKey(msg)
+		}
+
+		// And the outline panel
+		if m.outline.visible {
+			m.handleOutlineKey(msg)
+			return m.app, nil
+		}
+
+		// Switch between and close open notes
+		if cmd, ok := m.app.handleTabKey(msg); ok {
+			return m.app, cmd
+		}
+
+		// Capture hooks insert their command's output
+		if hook, ok := m.app.captureHook(msg); ok {
+			return m.app, runCapture(hook, m)
+		}
+
+		// Zen mode shows the content alone; Esc leaves it
+		if m.zen {
+			switch msg.String() {
+			case "esc", "alt+z":
+				m.toggleZen()
+			case "ctrl+s":
+				return m.app, m.requestSave()
+			case "ctrl+t":
+				return m.app, m.toggleTaskAtCursor()
+			case "ctrl+r":
+				m.renumberListAtCursor()
+			case "tab":
+				if m.expandSnippet() || m.indentListItem(false) {
+					m.growZenTextarea()
+				}
+			case "shift+tab":
+				if m.indentListItem(true) {
+					m.growZenTextarea()
+				}
+			case "enter":
+				if m.continueList() {
+					m.growZenTextarea()
+				} else {
+					m.updateZenContent(msg)
+				}
+			default:
+				m.updateZenContent(msg)
+			}
+			return m.app, nil
+		}
+		if msg.String() == "alt+z" {
+			m.toggleZen()
+			return m.app, nil
+		}
+
+		// Handle escape key; editing or selecting a tag takes it below
+		if msg.String() == "esc" && !m.tagEditMode && m.selectedTagIndex < 0 && m.recentTagIndex < 0 {
 			if m.showSuggestions {
 				m.showSuggestions = false
 				m.suggestionCursor = 0
 			} else {
-				return m.app, m.app.SwitchToView(ViewNotesList)
+				return m.app, m.app.back()
 			}
 			return m.app, nil
 		}
 
 		// Handle save key
 		if msg.String() == "ctrl+s" {
-			return m.app, m.saveNote()
+			return m.app, m.requestSave()
+		}
+
+		// Handle filling in the title from the content
+		if msg.String() == "alt+t" {
+			m.suggestTitle()
+			return m.app, nil
+		}
+
+		// Handle metadata panel for correcting timestamps. Alt+D is left
+		// to the fields, which delete the next word with it.
+		if msg.String() == "alt+m" {
+			return m.app, m.openMetadataPanel()
+		}
+
+		// Handle attachments panel
+		if msg.String() == "alt+a" {
+			return m.app, m.openAttachmentsPanel()
+		}
+
+		// Handle properties panel
+		if msg.String() == "alt+p" {
+			return m.app, m.toggleProperties()
+		}
+
+		// Handle links panel for opening web links
+		if msg.String() == "alt+l" {
+			m.openLinksPanel()
+			return m.app, nil
+		}
+
+		// Handle outline panel for jumping to a heading
+		if msg.String() == "alt+o" {
+			m.openOutlinePanel()
+			return m.app, nil
+		}
+
+		// Handle task checkbox toggle on the current content line
+		if msg.String() == "ctrl+t" && m.focused == 2 {
+			return m.app, m.toggleTaskAtCursor()
+		}
+
+		// Handle renumbering of the ordered list under the cursor
+		if msg.String() == "ctrl+r" && m.focused == 2 {
+			m.renumberListAtCursor()
+			return m.app, nil
+		}
+
+		// Handle expanding the frontmatter header in the preview
+		if msg.String() == "ctrl+g" {
+			m.preview.ToggleMetadata()
+			return m.app, nil
 		}
 
 		// Handle preview toggle
@@ -189,10 +424,46 @@ func (m *NoteEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.app, nil
 		}
 
-		// Handle tab navigation between fields
+		// Handle focusing the split-pane preview to scroll it
+		if msg.String() == "alt+v" {
+			m.togglePreviewFocus()
+			return m.app, nil
+		}
+
+		// Handle scroll sync between the content and the preview
+		if msg.String() == "alt+s" {
+			m.toggleScrollSync()
+			return m.app, nil
+		}
+
+		// Lists in the content continue on Enter and indent with Tab and
+		// Shift+Tab
+		if m.focused == 2 {
+			switch msg.String() {
+			case "enter":
+				if m.continueList() {
+					return m.app, nil
+				}
+			case "shift+tab":
+				if m.indentListItem(true) {
+					return m.app, nil
+				}
+			}
+		}
+
+		// Handle tab navigation between fields, after expanding any
+		// snippet trigger before the cursor or indenting a list item
 		if msg.String() == "tab" {
-			// Cycle through 0=title, 1=tags, 2=content (reordered)
-			m.focused = (m.focused + 1) % 3
+			if m.focused == 2 && (m.expandSnippet() || m.indentListItem(false)) {
+				return m.app, nil
+			}
+			// Cycle through 0=title, 1=tags, 2=content (reordered); the
+			// preview goes back to the content
+			if m.focused == focusPreview {
+				m.focused = 2
+			} else {
+				m.focused = (m.focused + 1) % 3
+			}
 			m.updateFocus()
 			m.showSuggestions = false
 			m.suggestionCursor = 0
@@ -202,77 +473,221 @@ func (m *NoteEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle input based on focused field
 		switch m.focused {
 		case 0: // Title field
-			m.titleInput, _ = m.titleInput.Update(msg)
+			updateInput(&m.titleInput, msg)
 		case 1: // Tags field (moved from position 2)
 			m.handleTagInput(msg)
 		case 2: // Content field (moved from position 1)
-			m.contentInput, _ = m.contentInput.Update(msg)
+			updateTextArea(&m.contentInput, msg)
+		case focusPreview:
+			m.handlePreviewKey(msg)
+			return m.app, nil
 		}
 
 		// Update preview if split pane is active
 		if m.splitPane {
 			m.UpdatePreview()
+			m.syncPreviewScroll()
 		}
 	}
 	return m.app, nil
 }
 
+// suggestTitle sets the title to the text of the content's first heading,
+// or else its first line. The title is left alone when the content has no
+// text.
+func (m *NoteEditorModel) suggestTitle() {
+	if title := utils.TitleFromContent(m.contentInput.Value()); title != "" {
+		m.titleInput.SetValue(title)
+		m.titleInput.CursorEnd()
+	}
+}
+
 // saveNote saves the current note
 func (m *NoteEditorModel) saveNote() tea.Cmd {
+	// Tidy the markdown first, so the editor shows what's saved
+	if cfg := m.app.GetConfig(); cfg.FormatOnSave {
+		m.formatContent(cfg.FormatOptions())
+	}
+
+	// Notes without titles take one from their content
+	if strings.TrimSpace(m.titleInput.Value()) == "" {
+		m.suggestTitle()
+	}
+
+	committer, _ := m.app.syncProvider().(sync.Committer)
+	watcher := m.app.watcher
 	return func() tea.Msg {
 		if strings.TrimSpace(m.titleInput.Value()) == "" {
-			// Don't save notes without titles
+			// Don't save notes with neither title nor content
 			return nil
 		}
 
-		var note *models.Note
-		var err error
-
-		if m.mode == "create" {
-			note, err = m.app.GetStorage().CreateNote(m.titleInput.Value(), m.contentInput.Value())
-			if err != nil {
+		// Save a copy so a failed save leaves the note as it was stored
+		note := models.NewNote(m.titleInput.Value(), m.contentInput.Value())
+		if m.mode != "create" {
+			if m.note == nil {
 				return nil
 			}
+			saved := *m.note
+			note = &saved
+			note.Title = m.titleInput.Value()
+			note.Content = m.contentInput.Value()
+		}
+
+		// The note and its tags are saved together or not at all
+		var tagNames []string
+		for _, tag := range m.tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+		if err := m.app.GetStorage().SaveNoteWithTags(note, tagNames); err != nil {
+			return noteSaveFailedMsg{err: err}
+		}
+		m.saveErr = ""
+		if m.mode == "create" {
+			// The tab stays open, so later saves must update this note
+			m.note = note
+			m.mode = "edit"
 		} else {
-			// Update existing note
-			if m.note != nil {
-				m.note.Title = m.titleInput.Value()
-				m.note.Content = m.contentInput.Value()
-				err = m.app.GetStorage().UpdateNote(m.note)
-				if err != nil {
-					return nil
-				}
-				note = m.note
-			}
+			*m.note = *note
 		}
 
-		// Save tags
-		if note != nil {
-			// Clear existing tags for this note
-			if m.mode == "edit" && m.note != nil {
-				for _, tag := range m.tags {
-					m.app.GetStorage().RemoveTagFromNote(note.ID, tag.ID)
-				}
+		// Apply corrected timestamps after the save so they aren't overwritten
+		if m.pendingCreated != nil {
+			if err := m.app.GetStorage().SetNoteTimestamps(note.ID, *m.pendingCreated, *m.pendingUpdated); err != nil {
+				return noteSaveFailedMsg{err: fmt.Errorf("saved, but the dates weren't: %w", err)}
 			}
+		}
 
-			// Add new tags
-			for _, tag := range m.tags {
-				err := m.app.GetStorage().AddTagToNote(note.ID, tag.Name)
-				if err != nil {
-					// For now, just ignore tag errors
-					continue
-				}
+		// Record the change in the sync repository; the note itself is
+		// saved, so a failed commit only shows in the status bar
+		var commitErr error
+		if committer != nil {
+			if _, commitErr = committer.CommitNotes("Update " + note.Title); commitErr != nil {
+				slog.Warn("failed to commit note", "id", note.ID, "err", commitErr)
 			}
 		}
+		// Write the note to the watched directory
+		if watcher != nil {
+			watcher.Trigger()
+		}
 
 		// Go back to notes list
-		return m.app.SwitchToView(ViewNotesList)()
+		switched := m.app.SwitchToView(ViewNotesList)()
+		if commitErr != nil {
+			return tea.BatchMsg{
+				func() tea.Msg { return switched },
+				func() tea.Msg { return syncDoneMsg{err: commitErr} },
+			}
+		}
+		return switched
+	}
+}
+
+// formatContent formats the content, keeping the cursor about where it was
+func (m *NoteEditorModel) formatContent(opts mdformat.Options) {
+	content := m.contentInput.Value()
+	formatted := mdformat.Format(content, opts)
+	if formatted == content {
+		return
+	}
+	row := m.contentInput.Line()
+	col := m.contentInput.LineInfo().StartColumn + m.contentInput.LineInfo().ColumnOffset
+	setTextareaValue(&m.contentInput, formatted, row, col)
+
+	if m.splitPane {
+		m.UpdatePreview()
 	}
 }
 
+// toggleTaskAtCursor toggles the task checkbox on the cursor line and
+// persists the change immediately when editing an existing note. Only the
+// toggled line is saved; other unsaved edits stay unsaved.
+func (m *NoteEditorModel) toggleTaskAtCursor() tea.Cmd {
+	row := m.contentInput.Line()
+	col := m.contentInput.LineInfo().StartColumn + m.contentInput.LineInfo().ColumnOffset
+
+	value := m.contentInput.Value()
+	content, ok := utils.ToggleTaskAt(value, row)
+	if !ok {
+		return nil
+	}
+	setTextareaValue(&m.contentInput, content, row, col)
+
+	if m.splitPane {
+		m.UpdatePreview()
+	}
+
+	// New notes are persisted on the regular save
+	if m.mode != "edit" || m.note == nil {
+		return nil
+	}
+
+	// The stored line is toggled when it's the same as the one in the
+	// editor; one that was edited or moved is left for the regular save
+	stored := strings.Split(m.note.Content, "\n")
+	if row >= len(stored) || stored[row] != strings.Split(value, "\n")[row] {
+		return nil
+	}
+	saved, _ := utils.ToggleTaskAt(m.note.Content, row)
+
+	// Save a copy; the result is applied in Update
+	note := *m.note
+	note.Content = saved
+	return func() tea.Msg {
+		err := m.app.GetStorage().UpdateNote(&note)
+		return taskToggledMsg{note: &note, err: err}
+	}
+}
+
+// renumberListAtCursor renumbers the ordered list containing the cursor line
+func (m *NoteEditorModel) renumberListAtCursor() {
+	row := m.contentInput.Line()
+	col := m.contentInput.LineInfo().StartColumn + m.contentInput.LineInfo().ColumnOffset
+
+	content, ok := utils.RenumberListAt(m.contentInput.Value(), row)
+	if !ok {
+		return
+	}
+	setTextareaValue(&m.contentInput, content, row, col)
+
+	if m.splitPane {
+		m.UpdatePreview()
+	}
+}
+
+// setTextareaValue replaces the textarea content while keeping the cursor
+// at the given logical row and column
+func setTextareaValue(ta *textarea.Model, value string, row, col int) {
+	ta.SetValue(value)
+
+	// SetValue leaves the cursor at the end; walk back up to the target row
+	for ta.Line() > row {
+		prev := ta.Line()
+		ta.CursorUp()
+		if ta.Line() == prev && ta.LineInfo().RowOffset == 0 {
+			break
+		}
+	}
+	ta.SetCursor(col)
+}
+
 // Messages
 type tagsLoadedMsg struct {
-	tags []*models.Tag
+	tags   []*models.Tag
+	recent []*models.Tag
+}
+
+// taskToggledMsg reports the saved copy of a note whose task was toggled
+// from the editor
+type taskToggledMsg struct {
+	note *models.Note
+	err  error
+}
+
+// noteSaveFailedMsg reports why saving the note failed. Nothing was saved
+// unless the error says otherwise.
+type noteSaveFailedMsg struct {
+	err error
 }
 
 // updateFocus updates the focus state of text inputs based on current focused field
@@ -285,6 +700,7 @@ func (m *NoteEditorModel) updateFocus() {
 		// Reset tag editing state when switching away from tags
 		m.deselectTag()
 		m.cancelEditTag()
+		m.recentTagIndex = -1
 	case 1: // Tags field (moved from position 2)
 		m.titleInput.Blur()
 		m.tagInput.Focus() // Always focus tag input when tags field is active
@@ -295,7 +711,15 @@ func (m *NoteEditorModel) updateFocus() {
 		// Reset tag editing state when switching away from tags
 		m.deselectTag()
 		m.cancelEditTag()
+		m.recentTagIndex = -1
 		m.contentInput.Focus()
+	case focusPreview:
+		m.titleInput.Blur()
+		m.tagInput.Blur()
+		m.deselectTag()
+		m.cancelEditTag()
+		m.recentTagIndex = -1
+		m.contentInput.Blur()
 	}
 }
 
@@ -309,19 +733,22 @@ func (m *NoteEditorModel) handleTagInput(msg tea.KeyMsg) {
 			m.cancelEditTag()
 		default:
 			// Update the editing tag name
-			m.tagInput, _ = m.tagInput.Update(msg)
+			updateInput(&m.tagInput, msg)
 			m.editingTagName = m.tagInput.Value()
 		}
 		return
 	}
 
-	// Handle tag selection mode (when a tag is selected)
+	// Handle tag selection mode (when a tag is selected); Enter renames
+	// the selected tag and Delete removes it
 	if m.selectedTagIndex >= 0 {
 		switch msg.String() {
 		case "left":
 			m.selectPreviousTag()
 		case "right":
 			m.selectNextTag()
+		case "enter":
+			m.startEditTag()
 		case "delete", "backspace":
 			m.deleteSelectedTag()
 		case "esc":
@@ -330,6 +757,11 @@ func (m *NoteEditorModel) handleTagInput(msg tea.KeyMsg) {
 		return
 	}
 
+	// The row of recent tags takes the keys while it has focus
+	if m.handleRecentTagKey(msg.String()) {
+		return
+	}
+
 	// Normal tag input handling
 	if m.showSuggestions {
 		// Handle suggestion navigation
@@ -351,33 +783,41 @@ func (m *NoteEditorModel) handleTagInput(msg tea.KeyMsg) {
 			m.suggestionCursor = 0
 		case "backspace":
 			// Handle backspace when suggestions are shown
-			m.tagInput, _ = m.tagInput.Update(msg)
+			updateInput(&m.tagInput, msg)
 			m.updateTagSuggestions()
 		default:
 			// Any other input hides suggestions and goes to textinput
 			m.showSuggestions = false
 			m.suggestionCursor = 0
-			m.tagInput, _ = m.tagInput.Update(msg)
+			updateInput(&m.tagInput, msg)
 			m.updateTagSuggestions()
 		}
 	} else {
 		// Update textinput and check for special keys
 		prevValue := m.tagInput.Value()
-		m.tagInput, _ = m.tagInput.Update(msg)
+		updateInput(&m.tagInput, msg)
 		newValue := m.tagInput.Value()
 
 		// Handle special keys that don't go through textinput normally
 		switch msg.String() {
 		case "left":
-			// Select last tag if there are tags
-			if len(m.tags) > 0 {
+			// Select last tag if there are tags; the arrows move through
+			// the input's text while it has any
+			if len(m.tags) > 0 && prevValue == "" {
 				m.selectTag(len(m.tags) - 1)
 			}
 		case "right":
 			// Select first tag if there are tags
-			if len(m.tags) > 0 {
+			if len(m.tags) > 0 && prevValue == "" {
 				m.selectTag(0)
 			}
+		case "backspace":
+			// Backspace in the empty input removes the last tag
+			if len(m.tags) > 0 && prevValue == "" {
+				m.tags = m.tags[:len(m.tags)-1]
+			} else if prevValue != newValue {
+				m.updateTagSuggestions()
+			}
 		case "enter":
 			if len(newValue) > 0 {
 				m.addTag(newValue)
@@ -411,8 +851,13 @@ func (m *NoteEditorModel) addTag(tagName string) {
 		}
 	}
 
-	// Add tag to current tags
+	// Add tag to current tags, in its color if it exists already
 	newTag := models.Tag{Name: tagName}
+	for _, known := range m.availableTags {
+		if strings.EqualFold(known.Name, tagName) {
+			newTag.Color = known.Color
+		}
+	}
 	m.tags = append(m.tags, newTag)
 
 	// Clear input and deselect tag
@@ -426,8 +871,6 @@ func (m *NoteEditorModel) addTag(tagName string) {
 func (m *NoteEditorModel) selectTag(index int) {
 	if index >= 0 && index < len(m.tags) {
 		m.selectedTagIndex = index
-		// Auto-enter edit mode when a tag is selected
-		m.startEditTag()
 		m.showSuggestions = false
 	}
 }
@@ -506,30 +949,7 @@ func (m *NoteEditorModel) cancelEditTag() {
 }
 
 func (m *NoteEditorModel) updateTagSuggestions() {
-	tagInputValue := m.tagInput.Value()
-	if len(tagInputValue) < 2 {
-		m.tagSuggestions = []string{}
-		m.showSuggestions = false
-		return
-	}
-
-	m.tagSuggestions = []string{}
-	for _, tag := range m.availableTags {
-		if strings.Contains(strings.ToLower(tag.Name), strings.ToLower(tagInputValue)) {
-			// Check if tag is already added
-			alreadyAdded := false
-			for _, existingTag := range m.tags {
-				if existingTag.ID == tag.ID {
-					alreadyAdded = true
-					break
-				}
-			}
-			if !alreadyAdded {
-				m.tagSuggestions = append(m.tagSuggestions, tag.Name)
-			}
-		}
-	}
-
+	m.tagSuggestions = suggestTags(m.availableTags, m.tagInput.Value(), m.tags)
 	m.showSuggestions = len(m.tagSuggestions) > 0
 	m.suggestionCursor = 0
 }
@@ -540,11 +960,28 @@ func (m *NoteEditorModel) ToggleSplitPane() {
 	if m.splitPane {
 		m.preview.ShowPreview(true)
 		m.preview.SetContent(m.contentInput.Value())
+		m.syncPreviewScroll()
 	} else {
 		m.preview.ShowPreview(false)
+		if m.focused == focusPreview {
+			m.focused = 2
+			m.updateFocus()
+		}
 	}
 }
 
+// resizePreview gives the preview the inner size of the split-pane preview box
+func (m *NoteEditorModel) resizePreview() {
+	width := m.width
+	if m.properties.visible {
+		width -= propertiesPanelWidth + 1
+	}
+	editorWidth := (width - 8) / 2
+	previewWidth := width - editorWidth - 4
+	// Subtract the pane padding plus the preview's own padding and margin
+	m.preview.SetSize(max(previewWidth-6, 10), m.height)
+}
+
 // UpdatePreview updates the markdown preview with current content
 func (m *NoteEditorModel) UpdatePreview() {
 	if m.preview != nil {
@@ -552,32 +989,15 @@ func (m *NoteEditorModel) UpdatePreview() {
 	}
 }
 
-// getTagBadgeStyle returns a badge style for tags (no borders, colored backgrounds)
-func (m *NoteEditorModel) getTagBadgeStyle(index int, _ string) lipgloss.Style {
+// getTagBadgeStyle returns a badge style for tags (no borders, colored
+// backgrounds in the tag's own color)
+func (m *NoteEditorModel) getTagBadgeStyle(index int, tag models.Tag) lipgloss.Style {
 	// Define colors for different tag states
 	var bgColor, textColor lipgloss.Color
 	isSelected := m.selectedTagIndex == index
 	isEditing := m.tagEditMode && m.selectedTagIndex == index
 
-	// Cycle through different background colors for variety
-	switch index % 4 {
-	case 0:
-		// Cyan badge
-		bgColor = lipgloss.Color("#38BDF8")
-	case 1:
-		// Green badge
-		bgColor = lipgloss.Color("#4ADE80")
-	case 2:
-		// Purple badge
-		bgColor = lipgloss.Color("#C084FC")
-	case 3:
-		// Orange badge
-		bgColor = lipgloss.Color("#FB923C")
-	default:
-		// Default cyan badge
-		bgColor = lipgloss.Color("#38BDF8")
-	}
-
+	bgColor = tagColor(tag)
 	textColor = lipgloss.Color("#0F172A") // Dark text
 
 	// Build base style
@@ -611,13 +1031,66 @@ func (m *NoteEditorModel) getTagBadgeStyle(index int, _ string) lipgloss.Style {
 	return style
 }
 
-// View renders the note editor
+// capturesEsc reports whether Esc closes something in the editor rather
+// than leaving it
+func (m *NoteEditorModel) capturesEsc() bool {
+	return m.tagPrompt.visible || m.metadata.visible || m.attachments.visible || m.properties.visible ||
+		m.links.visible || m.outline.visible || m.showSuggestions || m.tagEditMode || m.selectedTagIndex >= 0 || m.recentTagIndex >= 0 || m.zen
+}
+
+// dirty reports whether the title or content differ from the saved note
+func (m *NoteEditorModel) dirty() bool {
+	if m.mode != "edit" || m.note == nil {
+		return m.titleInput.Value() != "" || m.contentInput.Value() != ""
+	}
+	return m.titleInput.Value() != m.note.Title || m.contentInput.Value() != m.note.Content
+}
+
+// View renders the note editor below the tab bar of open notes
 func (m *NoteEditorModel) View() string {
+	if m.zen && !m.tagPrompt.visible {
+		return m.renderZen()
+	}
+	return m.app.renderTabBar() + "\n" + m.renderEditor()
+}
+
+// renderEditor renders the note editor
+func (m *NoteEditorModel) renderEditor() string {
 	mode := "Create Note"
 	if m.mode == "edit" {
 		mode = "Edit Note"
 	}
 
+	if m.tagPrompt.visible {
+		return m.renderTagPrompt()
+	}
+	if m.metadata.visible {
+		return m.renderMetadataPanel()
+	}
+	if m.attachments.visible {
+		return m.renderAttachmentsPanel()
+	}
+	if m.links.visible {
+		return m.renderLinksPanel()
+	}
+	if m.outline.visible {
+		return m.renderOutlinePanel()
+	}
+
+	// The properties panel takes its width from the editor
+	if m.properties.visible {
+		panel := m.renderPropertiesPanel()
+		width := m.width
+		m.width = max(width-propertiesPanelWidth-1, 20)
+		defer func() { m.width = width }()
+
+		editor := m.renderSinglePaneView(mode)
+		if m.splitPane {
+			editor = m.renderSplitPaneView(mode)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, editor, " ", panel)
+	}
+
 	if m.splitPane {
 		// Split-pane view
 		return m.renderSplitPaneView(mode)
@@ -710,7 +1183,7 @@ func (m *NoteEditorModel) renderSinglePaneView(mode string) string {
 	if len(m.tags) > 0 {
 		s += " " // Start with space for better spacing
 		for i, tag := range m.tags {
-			badgeStyle := m.getTagBadgeStyle(i, tag.Name)
+			badgeStyle := m.getTagBadgeStyle(i, tag)
 			tagText := tag.Name
 			if m.selectedTagIndex == i && !m.tagEditMode {
 				tagText += " ★" // Add star indicator for selected tag
@@ -737,7 +1210,8 @@ func (m *NoteEditorModel) renderSinglePaneView(mode string) string {
 		}()).
 		Width(tagInputWidth)
 
-	s += tagInputStyle.Render(tagInputField) + "\n\n"
+	s += tagInputStyle.Render(tagInputField) + "\n"
+	s += m.renderRecentTags(tagInputWidth) + "\n"
 
 	// Content field (moved to position 2)
 	contentLabel := "Content:"
@@ -784,10 +1258,13 @@ func (m *NoteEditorModel) renderSinglePaneView(mode string) string {
 		Foreground(lipgloss.Color("#94A3B8")).
 		MarginTop(1)
 
-	controls := "Tab - Switch fields • Ctrl+S - Save • Ctrl+P - Toggle preview • Esc - Cancel"
+	controls := "Tab - Switch fields • Ctrl+S - Save • Ctrl+P - Toggle preview • Ctrl+T - Toggle task • Ctrl+R - Renumber list • Alt+M - Dates • Esc - Back"
 	if m.width < 100 {
-		controls = "Tab: Switch • Ctrl+S: Save • Ctrl+P: Preview • Esc: Cancel"
+		// Short forms, wrapped at the width so no hint is cut off
+		controls = "Tab: Switch • Ctrl+S: Save • Ctrl+P: Preview • Ctrl+T: Task • Ctrl+R: Renum • Alt+M: Dates • Esc: Back"
+		controlsStyle = controlsStyle.Width(max(m.width-4, 20))
 	}
+	s += m.renderSaveError()
 	s += controlsStyle.Render(controls) + "\n"
 
 	if m.focused == 1 {
@@ -795,14 +1272,26 @@ func (m *NoteEditorModel) renderSinglePaneView(mode string) string {
 		if m.tagEditMode {
 			tagHelp = "Editing: Type new name • Enter: Save • Esc: Cancel"
 		} else {
-			tagHelp = "Tags: Type to add • ←→: Navigate tags • Del: Remove • Space/Enter: Confirm"
+			tagHelp = "Tags: Type to add • ↓: Recent tags • ←→: Select tags • Bksp: Remove last • Space/Enter: Confirm"
+			if m.selectedTagIndex >= 0 {
+				tagHelp = "Tag: ←→: Navigate tags • Del: Remove • Enter: Rename • Esc: Back to input"
+			}
+			if m.recentTagIndex >= 0 {
+				tagHelp = "Recent tags: 1-5 or Space/Enter: Add/remove • ←→: Navigate • ↑/Esc: Back to input"
+			}
 		}
 
 		if m.width < 100 {
 			if m.tagEditMode {
 				tagHelp = "Edit: Type • Enter: Save • Esc: Cancel"
 			} else {
-				tagHelp = "Tags: Type • ←→: Navigate • Del: Remove • Space/Enter: Add"
+				tagHelp = "Tags: Type • ↓: Recent • ←→: Navigate • Bksp: Remove last • Space/Enter: Add"
+				if m.selectedTagIndex >= 0 {
+					tagHelp = "Tag: ←→: Navigate • Del: Remove • Enter: Rename • Esc: Back"
+				}
+				if m.recentTagIndex >= 0 {
+					tagHelp = "Recent: 1-5/Enter: Toggle • ←→: Navigate • Esc: Back"
+				}
 			}
 		}
 		s += controlsStyle.Render(tagHelp) + "\n"
@@ -864,9 +1353,13 @@ func (m *NoteEditorModel) renderSplitPaneView(mode string) string {
 	editorContent := m.renderEditorContent(editorWidth-4, m.height-10)
 	editorBox := editorPane.Render(editorContent)
 
-	// Enhanced preview pane with orange accent
+	// Enhanced preview pane with orange accent, thick while it has focus
+	previewBorder := lipgloss.RoundedBorder()
+	if m.focused == focusPreview {
+		previewBorder = lipgloss.ThickBorder()
+	}
 	previewPane := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(previewBorder).
 		BorderForeground(lipgloss.Color(orangeHighlight)). // Orange accent
 		Width(previewWidth).
 		Height(m.height - 8).
@@ -884,15 +1377,33 @@ func (m *NoteEditorModel) renderSplitPaneView(mode string) string {
 		Foreground(lipgloss.Color("#94A3B8")).
 		MarginTop(1)
 
-	controls := "Tab: Switch fields • Ctrl+S: Save • Ctrl+P: Exit preview • Esc: Cancel"
-	if m.width < 120 {
-		controls = "Tab: Switch • Ctrl+S: Save • Ctrl+P: Exit • Esc: Cancel"
+	sync := "off"
+	if m.scrollSync {
+		sync = "on"
+	}
+	controls := "Tab: Switch fields • Ctrl+S: Save • Ctrl+P: Exit preview • Alt+V: Focus preview • Alt+S: Scroll sync (" + sync + ") • Esc: Back"
+	if m.focused == focusPreview {
+		controls = "j/k, PgUp/PgDn: Scroll preview • Alt+V, Tab: Back to content • Alt+S: Scroll sync (" + sync + ")"
+	} else if m.width < 120 {
+		controls = "Tab: Switch • Ctrl+S: Save • Ctrl+P: Exit • Alt+V: Preview • Esc: Back"
 	}
+	s += m.renderSaveError()
 	s += controlsStyle.Render(controls)
 
 	return s
 }
 
+// renderSaveError renders why the last save failed, if it did
+func (m *NoteEditorModel) renderSaveError() string {
+	if m.saveErr == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F43F5E")).
+		Width(max(m.width-4, 20)).
+		Render(m.saveErr) + "\n"
+}
+
 // renderEditorContent renders the editor content for split-pane view with orange highlights
 func (m *NoteEditorModel) renderEditorContent(width, height int) string {
 	// Define warm colors for highlighting (matching notes list)
@@ -954,7 +1465,7 @@ func (m *NoteEditorModel) renderEditorContent(width, height int) string {
 	if len(m.tags) > 0 {
 		s += " " // Start with space for better spacing
 		for i, tag := range m.tags {
-			badgeStyle := m.getTagBadgeStyle(i, tag.Name)
+			badgeStyle := m.getTagBadgeStyle(i, tag)
 			tagText := tag.Name
 			if m.selectedTagIndex == i && !m.tagEditMode {
 				tagText += " ★" // Add star indicator for selected tag
@@ -981,7 +1492,8 @@ func (m *NoteEditorModel) renderEditorContent(width, height int) string {
 		}()).
 		Width(tagInputWidth)
 
-	s += tagInputStyle.Render(tagInputField) + "\n\n"
+	s += tagInputStyle.Render(tagInputField) + "\n"
+	s += m.renderRecentTags(tagInputWidth) + "\n"
 
 	// Content section (moved to position 2)
 	contentLabel := "Content:"
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// peekLines is how many rendered lines of the selected note are shown
+	peekLines = 20
+	// peekPaneWidth is the width of the side pane on wide terminals
+	peekPaneWidth = 60
+	// peekSideBySideWidth is the terminal width from which the preview
+	// moves from below the results into a pane beside the list
+	peekSideBySideWidth = 150
+)
+
+// notePeek caches the rendered opening of the note under the cursor so
+// moving through the list doesn't re-render unchanged notes
+type notePeek struct {
+	visible  bool
+	renderer Renderer
+	key      string
+	lines    []string
+}
+
+// peekSideBySide reports whether the preview is shown as a pane beside the
+// list. The two-pane layout replaces it when active.
+func (m *NotesListModel) peekSideBySide() bool {
+	return m.peek.visible && m.width-m.sidebarSpace() >= peekSideBySideWidth && !m.browserActive()
+}
+
+// containerWidth returns the width of the list container, leaving room for
+// the sidebar and the preview pane when they are shown beside the list
+func (m *NotesListModel) containerWidth() int {
+	width := m.width - m.sidebarSpace()
+	if m.browserActive() {
+		return min(width*45/100, 100)
+	}
+	if m.peekSideBySide() {
+		return min(width-peekPaneWidth-6, 100)
+	}
+	return min(width-4, 100) // Max 100 chars width
+}
+
+// peekContent returns up to maxLines rendered lines from the start of the
+// note under the cursor, wrapped to width
+func (m *NotesListModel) peekContent(width, maxLines int) []string {
+	note := m.cursorNote()
+	if note == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("%d|%d|%d", note.ID, note.UpdatedAt.UnixNano(), width)
+	if key != m.peek.key {
+		if m.peek.renderer == nil {
+			m.peek.renderer = NewRenderer(m.app.GetConfig())
+		}
+		rendered, _ := renderWithFrontmatter(m.peek.renderer, note.Content, width, false)
+		lines := strings.Split(rendered, "\n")
+		m.peek.lines = lines[:min(len(lines), peekLines)]
+		m.peek.key = key
+	}
+	return m.peek.lines[:min(len(m.peek.lines), maxLines)]
+}
+
+// renderPeek renders the preview box for the note under the cursor in
+// width columns and at most height lines, border included
+func (m *NotesListModel) renderPeek(width, height int) string {
+	if len(m.filteredNotes) == 0 || height < 5 {
+		return ""
+	}
+	note := m.filteredNotes[m.cursor]
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Bold(true)
+
+	// Border and padding take 4 columns, the border and title 3 lines
+	inner := max(width-4, 10)
+	body := m.peekContent(inner, height-3)
+	if m.cursorNote() == nil {
+		body = []string{lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#64748B")).
+			Italic(true).
+			Render("Loading...")}
+	} else if strings.TrimSpace(strings.Join(body, "")) == "" {
+		body = []string{lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#64748B")).
+			Italic(true).
+			Render("Empty note")}
+	}
+
+	content := titleStyle.Render(ansi.Truncate(note.Title, inner, "…")) + "\n" + strings.Join(body, "\n")
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#475569")).
+		Padding(0, 1).
+		Width(width - 2).
+		MaxHeight(height).
+		Render(content)
+}
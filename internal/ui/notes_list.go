@@ -1,12 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
 	"markdown-note-taking-app/internal/models"
 	"markdown-note-taking-app/internal/utils"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -18,13 +23,75 @@ type NotesListModel struct {
 	filteredNotes []*models.Note // Store filtered notes for search
 	selectedNote  *models.Note
 	cursor        int
+	scrollOffset  int  // index of the first visible note
+	viewportRows  int  // notes that fit in the results viewport at the last render
+	pendingG      bool // true after a first "g" keypress, waiting for "gg"
 	loaded        bool
 	width         int
 	height        int
 
 	// Search functionality
-	searchQuery string
-	searchMode  bool // true when in search mode
+	searchQuery  string
+	searchMode   bool               // true when in search mode
+	searching    bool               // true while a query is in flight
+	searchSeq    int                // bumped on every query change to detect stale results
+	cancelSearch context.CancelFunc // cancels the in-flight query
+	spinner      spinner.Model
+
+	// Sort settings
+	sortBy        models.SortField
+	secondarySort models.SortField // tie-breaker for notes with equal sort values
+	sortReverse   bool             // flips the direction of the primary sort
+
+	// Multi-select and bulk operations
+	selected     map[int]bool // IDs of notes selected for bulk operations
+	selectAnchor int          // index of the last toggled note, where V ranges start
+	bulkAction   bulkAction   // bulk action awaiting confirmation or input
+	bulkTarget   *models.Note // note the action menu started the bulk action on
+	bulkInput    textinput.Model
+	statusMsg    string     // outcome of the last bulk operation
+	undo         *undoEntry // last destructive action, while it can be undone
+	undoSeq      int        // identifies the latest undo offer so stale expiries are ignored
+
+	// Notes matching the list and the current search, which may be more
+	// than were loaded, and whether the configured limits are lifted
+	allTotal    int
+	searchTotal int
+	showAll     bool
+	loadingMore bool // a further page of notes is being fetched
+
+	// Inline tag editing of the note under the cursor
+	tagEditor inlineTagEditor
+
+	// Popup listing the actions for the note under the cursor
+	menu actionMenu
+
+	// Preview of the note under the cursor
+	peek notePeek
+
+	// Two-pane layout with a live preview on large terminals
+	browser browserPane
+
+	// The note under the cursor loaded whole for the previews, as the list
+	// only holds summaries
+	whole loadedNote
+
+	// Notebooks, tags and special views beside the list on large terminals
+	sidebar sidebar
+}
+
+const (
+	// searchDebounce is how long typing must pause before a query is issued
+	searchDebounce = 250 * time.Millisecond
+	// minResultsHeight is the fewest lines the scrolling results get
+	minResultsHeight = 8
+)
+
+// secondarySortOptions lists the tie-breakers the user can cycle through
+var secondarySortOptions = []models.SortField{
+	models.SortByID,
+	models.SortByTitle,
+	models.SortByCreated,
 }
 
 // NewNotesListModel creates a new notes list model
@@ -38,6 +105,12 @@ func NewNotesListModel(app *App) *NotesListModel {
 		loaded:        false,
 		searchQuery:   "",
 		searchMode:    false,
+		spinner:       spinner.New(spinner.WithSpinner(spinner.Dot)),
+		sortBy:        models.SortByUpdated,
+		secondarySort: models.SortByID,
+		selected:      map[int]bool{},
+		bulkInput:     newBulkInput(),
+		tagEditor:     newInlineTagEditor(),
 	}
 }
 
@@ -48,39 +121,71 @@ func (m *NotesListModel) Init() tea.Cmd {
 
 // loadNotes loads notes from storage
 func (m *NotesListModel) loadNotes() tea.Cmd {
+	filter := models.NoteFilter{
+		Limit:         m.pageLimit(m.app.GetConfig().ListLimit, len(m.allNotes)),
+		SortBy:        m.sortBy,
+		SecondarySort: m.secondarySort,
+		Reverse:       m.sortReverse,
+		PinnedFirst:   true,
+	}
 	return func() tea.Msg {
-		notes, err := m.app.GetStorage().GetAllNotes(models.NoteFilter{Limit: 100})
+		notes, err := m.app.GetStorage().GetAllSummaries(filter)
 		if err != nil {
 			// For now, just return empty list on error
 			return notesLoadedMsg{notes: []*models.Note{}}
 		}
-		return notesLoadedMsg{notes: notes}
+		return notesLoadedMsg{notes: notes, total: m.countNotes(context.Background(), filter, len(notes))}
 	}
 }
 
-// filterNotes filters notes based on the current search query
-func (m *NotesListModel) filterNotes() {
-	if m.searchQuery == "" {
-		// If no search query, show all notes
-		m.filteredNotes = make([]*models.Note, len(m.allNotes))
-		copy(m.filteredNotes, m.allNotes)
-		return
+// limit returns the configured limit, or 0 once the user asked for every
+// note
+func (m *NotesListModel) limit(configured int) int {
+	if m.showAll {
+		return 0
 	}
+	return configured
+}
 
-	// Perform fuzzy search
-	searchTerms := utils.SplitWords(m.searchQuery)
-	m.filteredNotes = []*models.Note{}
+// countNotes returns how many notes match filter. Only a full page can have
+// left notes out, so the count is only queried then.
+func (m *NotesListModel) countNotes(ctx context.Context, filter models.NoteFilter, loaded int) int {
+	if filter.Limit == 0 || loaded < filter.Limit {
+		return loaded
+	}
+	total, err := m.app.GetStorage().CountNotesContext(ctx, filter)
+	if err != nil {
+		slog.Warn("failed to count notes", "err", err)
+		return loaded
+	}
+	return total
+}
 
-	for _, note := range m.allNotes {
-		// Search in title and content
-		titleWords := utils.SplitWords(note.Title)
-		contentWords := utils.SplitWords(note.Content)
+// resultTotal returns how many notes match the list or the current search
+func (m *NotesListModel) resultTotal() int {
+	if m.searchQuery != "" {
+		return m.searchTotal
+	}
+	return m.allTotal
+}
 
-		// Check if any search term matches title or content
-		if utils.ContainsAnyWord(searchTerms, titleWords) || utils.ContainsAnyWord(searchTerms, contentWords) {
-			m.filteredNotes = append(m.filteredNotes, note)
-		}
+// expandResults lifts the configured limits and loads every matching note
+func (m *NotesListModel) expandResults() tea.Cmd {
+	if m.showAll || m.resultTotal() <= len(m.filteredNotes) {
+		return nil
 	}
+	m.showAll = true
+	if m.searchQuery != "" {
+		m.searchSeq++
+		return m.runSearch(m.searchSeq)
+	}
+	return m.loadNotes()
+}
+
+// showAllNotes resets the visible list to every loaded note
+func (m *NotesListModel) showAllNotes() {
+	m.filteredNotes = make([]*models.Note, len(m.allNotes))
+	copy(m.filteredNotes, m.allNotes)
 
 	// Reset cursor if it's out of bounds
 	if m.cursor >= len(m.filteredNotes) {
@@ -88,19 +193,80 @@ func (m *NotesListModel) filterNotes() {
 	}
 }
 
+// scheduleSearch debounces a search for the current query. Every change bumps
+// the sequence number so that older timers and in-flight queries become stale.
+func (m *NotesListModel) scheduleSearch() tea.Cmd {
+	m.searchSeq++
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+		m.cancelSearch = nil
+	}
+	// A new query starts with the configured limit again
+	m.showAll = false
+
+	if m.searchQuery == "" {
+		m.searching = false
+		m.showAllNotes()
+		return nil
+	}
+
+	seq := m.searchSeq
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchDebounceMsg{seq: seq}
+	})
+}
+
+// runSearch queries storage for the current search query
+func (m *NotesListModel) runSearch(seq int) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel
+	m.searching = true
+
+	// Results are sorted the same way as the full list
+	filter := utils.ParseQuery(m.searchQuery)
+	filter.Limit = m.limit(m.app.GetConfig().SearchLimit)
+	filter.SortBy = m.sortBy
+	filter.SecondarySort = m.secondarySort
+	filter.Reverse = m.sortReverse
+	search := func() tea.Msg {
+		notes, err := m.app.GetStorage().GetAllSummariesContext(ctx, filter)
+		if err != nil {
+			return searchResultsMsg{seq: seq, err: err}
+		}
+		return searchResultsMsg{seq: seq, notes: notes, total: m.countNotes(ctx, filter, len(notes))}
+	}
+	return tea.Batch(m.spinner.Tick, search)
+}
+
+// showQuery shows the results of a search query, e.g. when jumping in from
+// the stats view. The results load when the list is next initialized.
+func (m *NotesListModel) showQuery(query string) {
+	m.searchMode = false
+	m.searchQuery = query
+	m.cursor = 0
+	m.clearSelection()
+}
+
 // setSearchMode enables/disables search mode
-func (m *NotesListModel) setSearchMode(enabled bool) {
+func (m *NotesListModel) setSearchMode(enabled bool) tea.Cmd {
 	m.searchMode = enabled
 	if enabled {
 		m.cursor = 0
-	} else {
-		m.searchQuery = ""
-		m.filterNotes() // Reset filter when exiting search mode
+		return nil
 	}
+	m.searchQuery = ""
+	return m.scheduleSearch() // Reset results when exiting search mode
 }
 
 // Update handles updates for the notes list
 func (m *NotesListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	// Whichever note ends up under the cursor, the previews need it whole
+	return model, tea.Batch(cmd, m.loadCursorNote())
+}
+
+// update handles a message for the notes list
+func (m *NotesListModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -108,40 +274,214 @@ func (m *NotesListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case notesLoadedMsg:
 		m.allNotes = msg.notes
-		m.filterNotes() // Apply current search filter to loaded notes
+		m.allTotal = msg.total
 		m.loaded = true
+		// Keep the sidebar counts in step with the notes
+		counts := m.loadSidebarCounts()
+		if m.searchQuery != "" {
+			// Re-run the active search against the refreshed data
+			m.searchSeq++
+			return m.app, tea.Batch(m.runSearch(m.searchSeq), counts)
+		}
+		m.showAllNotes()
+		return m.app, counts
+
+	case sidebarCountsMsg:
+		m.sidebar.counts = msg.counts
+		m.sidebar.saved = msg.saved
+		return m.app, nil
+
+	case searchDebounceMsg:
+		// Only the latest keystroke's timer triggers a query
+		if msg.seq != m.searchSeq {
+			return m.app, nil
+		}
+		return m.app, m.runSearch(msg.seq)
+
+	case notesPageMsg:
+		m.addPage(msg)
+		return m.app, nil
+
+	case cursorNoteMsg:
+		m.setCursorNote(msg)
+		return m.app, nil
+
+	case compareNotesMsg:
+		return m.app, m.app.openCompare(msg.left, msg.right)
+
+	case clipboardNoteMsg:
+		return m.app, m.openClipboardNote(msg)
+
+	case searchResultsMsg:
+		// Drop results from superseded queries
+		if msg.seq != m.searchSeq {
+			return m.app, nil
+		}
+		m.searching = false
+		m.cancelSearch = nil
+		if msg.err == nil {
+			m.filteredNotes = msg.notes
+			m.searchTotal = msg.total
+			if m.cursor >= len(m.filteredNotes) {
+				m.cursor = 0
+			}
+		}
+		return m.app, nil
+
+	case colorSetMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+		} else {
+			m.statusMsg = msg.status
+		}
+		return m.app, m.loadNotes()
+
+	case pinnedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+		} else if msg.status != "" {
+			m.statusMsg = msg.status
+		}
+		return m.app, m.loadNotes()
+
+	case bulkDoneMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			return m.app, m.loadNotes()
+		}
+		m.statusMsg = msg.status
+		m.clearSelection()
+		if msg.undo != nil {
+			return m.app, tea.Batch(m.loadNotes(), m.offerUndo(msg.status, msg.undo))
+		}
+		return m.app, m.loadNotes()
+
+	case undoExpiredMsg:
+		if msg.seq == m.undoSeq {
+			m.undo = nil
+		}
+		return m.app, nil
+
+	case undoneMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+		} else {
+			m.statusMsg = msg.status
+		}
+		return m.app, m.loadNotes()
+
+	case spinner.TickMsg:
+		if !m.searching {
+			return m.app, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m.app, cmd
+
+	case inlineTagSavedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+		}
 		return m.app, nil
 
 	case tea.KeyMsg:
+		// The inline tag editor captures all input until closed
+		if m.tagEditor.active {
+			return m.app, m.handleTagEditorKey(msg)
+		}
+
+		// Bulk action prompts capture all input until confirmed or cancelled
+		if m.bulkAction != bulkNone {
+			return m.app, m.handleBulkKey(msg)
+		}
+
+		// So does the action menu until an action is picked
+		if m.menu.active {
+			return m.app, m.handleMenuKey(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+s":
 			// Toggle search mode
-			m.setSearchMode(!m.searchMode)
+			return m.app, m.setSearchMode(!m.searchMode)
+		}
+
+		// Capture hooks start a note with their command's output
+		if hook, ok := m.app.captureHook(msg); ok {
+			return m.app, runCapture(hook, nil)
 		}
 
 		// Handle search mode input
 		if m.searchMode {
 			switch msg.String() {
-			case "escape":
+			case "esc", "escape":
 				// Exit search mode
-				m.setSearchMode(false)
+				return m.app, m.setSearchMode(false)
+			case "ctrl+w", "ctrl+u":
+				// The emacs preset deletes the last word or the whole query
+				if emacsKeys() && m.searchQuery != "" {
+					query := strings.TrimRight(m.searchQuery, " ")
+					if msg.String() == "ctrl+u" {
+						query = ""
+					} else if i := strings.LastIndex(query, " "); i >= 0 {
+						query = query[:i+1]
+					} else {
+						query = ""
+					}
+					killed(msg.String(), m.searchQuery, query)
+					m.searchQuery = query
+					return m.app, m.scheduleSearch()
+				}
+			case "ctrl+y":
+				if emacsKeys() && killBuffer.text != "" {
+					m.searchQuery += strings.ReplaceAll(killBuffer.text, "\n", " ")
+					return m.app, m.scheduleSearch()
+				}
 			case "backspace":
 				if len(m.searchQuery) > 0 {
-					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-					m.filterNotes()
+					runes := []rune(m.searchQuery)
+					m.searchQuery = string(runes[:len(runes)-1])
+					return m.app, m.scheduleSearch()
 				}
 			case "enter":
-				// Exit search mode on enter
-				m.setSearchMode(false)
+				// Leave search mode but keep the results
+				m.searchMode = false
 			default:
 				// Regular character input for search
-				char := msg.String()
-				if len(char) == 1 {
-					m.searchQuery += char
-					m.filterNotes()
+				if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+					m.searchQuery += string(msg.Runes)
+					return m.app, m.scheduleSearch()
 				}
 			}
 		} else {
+			// Ctrl+B moves focus between the sidebar and the list, and the
+			// focused sidebar takes the moving keys
+			if msg.String() == "ctrl+b" {
+				return m.app, m.focusSidebar()
+			}
+			if m.sidebar.focused && m.sidebarShown() {
+				if handled, cmd := m.handleSidebarKey(msg); handled {
+					return m.app, cmd
+				}
+			}
+
+			// Tab moves focus between the list and the preview pane, and
+			// the focused preview takes the scrolling keys
+			if m.browserActive() {
+				if msg.String() == "tab" {
+					m.browser.focused = !m.browser.focused
+					return m.app, nil
+				}
+				if m.browser.focused && m.handleBrowserKey(msg) {
+					return m.app, nil
+				}
+			}
+
+			// "gg" jumps to the top; any other key cancels a pending "g"
+			if msg.String() != "g" {
+				m.pendingG = false
+			}
+
 			// Normal navigation mode
 			switch msg.String() {
 			case "up", "k":
@@ -152,32 +492,234 @@ func (m *NotesListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.cursor < len(m.filteredNotes)-1 {
 					m.cursor++
 				}
+			case "pgup":
+				m.moveCursor(-m.visibleRows())
+			case "pgdown":
+				m.moveCursor(m.visibleRows())
+			case "home":
+				m.cursor = 0
+			case "end", "G":
+				m.cursor = max(len(m.filteredNotes)-1, 0)
+			case "g":
+				if m.pendingG {
+					m.cursor = 0
+				}
+				m.pendingG = !m.pendingG
 			case "n", "N":
 				// New note
 				m.selectedNote = nil
 				return m.app, m.app.SwitchToView(ViewNoteEditor)
+			case "ctrl+v":
+				// New note from the clipboard
+				return m.app, m.newNoteFromClipboard()
 			case "e", "enter":
 				// Edit selected note
 				if len(m.filteredNotes) > 0 {
 					m.selectedNote = m.filteredNotes[m.cursor]
 					return m.app, m.app.SwitchToView(ViewNoteEditor)
 				}
+			case " ":
+				// Toggle selection of the note under the cursor
+				m.toggleSelection()
+			case "V":
+				// Select from the last toggled note to the cursor
+				m.selectRange()
+			case "esc":
+				m.clearSelection()
 			case "d":
+				if len(m.selected) > 0 {
+					return m.app, m.startBulkAction(bulkDelete)
+				}
 				// Delete selected note
 				if len(m.filteredNotes) > 0 {
 					m.selectedNote = nil
 					return m.app, m.deleteNote()
 				}
+			case "P":
+				// Pin or unpin the note under the cursor
+				if len(m.filteredNotes) > 0 {
+					return m.app, m.togglePin(m.filteredNotes[m.cursor])
+				}
+			case "shift+up", "shift+down":
+				// Reorder pinned notes
+				offset := 1
+				if msg.String() == "shift+up" {
+					offset = -1
+				}
+				return m.app, m.movePinned(offset)
+			case "c":
+				// Compare the two selected notes side by side
+				if notes := m.selectedNotes(); len(notes) == 2 {
+					return m.app, m.compareNotes(notes[0], notes[1])
+				}
+				// Without a selection, cycle the color label of the note
+				// under the cursor
+				if len(m.selected) == 0 && len(m.filteredNotes) > 0 {
+					return m.app, m.cycleColor(m.filteredNotes[m.cursor])
+				}
+				m.statusMsg = "Select two notes with space to compare them"
+			case "ctrl+g":
+				// Commit, pull and push through the sync repository
+				return m.app, m.app.startSync()
+			case "v":
+				// Switch to another vault
+				return m.app, m.app.SwitchToView(ViewVaults)
+			case "S":
+				// Manage text snippets
+				return m.app, m.app.SwitchToView(ViewSnippets)
+			case "#":
+				// Manage tag colors and aliases
+				return m.app, m.app.SwitchToView(ViewTags)
+			case "+", "-", "m", "x":
+				if len(m.selected) == 0 {
+					// Without a selection, m opens the actions for the note
+					// under the cursor
+					if msg.String() == "m" {
+						m.openActionMenu()
+					}
+					break
+				}
+				actions := map[string]bulkAction{
+					"+": bulkAddTag,
+					"-": bulkRemoveTag,
+					"m": bulkMove,
+					"x": bulkExport,
+				}
+				return m.app, m.startBulkAction(actions[msg.String()])
+			case "o":
+				// Cycle the secondary sort key
+				m.cycleSecondarySort()
+				return m.app, m.loadNotes()
+			case "]":
+				// Return to the notes open in editor tabs
+				if len(m.app.editors) > 0 {
+					m.app.currentView = ViewNoteEditor
+				}
+			case "b":
+				// Toggle the two-pane layout
+				return m.app, m.toggleBrowser()
+			case "B":
+				// Show or hide the sidebar
+				return m.app, m.toggleSidebar()
+			case "p":
+				// Toggle the preview of the note under the cursor
+				m.peek.visible = !m.peek.visible
+				return m.app, nil
+			case "T":
+				// Edit the tags of the note under the cursor in place
+				return m.app, m.openTagEditor()
+			case "t":
+				// Task dashboard
+				return m.app, m.app.SwitchToView(ViewTasks)
+			case "C":
+				// Calendar of notes, daily notes and due dates
+				return m.app, m.app.SwitchToView(ViewCalendar)
+			case "1", "2", "3", "4", "5":
+				// Column hotkeys sort the table layout
+				if _, ok := m.layout().(tableLayout); ok {
+					field, _ := tableSortField(msg.String())
+					return m.app, m.sortByColumn(field)
+				}
+			case "A":
+				// Show every note beyond the configured limit
+				return m.app, m.expandResults()
+			case "L":
+				// Cycle compact, detailed and card layouts
+				m.scrollOffset = 0
+				return m.app, m.cycleLayout()
+			case "s":
+				// Stats and vault health
+				return m.app, m.app.SwitchToView(ViewStats)
 			case "h", "H":
 				// Help
 				return m.app, m.app.SwitchToView(ViewHelp)
+			case "ctrl+z":
+				// Undo the last delete, retag or move
+				return m.app, m.runUndo()
 			case "ctrl+c":
 				// Quit
 				return m.app, tea.Quit
 			}
 		}
 	}
-	return m.app, nil
+	// Moving near the end of the list loads the next page
+	return m.app, m.loadMore()
+}
+
+// moveCursor moves the cursor by delta notes, clamped to the list
+func (m *NotesListModel) moveCursor(delta int) {
+	m.cursor = max(min(m.cursor+delta, len(m.filteredNotes)-1), 0)
+}
+
+// visibleRows returns how many notes fit in the results viewport, as
+// measured by the last render
+func (m *NotesListModel) visibleRows() int {
+	if m.viewportRows > 0 {
+		return m.viewportRows
+	}
+	return max((m.height-10)/m.layout().rowHeight(), 1)
+}
+
+// maxTitleLength returns the responsive title length (more generous on wide terminals)
+func (m *NotesListModel) maxTitleLength() int {
+	if m.width < 80 {
+		return m.width - 15
+	} else if m.width < 120 {
+		return m.width - 20
+	}
+	return 60 // Cap at 60 for readability
+}
+
+// scrollToCursor adjusts the scroll offset so the cursor stays visible
+func (m *NotesListModel) scrollToCursor(rows int) {
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	}
+	if m.cursor >= m.scrollOffset+rows {
+		m.scrollOffset = m.cursor - rows + 1
+	}
+	m.scrollOffset = max(min(m.scrollOffset, len(m.filteredNotes)-rows), 0)
+}
+
+// cycleSecondarySort advances to the next secondary sort key
+func (m *NotesListModel) cycleSecondarySort() {
+	for i, field := range secondarySortOptions {
+		if field == m.secondarySort {
+			m.secondarySort = secondarySortOptions[(i+1)%len(secondarySortOptions)]
+			return
+		}
+	}
+	m.secondarySort = secondarySortOptions[0]
+}
+
+// sortLabel describes the active sort settings
+func (m *NotesListModel) sortLabel() string {
+	names := map[models.SortField]string{
+		models.SortByUpdated: "updated",
+		models.SortByCreated: "created",
+		models.SortByTitle:   "title",
+		models.SortByID:      "id",
+		models.SortByTags:    "tags",
+		models.SortByWords:   "words",
+	}
+	primary := names[m.sortBy]
+	if m.sortReverse {
+		primary += " (reversed)"
+	}
+	return fmt.Sprintf("Sort: %s, then %s (o to change)", primary, names[m.secondarySort])
+}
+
+// sortByColumn sorts by a table column, reversing the order when the list
+// is already sorted by it
+func (m *NotesListModel) sortByColumn(field models.SortField) tea.Cmd {
+	if m.sortBy == field {
+		m.sortReverse = !m.sortReverse
+	} else {
+		m.sortBy = field
+		m.sortReverse = false
+	}
+	m.cursor = 0
+	return m.loadNotes()
 }
 
 // deleteNote deletes the currently selected note
@@ -188,13 +730,17 @@ func (m *NotesListModel) deleteNote() tea.Cmd {
 
 	selectedNote := m.filteredNotes[m.cursor]
 	return func() tea.Msg {
-		err := m.app.GetStorage().DeleteNote(selectedNote.ID)
+		// Undoing restores the whole note, not the summary listed
+		notes, err := m.app.GetStorage().LoadContent([]*models.Note{selectedNote})
+		if err != nil {
+			return bulkDoneMsg{err: err}
+		}
+		revert := restoreNotes(notes)
+		err = m.app.GetStorage().DeleteNote(selectedNote.ID)
 		if err != nil {
-			// For now, just ignore errors
-			return nil
+			return bulkDoneMsg{err: err}
 		}
-		// Reload notes after deletion
-		return m.loadNotes()()
+		return bulkDoneMsg{status: fmt.Sprintf("Deleted %q", selectedNote.Title), undo: revert}
 	}
 }
 
@@ -252,20 +798,104 @@ func (m *NotesListModel) renderQuickActions() string {
 		Italic(true).
 		MarginBottom(1)
 
-	shortcuts := shortcutsStyle.Render("N: New • S: Search • ↑↓: Navigate • Enter: Edit • Ctrl+C: Quit")
+	shortcuts := shortcutsStyle.Render("N: New • S: Search • T: Tasks • ↑↓: Navigate • Enter: Edit • Ctrl+C: Quit")
 	return shortcuts
 }
 
 
-// View renders the notes list with centered layout and orange/yellow highlighting
+// renderSkeleton renders placeholder rows shown while notes are loading
+func (m *NotesListModel) renderSkeleton() string {
+	placeholderStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#334155")).
+		Background(lipgloss.Color("#1F2937")).
+		Padding(0, 1).
+		MarginLeft(1).
+		MarginRight(1)
+
+	widths := []int{38, 26, 44, 31, 22}
+	s := ""
+	for _, w := range widths {
+		s += "  " + placeholderStyle.Render(strings.Repeat("░", w)) + "\n"
+	}
+	s += lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#64748B")).
+		Italic(true).
+		Render("Loading notes...")
+	return s
+}
+
+// View renders the notes list with centered layout and orange/yellow highlighting.
+// The header and search box are pinned at the top of the container and the
+// results scroll in the space left below them.
 func (m *NotesListModel) View() string {
-	if !m.loaded {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#94A3B8")).
-			Bold(true).
-			Render("Loading notes...")
+	containerWidth := m.containerWidth()
+
+	// Border and padding take 6 lines of the container
+	innerHeight := max(m.height-6, 1)
+
+	// Measure the header as the container wraps it
+	headerStyle := lipgloss.NewStyle().Width(containerWidth - 4)
+	header := headerStyle.Render(m.renderHeaderRegion(true))
+	if innerHeight-lipgloss.Height(header) < minResultsHeight ||
+		lipgloss.Width(m.renderGradientHeader()) > containerWidth-4 {
+		// Drop the banner on short or narrow containers so results keep some room
+		header = headerStyle.Render(m.renderHeaderRegion(false))
+	}
+	resultsHeight := max(innerHeight-lipgloss.Height(header), minResultsHeight)
+
+	// On narrower terminals the preview takes the space below the results
+	// that the results can spare
+	peekBelow := ""
+	if m.peek.visible && !m.peekSideBySide() && !m.browserActive() {
+		if room := resultsHeight - minResultsHeight; room >= 5 {
+			peekBelow = m.renderPeek(containerWidth-4, min(room, peekLines+3))
+			resultsHeight -= lipgloss.Height(peekBelow)
+		}
+	}
+
+	content := header + "\n" + m.renderResults(resultsHeight)
+	if peekBelow != "" {
+		content += "\n" + peekBelow
+	}
+
+	// Wrap everything in a centered container
+	containerStyle := lipgloss.NewStyle().
+		Width(containerWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#334155")).
+		Padding(2, 2).
+		Background(lipgloss.Color("#0F172A"))
+
+	container := containerStyle.Render(content)
+	if m.browserActive() {
+		// The preview pane takes the rest of the width at the container's height
+		paneWidth := m.width - m.sidebarSpace() - containerWidth - 5
+		pane := m.renderBrowserPane(paneWidth, lipgloss.Height(container))
+		container = lipgloss.JoinHorizontal(lipgloss.Top, container, " ", pane)
+	} else if m.peekSideBySide() {
+		pane := m.renderPeek(peekPaneWidth, lipgloss.Height(container))
+		container = lipgloss.JoinHorizontal(lipgloss.Top, container, " ", pane)
 	}
+	if m.sidebarShown() {
+		sidebar := m.renderSidebar(lipgloss.Height(container))
+		container = lipgloss.JoinHorizontal(lipgloss.Top, sidebar, " ", container)
+	}
+
+	centeredContent := lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			container,
+	)
 
+	if m.menu.active {
+		return overlay(centeredContent, m.renderActionMenu(), m.width)
+	}
+	return centeredContent
+}
+
+// renderHeaderRegion renders the pinned part of the list: the banner (when
+// showBanner is set), quick actions, search box, sort label and status line
+func (m *NotesListModel) renderHeaderRegion(showBanner bool) string {
 	// Define warm colors for highlighting
 	orangeHighlight := "#EA580C" // Orange
 
@@ -291,7 +921,15 @@ func (m *NotesListModel) View() string {
 		Bold(true)
 
 	// Build the content
-	content := m.renderGradientHeader() + "\n\n"
+	content := ""
+	if showBanner {
+		content += m.renderGradientHeader() + "\n\n"
+	} else {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(orangeHighlight)).
+			Bold(true).
+			Render("QuillNotes") + "\n"
+	}
 
 	// Minimal shortcuts
 	content += m.renderQuickActions() + "\n\n"
@@ -314,9 +952,11 @@ func (m *NotesListModel) View() string {
 		if m.searchQuery != "" {
 			// Show search query with results count
 			content += searchInactiveStyle.Render(m.searchQuery)
-			content += lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#F59E0B")).
-				Render(fmt.Sprintf(" (%d results)", len(m.filteredNotes)))
+			if !m.searching {
+				content += lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#F59E0B")).
+					Render(fmt.Sprintf(" (%d results)", len(m.filteredNotes)))
+			}
 		} else {
 			// Inactive state with prompt
 			promptStyle := searchInactiveStyle.
@@ -325,107 +965,105 @@ func (m *NotesListModel) View() string {
 		}
 	}
 
-	content += "\n\n"
+	// Loading indicator while a query is in flight
+	if m.searching {
+		content += " " + m.spinner.View() + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F59E0B")).
+			Render("Searching...")
+	}
+
+	content += "\n"
+	content += lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#64748B")).
+		Render(m.sortLabel())
+	if indicator := m.app.syncIndicator(); indicator != "" {
+		content += "  " + indicator
+	}
+	if len(m.app.config.Vaults) > 0 {
+		content += "  " + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#A78BFA")).
+			Render("▣ "+m.app.vault.Name)
+	}
+	content += "\n"
+
+	// Inline tag editor, selection count and bulk prompts, or the outcome
+	// of the last bulk action
+	if m.tagEditor.active {
+		content += m.renderTagEditor()
+	} else if len(m.selected) > 0 || m.bulkAction != bulkNone {
+		content += m.renderSelectionBar()
+	} else if m.statusMsg != "" {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Italic(true).
+			Render(m.statusMsg)
+		if m.undo != nil {
+			content += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F59E0B")).
+				Bold(true).
+				Render("  ↶ ctrl+z: undo")
+		}
+	}
+	return content
+}
 
-	// Notes list with orange/yellow highlighting
+// renderResults renders the scrolling results region in height lines,
+// keeping the cursor in view
+func (m *NotesListModel) renderResults(height int) string {
+	if !m.loaded {
+		// Skeleton rows keep the layout stable until notes stream in
+		return m.renderSkeleton()
+	}
 	if len(m.filteredNotes) == 0 {
 		if m.searchQuery != "" {
-			content += lipgloss.NewStyle().
+			return lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#94A3B8")).
 				Italic(true).
 				Render("No notes found matching \"" + m.searchQuery + "\"")
-		} else {
-			content += lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#94A3B8")).
-				Italic(true).
-				Render("No notes yet. Press 'n' to create your first note.")
-		}
-	} else {
-		// Calculate responsive max lines
-		usedHeight := 6 // Reduced from 8
-		available := m.height - usedHeight - 4
-		maxLines := max(available, 5)
-
-		displayNotes := m.filteredNotes
-		if len(displayNotes) > maxLines {
-			displayNotes = displayNotes[:maxLines]
 		}
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Italic(true).
+			Render("No notes yet. Press 'n' to create your first note.")
+	}
 
-		// Calculate responsive title length (more generous)
-		maxTitleLength := func() int {
-			if m.width < 80 {
-				return m.width - 15
-			} else if m.width < 120 {
-				return m.width - 20
-			} else {
-				return 60 // Cap at 60 for readability
-			}
-		}()
-
-		for i, note := range displayNotes {
-			// Orange/amber cursor for selected item
-			cursor := "  "
-			if m.cursor == i {
-				cursor = lipgloss.NewStyle().
-					Foreground(lipgloss.Color(orangeHighlight)).
-					Bold(true).
-					Render("▶ ")
-			}
+	// Two lines are kept for the scroll hints above and below the notes
+	layout := m.layout()
+	m.viewportRows = max((height-2)/layout.rowHeight(), 1)
+	m.scrollToCursor(m.viewportRows)
+	end := min(m.scrollOffset+m.viewportRows, len(m.filteredNotes))
+	displayNotes := m.filteredNotes[m.scrollOffset:end]
 
-			// Truncate title
-			title := note.Title
-			if len(title) > maxTitleLength {
-				title = title[:maxTitleLength-3] + "..."
-			}
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#64748B")).
+		Italic(true)
 
-			// Apply orange/yellow highlighting for selected notes
-			itemStyle := lipgloss.NewStyle()
-			if m.cursor == i {
-				// Orange to amber gradient background
-				itemStyle = itemStyle.
-					Background(lipgloss.Color(orangeHighlight)).
-					Foreground(lipgloss.Color("#0F172A")).
-					Bold(true).
-					Padding(0, 1).
-					MarginLeft(1).
-					MarginRight(1)
-			} else {
-				// Subtle yellow background for non-selected
-				itemStyle = itemStyle.
-					Background(lipgloss.Color("#1F2937")). // Dark background
-					Foreground(lipgloss.Color("#F1F5F9")).
-					Padding(0, 1).
-					MarginLeft(1).
-					MarginRight(1)
-			}
+	content := ""
+	if m.scrollOffset > 0 {
+		content += hintStyle.Render(fmt.Sprintf("↑ %d more", m.scrollOffset))
+	}
+	content += "\n"
 
-			content += cursor + itemStyle.Render(title) + "\n"
-		}
+	rowWidth := m.containerWidth() - 6 // container padding and cursor
+	content += layout.render(m, displayNotes, m.cursor-m.scrollOffset, rowWidth, time.Now())
 
-		if len(m.filteredNotes) > maxLines {
-			content += lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#64748B")).
-				Italic(true).
-				Render(fmt.Sprintf("... and %d more", len(m.filteredNotes)-maxLines))
+	var hints []string
+	if len(m.filteredNotes) > m.viewportRows {
+		if below := len(m.filteredNotes) - end; below > 0 {
+			hints = append(hints, fmt.Sprintf("↓ %d more", below))
 		}
+		hints = append(hints, fmt.Sprintf("%d/%d", m.cursor+1, len(m.filteredNotes)))
 	}
-
-	// Wrap everything in a centered container
-	containerWidth := min(m.width-4, 100) // Max 100 chars width
-	containerStyle := lipgloss.NewStyle().
-		Width(containerWidth).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#334155")).
-		Padding(2, 2).
-		Background(lipgloss.Color("#0F172A"))
-
-	centeredContent := lipgloss.Place(
-			m.width, m.height,
-			lipgloss.Center, lipgloss.Center,
-			containerStyle.Render(content),
-	)
-
-	return centeredContent
+	if total := m.resultTotal(); total > len(m.filteredNotes) {
+		// More notes load on scrolling near the end
+		if m.loadingMore {
+			hints = append(hints, fmt.Sprintf("%d of %d loaded, loading more...", len(m.filteredNotes), total))
+		} else {
+			hints = append(hints, fmt.Sprintf("%d of %d loaded, A: load all", len(m.filteredNotes), total))
+		}
+	}
+	content += hintStyle.Render(strings.Join(hints, " • "))
+	return content
 }
 
 // Helper function
@@ -439,4 +1077,16 @@ func min(a, b int) int {
 // Messages
 type notesLoadedMsg struct {
 	notes []*models.Note
+	total int // notes in the database, more than loaded when limited
+}
+
+type searchDebounceMsg struct {
+	seq int
+}
+
+type searchResultsMsg struct {
+	seq   int
+	notes []*models.Note
+	total int // matching notes, more than returned when limited
+	err   error
 }
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"markdown-note-taking-app/internal/instance"
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openRequestTimeout bounds how long a later invocation waits for the
+// note it asked for to be opened
+const openRequestTimeout = 5 * time.Second
+
+// SetInstance gives the app the claim on its vault's database, so it
+// moves along when switching vaults
+func (a *App) SetInstance(server *instance.Server) {
+	a.instance = server
+}
+
+// OpenOnStart opens the note titled title once the vault can be read
+func (a *App) OpenOnStart(title string) {
+	a.startTitle = title
+}
+
+// OpenHandler returns the function answering a later invocation's request
+// to open a note, like tuinotes open, in the program p runs
+func OpenHandler(p *tea.Program) func(title string) error {
+	return func(title string) error {
+		reply := make(chan error, 1)
+		p.Send(openRequestMsg{title: title, reply: reply})
+		select {
+		case err := <-reply:
+			return err
+		case <-time.After(openRequestTimeout):
+			return errors.New("tuinotes didn't answer")
+		}
+	}
+}
+
+// openStartNote opens the note asked for on start, once
+func (a *App) openStartNote() tea.Cmd {
+	if a.startTitle == "" {
+		return nil
+	}
+	title := a.startTitle
+	a.startTitle = ""
+	return a.requestNote(title, nil)
+}
+
+// requestNote looks up the note titled title, telling reply whether it was
+// found when the request came from another invocation
+func (a *App) requestNote(title string, reply chan error) tea.Cmd {
+	service := a.storage
+	return func() tea.Msg {
+		note, err := service.FindNoteByTitle(title)
+		if reply != nil {
+			// The invocation that asked reports the error
+			reply <- err
+			if err != nil {
+				return nil
+			}
+		}
+		return requestedNoteMsg{note: note, err: err}
+	}
+}
+
+// answerOpenRequest opens the note another invocation asked for, unless
+// the vault is locked
+func (a *App) answerOpenRequest(msg openRequestMsg) tea.Cmd {
+	if a.storage.Locked() {
+		msg.reply <- errors.New("the vault is locked; unlock it first")
+		return nil
+	}
+	return a.requestNote(msg.title, msg.reply)
+}
+
+// openRequestedNote switches to the note asked for, so going back
+// returns to where the app was
+func (a *App) openRequestedNote(msg requestedNoteMsg) tea.Cmd {
+	if msg.err != nil {
+		a.notesList.statusMsg = "Error: " + msg.err.Error()
+		return nil
+	}
+	from := a.here()
+	a.currentView = ViewNoteEditor
+	cmd := a.openEditor(msg.note)
+	a.visited(from)
+	return cmd
+}
+
+// Messages
+
+// openRequestMsg carries another invocation's request to open a note
+type openRequestMsg struct {
+	title string
+	reply chan error
+}
+
+// requestedNoteMsg carries the note asked for on start or by another
+// invocation, or why it wasn't found
+type requestedNoteMsg struct {
+	note *models.Note
+	err  error
+}
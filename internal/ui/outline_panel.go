@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// outlinePanel lists the headings of the note as a tree. Sections can be
+// collapsed to hide their subheadings, and choosing a heading jumps to it.
+type outlinePanel struct {
+	visible   bool
+	headings  []utils.Heading
+	collapsed map[int]bool // indexes of collapsed headings
+	cursor    int          // index into rows()
+}
+
+// openOutlinePanel shows the outline of the note as it's being edited,
+// with the cursor on the section the content cursor is in
+func (m *NoteEditorModel) openOutlinePanel() {
+	m.outline = outlinePanel{
+		visible:   true,
+		headings:  utils.Headings(m.contentInput.Value()),
+		collapsed: map[int]bool{},
+	}
+	line := m.contentInput.Line()
+	for i, heading := range m.outline.headings {
+		if heading.Line <= line {
+			m.outline.cursor = i
+		}
+	}
+}
+
+// rows returns the indexes of the headings not hidden in a collapsed section
+func (p *outlinePanel) rows() []int {
+	var rows []int
+	hideBelow := 0 // headings deeper than this level are hidden, 0 for none
+	for i, heading := range p.headings {
+		if hideBelow > 0 && heading.Level > hideBelow {
+			continue
+		}
+		hideBelow = 0
+		rows = append(rows, i)
+		if p.collapsed[i] {
+			hideBelow = heading.Level
+		}
+	}
+	return rows
+}
+
+// hasChildren reports whether the heading at index i has subheadings
+func (p *outlinePanel) hasChildren(i int) bool {
+	return i+1 < len(p.headings) && p.headings[i+1].Level > p.headings[i].Level
+}
+
+// selected returns the index of the heading under the cursor, or -1
+func (p *outlinePanel) selected() int {
+	rows := p.rows()
+	if p.cursor < 0 || p.cursor >= len(rows) {
+		return -1
+	}
+	return rows[p.cursor]
+}
+
+// handleOutlineKey handles keys while the outline panel is open
+func (m *NoteEditorModel) handleOutlineKey(msg tea.KeyMsg) {
+	panel := &m.outline
+	i := panel.selected()
+	switch msg.String() {
+	case "esc", "alt+o":
+		panel.visible = false
+	case "up", "k":
+		panel.cursor = max(panel.cursor-1, 0)
+	case "down", "j":
+		panel.cursor = max(min(panel.cursor+1, len(panel.rows())-1), 0)
+	case "left", "h":
+		// Collapse the section, or move to its parent if it is already
+		if i < 0 {
+			return
+		}
+		if panel.hasChildren(i) && !panel.collapsed[i] {
+			panel.collapsed[i] = true
+			return
+		}
+		for parent := i - 1; parent >= 0; parent-- {
+			if panel.headings[parent].Level < panel.headings[i].Level {
+				panel.moveTo(parent)
+				return
+			}
+		}
+	case "right", "l":
+		if i >= 0 {
+			delete(panel.collapsed, i)
+		}
+	case " ":
+		if i >= 0 && panel.hasChildren(i) {
+			panel.collapsed[i] = !panel.collapsed[i]
+		}
+	case "enter":
+		if i >= 0 {
+			m.jumpToHeading(panel.headings[i])
+			panel.visible = false
+		}
+	}
+}
+
+// moveTo puts the cursor on the heading at index i, which must be shown
+func (p *outlinePanel) moveTo(i int) {
+	for row, index := range p.rows() {
+		if index == i {
+			p.cursor = row
+			return
+		}
+	}
+}
+
+// jumpToHeading moves the content cursor to a heading and scrolls the
+// split-pane preview to its section
+func (m *NoteEditorModel) jumpToHeading(heading utils.Heading) {
+	m.focused = 2
+	m.updateFocus()
+	m.showSuggestions = false
+
+	ta := &m.contentInput
+	for ta.Line() > heading.Line {
+		ta.CursorUp()
+	}
+	for ta.Line() < heading.Line && ta.Line() < ta.LineCount()-1 {
+		ta.CursorDown()
+	}
+	ta.CursorStart()
+	// The textarea scrolls to the cursor when it updates
+	*ta, _ = ta.Update(nil)
+
+	if m.splitPane {
+		m.preview.ScrollToSection(heading.Line)
+	}
+}
+
+// renderOutlinePanel renders the outline as a centered dialog
+func (m *NoteEditorModel) renderOutlinePanel() string {
+	panel := m.outline
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	markerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+	width := max(min(m.width-16, 60), 20)
+	height := max(m.height-12, 3)
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Outline") + "\n\n"
+
+	if len(panel.headings) == 0 {
+		s += hintStyle.Render("No headings in this note") + "\n"
+	}
+
+	top := 6
+	for _, heading := range panel.headings {
+		top = min(top, heading.Level)
+	}
+	rows := panel.rows()
+	start := max(min(panel.cursor-height/2, len(rows)-height), 0)
+	for row := start; row < min(start+height, len(rows)); row++ {
+		i := rows[row]
+		heading := panel.headings[i]
+		marker := " "
+		if panel.hasChildren(i) {
+			marker = "▾"
+			if panel.collapsed[i] {
+				marker = "▸"
+			}
+		}
+		indent := strings.Repeat("  ", heading.Level-top)
+		text := heading.Text
+		if text == "" {
+			text = "(untitled)"
+		}
+		line := fmt.Sprintf(" %-*s", width-len(indent), ansi.Truncate(text, width-len(indent), "…"))
+		if row == panel.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s += indent + markerStyle.Render(marker) + line + "\n"
+	}
+
+	s += "\n" + hintStyle.Render("Enter: Jump • ←→: Collapse/expand • Space: Toggle • Esc: Close")
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Render(s)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
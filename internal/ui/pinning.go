@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// togglePin pins the note under the cursor, or unpins it if it's pinned
+func (m *NotesListModel) togglePin(note *models.Note) tea.Cmd {
+	pinned := !note.Pinned
+	return func() tea.Msg {
+		if err := m.app.GetStorage().PinNote(note.ID, pinned); err != nil {
+			return pinnedMsg{err: err}
+		}
+		status := fmt.Sprintf("Pinned %q", note.Title)
+		if !pinned {
+			status = fmt.Sprintf("Unpinned %q", note.Title)
+		}
+		return pinnedMsg{status: status}
+	}
+}
+
+// movePinned moves the pinned note under the cursor offset places among the
+// pinned notes, which lead the list, and keeps the cursor on it
+func (m *NotesListModel) movePinned(offset int) tea.Cmd {
+	if len(m.filteredNotes) == 0 {
+		return nil
+	}
+	note := m.filteredNotes[m.cursor]
+	if !note.Pinned {
+		m.statusMsg = "Only pinned notes can be reordered (P pins a note)"
+		return nil
+	}
+	if m.searchQuery != "" {
+		m.statusMsg = "Clear the search to reorder pinned notes"
+		return nil
+	}
+
+	pinnedCount := 0
+	for pinnedCount < len(m.filteredNotes) && m.filteredNotes[pinnedCount].Pinned {
+		pinnedCount++
+	}
+	m.cursor = max(0, min(m.cursor+offset, pinnedCount-1))
+
+	return func() tea.Msg {
+		if err := m.app.GetStorage().MovePinnedNote(note.ID, offset); err != nil {
+			return pinnedMsg{err: err}
+		}
+		return pinnedMsg{}
+	}
+}
+
+// Messages
+
+// pinnedMsg reports the outcome of pinning, unpinning or reordering a note
+type pinnedMsg struct {
+	status string
+	err    error
+}
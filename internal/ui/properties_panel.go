@@ -0,0 +1,341 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// propertiesPanelWidth is the width of the properties panel, borders included
+const propertiesPanelWidth = 38
+
+// Rows of the properties panel above the custom properties
+const (
+	propertyRowNotebook = iota
+	propertyRowPinned
+	propertyRowArchived
+	propertyRowColor
+	fixedPropertyRows
+)
+
+// propertiesPanel shows a note's structured metadata beside the editor.
+// Changes are stored right away rather than on save.
+type propertiesPanel struct {
+	visible    bool
+	cursor     int
+	properties []models.Property // custom properties, sorted by key
+	editing    bool              // the input has focus
+	adding     bool              // the input holds a new property
+	input      textinput.Model   // notebook name, new key=value or property value
+	err        string
+}
+
+// newPropertiesPanel creates the properties panel
+func newPropertiesPanel() propertiesPanel {
+	input := textinput.New()
+	input.Width = propertiesPanelWidth - 10
+	return propertiesPanel{input: input}
+}
+
+// toggleProperties shows or hides the properties panel. Properties can
+// only be set on saved notes.
+func (m *NoteEditorModel) toggleProperties() tea.Cmd {
+	panel := &m.properties
+	panel.visible = !panel.visible
+	panel.editing = false
+	panel.err = ""
+	m.resizePreview()
+	if !panel.visible {
+		return nil
+	}
+	if m.note == nil {
+		panel.properties = nil
+		panel.err = "Save the note to set its properties"
+		return nil
+	}
+	return m.loadProperties()
+}
+
+// loadProperties reloads the note's metadata and custom properties
+func (m *NoteEditorModel) loadProperties() tea.Cmd {
+	noteID := m.note.ID
+	return func() tea.Msg {
+		note, err := m.app.GetStorage().GetNote(noteID)
+		if err != nil {
+			return propertiesLoadedMsg{noteID: noteID, err: err}
+		}
+		properties, err := m.app.GetStorage().GetNoteProperties(noteID)
+		return propertiesLoadedMsg{noteID: noteID, note: note, properties: properties, err: err}
+	}
+}
+
+// changeProperties runs a change to the note's metadata, then reloads it
+func (m *NoteEditorModel) changeProperties(change func(id int) error) tea.Cmd {
+	noteID := m.note.ID
+	return func() tea.Msg {
+		if err := change(noteID); err != nil {
+			return propertiesLoadedMsg{noteID: noteID, err: err}
+		}
+		return m.loadProperties()()
+	}
+}
+
+// applyLoadedProperties takes the metadata stored for the note. Title,
+// content and tags may have unsaved edits and are left alone.
+func (m *NoteEditorModel) applyLoadedProperties(msg propertiesLoadedMsg) {
+	if m.note == nil || m.note.ID != msg.noteID {
+		return
+	}
+	if msg.err != nil {
+		m.properties.err = msg.err.Error()
+		return
+	}
+	m.properties.err = ""
+	m.note.Notebook = msg.note.Notebook
+	m.note.Pinned = msg.note.Pinned
+	m.note.SortOrder = msg.note.SortOrder
+	m.note.Color = msg.note.Color
+	m.properties.properties = msg.properties
+	m.properties.cursor = min(m.properties.cursor, fixedPropertyRows+len(msg.properties)-1)
+}
+
+// handlePropertiesKey handles keys while the properties panel is open
+func (m *NoteEditorModel) handlePropertiesKey(msg tea.KeyMsg) tea.Cmd {
+	panel := &m.properties
+	if panel.editing {
+		switch msg.String() {
+		case "esc":
+			panel.editing = false
+			panel.input.Blur()
+			return nil
+		case "enter":
+			panel.editing = false
+			panel.input.Blur()
+			return m.applyPropertyInput()
+		}
+		return updateInput(&panel.input, msg)
+	}
+
+	switch msg.String() {
+	case "esc", "alt+p":
+		return m.toggleProperties()
+	}
+	if m.note == nil {
+		return nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		panel.cursor = max(panel.cursor-1, 0)
+	case "down", "j":
+		panel.cursor = min(panel.cursor+1, fixedPropertyRows+len(panel.properties)-1)
+	case "enter", " ":
+		return m.changeProperty(1)
+	case "right", "l", "left", "h":
+		if panel.cursor == propertyRowColor {
+			direction := 1
+			if msg.String() == "left" || msg.String() == "h" {
+				direction = -1
+			}
+			return m.changeProperty(direction)
+		}
+	case "a":
+		panel.err = ""
+		cmd := panel.edit("Add: ", "key=value", "")
+		panel.adding = true
+		return cmd
+	case "d", "delete":
+		if property := panel.selected(); property != nil {
+			key := property.Key
+			return m.changeProperties(func(id int) error {
+				return m.app.GetStorage().DeleteNoteProperty(id, key)
+			})
+		}
+	}
+	return nil
+}
+
+// changeProperty changes the property under the cursor: toggles pinned
+// and archived, steps through the color labels in direction, and edits
+// the notebook and custom properties in the input
+func (m *NoteEditorModel) changeProperty(direction int) tea.Cmd {
+	panel := &m.properties
+	storage := m.app.GetStorage()
+	note := m.note
+
+	switch panel.cursor {
+	case propertyRowNotebook:
+		return panel.edit("Notebook: ", "none", note.Notebook)
+	case propertyRowPinned:
+		return m.changeProperties(func(id int) error {
+			return storage.PinNote(id, !note.Pinned)
+		})
+	case propertyRowArchived:
+		notebook := models.ArchiveNotebook
+		if note.Archived() {
+			notebook = ""
+		}
+		return m.changeProperties(func(id int) error {
+			return storage.MoveNotesToNotebook([]int{id}, notebook)
+		})
+	case propertyRowColor:
+		color := nextColorLabel(note.Color, direction)
+		return m.changeProperties(func(id int) error {
+			return storage.SetNoteColor(id, color)
+		})
+	}
+
+	if property := panel.selected(); property != nil {
+		return panel.edit(property.Key+": ", "", property.Value)
+	}
+	return nil
+}
+
+// edit focuses the panel's input for entering a value
+func (p *propertiesPanel) edit(prompt, placeholder, value string) tea.Cmd {
+	p.editing = true
+	p.adding = false
+	p.input.Prompt = prompt
+	p.input.Placeholder = placeholder
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	return p.input.Focus()
+}
+
+// applyPropertyInput stores what was entered in the input for the row
+// under the cursor, or adds the key=value entered as a new property
+func (m *NoteEditorModel) applyPropertyInput() tea.Cmd {
+	panel := &m.properties
+	storage := m.app.GetStorage()
+	value := strings.TrimSpace(panel.input.Value())
+
+	switch {
+	case panel.adding:
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			panel.err = "Expected key=value"
+			return nil
+		}
+		return m.changeProperties(func(id int) error {
+			return storage.SetNoteProperty(id, key, val)
+		})
+	case panel.cursor == propertyRowNotebook:
+		return m.changeProperties(func(id int) error {
+			return storage.MoveNotesToNotebook([]int{id}, value)
+		})
+	}
+
+	if property := panel.selected(); property != nil {
+		key := property.Key
+		return m.changeProperties(func(id int) error {
+			return storage.SetNoteProperty(id, key, value)
+		})
+	}
+	return nil
+}
+
+// selected returns the custom property under the cursor, if any
+func (p *propertiesPanel) selected() *models.Property {
+	i := p.cursor - fixedPropertyRows
+	if i < 0 || i >= len(p.properties) {
+		return nil
+	}
+	return &p.properties[i]
+}
+
+// renderPropertiesPanel renders the properties panel shown beside the editor
+func (m *NoteEditorModel) renderPropertiesPanel() string {
+	panel := m.properties
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+	inner := propertiesPanelWidth - 6
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Properties") + "\n\n"
+
+	row := func(i int, label, value string, valueStyle lipgloss.Style) {
+		label = fmt.Sprintf("%-10s", ansi.Truncate(label, 9, "…"))
+		value = ansi.Truncate(value, inner-10, "…")
+		if i == panel.cursor && !panel.editing {
+			s += selectedStyle.Render(fmt.Sprintf("%-*s", inner, label+value)) + "\n"
+			return
+		}
+		s += labelStyle.Render(label) + valueStyle.Render(value) + "\n"
+	}
+
+	if m.note != nil {
+		valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		yesNo := func(b bool) string {
+			if b {
+				return "yes"
+			}
+			return "no"
+		}
+
+		notebook := m.note.Notebook
+		if notebook == "" {
+			notebook = "none"
+		}
+		color := "● " + m.note.Color
+		if m.note.Color == "" {
+			color = "none"
+		}
+		row(propertyRowNotebook, "Notebook", notebook, valueStyle)
+		row(propertyRowPinned, "Pinned", yesNo(m.note.Pinned), valueStyle)
+		row(propertyRowArchived, "Archived", yesNo(m.note.Archived()), valueStyle)
+		row(propertyRowColor, "Color", color, colorLabelStyle(m.note.Color))
+
+		s += "\n"
+		if len(panel.properties) == 0 {
+			s += hintStyle.Render("No custom properties") + "\n"
+		}
+		for i, property := range panel.properties {
+			row(fixedPropertyRows+i, property.Key, property.Value, valueStyle)
+		}
+	}
+
+	if panel.editing {
+		s += "\n" + panel.input.View() + "\n"
+	}
+	if panel.err != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Width(inner).Render(panel.err) + "\n"
+	}
+
+	hint := "Enter: Change • ←→: Color • a: Add • d: Delete • Esc: Close"
+	if panel.editing {
+		hint = "Enter: Save • Esc: Cancel"
+	}
+	s += "\n" + hintStyle.Width(inner).Render(hint)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Width(propertiesPanelWidth - 2).
+		Render(s)
+}
+
+// Messages
+
+// propertiesLoadedMsg carries a note's stored metadata and custom
+// properties, or why changing or loading them failed
+type propertiesLoadedMsg struct {
+	noteID     int
+	note       *models.Note
+	properties []models.Property
+	err        error
+}
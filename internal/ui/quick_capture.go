@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// QuickCaptureModel is a small program of its own for jotting down a note
+// without the rest of the app: a title, the content, and Ctrl+S to save
+// and quit
+type QuickCaptureModel struct {
+	service *storage.Service
+	title   textinput.Model
+	content textarea.Model
+	onTitle bool // the title has focus rather than the content
+	saving  bool
+	saved   *models.Note
+	err     string
+	discard bool // Esc was pressed once on a note with text
+	width   int
+	height  int
+}
+
+// NewQuickCapture creates the quick capture program for the notes in
+// service
+func NewQuickCapture(service *storage.Service) *QuickCaptureModel {
+	title := textinput.New()
+	title.Prompt = "Title: "
+	title.Placeholder = "taken from the first line if left empty"
+	title.CharLimit = 100
+	title.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+
+	content := textarea.New()
+	content.Placeholder = "Write it down..."
+	content.CharLimit = 10000
+	content.ShowLineNumbers = false
+	content.FocusedStyle.Text = lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+	content.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	content.Focus()
+
+	return &QuickCaptureModel{service: service, title: title, content: content}
+}
+
+// Saved returns the note saved before quitting, or nil when it was
+// discarded
+func (m *QuickCaptureModel) Saved() *models.Note {
+	return m.saved
+}
+
+// Init starts the cursor blinking in the content, where typing starts
+func (m *QuickCaptureModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles updates for quick capture
+func (m *QuickCaptureModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.title.Width = max(msg.Width-len(m.title.Prompt)-6, 10)
+		m.content.SetWidth(max(msg.Width-4, 10))
+		// The header, title, hint and borders take 6 lines
+		m.content.SetHeight(max(msg.Height-6, 3))
+		return m, nil
+
+	case quickSavedMsg:
+		m.saving = false
+		if msg.err != nil {
+			m.err = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		m.saved = msg.note
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		discard := m.discard
+		m.discard = false
+		m.err = ""
+		switch msg.String() {
+		case "ctrl+s":
+			return m, m.save()
+		case "esc", "ctrl+c", "ctrl+q":
+			// Don't lose text to a stray Esc
+			if discard || m.empty() {
+				return m, tea.Quit
+			}
+			m.discard = true
+			return m, nil
+		case "tab", "shift+tab":
+			return m, m.switchField()
+		case "enter":
+			if m.onTitle {
+				return m, m.switchField()
+			}
+		}
+	}
+
+	if m.onTitle {
+		return m, updateInput(&m.title, msg)
+	}
+	return m, updateTextArea(&m.content, msg)
+}
+
+// empty reports whether nothing has been written yet
+func (m *QuickCaptureModel) empty() bool {
+	return strings.TrimSpace(m.title.Value()) == "" && strings.TrimSpace(m.content.Value()) == ""
+}
+
+// switchField moves focus between the title and the content
+func (m *QuickCaptureModel) switchField() tea.Cmd {
+	m.onTitle = !m.onTitle
+	if m.onTitle {
+		m.content.Blur()
+		return m.title.Focus()
+	}
+	m.title.Blur()
+	return m.content.Focus()
+}
+
+// save saves the note in the background. A note without a title takes
+// one from its content.
+func (m *QuickCaptureModel) save() tea.Cmd {
+	if m.saving {
+		return nil
+	}
+	if m.empty() {
+		m.err = "Write something first, or press Esc to quit"
+		return nil
+	}
+
+	title := strings.TrimSpace(m.title.Value())
+	content := strings.TrimRight(m.content.Value(), "\n")
+	if title == "" {
+		title = utils.TitleFromContent(content)
+	}
+	m.saving = true
+	return func() tea.Msg {
+		note, err := m.service.CreateNote(title, content)
+		return quickSavedMsg{note: note, err: err}
+	}
+}
+
+// View renders the title above the content
+func (m *QuickCaptureModel) View() string {
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E"))
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Quick Note") + "\n\n"
+	s += m.title.View() + "\n"
+
+	border := lipgloss.Color("#334155")
+	if !m.onTitle {
+		border = lipgloss.Color("#EA580C")
+	}
+	s += lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Render(m.content.View()) + "\n"
+
+	switch {
+	case m.saving:
+		s += hintStyle.Render("Saving...")
+	case m.err != "":
+		s += errStyle.Render(m.err)
+	case m.discard:
+		s += errStyle.Render("Press Esc again to discard the note")
+	default:
+		s += hintStyle.Render("Ctrl+S: Save and quit • Tab: Title/content • Esc: Discard")
+	}
+	return s
+}
+
+// Messages
+
+// quickSavedMsg carries the note saved by quick capture, or why saving failed
+type quickSavedMsg struct {
+	note *models.Note
+	err  error
+}
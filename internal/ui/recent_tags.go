@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// recentTagLimit is how many recently used tags are offered under the tag
+// input, each toggled by its number while the row has focus
+const recentTagLimit = 5
+
+// loadTagLists loads the tags by usage and the most recently used ones
+func loadTagLists(service *storage.Service) tagsLoadedMsg {
+	tags, err := service.GetTagsByUsage()
+	if err != nil {
+		return tagsLoadedMsg{tags: []*models.Tag{}}
+	}
+	recent, err := service.GetRecentTags(recentTagLimit)
+	if err != nil {
+		recent = nil
+	}
+	return tagsLoadedMsg{tags: tags, recent: recent}
+}
+
+// handleRecentTagKey handles a key for the row of recent tags. ↓ in the
+// empty tag input focuses the row; there ←→ move along it, a number or
+// Space/Enter toggles a tag and ↑ or Esc go back to the input. Digits only
+// toggle tags while the row has focus, so tags like "2024" can still be
+// typed. Returns whether the key was handled.
+func (m *NoteEditorModel) handleRecentTagKey(key string) bool {
+	if m.recentTagIndex < 0 {
+		if key != "down" || m.showSuggestions || m.tagInput.Value() != "" || len(m.recentTags) == 0 {
+			return false
+		}
+		m.recentTagIndex = 0
+		return true
+	}
+
+	switch key {
+	case "left":
+		m.recentTagIndex = max(m.recentTagIndex-1, 0)
+	case "right":
+		m.recentTagIndex = min(m.recentTagIndex+1, len(m.recentTags)-1)
+	case " ", "enter":
+		m.toggleRecentTag(m.recentTagIndex)
+	case "up", "esc":
+		m.recentTagIndex = -1
+	default:
+		if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < len(m.recentTags) {
+			m.recentTagIndex = int(key[0] - '1')
+			m.toggleRecentTag(m.recentTagIndex)
+		}
+	}
+	return true
+}
+
+// toggleRecentTag adds the recent tag at index to the note, or removes it
+// if the note has it already
+func (m *NoteEditorModel) toggleRecentTag(index int) {
+	if index < 0 || index >= len(m.recentTags) {
+		return
+	}
+	name := m.recentTags[index].Name
+	for i, tag := range m.tags {
+		if strings.EqualFold(tag.Name, name) {
+			m.tags = append(m.tags[:i], m.tags[i+1:]...)
+			return
+		}
+	}
+	m.addTag(name)
+}
+
+// renderRecentTags renders the recent tags as numbered chips, those on the
+// note highlighted and the one under the cursor underlined
+func (m *NoteEditorModel) renderRecentTags(width int) string {
+	if m.focused != 1 || len(m.recentTags) == 0 || m.tagEditMode {
+		return ""
+	}
+
+	chipStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Padding(0, 1)
+	activeStyle := chipStyle.
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	chips := []string{numberStyle.Render("Recent:")}
+	for i, recent := range m.recentTags {
+		style := chipStyle
+		if i == m.recentTagIndex {
+			style = style.Underline(true).Foreground(lipgloss.Color("#F1F5F9"))
+		}
+		for _, tag := range m.tags {
+			if strings.EqualFold(tag.Name, recent.Name) {
+				style = activeStyle.Underline(i == m.recentTagIndex)
+				break
+			}
+		}
+		chips = append(chips, style.Render(fmt.Sprintf("%d %s", i+1, recent.Name)))
+	}
+	return ansi.Truncate(strings.Join(chips, " "), width, "…") + "\n"
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logPanic logs a recovered panic with the stack it was raised on
+func logPanic(value any) {
+	slog.Error("panic", "value", fmt.Sprint(value), "stack", string(debug.Stack()))
+}
+
+// recoverPanic logs a panic in Update or View and panics again, so the
+// program restores the terminal before exiting. Call it deferred.
+func recoverPanic() {
+	if r := recover(); r != nil {
+		logPanic(r)
+		panic(r)
+	}
+}
+
+// guard catches a panic in cmd, or in the commands of a batch it returns,
+// and reports it as a crashMsg. A panic in a command's goroutine would
+// otherwise be printed over the alternate screen and lost with it.
+func guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(r)
+				msg = crashMsg{value: r}
+			}
+		}()
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i := range batch {
+				batch[i] = guard(batch[i])
+			}
+		}
+		return msg
+	}
+}
+
+// Crashed reports whether the app quit because of a panic in a command
+func (a *App) Crashed() bool {
+	return a.crashed
+}
+
+// WriteRecovery writes the open notes with unsaved changes to a markdown
+// file in dir after a crash. It returns the file's path, or "" when no
+// note had changes.
+func (a *App) WriteRecovery(dir string) (path string, err error) {
+	// The editors may be what broke
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(r)
+			err = fmt.Errorf("failed to read unsaved notes: %v", r)
+		}
+	}()
+
+	var b strings.Builder
+	for _, editor := range a.editors {
+		if !editor.dirty() {
+			continue
+		}
+		title := editor.titleInput.Value()
+		if strings.TrimSpace(title) == "" {
+			title = "Untitled"
+		}
+		if editor.note != nil && editor.mode == "edit" {
+			fmt.Fprintf(&b, "<!-- note %d -->\n", editor.note.ID)
+		} else {
+			b.WriteString("<!-- new note -->\n")
+		}
+		fmt.Fprintf(&b, "# %s\n\n%s\n\n", title, strings.TrimRight(editor.contentInput.Value(), "\n"))
+	}
+	if b.Len() == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create recovery directory: %w", err)
+	}
+	path = filepath.Join(dir, "recovery-"+time.Now().Format("20060102-150405")+".md")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write recovery file: %w", err)
+	}
+	return path, nil
+}
+
+// Messages
+
+// crashMsg reports a panic caught in a command
+type crashMsg struct {
+	value any
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// renderedLine is the output of one source line and the list state the
+// renderer is left in after it
+type renderedLine struct {
+	lines       []string
+	listIndents []int
+	listNumbers []int
+	listHangs   []int
+}
+
+// lineKey identifies the output of a source line: the line and everything
+// else the renderer's output for it depends on
+func (r *nativeRenderer) lineKey(line string) string {
+	return fmt.Sprintf("%d %t %t %v %v %v %s\x00%s", r.width, r.atTop, r.prevBlank,
+		r.listIndents, r.listNumbers, r.listHangs, r.footnoteKey, line)
+}
+
+// renderLine renders one source line, reusing its output from the last
+// render when neither the line nor the state it's rendered in changed.
+// While typing, only the edited line and any it affects are rendered again.
+func (r *nativeRenderer) renderLine(line string) []string {
+	if _, _, ok := parseImageLine(strings.TrimSpace(line)); ok {
+		// Images are cached by file and modification time, so a changed
+		// file shows without editing the note
+		return r.processEnhancedLine(line)
+	}
+
+	key := r.lineKey(line)
+	cached, ok := r.lineCache[key]
+	if !ok {
+		cached, ok = r.lastLineCache[key]
+	}
+	if ok {
+		r.listIndents = slices.Clone(cached.listIndents)
+		r.listNumbers = slices.Clone(cached.listNumbers)
+		r.listHangs = slices.Clone(cached.listHangs)
+	} else {
+		cached = renderedLine{
+			lines:       r.processEnhancedLine(line),
+			listIndents: slices.Clone(r.listIndents),
+			listNumbers: slices.Clone(r.listNumbers),
+			listHangs:   slices.Clone(r.listHangs),
+		}
+	}
+	r.lineCache[key] = cached
+	return cached.lines
+}
+
+// startLineCache keeps the lines of the last render for this one, dropping
+// those of earlier renders so the cache holds about one note
+func (r *nativeRenderer) startLineCache() {
+	r.lastLineCache = r.lineCache
+	r.lineCache = make(map[string]renderedLine, len(r.lastLineCache))
+	r.footnoteKey = fmt.Sprint(r.footnoteNumbers)
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/utils"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// LineMap maps each rendered output line to the zero-based source line it
+// was produced from. Preview features such as scroll sync and search
+// highlighting rely on it instead of on renderer internals.
+type LineMap []int
+
+// SourceLine returns the source line for a rendered line, or -1 if unknown
+func (lm LineMap) SourceLine(renderedLine int) int {
+	if renderedLine < 0 || renderedLine >= len(lm) {
+		return -1
+	}
+	return lm[renderedLine]
+}
+
+// RenderedLine returns the first rendered line produced from a source line,
+// falling back to the closest preceding one
+func (lm LineMap) RenderedLine(sourceLine int) int {
+	best := 0
+	for i, src := range lm {
+		if src == sourceLine {
+			return i
+		}
+		if src < sourceLine {
+			best = i
+		}
+	}
+	return best
+}
+
+// Renderer converts markdown into styled terminal output
+type Renderer interface {
+	RenderMarkdown(content string, width int) (string, LineMap)
+}
+
+// NewRenderer returns the renderer selected in the config
+func NewRenderer(cfg *config.Config) Renderer {
+	var renderer Renderer
+	switch cfg.Renderer {
+	case config.RendererGlamour:
+		renderer = newGlamourRenderer()
+	default:
+		native := newNativeRenderer()
+		native.hyperlinks = hyperlinksEnabled(cfg.Hyperlinks)
+		native.images = imageProtocolFor(cfg.Images)
+		renderer = native
+	}
+
+	renderer = linkNumberRenderer{renderer}
+	if cfg.SmartTypography {
+		renderer = smartTypographyRenderer{renderer}
+	}
+	if rules := cfg.AutolinkRules(); len(rules) > 0 {
+		renderer = autolinkRenderer{renderer, rules}
+	}
+	return renderer
+}
+
+// smartTypographyRenderer applies typographic replacements before rendering.
+// Replacements never add or remove lines, so the line map stays accurate.
+type smartTypographyRenderer struct {
+	Renderer
+}
+
+// RenderMarkdown smartens the content and renders it with the wrapped renderer
+func (r smartTypographyRenderer) RenderMarkdown(content string, width int) (string, LineMap) {
+	return r.Renderer.RenderMarkdown(utils.Smarten(content), width)
+}
+
+// linkNumberRenderer numbers web links so they can be opened by number
+// from the links panel. Numbers are added within lines, so the line map
+// stays accurate.
+type linkNumberRenderer struct {
+	Renderer
+}
+
+// RenderMarkdown numbers the links and renders with the wrapped renderer
+func (r linkNumberRenderer) RenderMarkdown(content string, width int) (string, LineMap) {
+	numbered, _ := utils.NumberWebLinks(content)
+	return r.Renderer.RenderMarkdown(numbered, width)
+}
+
+// autolinkRenderer turns references matching the configured autolink rules
+// into links before rendering. Links are added within lines, so the line
+// map stays accurate.
+type autolinkRenderer struct {
+	Renderer
+	rules []utils.AutolinkRule
+}
+
+// RenderMarkdown links the content and renders it with the wrapped renderer
+func (r autolinkRenderer) RenderMarkdown(content string, width int) (string, LineMap) {
+	return r.Renderer.RenderMarkdown(utils.Autolink(content, r.rules), width)
+}
+
+// glamourRenderer renders markdown with glamour
+type glamourRenderer struct {
+	term  *glamour.TermRenderer
+	width int
+}
+
+// newGlamourRenderer creates a glamour-based renderer
+func newGlamourRenderer() *glamourRenderer {
+	return &glamourRenderer{}
+}
+
+// RenderMarkdown renders content with glamour. Glamour doesn't expose where
+// output lines come from, so the line map is interpolated proportionally.
+func (r *glamourRenderer) RenderMarkdown(content string, width int) (string, LineMap) {
+	if r.term == nil || r.width != width {
+		term, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle("dark"),
+			glamour.WithWordWrap(max(width-4, 20)),
+		)
+		if err != nil {
+			// Fall back to the native renderer if glamour can't be set up
+			return newNativeRenderer().RenderMarkdown(content, width)
+		}
+		r.term = term
+		r.width = width
+	}
+
+	out, err := r.term.Render(content)
+	if err != nil {
+		return newNativeRenderer().RenderMarkdown(content, width)
+	}
+	out = strings.Trim(out, "\n")
+
+	sourceLines := strings.Count(content, "\n") + 1
+	renderedLines := strings.Count(out, "\n") + 1
+	lineMap := make(LineMap, renderedLines)
+	for i := range lineMap {
+		lineMap[i] = min(i*sourceLines/renderedLines, sourceLines-1)
+	}
+
+	return out, lineMap
+}
@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/ui/theme"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sidebarWidth is the width of the sidebar, borders included
+const sidebarWidth = 30
+
+// sidebar lists the special views, saved searches, notebooks and tags
+// beside the notes list on large terminals. Picking an entry searches for
+// its notes.
+type sidebar struct {
+	focused bool // true while keys move through the sidebar instead of the list
+	cursor  int
+	counts  *models.NoteCounts
+	saved   map[string]int // note counts of the saved searches, by query
+}
+
+// savedSection heads the saved searches in the sidebar
+const savedSection = "Saved searches"
+
+// sidebarEntry is a row of the sidebar that can be picked
+type sidebarEntry struct {
+	section string
+	label   string
+	count   int
+	query   string         // search showing the entry's notes, empty for all notes
+	color   lipgloss.Color // color of the label, empty for the default
+}
+
+// sidebarShown reports whether the sidebar is shown beside the list
+func (m *NotesListModel) sidebarShown() bool {
+	return !m.app.GetConfig().HideSidebar && theme.NewResponsive(m.width, m.height).IsLarge()
+}
+
+// sidebarSpace returns the columns the sidebar takes from the list,
+// including the gap after it
+func (m *NotesListModel) sidebarSpace() int {
+	if m.sidebarShown() {
+		return sidebarWidth + 1
+	}
+	return 0
+}
+
+// loadSidebarCounts reloads the note counts shown in the sidebar
+func (m *NotesListModel) loadSidebarCounts() tea.Cmd {
+	searches := append([]config.SavedSearch(nil), m.app.GetConfig().SavedSearches...)
+	return func() tea.Msg {
+		store := m.app.GetStorage()
+		counts, err := store.GetNoteCounts()
+		if err != nil {
+			slog.Warn("failed to count notes for the sidebar", "err", err)
+			return nil
+		}
+
+		saved := map[string]int{}
+		for _, search := range searches {
+			count, err := store.CountNotesContext(context.Background(), utils.ParseQuery(search.Query))
+			if err != nil {
+				slog.Warn("failed to count notes for a saved search", "query", search.Query, "err", err)
+				continue
+			}
+			saved[search.Query] = count
+		}
+		return sidebarCountsMsg{counts: counts, saved: saved}
+	}
+}
+
+// saveSearch keeps the current search in the sidebar, named by its query
+func (m *NotesListModel) saveSearch() tea.Cmd {
+	query := strings.TrimSpace(m.searchQuery)
+	if query == "" {
+		m.statusMsg = "Search for something to save it"
+		return nil
+	}
+	cfg := m.app.GetConfig()
+	for _, search := range cfg.SavedSearches {
+		if search.Query == query {
+			m.statusMsg = fmt.Sprintf("%q is already saved", query)
+			return nil
+		}
+	}
+
+	cfg.SavedSearches = append(cfg.SavedSearches, config.SavedSearch{Name: query, Query: query})
+	m.statusMsg = fmt.Sprintf("Saved search %q", query)
+	return tea.Batch(m.app.saveConfig(), m.loadSidebarCounts())
+}
+
+// removeSavedSearch drops the saved search with the given query
+func (m *NotesListModel) removeSavedSearch(query string) tea.Cmd {
+	cfg := m.app.GetConfig()
+	for i, search := range cfg.SavedSearches {
+		if search.Query == query {
+			cfg.SavedSearches = append(cfg.SavedSearches[:i:i], cfg.SavedSearches[i+1:]...)
+			m.statusMsg = fmt.Sprintf("Removed saved search %q", search.Name)
+			return m.app.saveConfig()
+		}
+	}
+	return nil
+}
+
+// toggleSidebar shows or hides the sidebar and saves the choice
+func (m *NotesListModel) toggleSidebar() tea.Cmd {
+	cfg := m.app.GetConfig()
+	cfg.HideSidebar = !cfg.HideSidebar
+	m.sidebar.focused = false
+
+	switch {
+	case cfg.HideSidebar:
+		m.statusMsg = "Sidebar hidden"
+	case m.sidebarShown():
+		m.statusMsg = "Sidebar shown (Ctrl+B to focus it)"
+	default:
+		m.statusMsg = "Sidebar shown; widen the terminal to see it"
+	}
+	return m.app.saveConfig()
+}
+
+// focusSidebar moves focus to the sidebar, or back to the list when it
+// has it. A hidden sidebar is shown first.
+func (m *NotesListModel) focusSidebar() tea.Cmd {
+	if m.sidebar.focused {
+		m.sidebar.focused = false
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if m.app.GetConfig().HideSidebar {
+		cmd = m.toggleSidebar()
+	}
+	if !m.sidebarShown() {
+		m.statusMsg = "Widen the terminal to see the sidebar"
+		return cmd
+	}
+	m.sidebar.focused = true
+	m.browser.focused = false
+	return cmd
+}
+
+// sidebarEntries lists the rows of the sidebar: the special views, the
+// saved searches, then each notebook and tag with notes
+func (m *NotesListModel) sidebarEntries() []sidebarEntry {
+	counts := m.sidebar.counts
+	if counts == nil {
+		counts = &models.NoteCounts{}
+	}
+
+	entries := []sidebarEntry{
+		{"Views", "All notes", counts.All, "", ""},
+		{"Views", "Pinned", counts.Pinned, "is:pinned", ""},
+		{"Views", "Archived", counts.Archived, "notebook:" + models.ArchiveNotebook, ""},
+		{"Views", "Trash", counts.Trash, "notebook:" + models.TrashNotebook, ""},
+	}
+	for _, search := range m.app.GetConfig().SavedSearches {
+		entries = append(entries, sidebarEntry{savedSection, search.Name, m.sidebar.saved[search.Query], search.Query, ""})
+	}
+	for _, notebook := range counts.Notebooks {
+		entries = append(entries, sidebarEntry{"Notebooks", notebook.Name, notebook.Count, "notebook:" + queryValue(notebook.Name), ""})
+	}
+	// Nested tags are indented under their parent
+	for _, tag := range counts.Tags {
+		label := strings.Repeat("  ", utils.TagDepth(tag.Name)) + "#" + utils.TagLeaf(tag.Name)
+		color := tagColor(models.Tag{Name: tag.Name, Color: tag.Color})
+		entries = append(entries, sidebarEntry{"Tags", label, tag.Count, "tag:" + queryValue(tag.Name), color})
+	}
+	return entries
+}
+
+// queryValue quotes an operator value for the search syntax when it
+// contains spaces
+func queryValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// handleSidebarKey moves through the sidebar while it has focus. Keys it
+// doesn't handle fall through to the list.
+func (m *NotesListModel) handleSidebarKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	entries := m.sidebarEntries()
+
+	switch msg.String() {
+	case "up", "k":
+		m.sidebar.cursor = max(m.sidebar.cursor-1, 0)
+	case "down", "j":
+		m.sidebar.cursor = min(m.sidebar.cursor+1, len(entries)-1)
+	case "home", "g":
+		m.sidebar.cursor = 0
+	case "end", "G":
+		m.sidebar.cursor = len(entries) - 1
+	case "enter", " ":
+		// Show the entry's notes and go back to the list to pick one
+		entry := entries[min(m.sidebar.cursor, len(entries)-1)]
+		m.sidebar.focused = false
+		m.showQuery(entry.query)
+		return true, m.scheduleSearch()
+	case "a":
+		return true, m.saveSearch()
+	case "d":
+		// Only saved searches can be removed; other entries ignore it
+		// rather than letting the list delete notes
+		entry := entries[min(m.sidebar.cursor, len(entries)-1)]
+		if entry.section == savedSection {
+			return true, m.removeSavedSearch(entry.query)
+		}
+	case "esc", "tab":
+		m.sidebar.focused = false
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// renderSidebar renders the sidebar at the given height, border included
+func (m *NotesListModel) renderSidebar(height int) string {
+	sectionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+	countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#EA580C")).Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+	inner := sidebarWidth - 4
+
+	entries := m.sidebarEntries()
+	m.sidebar.cursor = max(min(m.sidebar.cursor, len(entries)-1), 0)
+
+	var lines []string
+	cursorLine := 0
+	section := ""
+	for i, entry := range entries {
+		if entry.section != section {
+			if section != "" {
+				lines = append(lines, "")
+			}
+			section = entry.section
+			lines = append(lines, sectionStyle.Render(section))
+		}
+
+		count := fmt.Sprint(entry.count)
+		label := ansi.Truncate(entry.label, inner-len(count)-3, "…")
+		gap := strings.Repeat(" ", max(inner-2-lipgloss.Width(label)-len(count), 1))
+		switch {
+		case m.sidebar.focused && i == m.sidebar.cursor:
+			cursorLine = len(lines)
+			lines = append(lines, selectedStyle.Render("  "+label+gap+count))
+		case entry.query == m.searchQuery:
+			lines = append(lines, activeStyle.Render("▸ "+label)+gap+countStyle.Render(count))
+		default:
+			style := labelStyle
+			if entry.color != "" {
+				style = style.Foreground(entry.color)
+			}
+			lines = append(lines, "  "+style.Render(label)+gap+countStyle.Render(count))
+		}
+	}
+
+	hint := "Ctrl+B: Focus • B: Hide"
+	switch {
+	case m.sidebar.focused && entries[m.sidebar.cursor].section == savedSection:
+		hint = "Enter • d: Remove • Esc"
+	case m.sidebar.focused:
+		hint = "Enter • a: Save • Esc"
+	}
+
+	// Border and padding take 4 lines, the hint 2 more; the entries
+	// scroll to keep the cursor in view
+	rows := max(height-6, 1)
+	offset := max(cursorLine-rows+1, 0)
+	lines = lines[offset:min(offset+rows, len(lines))]
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+	content := strings.Join(lines, "\n") + "\n\n" + hintStyle.Render(ansi.Truncate(hint, inner, "…"))
+
+	border := lipgloss.Color("#334155")
+	if m.sidebar.focused {
+		border = lipgloss.Color("#EA580C")
+	}
+	return lipgloss.NewStyle().
+		Width(sidebarWidth-2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Padding(1, 1).
+		Background(lipgloss.Color("#0F172A")).
+		Render(content)
+}
+
+// Messages
+
+// sidebarCountsMsg carries the note counts shown in the sidebar
+type sidebarCountsMsg struct {
+	counts *models.NoteCounts
+	saved  map[string]int
+}
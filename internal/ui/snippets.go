@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadSnippets loads the stored snippets in the background
+func (a *App) loadSnippets() tea.Cmd {
+	return func() tea.Msg {
+		snippets, err := a.storage.GetSnippets()
+		if err != nil {
+			slog.Warn("failed to load snippets", "err", err)
+			return snippetsLoadedMsg{}
+		}
+		return snippetsLoadedMsg{snippets: snippets}
+	}
+}
+
+// snippetBody returns the body of the snippet a trigger expands to.
+// Stored snippets take precedence over the built-in ones.
+func (a *App) snippetBody(trigger string) (string, bool) {
+	if trigger == "" {
+		return "", false
+	}
+	for _, snippet := range a.snippets {
+		if snippet.Trigger == trigger {
+			return snippet.Body, true
+		}
+	}
+	body, ok := utils.BuiltinSnippets[trigger]
+	return body, ok
+}
+
+// expandSnippet replaces a snippet trigger right before the cursor with the
+// snippet, leaving the cursor at its {{cursor}} placeholder. Reports false,
+// changing nothing, when there's no trigger there.
+func (m *NoteEditorModel) expandSnippet() bool {
+	ta := &m.contentInput
+	lines := strings.Split(ta.Value(), "\n")
+	row := ta.Line()
+	col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset
+	if row >= len(lines) {
+		return false
+	}
+	line := []rune(lines[row])
+	col = min(col, len(line))
+
+	trigger := utils.TriggerBefore(string(line), col)
+	body, ok := m.app.snippetBody(trigger)
+	if !ok {
+		return false
+	}
+
+	indent := string(line[:len(line)-len([]rune(strings.TrimLeft(string(line), " \t")))])
+	text, cursor := utils.ExpandSnippet(body, indent, time.Now())
+	start := string(line[:col-len([]rune(trigger))])
+	lines[row] = start + text + string(line[col:])
+
+	// Find the row and column the cursor ends up at
+	beforeCursor := start + string([]rune(text)[:cursor])
+	newRow := row + strings.Count(beforeCursor, "\n")
+	newCol := len([]rune(beforeCursor[strings.LastIndex(beforeCursor, "\n")+1:]))
+
+	setTextareaValue(ta, strings.Join(lines, "\n"), newRow, newCol)
+	m.contentChanged()
+	return true
+}
+
+// Messages
+
+// snippetsLoadedMsg carries the stored snippets
+type snippetsLoadedMsg struct {
+	snippets []*models.Snippet
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SnippetsModel lists the stored snippets and adds, edits and deletes them
+type SnippetsModel struct {
+	app    *App
+	cursor int
+	err    string
+	width  int
+	height int
+
+	// The form for the snippet being added or edited
+	editing      bool
+	editID       int // 0 for a new snippet
+	trigger      textinput.Model
+	body         textarea.Model
+	focusOnBody  bool
+	confirmingID int // snippet waiting for a second d to be deleted
+}
+
+// NewSnippetsModel creates the snippet manager
+func NewSnippetsModel(app *App) *SnippetsModel {
+	trigger := textinput.New()
+	trigger.Prompt = "Trigger: "
+	trigger.Placeholder = ";sig"
+	trigger.CharLimit = 32
+
+	body := textarea.New()
+	body.Placeholder = "Text to insert; {{date}}, {{time}}, {{weekday}} and {{cursor}} are filled in"
+	body.ShowLineNumbers = false
+	body.SetHeight(8)
+
+	return &SnippetsModel{app: app, trigger: trigger, body: body}
+}
+
+// Init shows the list of snippets, reloading them
+func (m *SnippetsModel) Init() tea.Cmd {
+	m.editing = false
+	m.err = ""
+	m.confirmingID = 0
+	return m.app.loadSnippets()
+}
+
+// Update handles updates for the snippet manager
+func (m *SnippetsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.body.SetWidth(max(min(m.width-8, 80), 20))
+
+	case snippetSavedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m.app, nil
+		}
+		m.err = ""
+		m.editing = false
+		m.trigger.Blur()
+		m.body.Blur()
+		return m.app, m.app.loadSnippets()
+
+	case tea.KeyMsg:
+		if m.editing {
+			return m.app, m.handleFormKey(msg)
+		}
+
+		snippets := m.app.snippets
+		confirming := m.confirmingID
+		m.confirmingID = 0
+		switch msg.String() {
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = max(min(m.cursor+1, len(snippets)-1), 0)
+		case "a", "n":
+			return m.app, m.edit(&models.Snippet{})
+		case "enter", "e":
+			if snippet := m.selected(); snippet != nil {
+				return m.app, m.edit(snippet)
+			}
+		case "d":
+			snippet := m.selected()
+			if snippet == nil {
+				break
+			}
+			if confirming != snippet.ID {
+				m.confirmingID = snippet.ID
+				break
+			}
+			id := snippet.ID
+			return m.app, func() tea.Msg {
+				return snippetSavedMsg{err: m.app.GetStorage().DeleteSnippet(id)}
+			}
+		}
+	}
+	return m.app, nil
+}
+
+// selected returns the snippet under the cursor, if any
+func (m *SnippetsModel) selected() *models.Snippet {
+	if m.cursor < 0 || m.cursor >= len(m.app.snippets) {
+		return nil
+	}
+	return m.app.snippets[m.cursor]
+}
+
+// edit opens the form on a snippet, which is new when it has no ID
+func (m *SnippetsModel) edit(snippet *models.Snippet) tea.Cmd {
+	m.editing = true
+	m.err = ""
+	m.editID = snippet.ID
+	m.trigger.SetValue(snippet.Trigger)
+	m.trigger.CursorEnd()
+	m.body.SetValue(snippet.Body)
+	m.focusOnBody = false
+	m.body.Blur()
+	return m.trigger.Focus()
+}
+
+// handleFormKey handles keys while a snippet is being added or edited
+func (m *SnippetsModel) handleFormKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.editing = false
+		m.err = ""
+		m.trigger.Blur()
+		m.body.Blur()
+		return nil
+	case "ctrl+s":
+		snippet := &models.Snippet{ID: m.editID, Trigger: m.trigger.Value(), Body: m.body.Value()}
+		return func() tea.Msg {
+			return snippetSavedMsg{err: m.app.GetStorage().SaveSnippet(snippet)}
+		}
+	case "tab":
+		m.focusOnBody = !m.focusOnBody
+		if m.focusOnBody {
+			m.trigger.Blur()
+			return m.body.Focus()
+		}
+		m.body.Blur()
+		return m.trigger.Focus()
+	}
+
+	if m.focusOnBody {
+		return updateTextArea(&m.body, msg)
+	}
+	return updateInput(&m.trigger, msg)
+}
+
+// View renders the snippets, or the form while one is being edited
+func (m *SnippetsModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	bodyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E"))
+
+	if m.editing {
+		title := "New Snippet"
+		if m.editID != 0 {
+			title = "Edit Snippet"
+		}
+		s := titleStyle.Render(title) + "\n\n"
+		s += "  " + m.trigger.View() + "\n\n"
+		s += lipgloss.NewStyle().MarginLeft(2).Render(m.body.View()) + "\n\n"
+		if m.err != "" {
+			s += "  " + errStyle.Render(m.err) + "\n\n"
+		}
+		return s + hintStyle.Render("Tab: Switch field • Ctrl+S: Save • Esc: Cancel")
+	}
+
+	s := titleStyle.Render("Snippets") + "\n\n"
+	if len(m.app.snippets) == 0 {
+		s += "  " + hintStyle.Italic(true).Render("No snippets yet. Built in: /date, /time and /now.") + "\n"
+	}
+	for i, snippet := range m.app.snippets {
+		cursor := "  "
+		triggerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		if i == m.cursor {
+			cursor = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EA580C")).
+				Bold(true).
+				Render("▶ ")
+			triggerStyle = triggerStyle.Bold(true)
+		}
+		body := strings.ReplaceAll(snippet.Body, "\n", "⏎")
+		line := "  " + cursor + triggerStyle.Render(fmt.Sprintf("%-14s", snippet.Trigger)) + " " + bodyStyle.Render(body)
+		s += ansi.Truncate(line, m.width, "…") + "\n"
+	}
+
+	s += "\n"
+	switch {
+	case m.err != "":
+		s += errStyle.Render(m.err) + "\n\n"
+	case m.confirmingID != 0:
+		s += errStyle.Render("Press d again to delete the snippet") + "\n\n"
+	default:
+		s += hintStyle.Italic(true).Render("Type a trigger in a note and press Tab to expand it.") + "\n\n"
+	}
+
+	return s + hintStyle.Render("↑↓: Navigate • a: Add • Enter: Edit • d: Delete • Esc: Back")
+}
+
+// snippetsView returns the snippet manager, creating it on first use
+func (a *App) snippetsView() *SnippetsModel {
+	if a.snippetManager == nil {
+		a.snippetManager = NewSnippetsModel(a)
+		a.snippetManager.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.snippetManager
+}
+
+// formOpen reports whether a snippet or a tag alias is being entered,
+// when keys go to the form rather than the app
+func (a *App) formOpen() bool {
+	switch a.currentView {
+	case ViewSnippets:
+		return a.snippetsView().editing
+	case ViewTags:
+		return a.tagsView().adding
+	}
+	return false
+}
+
+// Messages
+
+// snippetSavedMsg reports a snippet saved or deleted, or why that failed
+type snippetSavedMsg struct {
+	err error
+}
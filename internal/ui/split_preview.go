@@ -0,0 +1,58 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// focusPreview is the focused field while the split-pane preview has
+// focus, after 0=title, 1=tags and 2=content
+const focusPreview = 3
+
+// togglePreviewFocus moves focus between the content and the split-pane
+// preview, opening the preview first if needed
+func (m *NoteEditorModel) togglePreviewFocus() {
+	if m.focused == focusPreview {
+		m.focused = 2
+		m.updateFocus()
+		return
+	}
+	if !m.splitPane {
+		m.ToggleSplitPane()
+	}
+	m.focused = focusPreview
+	m.showSuggestions = false
+	m.updateFocus()
+}
+
+// handlePreviewKey scrolls the focused preview
+func (m *NoteEditorModel) handlePreviewKey(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "up", "k":
+		m.preview.ScrollUp()
+	case "down", "j":
+		m.preview.ScrollDown()
+	case "pgup", "ctrl+b":
+		m.preview.PageUp()
+	case "pgdown", "ctrl+f", " ":
+		m.preview.PageDown()
+	case "home", "g":
+		m.preview.ScrollToTop()
+	case "end", "G":
+		m.preview.ScrollToBottom()
+	}
+}
+
+// toggleScrollSync turns keeping the preview aligned with the cursor line
+// on or off
+func (m *NoteEditorModel) toggleScrollSync() {
+	m.scrollSync = !m.scrollSync
+	m.syncPreviewScroll()
+}
+
+// syncPreviewScroll scrolls the preview to the content's cursor line when
+// scroll sync is on
+func (m *NoteEditorModel) syncPreviewScroll() {
+	if m.scrollSync && m.splitPane {
+		m.preview.ScrollToSourceLine(m.contentInput.Line())
+	}
+}
@@ -0,0 +1,329 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// staleAfter is how long a note can go without edits before it counts as stale
+const staleAfter = 180 * 24 * time.Hour
+
+// StatsModel manages the stats view with the vault health summary
+type StatsModel struct {
+	app      *App
+	health   *models.VaultHealth
+	activity []models.ActivityDay
+	loaded   bool
+	err      error
+	status   string // outcome of the last maintenance action
+	confirm  bool   // true while waiting to confirm pruning orphan tags
+	width    int
+	height   int
+}
+
+// NewStatsModel creates a new stats view model
+func NewStatsModel(app *App) *StatsModel {
+	return &StatsModel{app: app}
+}
+
+// Init initializes the stats view
+func (m *StatsModel) Init() tea.Cmd {
+	m.confirm = false
+	return m.loadHealth()
+}
+
+// loadHealth computes the vault health summary and the past year's
+// activity in the background
+func (m *StatsModel) loadHealth() tea.Cmd {
+	return func() tea.Msg {
+		health, err := m.app.GetStorage().GetVaultHealth(staleAfter)
+		if err != nil {
+			return healthLoadedMsg{err: err}
+		}
+		since := heatmapStart(time.Now(), heatmapWeeks).Format("2006-01-02")
+		activity, err := m.app.GetStorage().GetActivity(since)
+		return healthLoadedMsg{health: health, activity: activity, err: err}
+	}
+}
+
+// Update handles updates for the stats view
+func (m *StatsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case healthLoadedMsg:
+		m.health = msg.health
+		m.activity = msg.activity
+		m.err = msg.err
+		m.loaded = true
+		return m.app, nil
+
+	case maintenanceDoneMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.status = msg.status
+		}
+		return m.app, tea.Batch(m.loadHealth(), m.app.loadTags())
+
+	case tea.KeyMsg:
+		if m.confirm {
+			m.confirm = false
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m.app, m.pruneOrphanTags()
+			}
+			m.status = "Cancelled"
+			return m.app, nil
+		}
+
+		switch msg.String() {
+		case "u":
+			return m.app, m.jumpToNotes("is:untagged")
+		case "s":
+			cutoff := time.Now().Add(-staleAfter).Format("2006-01-02")
+			return m.app, m.jumpToNotes("updated:<" + cutoff)
+		case "d":
+			return m.app, m.jumpToNotes("is:duplicate")
+		case "D":
+			// Review notes that look alike, not just those sharing a title
+			return m.app, m.app.SwitchToView(ViewDuplicates)
+		case "o":
+			if m.health != nil && m.health.OrphanTags > 0 {
+				m.confirm = true
+			}
+		case "c":
+			m.status = "Compacting database..."
+			return m.app, m.compact()
+		case "i":
+			m.status = "Checking database integrity..."
+			return m.app, m.checkIntegrity()
+		case "r":
+			m.status = ""
+			return m.app, m.loadHealth()
+		case "q":
+			return m.app, m.app.SwitchToView(ViewNotesList)
+		}
+	}
+	return m.app, nil
+}
+
+// jumpToNotes opens the notes list filtered by a search query
+func (m *StatsModel) jumpToNotes(query string) tea.Cmd {
+	m.app.notesList.showQuery(query)
+	return m.app.SwitchToView(ViewNotesList)
+}
+
+// pruneOrphanTags deletes tags that no note uses
+func (m *StatsModel) pruneOrphanTags() tea.Cmd {
+	return func() tea.Msg {
+		removed, err := m.app.GetStorage().PruneOrphanTags()
+		return maintenanceDoneMsg{status: fmt.Sprintf("Removed %d unused tags", removed), err: err}
+	}
+}
+
+// compact reclaims free pages in the database file
+func (m *StatsModel) compact() tea.Cmd {
+	return func() tea.Msg {
+		err := m.app.GetStorage().Compact()
+		return maintenanceDoneMsg{status: "Database compacted", err: err}
+	}
+}
+
+// checkIntegrity checks the database file and the references between
+// its tables, reporting the first problem found
+func (m *StatsModel) checkIntegrity() tea.Cmd {
+	return func() tea.Msg {
+		problems, err := m.app.GetStorage().CheckIntegrity()
+		switch {
+		case err != nil:
+			return maintenanceDoneMsg{err: err}
+		case len(problems) == 0:
+			return maintenanceDoneMsg{status: "Integrity check passed"}
+		case len(problems) == 1:
+			return maintenanceDoneMsg{err: fmt.Errorf("integrity check failed: %s", problems[0])}
+		}
+		return maintenanceDoneMsg{err: fmt.Errorf("integrity check found %d problems, first: %s", len(problems), problems[0])}
+	}
+}
+
+// View renders the stats view
+func (m *StatsModel) View() string {
+	if !m.loaded {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Bold(true).
+			Render("Checking vault health...")
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#0D9488")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+
+	s := titleStyle.Render("Vault Health") + "\n\n"
+
+	if m.err != nil {
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F43F5E")).
+			Render("Error: "+m.err.Error()) + "\n\n"
+		return s + m.renderControls()
+	}
+
+	h := m.health
+	score := h.Score()
+	s += lipgloss.NewStyle().
+		Foreground(lipgloss.Color(scoreColor(score))).
+		Bold(true).
+		Render(fmt.Sprintf("Score %d/100", score))
+	s += lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render(fmt.Sprintf("  •  %d notes  •  %d tags", h.TotalNotes, h.TotalTags)) + "\n\n"
+
+	rows := []struct {
+		key, label string
+		count      int
+		pct        float64
+		unit       string
+	}{
+		{"u", "Untagged", h.UntaggedNotes, h.UntaggedPercent(), "notes"},
+		{"o", "Orphan tags", h.OrphanTags, h.OrphanPercent(), "tags"},
+		{"s", "Stale", h.StaleNotes, h.StalePercent(), "notes"},
+		{"d", "Duplicates", h.DuplicateNotes, h.DuplicatePercent(), "notes"},
+		{"c", "Fragmentation", h.FreePages, h.FragmentationPercent(), "free pages"},
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#2DD4BF")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9")).Width(15)
+	countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	for _, row := range rows {
+		s += "  " + keyStyle.Render("["+row.key+"]") + " " +
+			labelStyle.Render(row.label) +
+			renderHealthBar(row.pct) +
+			fmt.Sprintf(" %5.1f%%  ", row.pct) +
+			countStyle.Render(fmt.Sprintf("%d %s", row.count, row.unit)) + "\n"
+	}
+
+	weeks := heatmapWeeks
+	if m.width > 0 {
+		weeks = max(min(weeks, (m.width-4)/2), 1)
+	}
+	s += "\n" + lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#2DD4BF")).
+		Bold(true).
+		Render("Activity") + "\n"
+	s += renderHeatmap(m.activity, time.Now(), weeks)
+
+	if suggestions := healthSuggestions(h); len(suggestions) > 0 {
+		s += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F59E0B")).
+			Bold(true).
+			Render("Suggestions") + "\n"
+		for _, suggestion := range suggestions {
+			s += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#CBD5E1")).
+				Render("  • "+suggestion) + "\n"
+		}
+	}
+
+	if m.confirm {
+		s += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F43F5E")).
+			Bold(true).
+			Render(fmt.Sprintf("Delete %d unused tags? (y/n)", h.OrphanTags)) + "\n"
+	} else if m.status != "" {
+		s += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#4ADE80")).
+			Render(m.status) + "\n"
+	}
+
+	return s + "\n" + m.renderControls()
+}
+
+// healthSuggestions lists maintenance steps for the problems worth fixing
+func healthSuggestions(h *models.VaultHealth) []string {
+	var suggestions []string
+	if h.UntaggedNotes > 0 && h.UntaggedPercent() >= 20 {
+		suggestions = append(suggestions, fmt.Sprintf("Tag your %d untagged notes (u, then select and +)", h.UntaggedNotes))
+	}
+	if h.OrphanTags > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("Remove %d tags no note uses (o)", h.OrphanTags))
+	}
+	if h.StaleNotes > 0 && h.StalePercent() >= 25 {
+		suggestions = append(suggestions, fmt.Sprintf("Review %d notes untouched for %d days (s)", h.StaleNotes, int(staleAfter.Hours()/24)))
+	}
+	if len(h.DuplicateTitles) > 0 {
+		titles := h.DuplicateTitles
+		more := ""
+		if len(titles) > 3 {
+			more = fmt.Sprintf(" and %d more", len(titles)-3)
+			titles = titles[:3]
+		}
+		suggestions = append(suggestions, fmt.Sprintf("Merge or rename duplicates: %s%s (d, or D to review)", strings.Join(titles, ", "), more))
+	}
+	if h.FragmentationPercent() >= 10 {
+		suggestions = append(suggestions, "Compact the database to reclaim space (c)")
+	}
+	return suggestions
+}
+
+// renderHealthBar draws a short bar showing how much of the vault is affected
+func renderHealthBar(pct float64) string {
+	const width = 20
+	filled := min(int(pct*width/100+0.5), width)
+
+	color := "#4ADE80" // Green when little is affected
+	switch {
+	case pct >= 30:
+		color = "#F43F5E"
+	case pct >= 10:
+		color = "#F59E0B"
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#334155")).Render(strings.Repeat("░", width-filled))
+}
+
+// scoreColor picks a color for the overall health score
+func scoreColor(score int) string {
+	switch {
+	case score >= 80:
+		return "#4ADE80"
+	case score >= 60:
+		return "#F59E0B"
+	default:
+		return "#F43F5E"
+	}
+}
+
+// renderControls renders the key hints for the stats view
+func (m *StatsModel) renderControls() string {
+	controls := "u/s/d: Show notes • D: Review duplicates • o: Prune tags • c: Compact • i: Check integrity • r: Refresh • Esc: Back"
+	if m.width < 120 {
+		controls = "u/s/d: Notes • D: Duplicates • o: Prune • c: Compact • i: Check • Esc: Back"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render(controls)
+}
+
+// Messages
+type healthLoadedMsg struct {
+	health   *models.VaultHealth
+	activity []models.ActivityDay
+	err      error
+}
+
+type maintenanceDoneMsg struct {
+	status string
+	err    error
+}
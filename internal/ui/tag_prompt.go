@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxTagSuggestions caps the tags suggested when saving
+const maxTagSuggestions = 8
+
+// tagPrompt suggests tags for the note when it's saved: existing tags
+// named in the content first, then its most frequent keywords. Declining
+// saves the note as it is.
+type tagPrompt struct {
+	visible     bool
+	suggestions []string
+	chosen      map[string]bool
+	cursor      int
+	declined    map[string]bool // keys of suggestions not to make again for this note
+}
+
+// tagSuggestionsFor returns the tags to suggest for the content, leaving
+// out tags the note has and suggestions declined before
+func (m *NoteEditorModel) tagSuggestionsFor(content string) []string {
+	var names []string
+	for _, tag := range m.availableTags {
+		names = append(names, tag.Name)
+	}
+
+	seen := map[string]bool{}
+	for _, tag := range m.tags {
+		seen[utils.TagKey(tag.Name)] = true
+	}
+	for key := range m.tagPrompt.declined {
+		seen[key] = true
+	}
+
+	var suggestions []string
+	candidates := append(utils.MentionedTags(content, names), utils.Keywords(content, maxTagSuggestions)...)
+	for _, name := range candidates {
+		key := utils.TagKey(name)
+		if seen[key] || len(suggestions) == maxTagSuggestions {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, name)
+	}
+	return suggestions
+}
+
+// requestSave saves the note, first suggesting tags for it when there are
+// any to suggest
+func (m *NoteEditorModel) requestSave() tea.Cmd {
+	if m.app.GetConfig().NoTagSuggestions {
+		return m.saveNote()
+	}
+	suggestions := m.tagSuggestionsFor(m.contentInput.Value())
+	if len(suggestions) == 0 {
+		return m.saveNote()
+	}
+	m.tagPrompt.visible = true
+	m.tagPrompt.suggestions = suggestions
+	m.tagPrompt.chosen = map[string]bool{}
+	m.tagPrompt.cursor = 0
+	return nil
+}
+
+// handleTagPromptKey handles keys while tags are being suggested. Enter
+// adds the chosen tags, or the one under the cursor when none are, and
+// saves; Esc declines them all and saves.
+func (m *NoteEditorModel) handleTagPromptKey(msg tea.KeyMsg) tea.Cmd {
+	prompt := &m.tagPrompt
+	switch msg.String() {
+	case "up", "k":
+		prompt.cursor = max(prompt.cursor-1, 0)
+	case "down", "j":
+		prompt.cursor = min(prompt.cursor+1, len(prompt.suggestions)-1)
+	case " ", "x":
+		name := prompt.suggestions[prompt.cursor]
+		prompt.chosen[name] = !prompt.chosen[name]
+	case "a":
+		for _, name := range prompt.suggestions {
+			prompt.chosen[name] = true
+		}
+		return m.answerTagPrompt()
+	case "enter":
+		if len(prompt.chosen) == 0 {
+			prompt.chosen[prompt.suggestions[prompt.cursor]] = true
+		}
+		return m.answerTagPrompt()
+	case "esc":
+		return m.answerTagPrompt()
+	}
+	return nil
+}
+
+// answerTagPrompt adds the chosen suggestions to the note, remembers the
+// rest as declined and saves without suggesting more
+func (m *NoteEditorModel) answerTagPrompt() tea.Cmd {
+	prompt := &m.tagPrompt
+	prompt.visible = false
+	for _, name := range prompt.suggestions {
+		if prompt.chosen[name] {
+			m.addTag(name)
+		} else {
+			prompt.declined[utils.TagKey(name)] = true
+		}
+	}
+	return m.saveNote()
+}
+
+// renderTagPrompt renders the tag suggestions as a centered dialog
+func (m *NoteEditorModel) renderTagPrompt() string {
+	prompt := m.tagPrompt
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Italic(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#0F172A")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true)
+
+	s := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		Render("Suggested Tags") + "\n\n"
+	s += labelStyle.Render("Tag this note before saving?") + "\n\n"
+
+	existing := map[string]bool{}
+	for _, tag := range m.availableTags {
+		existing[tag.Name] = true
+	}
+	for i, name := range prompt.suggestions {
+		check := "[ ]"
+		if prompt.chosen[name] {
+			check = "[x]"
+		}
+		kind := "keyword"
+		if existing[name] {
+			kind = "tag"
+		}
+		line := fmt.Sprintf(" %s #%-28s", check, ansi.Truncate(name, 28, "…"))
+		if i == prompt.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s += line + labelStyle.Render(fmt.Sprintf(" %-7s", kind)) + "\n"
+	}
+
+	s += "\n" + hintStyle.Render("Space: Choose • Enter: Add and save • a: Add all • Esc: Save without")
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#EA580C")).
+		Padding(1, 2).
+		Render(s)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
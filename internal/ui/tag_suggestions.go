@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// suggestTags returns the names of available tags matching the typed input,
+// skipping tags the note already has. Input shorter than two characters
+// suggests nothing. Available tags are expected to be ranked by usage; that
+// order is kept but prefix matches are listed ahead of substring matches.
+func suggestTags(available []*models.Tag, input string, current []models.Tag) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if len(input) < 2 {
+		return []string{}
+	}
+
+	var prefixMatches, substringMatches []string
+	for _, tag := range available {
+		name := strings.ToLower(tag.Name)
+		if !strings.Contains(name, input) {
+			continue
+		}
+
+		// Check if tag is already added
+		alreadyAdded := false
+		for _, existingTag := range current {
+			if existingTag.ID == tag.ID || strings.EqualFold(existingTag.Name, tag.Name) {
+				alreadyAdded = true
+				break
+			}
+		}
+		if alreadyAdded {
+			continue
+		}
+
+		if strings.HasPrefix(name, input) {
+			prefixMatches = append(prefixMatches, tag.Name)
+		} else {
+			substringMatches = append(substringMatches, tag.Name)
+		}
+	}
+
+	return append(prefixMatches, substringMatches...)
+}
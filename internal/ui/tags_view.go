@@ -0,0 +1,321 @@
+package ui
+
+import (
+	"fmt"
+
+	"markdown-note-taking-app/internal/models"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TagsModel lists the tags with their aliases, sets tag colors and adds
+// and deletes aliases
+type TagsModel struct {
+	app     *App
+	tags    []*models.Tag
+	aliases []models.TagAlias
+	cursor  int
+	err     string
+	status  string
+	width   int
+	height  int
+
+	// The form for a new alias
+	adding     bool
+	alias      textinput.Model
+	tag        textinput.Model
+	onTag      bool   // the tag input has focus
+	confirming string // alias waiting for a second d to be deleted
+}
+
+// tagRow is a line of the tag manager: a tag, or an alias listed under
+// the tag it stands for
+type tagRow struct {
+	tag   *models.Tag
+	alias *models.TagAlias
+}
+
+// NewTagsModel creates the tag manager
+func NewTagsModel(app *App) *TagsModel {
+	alias := textinput.New()
+	alias.Prompt = "Alias: "
+	alias.Placeholder = "golang"
+
+	tag := textinput.New()
+	tag.Prompt = "Tag:   "
+	tag.Placeholder = "go"
+
+	return &TagsModel{app: app, alias: alias, tag: tag}
+}
+
+// Init shows the list of tags, reloading them
+func (m *TagsModel) Init() tea.Cmd {
+	m.adding = false
+	m.err = ""
+	m.status = ""
+	m.confirming = ""
+	return m.loadTags()
+}
+
+// loadTags loads the tags and their aliases
+func (m *TagsModel) loadTags() tea.Cmd {
+	return func() tea.Msg {
+		tags, err := m.app.GetStorage().GetAllTags()
+		if err != nil {
+			return tagsManagerLoadedMsg{err: err}
+		}
+		aliases, err := m.app.GetStorage().GetTagAliases()
+		return tagsManagerLoadedMsg{tags: tags, aliases: aliases, err: err}
+	}
+}
+
+// rows lists each tag followed by its aliases, then aliases of tags that
+// no longer exist
+func (m *TagsModel) rows() []tagRow {
+	listed := map[string]bool{}
+	var rows []tagRow
+	for _, tag := range m.tags {
+		rows = append(rows, tagRow{tag: tag})
+		for i, alias := range m.aliases {
+			if alias.Tag == tag.Name {
+				rows = append(rows, tagRow{alias: &m.aliases[i]})
+				listed[alias.Alias] = true
+			}
+		}
+	}
+	for i, alias := range m.aliases {
+		if !listed[alias.Alias] {
+			rows = append(rows, tagRow{alias: &m.aliases[i]})
+		}
+	}
+	return rows
+}
+
+// Update handles updates for the tag manager
+func (m *TagsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tagsManagerLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m.app, nil
+		}
+		m.tags = msg.tags
+		m.aliases = msg.aliases
+		m.cursor = max(min(m.cursor, len(m.rows())-1), 0)
+		return m.app, nil
+
+	case tagChangedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m.app, nil
+		}
+		m.err = ""
+		m.status = msg.status
+		m.adding = false
+		m.alias.Blur()
+		m.tag.Blur()
+		// Merged and recolored tags change the tags the editor shows
+		return m.app, tea.Batch(m.loadTags(), m.app.loadTags())
+
+	case tea.KeyMsg:
+		if m.adding {
+			return m.app, m.handleFormKey(msg)
+		}
+
+		rows := m.rows()
+		var row tagRow
+		if m.cursor < len(rows) {
+			row = rows[m.cursor]
+		}
+		confirming := m.confirming
+		m.confirming = ""
+		switch msg.String() {
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = max(min(m.cursor+1, len(rows)-1), 0)
+		case "c", "C":
+			if row.tag == nil {
+				break
+			}
+			direction := 1
+			if msg.String() == "C" {
+				direction = -1
+			}
+			return m.app, m.setColor(row.tag, nextColorLabel(row.tag.Color, direction))
+		case "a", "n":
+			m.adding = true
+			m.err = ""
+			m.status = ""
+			m.alias.SetValue("")
+			m.tag.SetValue("")
+			if row.tag != nil {
+				m.tag.SetValue(row.tag.Name)
+			}
+			m.onTag = false
+			m.tag.Blur()
+			return m.app, m.alias.Focus()
+		case "d":
+			if row.alias == nil {
+				break
+			}
+			alias := row.alias.Alias
+			if confirming != alias {
+				m.confirming = alias
+				break
+			}
+			return m.app, func() tea.Msg {
+				err := m.app.GetStorage().DeleteTagAlias(alias)
+				return tagChangedMsg{status: fmt.Sprintf("Removed alias %q", alias), err: err}
+			}
+		}
+	}
+	return m.app, nil
+}
+
+// setColor gives a tag a color label, or removes it when color is empty
+func (m *TagsModel) setColor(tag *models.Tag, color string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.app.GetStorage().SetTagColor(tag.ID, color)
+		if color == "" {
+			return tagChangedMsg{status: fmt.Sprintf("Removed the color of #%s", tag.Name), err: err}
+		}
+		return tagChangedMsg{status: fmt.Sprintf("Colored #%s %s", tag.Name, color), err: err}
+	}
+}
+
+// handleFormKey handles keys while an alias is being added
+func (m *TagsModel) handleFormKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.adding = false
+		m.err = ""
+		m.alias.Blur()
+		m.tag.Blur()
+		return nil
+	case "enter", "ctrl+s":
+		if !m.onTag && msg.String() == "enter" {
+			m.onTag = true
+			m.alias.Blur()
+			return m.tag.Focus()
+		}
+		alias, tag := m.alias.Value(), m.tag.Value()
+		return func() tea.Msg {
+			err := m.app.GetStorage().SetTagAlias(alias, tag)
+			return tagChangedMsg{status: fmt.Sprintf("%q now means %q", alias, tag), err: err}
+		}
+	case "tab", "shift+tab":
+		m.onTag = !m.onTag
+		if m.onTag {
+			m.alias.Blur()
+			return m.tag.Focus()
+		}
+		m.tag.Blur()
+		return m.alias.Focus()
+	}
+
+	if m.onTag {
+		return updateInput(&m.tag, msg)
+	}
+	return updateInput(&m.alias, msg)
+}
+
+// View renders the tags and their aliases, with the form below while an
+// alias is being added
+func (m *TagsModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8"))
+	aliasStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E"))
+
+	s := titleStyle.Render("Tags") + "\n\n"
+	rows := m.rows()
+	if len(rows) == 0 {
+		s += "  " + hintStyle.Italic(true).Render("No tags yet.") + "\n"
+	}
+
+	// Keep the cursor in view on long lists
+	height := max(m.height-12, 5)
+	offset := max(m.cursor-height+1, 0)
+	for i := offset; i < min(offset+height, len(rows)); i++ {
+		row := rows[i]
+		cursor := "  "
+		if i == m.cursor && !m.adding {
+			cursor = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EA580C")).
+				Bold(true).
+				Render("▶ ")
+		}
+
+		var line string
+		if row.tag != nil {
+			style := lipgloss.NewStyle().Foreground(tagColor(*row.tag)).Bold(i == m.cursor)
+			color := row.tag.Color
+			if color == "" {
+				color = "no color"
+			}
+			line = style.Render(fmt.Sprintf("#%-24s", row.tag.Name)) + " " + aliasStyle.Render(color)
+		} else {
+			line = aliasStyle.Render(fmt.Sprintf("  ↳ %-22s", row.alias.Alias)) + " " + aliasStyle.Render("alias of #"+row.alias.Tag)
+		}
+		s += ansi.Truncate("  "+cursor+line, m.width, "…") + "\n"
+	}
+
+	if m.adding {
+		s += "\n  " + m.alias.View() + "\n  " + m.tag.View() + "\n"
+	}
+
+	s += "\n"
+	switch {
+	case m.err != "":
+		s += errStyle.Render(m.err) + "\n\n"
+	case m.confirming != "":
+		s += errStyle.Render("Press d again to delete the alias") + "\n\n"
+	case m.status != "":
+		s += hintStyle.Render(m.status) + "\n\n"
+	case !m.adding:
+		s += hintStyle.Italic(true).Render("Tagging a note with an alias tags it with the tag instead.") + "\n\n"
+	}
+
+	if m.adding {
+		return s + hintStyle.Render("Tab: Switch field • Enter: Save • Esc: Cancel")
+	}
+	return s + hintStyle.Render("↑↓: Navigate • c/C: Color • a: Add alias • d: Delete alias • Esc: Back")
+}
+
+// tagsView returns the tag manager, creating it on first use
+func (a *App) tagsView() *TagsModel {
+	if a.tagManager == nil {
+		a.tagManager = NewTagsModel(a)
+		a.tagManager.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.tagManager
+}
+
+// Messages
+
+// tagsManagerLoadedMsg carries the tags and aliases for the tag manager
+type tagsManagerLoadedMsg struct {
+	tags    []*models.Tag
+	aliases []models.TagAlias
+	err     error
+}
+
+// tagChangedMsg reports a tag recolored or an alias added or deleted, or
+// why that failed
+type tagChangedMsg struct {
+	status string
+	err    error
+}
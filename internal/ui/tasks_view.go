@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// taskGroup holds the tasks found in a single note
+type taskGroup struct {
+	note  *models.Note
+	tasks []utils.Task
+}
+
+// taskRow points at a selectable task inside the grouped list
+type taskRow struct {
+	group int
+	task  int
+}
+
+// TasksModel manages the global task dashboard view
+type TasksModel struct {
+	app    *App
+	groups []taskGroup
+	rows   []taskRow
+	cursor int
+	loaded bool
+	width  int
+	height int
+
+	// Filtering
+	tags          []*models.Tag
+	tagFilter     int  // -1 = all tags, otherwise index into tags
+	hideCompleted bool // true to only show open tasks
+
+	saveErr string // why the last toggled task couldn't be saved
+}
+
+// NewTasksModel creates a new task dashboard model
+func NewTasksModel(app *App) *TasksModel {
+	return &TasksModel{
+		app:       app,
+		groups:    []taskGroup{},
+		rows:      []taskRow{},
+		cursor:    0,
+		loaded:    false,
+		tagFilter: -1,
+	}
+}
+
+// Init initializes the task dashboard
+func (m *TasksModel) Init() tea.Cmd {
+	return m.loadTasks()
+}
+
+// loadTasks scans all notes (optionally filtered by tag) for task items
+func (m *TasksModel) loadTasks() tea.Cmd {
+	filter := models.NoteFilter{}
+	if m.tagFilter >= 0 && m.tagFilter < len(m.tags) {
+		filter.TagIDs = []int{m.tags[m.tagFilter].ID}
+	}
+
+	return func() tea.Msg {
+		notes, err := m.app.GetStorage().GetAllNotes(filter)
+		if err != nil {
+			notes = []*models.Note{}
+		}
+		tags, err := m.app.GetStorage().GetAllTags()
+		if err != nil {
+			tags = []*models.Tag{}
+		}
+		return tasksLoadedMsg{notes: notes, tags: tags}
+	}
+}
+
+// buildGroups extracts tasks from the loaded notes and rebuilds the selectable rows
+func (m *TasksModel) buildGroups(notes []*models.Note) {
+	m.groups = []taskGroup{}
+	for _, note := range notes {
+		tasks := utils.ParseTasks(note.Content)
+		if len(tasks) == 0 {
+			continue
+		}
+		m.groups = append(m.groups, taskGroup{note: note, tasks: tasks})
+	}
+	m.buildRows()
+}
+
+// buildRows flattens the visible tasks into cursor-addressable rows
+func (m *TasksModel) buildRows() {
+	m.rows = []taskRow{}
+	for gi, group := range m.groups {
+		for ti, task := range group.tasks {
+			if m.hideCompleted && task.Done {
+				continue
+			}
+			m.rows = append(m.rows, taskRow{group: gi, task: ti})
+		}
+	}
+
+	if m.cursor >= len(m.rows) {
+		m.cursor = max(len(m.rows)-1, 0)
+	}
+}
+
+// Update handles updates for the task dashboard
+func (m *TasksModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tasksLoadedMsg:
+		m.tags = msg.tags
+		if m.tagFilter >= len(m.tags) {
+			m.tagFilter = -1
+		}
+		m.buildGroups(msg.notes)
+		m.loaded = true
+		return m.app, nil
+
+	case taskSavedMsg:
+		if msg.err != nil {
+			// Reload so the dashboard shows what's stored again
+			slog.Warn("failed to save task", "id", msg.note.ID, "err", msg.err)
+			m.saveErr = "Error: " + msg.err.Error()
+			return m.app, m.loadTasks()
+		}
+		m.saveErr = ""
+		for _, group := range m.groups {
+			if group.note.ID == msg.note.ID {
+				*group.note = *msg.note
+			}
+		}
+		return m.app, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.rows)-1 {
+				m.cursor++
+			}
+		case " ", "x":
+			return m.app, m.toggleSelectedTask()
+		case "enter", "e":
+			// Jump to the note containing the selected task
+			if row, ok := m.selectedRow(); ok {
+				m.app.notesList.selectedNote = m.groups[row.group].note
+				return m.app, m.app.SwitchToView(ViewNoteEditor)
+			}
+		case "t":
+			// Cycle through tag filters (all -> each tag -> all)
+			m.tagFilter++
+			if m.tagFilter >= len(m.tags) {
+				m.tagFilter = -1
+			}
+			m.cursor = 0
+			return m.app, m.loadTasks()
+		case "c":
+			// Toggle visibility of completed tasks
+			m.hideCompleted = !m.hideCompleted
+			m.buildRows()
+		case "r":
+			return m.app, m.loadTasks()
+		case "q":
+			return m.app, m.app.SwitchToView(ViewNotesList)
+		}
+	}
+	return m.app, nil
+}
+
+// selectedRow returns the row under the cursor
+func (m *TasksModel) selectedRow() (taskRow, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return taskRow{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+// toggleSelectedTask flips the selected task and persists the note content
+func (m *TasksModel) toggleSelectedTask() tea.Cmd {
+	row, ok := m.selectedRow()
+	if !ok {
+		return nil
+	}
+
+	group := &m.groups[row.group]
+	task := &group.tasks[row.task]
+
+	content, ok := utils.ToggleTaskAt(group.note.Content, task.Line)
+	if !ok {
+		return nil
+	}
+
+	// Update in memory right away so the dashboard feels responsive
+	group.note.Content = content
+	task.Done = !task.Done
+	m.buildRows()
+
+	// Save a copy; the result is applied in Update
+	note := *group.note
+	return func() tea.Msg {
+		err := m.app.GetStorage().UpdateNote(&note)
+		return taskSavedMsg{note: &note, err: err}
+	}
+}
+
+// countTasks returns the number of open and completed tasks across all groups
+func (m *TasksModel) countTasks() (open, done int) {
+	for _, group := range m.groups {
+		for _, task := range group.tasks {
+			if task.Done {
+				done++
+			} else {
+				open++
+			}
+		}
+	}
+	return open, done
+}
+
+// renderDueDate renders a due annotation colored by urgency
+func renderDueDate(due time.Time, done bool) string {
+	// Compare calendar days in local time
+	today := time.Now().Format("2006-01-02")
+	dueDay := due.Format("2006-01-02")
+
+	color := "#64748B" // Muted for future dates
+	label := due.Format("Jan 2, 2006")
+	if !done {
+		switch {
+		case dueDay < today:
+			color = "#F43F5E" // Rose for overdue
+			label += " (overdue)"
+		case dueDay == today:
+			color = "#F59E0B" // Amber for due today
+			label += " (today)"
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(color)).
+		Render("  due " + label)
+}
+
+// View renders the task dashboard
+func (m *TasksModel) View() string {
+	if !m.loaded {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Bold(true).
+			Render("Scanning notes for tasks...")
+	}
+
+	orangeHighlight := "#EA580C" // Orange
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color(orangeHighlight)).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+
+	s := titleStyle.Render("Tasks") + "\n\n"
+
+	// Summary line with counts and active filter
+	open, done := m.countTasks()
+	filterLabel := "all notes"
+	if m.tagFilter >= 0 && m.tagFilter < len(m.tags) {
+		filterLabel = "#" + m.tags[m.tagFilter].Name
+	}
+	summary := fmt.Sprintf("%d open • %d completed • %s", open, done, filterLabel)
+	if m.hideCompleted {
+		summary += " • hiding completed"
+	}
+	s += lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Render(summary) + "\n\n"
+	if m.saveErr != "" {
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F43F5E")).
+			Render(m.saveErr) + "\n\n"
+	}
+
+	if len(m.rows) == 0 {
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Italic(true).
+			Render("No tasks found. Add \"- [ ] something\" to a note to track it here.")
+		return s + "\n\n" + m.renderControls()
+	}
+
+	noteStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#38BDF8")).
+		Bold(true)
+
+	// Build all lines, remembering where the cursor lands so we can scroll to it
+	var lines []string
+	cursorLine := 0
+	rowIndex := 0
+	for gi, group := range m.groups {
+		var groupLines []string
+		for _, task := range group.tasks {
+			if m.hideCompleted && task.Done {
+				continue
+			}
+
+			selected := rowIndex == m.cursor
+			if selected {
+				cursorLine = len(lines) + len(groupLines) + 1
+			}
+			groupLines = append(groupLines, m.renderTaskLine(task, selected))
+			rowIndex++
+		}
+		if len(groupLines) == 0 {
+			continue
+		}
+
+		lines = append(lines, noteStyle.Render("📝 "+group.note.Title))
+		lines = append(lines, groupLines...)
+		if gi < len(m.groups)-1 {
+			lines = append(lines, "")
+		}
+	}
+
+	// Keep the cursor line within the visible window
+	maxLines := max(m.height-10, 5)
+	start := 0
+	if cursorLine >= maxLines {
+		start = cursorLine - maxLines + 1
+	}
+	end := min(start+maxLines, len(lines))
+
+	s += strings.Join(lines[start:end], "\n") + "\n\n"
+	s += m.renderControls()
+	return s
+}
+
+// renderTaskLine renders one task row
+func (m *TasksModel) renderTaskLine(task utils.Task, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#EA580C")).
+			Bold(true).
+			Render("▶ ")
+	}
+
+	var box, text string
+	if task.Done {
+		box = lipgloss.NewStyle().Foreground(lipgloss.Color("#4ADE80")).Render("☑ ")
+		text = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#64748B")).
+			Strikethrough(true).
+			Render(task.Text)
+	} else {
+		textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		if selected {
+			textStyle = textStyle.Bold(true)
+		}
+		box = lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Render("☐ ")
+		text = textStyle.Render(task.Text)
+	}
+
+	line := "  " + cursor + box + text
+	if task.Due != nil {
+		line += renderDueDate(*task.Due, task.Done)
+	}
+	return line
+}
+
+// renderControls renders the key hints for the dashboard
+func (m *TasksModel) renderControls() string {
+	controls := "↑↓: Navigate • Space: Toggle • Enter: Open note • t: Filter tag • c: Hide completed • Esc: Back"
+	if m.width < 100 {
+		controls = "↑↓: Nav • Space: Toggle • Enter: Open • t: Tag • c: Done • Esc: Back"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render(controls)
+}
+
+// Messages
+type tasksLoadedMsg struct {
+	notes []*models.Note
+	tags  []*models.Tag
+}
+
+// taskSavedMsg reports the saved copy of a note whose task was toggled
+type taskSavedMsg struct {
+	note *models.Note
+	err  error
+}
@@ -38,16 +38,18 @@ var Colors = Color{
 	BorderInactive: lipgloss.Color("#475569"), // Dimmer border for inactive elements
 }
 
-// Tag colors for variety and visual hierarchy
+// Tag colors for variety and visual hierarchy. Note color labels use them
+// too and are stored by Name.
 var TagColors = []struct {
+	Name       string
 	Foreground lipgloss.Color
 	Background lipgloss.Color
 	Border     lipgloss.Color
 }{
-	{Colors.Primary, Colors.Background, lipgloss.Color("#0EA5E9")},     // Cyan
-	{Colors.Secondary, Colors.Background, lipgloss.Color("#22C55E")},   // Green
-	{lipgloss.Color("#C084FC"), Colors.Background, lipgloss.Color("#A855F7")}, // Purple
-	{lipgloss.Color("#FB923C"), Colors.Background, lipgloss.Color("#F97316")}, // Orange
+	{"cyan", Colors.Primary, Colors.Background, lipgloss.Color("#0EA5E9")},
+	{"green", Colors.Secondary, Colors.Background, lipgloss.Color("#22C55E")},
+	{"purple", lipgloss.Color("#C084FC"), Colors.Background, lipgloss.Color("#A855F7")},
+	{"orange", lipgloss.Color("#FB923C"), Colors.Background, lipgloss.Color("#F97316")},
 }
 
 // Heading colors for markdown preview
@@ -0,0 +1,37 @@
+package theme
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HeadingDecoration describes how a heading level is decorated in the preview
+type HeadingDecoration struct {
+	Rule        string // Character repeated across the pane width under the heading, empty for none
+	SpaceBefore int    // Blank lines inserted before the heading (skipped at the top of a note)
+	SpaceAfter  int    // Blank lines inserted after the heading and its rule
+	ShowPrefix  bool   // Keep the "#" markers in front of the heading text
+}
+
+// HeadingDecorations holds the decoration for H1, H2, ... with the last entry used for deeper levels
+var HeadingDecorations = []HeadingDecoration{
+	{Rule: "═", SpaceBefore: 1, SpaceAfter: 1, ShowPrefix: false}, // H1 - double rule
+	{Rule: "─", SpaceBefore: 1, SpaceAfter: 0, ShowPrefix: false}, // H2 - single rule
+	{Rule: "", SpaceBefore: 0, SpaceAfter: 0, ShowPrefix: true},   // H3+ - marker only
+}
+
+// SectionSeparator is the character used for thematic breaks (---)
+var SectionSeparator = "─"
+
+// SectionSeparatorColor is the color of thematic breaks
+var SectionSeparatorColor = lipgloss.Color("#475569")
+
+// HeadingDecorationFor returns the decoration for a heading level (1-based)
+func HeadingDecorationFor(level int) HeadingDecoration {
+	if level < 1 {
+		level = 1
+	}
+	if level > len(HeadingDecorations) {
+		return HeadingDecorations[len(HeadingDecorations)-1]
+	}
+	return HeadingDecorations[level-1]
+}
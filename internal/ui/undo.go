@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// undoWindow is how long the last destructive action can be undone
+const undoWindow = 10 * time.Second
+
+// undoEntry reverts the last destructive action in the notes list
+type undoEntry struct {
+	label  string // what was done, e.g. "Deleted 3 notes"
+	revert func(s *storage.Service) error
+}
+
+// inverseOf captures what's needed to revert action on notes before it
+// runs. Returns nil for actions that don't change notes.
+func inverseOf(action bulkAction, notes []*models.Note, value string) func(s *storage.Service) error {
+	switch action {
+	case bulkDelete:
+		return restoreNotes(notes)
+
+	case bulkAddTag:
+		// Only notes that didn't carry the tag lose it again
+		var ids []int
+		for _, note := range notes {
+			if !hasTag(note, value) {
+				ids = append(ids, note.ID)
+			}
+		}
+		return func(s *storage.Service) error {
+			if len(ids) == 0 {
+				return nil
+			}
+			return s.RemoveTagFromNotes(ids, value)
+		}
+
+	case bulkRemoveTag:
+		var ids []int
+		for _, note := range notes {
+			if hasTag(note, value) {
+				ids = append(ids, note.ID)
+			}
+		}
+		return func(s *storage.Service) error {
+			if len(ids) == 0 {
+				return nil
+			}
+			return s.AddTagToNotes(ids, value)
+		}
+
+	case bulkMove:
+		// Each note goes back to the notebook it came from
+		byNotebook := map[string][]int{}
+		for _, note := range notes {
+			byNotebook[note.Notebook] = append(byNotebook[note.Notebook], note.ID)
+		}
+		return func(s *storage.Service) error {
+			for notebook, ids := range byNotebook {
+				if err := s.MoveNotesToNotebook(ids, notebook); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// restoreNotes copies notes about to be deleted and returns a revert that
+// recreates them
+func restoreNotes(notes []*models.Note) func(s *storage.Service) error {
+	copies := make([]*models.Note, len(notes))
+	for i, note := range notes {
+		copied := *note
+		copied.Tags = append([]models.Tag(nil), note.Tags...)
+		copies[i] = &copied
+	}
+	return func(s *storage.Service) error {
+		return s.RestoreNotes(copies)
+	}
+}
+
+// hasTag reports whether note carries the tag named name
+func hasTag(note *models.Note, name string) bool {
+	for _, tag := range note.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// offerUndo keeps revert as the action Ctrl+Z undoes until the undo window
+// closes
+func (m *NotesListModel) offerUndo(label string, revert func(s *storage.Service) error) tea.Cmd {
+	m.undoSeq++
+	m.undo = &undoEntry{label: label, revert: revert}
+	seq := m.undoSeq
+	return tea.Tick(undoWindow, func(time.Time) tea.Msg {
+		return undoExpiredMsg{seq: seq}
+	})
+}
+
+// runUndo reverts the last destructive action if it's still undoable
+func (m *NotesListModel) runUndo() tea.Cmd {
+	if m.undo == nil {
+		m.statusMsg = "Nothing to undo"
+		return nil
+	}
+	entry := m.undo
+	m.undo = nil
+	return func() tea.Msg {
+		if err := entry.revert(m.app.GetStorage()); err != nil {
+			return undoneMsg{err: err}
+		}
+		return undoneMsg{status: fmt.Sprintf("Undone: %s", entry.label)}
+	}
+}
+
+// Messages
+
+// undoExpiredMsg closes the undo window of the action offered as seq
+type undoExpiredMsg struct {
+	seq int
+}
+
+// undoneMsg reports the outcome of an undo
+type undoneMsg struct {
+	status string
+	err    error
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"markdown-note-taking-app/internal/storage"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// idleCheckInterval is how often the idle lock checks for inactivity
+const idleCheckInterval = 15 * time.Second
+
+// UnlockModel manages the passphrase prompt shown while notes are encrypted
+// and locked
+type UnlockModel struct {
+	app       *App
+	input     textinput.Model
+	err       string
+	unlocking bool // true while the key is being derived
+	width     int
+	height    int
+}
+
+// NewUnlockModel creates a new unlock view model
+func NewUnlockModel(app *App) *UnlockModel {
+	input := textinput.New()
+	input.Placeholder = "Passphrase"
+	input.EchoMode = textinput.EchoPassword
+	input.EchoCharacter = '•'
+	input.Width = 40
+	input.Focus()
+
+	return &UnlockModel{app: app, input: input}
+}
+
+// Init initializes the unlock view with an empty prompt
+func (m *UnlockModel) Init() tea.Cmd {
+	m.input.Reset()
+	m.unlocking = false
+	return textinput.Blink
+}
+
+// unlock derives the key in the background; Argon2 takes a moment
+func (m *UnlockModel) unlock(passphrase string) tea.Cmd {
+	return func() tea.Msg {
+		return unlockedMsg{err: m.app.GetStorage().Unlock(passphrase)}
+	}
+}
+
+// Update handles updates for the unlock view
+func (m *UnlockModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m.app, nil
+
+	case unlockedMsg:
+		m.unlocking = false
+		if msg.err != nil {
+			if errors.Is(msg.err, storage.ErrWrongPassphrase) {
+				m.err = "Wrong passphrase"
+			} else {
+				m.err = msg.err.Error()
+			}
+			m.input.Reset()
+			return m.app, nil
+		}
+		m.err = ""
+		m.input.Reset()
+		return m.app, m.app.unlocked()
+
+	case tea.KeyMsg:
+		if m.unlocking {
+			return m.app, nil
+		}
+		if msg.String() == "enter" && m.input.Value() != "" {
+			m.unlocking = true
+			m.err = ""
+			return m.app, m.unlock(m.input.Value())
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m.app, cmd
+}
+
+// View renders the passphrase prompt centred on the screen
+func (m *UnlockModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	s := titleStyle.Render("🔒 Notes are locked") + "\n\n"
+	s += m.input.View() + "\n\n"
+	switch {
+	case m.unlocking:
+		s += hintStyle.Render("Unlocking...")
+	case m.err != "":
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#F43F5E")).Render(m.err)
+	default:
+		s += hintStyle.Render("enter: unlock • ctrl+c: quit")
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#475569")).
+		Padding(1, 2).
+		Render(s)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// lockAfter returns how long the app may sit idle before locking, or 0
+// when it never locks
+func (a *App) lockAfter() time.Duration {
+	if !a.storage.EncryptionEnabled() {
+		return 0
+	}
+	return time.Duration(a.config.LockAfterMinutes) * time.Minute
+}
+
+// idleCheck schedules the next inactivity check
+func (a *App) idleCheck() tea.Cmd {
+	if a.lockAfter() == 0 {
+		return nil
+	}
+	return tea.Tick(idleCheckInterval, func(time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+// lock forgets the key and shows the unlock screen. Open tabs are kept so
+// unsaved edits survive; the view they were in is restored after unlocking.
+func (a *App) lock() tea.Cmd {
+	a.storage.Lock()
+	if a.currentView != ViewUnlock {
+		a.lockedView = a.currentView
+	}
+	a.currentView = ViewUnlock
+	return a.unlockView.Init()
+}
+
+// unlocked returns to the view that was shown before locking and reloads
+// the notes that couldn't be read while locked
+func (a *App) unlocked() tea.Cmd {
+	a.currentView = a.lockedView
+	a.lastInput = time.Now()
+	return tea.Batch(a.notesList.Init(), a.loadTags(), a.loadSnippets(), a.idleCheck(), a.openStartNote())
+}
+
+// Messages
+
+type unlockedMsg struct {
+	err error
+}
+
+type idleCheckMsg struct{}
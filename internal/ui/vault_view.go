@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"markdown-note-taking-app/internal/config"
+	"markdown-note-taking-app/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// VaultsModel lists the configured vaults and switches to the chosen one
+type VaultsModel struct {
+	app     *App
+	vaults  []config.Vault
+	cursor  int
+	err     string
+	opening string // name of the vault being opened
+	width   int
+	height  int
+}
+
+// NewVaultsModel creates a new vault switcher
+func NewVaultsModel(app *App) *VaultsModel {
+	return &VaultsModel{app: app}
+}
+
+// Init lists the vaults with the cursor on the open one
+func (m *VaultsModel) Init() tea.Cmd {
+	m.err = ""
+	m.opening = ""
+	vaults, err := m.app.config.AllVaults()
+	if err != nil {
+		m.err = err.Error()
+	}
+	m.vaults = vaults
+
+	m.cursor = 0
+	for i, vault := range m.vaults {
+		if vault.Name == m.app.vault.Name {
+			m.cursor = i
+		}
+	}
+	return nil
+}
+
+// Update handles updates for the vault switcher
+func (m *VaultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.opening != "" {
+			return m.app, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, len(m.vaults)-1)
+		case "enter":
+			if len(m.vaults) == 0 {
+				break
+			}
+			vault := m.vaults[m.cursor]
+			if vault.Name == m.app.vault.Name {
+				return m.app, m.app.SwitchToView(ViewNotesList)
+			}
+			if reason := m.app.vaultBusy(); reason != "" {
+				m.err = reason
+				break
+			}
+			m.err = ""
+			m.opening = vault.Name
+			return m.app, openVault(vault)
+		}
+	}
+	return m.app, nil
+}
+
+// View renders the vaults, marking the open one
+func (m *VaultsModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F1F5F9")).
+		Background(lipgloss.Color("#EA580C")).
+		Bold(true).
+		Padding(0, 1).
+		MarginBottom(1)
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B"))
+
+	s := titleStyle.Render("Vaults") + "\n\n"
+	for i, vault := range m.vaults {
+		cursor := "  "
+		nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F5F9"))
+		if i == m.cursor {
+			cursor = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EA580C")).
+				Bold(true).
+				Render("▶ ")
+			nameStyle = nameStyle.Bold(true)
+		}
+
+		line := "  " + cursor + nameStyle.Render(vault.Name)
+		if vault.Name == m.app.vault.Name {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("#4ADE80")).Render(" (open)")
+		}
+		line += "  " + pathStyle.Render(vault.Path)
+		s += ansi.Truncate(line, m.width, "…") + "\n"
+	}
+
+	s += "\n"
+	switch {
+	case m.opening != "":
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F59E0B")).
+			Render(fmt.Sprintf("Opening %s...", m.opening)) + "\n\n"
+	case m.err != "":
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F43F5E")).
+			Render(m.err) + "\n\n"
+	case len(m.vaults) < 2:
+		s += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#94A3B8")).
+			Italic(true).
+			Render("Add vaults to \"vaults\" in the config file to switch between databases.") + "\n\n"
+	}
+
+	return s + lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Render("↑↓: Navigate • Enter: Open vault • Esc: Back")
+}
+
+// vaultView returns the vault switcher, creating it on first use
+func (a *App) vaultView() *VaultsModel {
+	if a.vaults == nil {
+		a.vaults = NewVaultsModel(a)
+		a.vaults.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	}
+	return a.vaults
+}
+
+// vaultBusy returns why the open vault can't be closed yet, or "" when it can
+func (a *App) vaultBusy() string {
+	if a.syncState == syncRunning {
+		return "Wait for the sync to finish before switching vaults"
+	}
+	for _, editor := range a.editors {
+		if editor.dirty() {
+			return "Save or close the notes with unsaved changes before switching vaults"
+		}
+	}
+	return ""
+}
+
+// openVault opens the vault's database in the background; migrations may
+// take a moment
+func openVault(vault config.Vault) tea.Cmd {
+	return func() tea.Msg {
+		service, err := storage.NewService(vault.Path)
+		return vaultOpenedMsg{vault: vault, service: service, err: err}
+	}
+}
+
+// vaultOpened closes the previous vault and shows the one just opened
+func (a *App) vaultOpened(msg vaultOpenedMsg) tea.Cmd {
+	if msg.err != nil {
+		if a.vaults != nil {
+			a.vaults.opening = ""
+			a.vaults.err = fmt.Sprintf("Failed to open %s: %v", msg.vault.Name, msg.err)
+		}
+		return nil
+	}
+
+	// Another instance may have the vault open
+	if a.instance != nil {
+		if err := a.instance.Move(msg.vault.Path); err != nil {
+			msg.service.Close()
+			if a.vaults != nil {
+				a.vaults.opening = ""
+				a.vaults.err = fmt.Sprintf("Failed to open %s: %v", msg.vault.Name, err)
+			}
+			return nil
+		}
+	}
+
+	// The new vault is open either way
+	if err := a.storage.Close(); err != nil {
+		slog.Warn("failed to close vault", "vault", a.vault.Name, "err", err)
+	}
+	a.open(msg.service, msg.vault)
+	if a.vaults != nil {
+		a.vaults.opening = ""
+	}
+	return a.Init()
+}
+
+// Messages
+
+type vaultOpenedMsg struct {
+	vault   config.Vault
+	service *storage.Service
+	err     error
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"markdown-note-taking-app/internal/models"
+	"markdown-note-taking-app/internal/sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startWatching mirrors the notes into the vault's watch directory and
+// watches it for files changed by other programs
+func (a *App) startWatching() tea.Cmd {
+	dir := a.vault.WatchDir
+	if dir == "" || a.watcher != nil {
+		return nil
+	}
+	if a.storage.EncryptionEnabled() {
+		// Mirroring would write the notes out in plain text
+		a.notesList.statusMsg = "Watching " + dir + " isn't available for encrypted notes"
+		return nil
+	}
+
+	mirror, err := sync.NewDir(a.storage, dir)
+	if err != nil {
+		slog.Warn("failed to set up watch directory", "dir", dir, "err", err)
+		a.notesList.statusMsg = "Error: couldn't watch " + dir
+		return nil
+	}
+	watcher, err := sync.Watch(dir)
+	if err != nil {
+		slog.Warn("failed to set up watch directory", "dir", dir, "err", err)
+		a.notesList.statusMsg = "Error: couldn't watch " + dir
+		return nil
+	}
+	slog.Debug("watching notes directory", "dir", dir)
+	a.mirror = mirror
+	a.watcher = watcher
+	return tea.Batch(a.runMirror(), waitForChanges(watcher))
+}
+
+// stopWatching stops watching the open vault's directory
+func (a *App) stopWatching() {
+	if a.watcher == nil {
+		return
+	}
+	if err := a.watcher.Close(); err != nil {
+		slog.Warn("failed to stop watching", "dir", a.vault.WatchDir, "err", err)
+	}
+	a.watcher = nil
+	a.mirror = nil
+	a.mirroring = false
+	a.mirrorAgain = false
+}
+
+// notesChanged asks for the notes to be mirrored after they changed in
+// the app
+func (a *App) notesChanged() {
+	if a.watcher != nil {
+		a.watcher.Trigger()
+	}
+}
+
+// waitForChanges waits for the watched files to change
+func waitForChanges(watcher *sync.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-watcher.Changes(); !ok {
+			return nil
+		}
+		return watchChangedMsg{watcher: watcher}
+	}
+}
+
+// runMirror syncs the notes with the watched directory in the background,
+// after the mirror already running if there is one. The notes open in
+// tabs without unsaved changes are read again afterwards.
+func (a *App) runMirror() tea.Cmd {
+	if a.mirroring {
+		a.mirrorAgain = true
+		return nil
+	}
+	a.mirroring = true
+
+	var open []int
+	for _, editor := range a.editors {
+		if editor.mode == "edit" && editor.note != nil && !editor.dirty() {
+			open = append(open, editor.note.ID)
+		}
+	}
+	mirror, service := a.mirror, a.storage
+	return func() tea.Msg {
+		result, merged, err := mirrorNotes(mirror)
+		msg := mirrorDoneMsg{mirror: mirror, result: result, merged: merged, err: err}
+		if err != nil || (result.Imported.Updated == 0 && len(merged) == 0) {
+			return msg
+		}
+		for _, id := range open {
+			if note, err := service.GetNote(id); err == nil {
+				msg.notes = append(msg.notes, note)
+			}
+		}
+		return msg
+	}
+}
+
+// mirrorNotes syncs the notes with the watched directory. Both sides are
+// this machine, so notes changed on both keep both versions rather than
+// waiting for a choice; the names of their files are returned.
+func mirrorNotes(mirror *sync.Mirror) (sync.Result, []string, error) {
+	result, err := mirror.Pull()
+	if err != nil {
+		return result, nil, err
+	}
+
+	var merged []string
+	for _, conflict := range result.Conflicts {
+		if _, err := mirror.Resolve(conflict, sync.KeepBoth); err != nil {
+			return result, merged, err
+		}
+		merged = append(merged, conflict.Path)
+	}
+	result.Conflicts = nil
+
+	pushed, err := mirror.Push()
+	result.Uploaded = pushed.Uploaded
+	return result, merged, err
+}
+
+// mirrorFinished shows the notes changed in the watched directory
+func (a *App) mirrorFinished(msg mirrorDoneMsg) tea.Cmd {
+	if msg.mirror != a.mirror {
+		// The vault was closed while mirroring
+		return nil
+	}
+	a.mirroring = false
+
+	var cmds []tea.Cmd
+	if a.mirrorAgain {
+		a.mirrorAgain = false
+		cmds = append(cmds, a.runMirror())
+	}
+
+	if msg.err != nil {
+		slog.Warn("failed to mirror notes", "dir", a.vault.WatchDir, "err", msg.err)
+		a.notesList.statusMsg = fmt.Sprintf("Failed to mirror %s: %v", a.vault.WatchDir, msg.err)
+		return tea.Batch(cmds...)
+	}
+
+	imported := msg.result.Imported
+	if imported.Created == 0 && imported.Updated == 0 && msg.result.Deleted == 0 && len(msg.merged) == 0 {
+		return tea.Batch(cmds...)
+	}
+	slog.Debug("mirrored notes", "dir", a.vault.WatchDir, "created", imported.Created,
+		"updated", imported.Updated, "deleted", msg.result.Deleted, "merged", len(msg.merged))
+
+	if len(msg.merged) > 0 {
+		a.notesList.statusMsg = fmt.Sprintf("Kept both versions of %s, changed here and in %s",
+			strings.Join(msg.merged, ", "), a.vault.WatchDir)
+	} else {
+		a.notesList.statusMsg = fmt.Sprintf("Read changes from %s: %d new, %d updated, %d moved to Trash",
+			a.vault.WatchDir, imported.Created, imported.Updated, msg.result.Deleted)
+	}
+
+	// Tabs left alone since the mirror started show the new versions
+	for _, note := range msg.notes {
+		for _, editor := range a.editors {
+			if editor.note != nil && editor.note.ID == note.ID && !editor.dirty() {
+				editor.reload(note)
+			}
+		}
+	}
+	cmds = append(cmds, a.notesList.Init(), a.loadTags())
+	return tea.Batch(cmds...)
+}
+
+// reload shows a newer version of the note being edited, keeping the
+// field that has focus
+func (m *NoteEditorModel) reload(note *models.Note) {
+	if note.Title == m.note.Title && note.Content == m.note.Content && slices.Equal(note.Tags, m.note.Tags) {
+		return
+	}
+	m.note = note
+	m.titleInput.SetValue(note.Title)
+	m.contentInput.SetValue(note.Content)
+	m.tags = make([]models.Tag, len(note.Tags))
+	copy(m.tags, note.Tags)
+	m.UpdatePreview()
+}
+
+// Messages
+
+// watchChangedMsg reports files changed in the watched directory
+type watchChangedMsg struct {
+	watcher *sync.Watcher
+}
+
+// mirrorDoneMsg carries the outcome of mirroring the notes and the notes
+// open in tabs, read again when the mirror changed notes
+type mirrorDoneMsg struct {
+	mirror *sync.Mirror
+	result sync.Result
+	merged []string
+	notes  []*models.Note
+	err    error
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// zenColumnWidth is the widest the text runs in zen mode, a comfortable
+// line length for prose
+const zenColumnWidth = 72
+
+// zenSettings holds the content textarea's settings from before zen mode
+// changed them
+type zenSettings struct {
+	prompt      string
+	lineNumbers bool
+	maxHeight   int
+	width       int
+	height      int
+}
+
+// toggleZen switches distraction-free writing on or off. Zen mode shows
+// only the content, in a centered column, and scrolls it so the cursor
+// line stays in the middle of the screen.
+func (m *NoteEditorModel) toggleZen() {
+	ta := &m.contentInput
+	if m.zen {
+		m.zen = false
+		ta.Prompt = m.zenSaved.prompt
+		ta.ShowLineNumbers = m.zenSaved.lineNumbers
+		ta.MaxHeight = m.zenSaved.maxHeight
+		ta.SetWidth(m.zenSaved.width)
+		ta.SetHeight(m.zenSaved.height)
+		return
+	}
+
+	width := ta.Width() + lipgloss.Width(ta.Prompt)
+	if ta.ShowLineNumbers {
+		width += 4 // as reserved by the textarea
+	}
+	m.zenSaved = zenSettings{
+		prompt:      ta.Prompt,
+		lineNumbers: ta.ShowLineNumbers,
+		maxHeight:   ta.MaxHeight,
+		width:       width,
+		height:      ta.Height(),
+	}
+
+	m.zen = true
+	m.focused = 2
+	m.updateFocus()
+	m.showSuggestions = false
+	ta.Prompt = ""
+	ta.ShowLineNumbers = false
+	ta.MaxHeight = 0 // so the height can cover the whole note
+	ta.SetWidth(m.zenWidth())
+
+	// Setting the value scrolls the textarea to the top. It's kept tall
+	// enough never to scroll again, and the zen view scrolls instead.
+	col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset
+	setTextareaValue(ta, ta.Value(), ta.Line(), col)
+	m.growZenTextarea()
+}
+
+// zenWidth returns the width of the zen column for the window
+func (m *NoteEditorModel) zenWidth() int {
+	return max(min(zenColumnWidth, m.width-4), 10)
+}
+
+// growZenTextarea makes the textarea taller than the note with room to
+// spare, so typing or pasting never scrolls it
+func (m *NoteEditorModel) growZenTextarea() {
+	rows, _ := textareaRows(&m.contentInput)
+	m.contentInput.SetHeight(rows + m.height + 1)
+}
+
+// renderZen renders the content alone, centered, with the cursor line in
+// the middle of the screen
+func (m *NoteEditorModel) renderZen() string {
+	ta := &m.contentInput
+	if width := m.zenWidth(); ta.Width() != width {
+		ta.SetWidth(width)
+	}
+	m.growZenTextarea()
+
+	height := m.height + 1 // the tab bar is hidden too
+	_, cursorRow := textareaRows(ta)
+	lines := strings.Split(ta.View(), "\n")
+	top := cursorRow - height/2
+
+	visible := make([]string, height)
+	for i := range visible {
+		if row := top + i; row >= 0 && row < len(lines) {
+			visible[i] = lines[row]
+		}
+	}
+	return lipgloss.PlaceHorizontal(m.width, lipgloss.Center, strings.Join(visible, "\n"))
+}
+
+// textareaRows returns how many screen rows the textarea's content takes
+// once soft wrapped, and the row the cursor is on
+func textareaRows(ta *textarea.Model) (rows, cursorRow int) {
+	for i, line := range strings.Split(ta.Value(), "\n") {
+		if i == ta.Line() {
+			cursorRow = rows + ta.LineInfo().RowOffset
+		}
+		rows += wrappedRows([]rune(line), ta.Width())
+	}
+	return rows, cursorRow
+}
+
+// wrappedRows returns how many rows the textarea soft wraps a line into.
+// It follows the textarea's word wrapping: words move to the next row
+// when they don't fit with their trailing spaces, words wider than the
+// row are broken, and the cursor's place after the last character can
+// take a row of its own.
+func wrappedRows(line []rune, width int) int {
+	rows := 1
+	current := 0 // width of the current row
+	word := 0    // width of the word being read
+	spaces := 0
+	for _, r := range line {
+		if unicode.IsSpace(r) {
+			spaces++
+		} else {
+			word += ansi.StringWidth(string(r))
+		}
+
+		if spaces > 0 {
+			if current+word+spaces > width {
+				rows++
+				current = 0
+			}
+			current += word + spaces
+			word, spaces = 0, 0
+		} else if word > 0 && word+ansi.StringWidth(string(r)) > width {
+			if current > 0 {
+				rows++
+			}
+			current = word
+			word = 0
+		}
+	}
+	if current+word+spaces >= width {
+		rows++
+	}
+	return rows
+}
+
+// updateZenContent passes a key to the content textarea in zen mode,
+// putting the textarea back at the top if the key made it scroll
+func (m *NoteEditorModel) updateZenContent(msg tea.KeyMsg) {
+	ta := &m.contentInput
+	m.growZenTextarea()
+	height := ta.Height()
+	updateTextArea(ta, msg)
+
+	if _, cursorRow := textareaRows(ta); cursorRow >= height {
+		col := ta.LineInfo().StartColumn + ta.LineInfo().ColumnOffset
+		setTextareaValue(ta, ta.Value(), ta.Line(), col)
+		m.growZenTextarea()
+	}
+}
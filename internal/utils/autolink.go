@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AutolinkRule turns text matching Pattern into a link to URL. URL may refer
+// to the match as $0 and to capture groups as $1, $2 or ${name}.
+type AutolinkRule struct {
+	Pattern *regexp.Regexp
+	URL     string
+}
+
+// protectedRegex matches the parts of a line autolinks must not touch:
+// inline code, existing links and images, <autolinks> and bare URLs
+var protectedRegex = regexp.MustCompile("`[^`]*`|!?\\[[^\\]]*\\]\\([^)]*\\)|<[^>\\s]+>|https?://\\S+")
+
+// Autolink turns every match of the rules into a markdown link. Code
+// blocks, inline code, existing links and URLs are left alone, and the
+// number of lines never changes. Earlier rules win where rules overlap.
+func Autolink(content string, rules []AutolinkRule) string {
+	if len(rules) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	inCodeBlock := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		for _, rule := range rules {
+			line = autolinkLine(line, rule)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// autolinkLine applies one rule to the unprotected parts of a line
+func autolinkLine(line string, rule AutolinkRule) string {
+	template := "[$0](" + rule.URL + ")"
+
+	var b strings.Builder
+	last := 0
+	for _, span := range protectedRegex.FindAllStringIndex(line, -1) {
+		b.WriteString(rule.Pattern.ReplaceAllString(line[last:span[0]], template))
+		b.WriteString(line[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(rule.Pattern.ReplaceAllString(line[last:], template))
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAutolink(t *testing.T) {
+	rules := []AutolinkRule{
+		{regexp.MustCompile(`\b([A-Z]+)-(\d+)\b`), "https://jira.example.com/browse/$1-$2"},
+		{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`), "https://journal.example.com/$0"},
+	}
+	content := "Fixed JIRA-1234 on 2024-05-01, see [OPS-7](https://x.io/OPS-7)\n" +
+		"`ABC-1` and https://x.io/DEF-2 stay\n" +
+		"```\nGHI-3\n```\n" +
+		"    JKL-4 in indented code"
+	want := "Fixed [JIRA-1234](https://jira.example.com/browse/JIRA-1234) on [2024-05-01](https://journal.example.com/2024-05-01), see [OPS-7](https://x.io/OPS-7)\n" +
+		"`ABC-1` and https://x.io/DEF-2 stay\n" +
+		"```\nGHI-3\n```\n" +
+		"    JKL-4 in indented code"
+	if got := Autolink(content, rules); got != want {
+		t.Errorf("Autolink =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Autolink(content, nil); got != content {
+		t.Errorf("Expected content unchanged without rules, got %q", got)
+	}
+}
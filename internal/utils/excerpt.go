@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	excerptLinkRegex   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	excerptMarkerRegex = regexp.MustCompile("[*_`~]+")
+)
+
+// Excerpt returns the opening text of a markdown note as a single plain line
+// of at most maxRunes runes. Frontmatter, code blocks and markdown syntax are
+// skipped; headings are dropped entirely.
+func Excerpt(content string, maxRunes int) string {
+	if _, body, ok := ParseFrontmatter(content); ok {
+		content = body
+	}
+
+	var words []string
+	length := 0
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" || strings.HasPrefix(trimmed, "#") || isRuleLine(trimmed) {
+			continue
+		}
+
+		// Strip block markers so only the text remains
+		_, trimmed = BlockquoteDepth(trimmed)
+		if task, ok := ParseTaskLine(trimmed); ok {
+			trimmed = task.Text
+		} else if item, ok := ParseListItem(trimmed); ok {
+			trimmed = item.Text
+		}
+		trimmed = excerptLinkRegex.ReplaceAllString(trimmed, "$1")
+		trimmed = excerptMarkerRegex.ReplaceAllString(trimmed, "")
+
+		for _, word := range strings.Fields(trimmed) {
+			words = append(words, word)
+			length += len([]rune(word)) + 1
+		}
+		if length > maxRunes {
+			break
+		}
+	}
+
+	return ShortenExcerpt(strings.Join(words, " "), maxRunes)
+}
+
+// ShortenExcerpt cuts an excerpt down to at most maxRunes runes, ending it
+// with an ellipsis when anything was cut
+func ShortenExcerpt(excerpt string, maxRunes int) string {
+	runes := []rune(excerpt)
+	if len(runes) <= maxRunes {
+		return excerpt
+	}
+	if maxRunes <= 1 {
+		return string(runes[:max(maxRunes, 0)])
+	}
+	return strings.TrimRight(string(runes[:maxRunes-1]), " ") + "…"
+}
+
+// maxTitleRunes is the longest title TitleFromContent suggests
+const maxTitleRunes = 80
+
+// TitleFromContent suggests a title for a note: the text of its first
+// heading, or else of its first line of text, without markdown syntax.
+// Returns "" when the note has no text to take a title from.
+func TitleFromContent(content string) string {
+	for _, heading := range Headings(content) {
+		if title := Excerpt(heading.Text, maxTitleRunes); title != "" {
+			return title
+		}
+	}
+
+	if _, body, ok := ParseFrontmatter(content); ok {
+		content = body
+	}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if title := Excerpt(line, maxTitleRunes); title != "" {
+			return title
+		}
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExcerpt(t *testing.T) {
+	content := "---\ntitle: x\n---\n# Heading\n\nSome **bold** text with a [link](http://x.io).\n\n```\ncode\n```\n- [ ] open task\n> quoted"
+	if got := Excerpt(content, 100); got != "Some bold text with a link. open task quoted" {
+		t.Errorf("Excerpt = %q", got)
+	}
+	if got := Excerpt(content, 12); got != "Some bold t…" {
+		t.Errorf("Excerpt truncated = %q", got)
+	}
+	if got := Excerpt("# Only a heading", 20); got != "" {
+		t.Errorf("Excerpt of heading-only note = %q, want empty", got)
+	}
+}
+
+func TestTitleFromContent(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"Intro line\n\n## The **plan**\ntext", "The plan"},
+		{"---\ntitle: x\n---\n```\ncode\n```\n- [ ] Call [Sam](http://x.io)\nmore", "Call Sam"},
+		{"#tag\n\nFirst words", "First words"},
+		{strings.Repeat("word ", 30), strings.Repeat("word ", 16)[:79] + "…"},
+		{"\n\n---\n", ""},
+	}
+	for _, test := range tests {
+		if got := TitleFromContent(test.content); got != test.expected {
+			t.Errorf("TitleFromContent(%q) = %q, want %q", test.content, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// footnoteDefRegex matches a footnote definition such as "[^1]: text"
+	footnoteDefRegex = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s*(.*)$`)
+	// footnoteRefRegex matches a footnote reference such as "[^note]"
+	footnoteRefRegex = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+)
+
+// Footnote is a footnote definition found in note content
+type Footnote struct {
+	Number    int    // Display number, in order of first reference
+	Label     string // Label between "[^" and "]"
+	Text      string // Definition text, continuation lines joined with spaces
+	StartLine int    // Zero-based line index of the definition
+	EndLine   int    // Zero-based index of its last indented continuation line
+}
+
+// ParseFootnotes collects the footnote definitions in content and numbers
+// them in the order they are first referenced. Definitions that are never
+// referenced are numbered after the rest, in document order. Code blocks
+// are skipped.
+func ParseFootnotes(content string) []Footnote {
+	lines := strings.Split(content, "\n")
+
+	var footnotes []Footnote
+	var refs []string
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if match := footnoteDefRegex.FindStringSubmatch(lines[i]); match != nil {
+			note := Footnote{Label: match[1], Text: strings.TrimSpace(match[2]), StartLine: i, EndLine: i}
+			// Indented lines directly below continue the definition
+			for i+1 < len(lines) && isIndentedText(lines[i+1]) {
+				i++
+				note.Text = strings.TrimSpace(note.Text + " " + strings.TrimSpace(lines[i]))
+				note.EndLine = i
+			}
+			refs = append(refs, footnoteRefRegex.FindAllString(note.Text, -1)...)
+			footnotes = append(footnotes, note)
+			continue
+		}
+		refs = append(refs, footnoteRefRegex.FindAllString(lines[i], -1)...)
+	}
+
+	// Number referenced footnotes first, then the rest
+	next := 1
+	for _, ref := range refs {
+		label := ref[2 : len(ref)-1]
+		for j := range footnotes {
+			if footnotes[j].Label == label && footnotes[j].Number == 0 {
+				footnotes[j].Number = next
+				next++
+				break
+			}
+		}
+	}
+	for j := range footnotes {
+		if footnotes[j].Number == 0 {
+			footnotes[j].Number = next
+			next++
+		}
+	}
+
+	sorted := make([]Footnote, len(footnotes))
+	for _, note := range footnotes {
+		sorted[note.Number-1] = note
+	}
+	return sorted
+}
+
+// isIndentedText reports whether a line is indented and not blank
+func isIndentedText(line string) bool {
+	trimmed := strings.TrimLeft(line, " \t")
+	return trimmed != "" && len(trimmed) < len(line)
+}
+
+// ReplaceFootnoteRefs replaces every footnote reference in text with the
+// result of replace. References replace rejects are left untouched.
+func ReplaceFootnoteRefs(text string, replace func(label string) (string, bool)) string {
+	return footnoteRefRegex.ReplaceAllStringFunc(text, func(ref string) string {
+		if out, ok := replace(ref[2 : len(ref)-1]); ok {
+			return out
+		}
+		return ref
+	})
+}
+
+// Superscript writes a number with Unicode superscript digits
+func Superscript(n int) string {
+	superscripts := []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+	var b strings.Builder
+	for _, d := range []byte(strconv.Itoa(n)) {
+		b.WriteRune(superscripts[d-'0'])
+	}
+	return b.String()
+}
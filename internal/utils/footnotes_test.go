@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestParseFootnotes(t *testing.T) {
+	content := "See this[^b] and that[^a].\n\n[^a]: First defined\n    and continued\n[^b]: Second defined\n[^unused]: Never referenced\n\n```\n[^code]: not a footnote\n```"
+
+	footnotes := ParseFootnotes(content)
+	if len(footnotes) != 3 {
+		t.Fatalf("Expected 3 footnotes, got %d", len(footnotes))
+	}
+
+	want := []struct {
+		label, text        string
+		number, start, end int
+	}{
+		{"b", "Second defined", 1, 4, 4},
+		{"a", "First defined and continued", 2, 2, 3},
+		{"unused", "Never referenced", 3, 5, 5},
+	}
+	for i, w := range want {
+		got := footnotes[i]
+		if got.Label != w.label || got.Text != w.text || got.Number != w.number ||
+			got.StartLine != w.start || got.EndLine != w.end {
+			t.Errorf("Footnote %d: got %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestReplaceFootnoteRefs(t *testing.T) {
+	numbers := map[string]int{"a": 2}
+	got := ReplaceFootnoteRefs("x[^a] y[^missing]", func(label string) (string, bool) {
+		n, ok := numbers[label]
+		return Superscript(n), ok
+	})
+	if want := "x² y[^missing]"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+
+	if got := Superscript(105); got != "¹⁰⁵" {
+		t.Errorf("Superscript(105) = %q", got)
+	}
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frontmatterDelimiter opens and closes a frontmatter block
+const frontmatterDelimiter = "---"
+
+// Frontmatter holds the fields of a YAML-style frontmatter block in order.
+// Only the subset needed for note metadata is supported: scalar values and
+// lists written inline ([a, b]) or as "- item" lines.
+type Frontmatter struct {
+	fields []frontmatterField
+}
+
+type frontmatterField struct {
+	key    string
+	value  string
+	list   []string
+	isList bool
+}
+
+// Set sets a scalar field, replacing any existing value
+func (f *Frontmatter) Set(key, value string) {
+	f.set(frontmatterField{key: key, value: value})
+}
+
+// SetList sets a list field, replacing any existing value
+func (f *Frontmatter) SetList(key string, values []string) {
+	f.set(frontmatterField{key: key, list: values, isList: true})
+}
+
+func (f *Frontmatter) set(field frontmatterField) {
+	for i := range f.fields {
+		if f.fields[i].key == field.key {
+			f.fields[i] = field
+			return
+		}
+	}
+	f.fields = append(f.fields, field)
+}
+
+// Get returns a scalar field
+func (f Frontmatter) Get(key string) (string, bool) {
+	for _, field := range f.fields {
+		if field.key == key && !field.isList {
+			return field.value, true
+		}
+	}
+	return "", false
+}
+
+// List returns a list field. A scalar field is returned as a one-item list.
+func (f Frontmatter) List(key string) []string {
+	for _, field := range f.fields {
+		if field.key != key {
+			continue
+		}
+		if field.isList {
+			return field.list
+		}
+		if field.value != "" {
+			return []string{field.value}
+		}
+	}
+	return nil
+}
+
+// Keys returns the field names in order
+func (f Frontmatter) Keys() []string {
+	keys := make([]string, len(f.fields))
+	for i, field := range f.fields {
+		keys[i] = field.key
+	}
+	return keys
+}
+
+// String renders the block including its delimiters and a trailing newline
+func (f Frontmatter) String() string {
+	var b strings.Builder
+	b.WriteString(frontmatterDelimiter + "\n")
+	for _, field := range f.fields {
+		b.WriteString(field.key + ": ")
+		if field.isList {
+			quoted := make([]string, len(field.list))
+			for i, v := range field.list {
+				quoted[i] = quoteFrontmatterValue(v, true)
+			}
+			b.WriteString("[" + strings.Join(quoted, ", ") + "]")
+		} else {
+			b.WriteString(quoteFrontmatterValue(field.value, false))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(frontmatterDelimiter + "\n")
+	return b.String()
+}
+
+// ParseFrontmatter splits content into its frontmatter and body. Returns
+// false, with the content unchanged as body, when there is no frontmatter.
+func ParseFrontmatter(content string) (Frontmatter, string, bool) {
+	var fm Frontmatter
+
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, frontmatterDelimiter+"\n") {
+		return fm, content, false
+	}
+
+	lines := strings.Split(normalized, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], " ") == frontmatterDelimiter {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return fm, content, false
+	}
+
+	var current *frontmatterField
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Block list item belonging to the previous key
+		if strings.HasPrefix(trimmed, "- ") && current != nil {
+			current.isList = true
+			current.list = append(current.list, parseFrontmatterValue(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		field := frontmatterField{key: key}
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			field.isList = true
+			field.list = splitFrontmatterList(value[1 : len(value)-1])
+		} else {
+			field.value = parseFrontmatterValue(value)
+		}
+		fm.set(field)
+		current = &fm.fields[len(fm.fields)-1]
+	}
+
+	body := strings.Join(lines[end+1:], "\n")
+	return fm, body, true
+}
+
+// NoteMetadata is the structured metadata a note declares in its frontmatter
+type NoteMetadata struct {
+	Tags    []string   // Tags to apply to the note, without "#"
+	Aliases []string   // Other names the note goes by
+	Date    *time.Time // Date the note is about, nil when absent or invalid
+}
+
+// frontmatterDateLayouts are the date formats accepted for the date key
+var frontmatterDateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// ParseNoteMetadata reads the tags, aliases and date keys from the
+// frontmatter at the top of content. Scalar tags and aliases may hold
+// several comma-separated values.
+func ParseNoteMetadata(content string) NoteMetadata {
+	fm, _, ok := ParseFrontmatter(content)
+	if !ok {
+		return NoteMetadata{}
+	}
+
+	var meta NoteMetadata
+	for _, tag := range metadataList(fm, "tags", "tag") {
+		if tag = strings.TrimPrefix(tag, "#"); tag != "" {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	meta.Aliases = metadataList(fm, "aliases", "alias")
+
+	if value, ok := fm.Get("date"); ok {
+		for _, layout := range frontmatterDateLayouts {
+			if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				meta.Date = &date
+				break
+			}
+		}
+	}
+	return meta
+}
+
+// metadataList returns the values of the first key present, splitting
+// scalar values on commas
+func metadataList(fm Frontmatter, keys ...string) []string {
+	for _, key := range keys {
+		values := fm.List(key)
+		if values == nil {
+			continue
+		}
+		if _, scalar := fm.Get(key); scalar {
+			values = strings.Split(values[0], ",")
+		}
+
+		var list []string
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				list = append(list, value)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// splitFrontmatterList splits an inline list on commas outside quotes
+func splitFrontmatterList(s string) []string {
+	var items []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	flush := func() {
+		item := strings.TrimSpace(current.String())
+		if item != "" {
+			items = append(items, parseFrontmatterValue(item))
+		}
+		current.Reset()
+	}
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuotes:
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ',' && !inQuotes:
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return items
+}
+
+// parseFrontmatterValue unquotes a scalar value
+func parseFrontmatterValue(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// quoteFrontmatterValue quotes values that wouldn't survive parsing as-is
+func quoteFrontmatterValue(value string, inList bool) string {
+	needsQuotes := value == "" ||
+		value != strings.TrimSpace(value) ||
+		strings.ContainsAny(value, "\"'\n#") ||
+		strings.HasPrefix(value, "[") ||
+		strings.HasPrefix(value, "- ") ||
+		(inList && strings.ContainsAny(value, ",]"))
+	if needsQuotes {
+		return strconv.Quote(value)
+	}
+	return value
+}
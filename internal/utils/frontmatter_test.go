@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrontmatterRoundTrip(t *testing.T) {
+	var fm Frontmatter
+	fm.Set("id", "12")
+	fm.Set("title", `Plans: "Q3", #draft`)
+	fm.SetList("tags", []string{"work", "a, b", "it's"})
+
+	content := fm.String() + "# Body\n\n---\nmore"
+	parsed, body, ok := ParseFrontmatter(content)
+	if !ok {
+		t.Fatal("Expected frontmatter to be found")
+	}
+	if body != "# Body\n\n---\nmore" {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if title, _ := parsed.Get("title"); title != `Plans: "Q3", #draft` {
+		t.Errorf("Unexpected title %q", title)
+	}
+	if tags := parsed.List("tags"); !reflect.DeepEqual(tags, []string{"work", "a, b", "it's"}) {
+		t.Errorf("Unexpected tags %q", tags)
+	}
+}
+
+func TestParseFrontmatterBlockList(t *testing.T) {
+	content := "---\ntitle: Trip\ntags:\n  - travel\n  - 'summer'\n---\nBody"
+	fm, body, ok := ParseFrontmatter(content)
+	if !ok || body != "Body" {
+		t.Fatalf("Unexpected parse result ok=%v body=%q", ok, body)
+	}
+	if tags := fm.List("tags"); !reflect.DeepEqual(tags, []string{"travel", "summer"}) {
+		t.Errorf("Unexpected tags %q", tags)
+	}
+
+	if _, body, ok := ParseFrontmatter("No frontmatter"); ok || body != "No frontmatter" {
+		t.Errorf("Expected content without frontmatter to be returned unchanged")
+	}
+}
+
+func TestParseNoteMetadata(t *testing.T) {
+	content := "---\ntags: work, #urgent\nalias: Plan\ndate: 2024-03-05\n---\nBody"
+	meta := ParseNoteMetadata(content)
+	if !reflect.DeepEqual(meta.Tags, []string{"work", "urgent"}) {
+		t.Errorf("Unexpected tags %q", meta.Tags)
+	}
+	if !reflect.DeepEqual(meta.Aliases, []string{"Plan"}) {
+		t.Errorf("Unexpected aliases %q", meta.Aliases)
+	}
+	if meta.Date == nil || meta.Date.Format("2006-01-02") != "2024-03-05" {
+		t.Errorf("Unexpected date %v", meta.Date)
+	}
+
+	meta = ParseNoteMetadata("---\naliases:\n  - One, Two\n  - Three\ndate: someday\n---\n")
+	if !reflect.DeepEqual(meta.Aliases, []string{"One, Two", "Three"}) {
+		t.Errorf("Unexpected aliases %q", meta.Aliases)
+	}
+	if meta.Date != nil {
+		t.Errorf("Expected invalid date to be ignored, got %v", meta.Date)
+	}
+
+	if meta := ParseNoteMetadata("# Just a note"); meta.Tags != nil || meta.Aliases != nil || meta.Date != nil {
+		t.Errorf("Expected no metadata, got %+v", meta)
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headingRegex matches an ATX heading line, capturing its marks
+var headingRegex = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]|$)`)
+
+// DemoteHeadings moves every heading outside code blocks down by levels,
+// so content can be nested under other headings. Headings never go below
+// level 6.
+func DemoteHeadings(content string, levels int) string {
+	lines := strings.Split(content, "\n")
+	inCodeBlock := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		match := headingRegex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		level := min(match[3]-match[2]+levels, 6)
+		lines[i] = line[:match[2]] + strings.Repeat("#", level) + line[match[3]:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Heading is an ATX heading in a note
+type Heading struct {
+	Level int
+	Text  string
+	Line  int // zero-based line of the heading in the content
+}
+
+// Headings returns the headings in content outside code blocks and
+// frontmatter, in order. Closing #s are left out of the text.
+func Headings(content string) []Heading {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start := 0
+	if _, body, ok := ParseFrontmatter(content); ok {
+		start = len(lines) - len(strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n"))
+	}
+
+	var headings []Heading
+	inCodeBlock := false
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		match := headingRegex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(line[match[3]:])
+		if closed := strings.TrimRight(text, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+			text = strings.TrimSpace(closed)
+		}
+		headings = append(headings, Heading{Level: match[3] - match[2], Text: text, Line: i})
+	}
+	return headings
+}
+
+// Section is the part of a note under one heading
+type Section struct {
+	Title string // the heading's text
+	Body  string // the lines after the heading, up to the next section
+}
+
+// SplitSections splits content at its level 1 and 2 headings. The preamble
+// is what comes before the first of them, frontmatter included. Deeper
+// headings stay inside their section.
+func SplitSections(content string) (string, []Section) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	var starts []Heading
+	for _, heading := range Headings(content) {
+		if heading.Level <= 2 {
+			starts = append(starts, heading)
+		}
+	}
+	if len(starts) == 0 {
+		return content, nil
+	}
+
+	preamble := strings.Join(lines[:starts[0].Line], "\n")
+	sections := make([]Section, len(starts))
+	for i, heading := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1].Line
+		}
+		sections[i] = Section{
+			Title: heading.Text,
+			Body:  strings.Trim(strings.Join(lines[heading.Line+1:end], "\n"), "\n"),
+		}
+	}
+	return preamble, sections
+}
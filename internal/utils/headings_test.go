@@ -0,0 +1,55 @@
+package utils
+
+import "testing"
+
+func TestDemoteHeadings(t *testing.T) {
+	input := "# Title\ntext #not\n### Deep\n##### Deeper\n```\n# comment\n```\n#hashtag\n##"
+	expected := "### Title\ntext #not\n##### Deep\n###### Deeper\n```\n# comment\n```\n#hashtag\n####"
+	if got := DemoteHeadings(input, 2); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	input := "---\ntitle: x\n# comment\n---\n# Title\ntext #not\n## Setup ##\n```\n# comment\n```\n#hashtag\n### C#\n##"
+	expected := []Heading{
+		{Level: 1, Text: "Title", Line: 4},
+		{Level: 2, Text: "Setup", Line: 6},
+		{Level: 3, Text: "C#", Line: 11},
+		{Level: 2, Text: "", Line: 12},
+	}
+	got := Headings(input)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d headings, got %v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Heading %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	input := "---\ntags: [a]\n---\nIntro\n\n# One\n\nFirst\n### Deep\n```\n# not a heading\n```\n## Two\nSecond\n\n# Three"
+	preamble, sections := SplitSections(input)
+	if preamble != "---\ntags: [a]\n---\nIntro\n" {
+		t.Errorf("Unexpected preamble %q", preamble)
+	}
+	expected := []Section{
+		{Title: "One", Body: "First\n### Deep\n```\n# not a heading\n```"},
+		{Title: "Two", Body: "Second"},
+		{Title: "Three", Body: ""},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %+v", len(expected), sections)
+	}
+	for i := range expected {
+		if sections[i] != expected[i] {
+			t.Errorf("Section %d: expected %+v, got %+v", i, expected[i], sections[i])
+		}
+	}
+
+	if preamble, sections := SplitSections("No headings\n### Deep"); preamble != "No headings\n### Deep" || sections != nil {
+		t.Errorf("Expected content without H1/H2 to stay whole, got %q and %+v", preamble, sections)
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// minKeywordCount is how often a word must occur to be a keyword
+const minKeywordCount = 2
+
+// stopWords are common English words that never make good keywords
+var stopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "all": true, "also": true, "and": true,
+	"any": true, "are": true, "because": true, "been": true, "before": true, "being": true,
+	"but": true, "can": true, "could": true, "did": true, "does": true, "doing": true,
+	"done": true, "each": true, "even": true, "for": true, "from": true, "get": true,
+	"had": true, "has": true, "have": true, "her": true, "here": true, "him": true,
+	"his": true, "how": true, "into": true, "its": true, "just": true, "like": true,
+	"make": true, "more": true, "most": true, "much": true, "need": true, "not": true,
+	"now": true, "off": true, "one": true, "only": true, "other": true, "our": true,
+	"out": true, "over": true, "same": true, "she": true, "should": true, "some": true,
+	"such": true, "than": true, "that": true, "the": true, "their": true, "them": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true, "those": true,
+	"through": true, "too": true, "use": true, "used": true, "very": true, "was": true,
+	"way": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "who": true, "why": true, "will": true, "with": true, "would": true,
+	"yes": true, "yet": true, "you": true, "your": true,
+}
+
+// Keywords returns up to n words that occur most often in a markdown
+// note, most frequent first. Words are counted by term frequency alone:
+// short words, numbers and common English words are skipped, as are
+// frontmatter, code blocks and link targets, and a word must occur at
+// least twice. Spellings that share a TagKey are counted together.
+func Keywords(content string, n int) []string {
+	counts := map[string]int{}
+	spelling := map[string]string{}
+	var order []string
+	for _, word := range noteWords(content) {
+		word = strings.ToLower(word)
+		if len([]rune(word)) < 3 || stopWords[word] || !strings.ContainsFunc(word, unicode.IsLetter) {
+			continue
+		}
+		key := TagKey(word)
+		if counts[key] == 0 {
+			spelling[key] = word
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	// Ties keep the order the words first appear in
+	slices.SortStableFunc(order, func(a, b string) int {
+		return counts[b] - counts[a]
+	})
+
+	var keywords []string
+	for _, key := range order {
+		if len(keywords) == n || counts[key] < minKeywordCount {
+			break
+		}
+		keywords = append(keywords, spelling[key])
+	}
+	return keywords
+}
+
+// MentionedTags returns the tags whose names appear in a markdown note,
+// in the order given. Names are compared by TagKey, so "go-lang" mentions
+// the tag Golang; a nested tag is mentioned by its last level, and a
+// name of two words by the words next to each other.
+func MentionedTags(content string, tags []string) []string {
+	words := noteWords(content)
+	mentioned := map[string]bool{}
+	for i, word := range words {
+		mentioned[TagKey(word)] = true
+		if i > 0 {
+			mentioned[TagKey(words[i-1]+word)] = true
+		}
+	}
+
+	var found []string
+	for _, tag := range tags {
+		if key := TagKey(TagLeaf(tag)); key != "" && mentioned[key] {
+			found = append(found, tag)
+		}
+	}
+	return found
+}
+
+// noteWords splits the text of a markdown note into words: runs of letters
+// and digits, joined by single dashes or underscores like go-lang.
+// Frontmatter, code blocks and link targets are left out.
+func noteWords(content string) []string {
+	if _, body, ok := ParseFrontmatter(content); ok {
+		content = body
+	}
+
+	var words []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = excerptLinkRegex.ReplaceAllString(line, "$1")
+
+		words = append(words, strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+		})...)
+	}
+
+	// Dashes and underscores only join words; they aren't words themselves
+	var trimmed []string
+	for _, word := range words {
+		if word = strings.Trim(word, "-_"); word != "" {
+			trimmed = append(trimmed, word)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeywords(t *testing.T) {
+	content := "---\ntags: [ignored, ignored]\n---\n" +
+		"Kubernetes upgrade plan. The cluster upgrade needs the new kubernetes release.\n" +
+		"```\ncluster cluster cluster\n```\n" +
+		"See [the cluster docs](http://docs.io/upgrade/upgrade) for 2024 and 2024.\n" +
+		"Clusters and the release notes."
+	got := Keywords(content, 3)
+	want := []string{"cluster", "kubernetes", "upgrade"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Keywords = %q, want %q", got, want)
+	}
+
+	if got := Keywords(content, 10); len(got) != 4 || got[3] != "release" {
+		t.Errorf("Keywords with room for more = %q, want release last", got)
+	}
+	if got := Keywords("each word once", 5); len(got) != 0 {
+		t.Errorf("Keywords of words said once = %q, want none", got)
+	}
+}
+
+func TestMentionedTags(t *testing.T) {
+	content := "Notes on go-lang generics for #project-x, and some Python.\n\n```\nrust\n```"
+	tags := []string{"rust", "Golang", "work/project x", "python", "java"}
+	got := MentionedTags(content, tags)
+	want := []string{"Golang", "work/project x", "python"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MentionedTags = %q, want %q", got, want)
+	}
+}
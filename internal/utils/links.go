@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// inlineLinkRegex matches the start of an inline link or image up to and
+// including its target, e.g. `![diagram](img/flow.png`
+var inlineLinkRegex = regexp.MustCompile(`(!?\[[^\]]*\]\(\s*)(<[^>\n]*>|[^)\s]+)`)
+
+// urlSchemeRegex matches a URL scheme such as "https:" or "mailto:"
+var urlSchemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]+:`)
+
+// IsLocalLink reports whether a link target names a file on disk rather
+// than a URL or an anchor within the note
+func IsLocalLink(target string) bool {
+	return target != "" && !strings.HasPrefix(target, "#") && !urlSchemeRegex.MatchString(target)
+}
+
+// RewriteLocalLinks replaces the target of every inline link and image that
+// points at a local file with what rewrite returns for it. Targets are passed
+// without angle brackets. Links inside fenced code blocks are left alone.
+func RewriteLocalLinks(content string, rewrite func(target string) string) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		lines[i] = inlineLinkRegex.ReplaceAllStringFunc(line, func(match string) string {
+			parts := inlineLinkRegex.FindStringSubmatch(match)
+			target := parts[2]
+			bracketed := strings.HasPrefix(target, "<")
+			if bracketed {
+				target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+			if !IsLocalLink(target) {
+				return match
+			}
+
+			target = rewrite(target)
+			if bracketed || strings.ContainsAny(target, " \t") {
+				target = "<" + target + ">"
+			}
+			return parts[1] + target
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NoteLinkScheme starts links to a note by UUID or ID, e.g.
+// note://4f1c…-… or note://42
+const NoteLinkScheme = "note://"
+
+// NoteRef is the note a link points at. Exactly one field is set.
+type NoteRef struct {
+	ID    int
+	UUID  string
+	Title string // As written in the link
+}
+
+// NoteLinkTarget reports whether a link target points at another note,
+// either by UUID or ID as note://<uuid> or note://42, or by title as a
+// relative link such as "Meeting%20notes.md", "./meeting-notes.md" or
+// <Meeting notes>. UUIDs are the same on every machine, while IDs are only
+// meaningful in the database that assigned them.
+func NoteLinkTarget(target string) (NoteRef, bool) {
+	target = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">"))
+	if ref, found := strings.CutPrefix(target, NoteLinkScheme); found {
+		ref = strings.Trim(ref, "/")
+		if IsUUID(ref) {
+			return NoteRef{UUID: strings.ToLower(ref)}, true
+		}
+		id, err := strconv.Atoi(ref)
+		return NoteRef{ID: id}, err == nil && id > 0
+	}
+	if !IsLocalLink(target) || strings.HasPrefix(target, "/") || strings.HasPrefix(target, "~") {
+		return NoteRef{}, false
+	}
+
+	target, _, _ = strings.Cut(target, "#")
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+	name := path.Base(target)
+	switch ext := path.Ext(name); strings.ToLower(ext) {
+	case ".md", ".markdown":
+		name = strings.TrimSuffix(name, ext)
+	case "":
+	default:
+		// Other files, such as images, aren't notes
+		return NoteRef{}, false
+	}
+	name = strings.TrimSpace(name)
+	return NoteRef{Title: name}, name != "" && name != "." && name != ".."
+}
+
+// NoteLinks returns the targets of the inline links in the content that
+// point at other notes, in order and without duplicates. Links in code
+// blocks are left out.
+func NoteLinks(content string) []string {
+	var targets []string
+	seen := map[string]bool{}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, parts := range inlineLinkRegex.FindAllStringSubmatch(line, -1) {
+			if strings.HasPrefix(parts[1], "!") {
+				continue
+			}
+			target := strings.TrimSuffix(strings.TrimPrefix(parts[2], "<"), ">")
+			if _, ok := NoteLinkTarget(target); ok && !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// webLinkRegex matches inline code, which is skipped, and the forms a web
+// link takes: an inline link or image (image marker in group 1, URL in 2),
+// an <autolink> (URL in 3) and a bare URL (4)
+var webLinkRegex = regexp.MustCompile("`[^`]*`|(!?)\\[[^\\]]*\\]\\(\\s*<?(https?://[^\\s)>]+)>?[^)]*\\)|<(https?://[^>\\s]+)>|(https?://[^\\s<>]+)")
+
+// NumberWebLinks appends a reference number such as " [1]" to every http(s)
+// link in the content and returns the numbered content with the linked
+// URLs, the first numbered 1. A URL linked more than once keeps its
+// number. Images and links in code are left alone, and the number of lines
+// never changes.
+func NumberWebLinks(content string) (string, []string) {
+	var urls []string
+	numbers := map[string]int{}
+
+	lines := strings.Split(content, "\n")
+	inCodeBlock := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, m := range webLinkRegex.FindAllStringSubmatchIndex(line, -1) {
+			end := m[1]
+			var url string
+			switch {
+			case m[4] >= 0 && m[3] == m[2]:
+				url = line[m[4]:m[5]]
+			case m[6] >= 0:
+				url = line[m[6]:m[7]]
+			case m[8] >= 0:
+				url = trimURLPunctuation(line[m[8]:m[9]])
+				end = m[8] + len(url)
+			default:
+				continue // inline code or an image
+			}
+
+			number, ok := numbers[url]
+			if !ok {
+				urls = append(urls, url)
+				number = len(urls)
+				numbers[url] = number
+			}
+			b.WriteString(line[last:end])
+			fmt.Fprintf(&b, " [%d]", number)
+			last = end
+		}
+		b.WriteString(line[last:])
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), urls
+}
+
+// trimURLPunctuation drops punctuation that ends the sentence around a bare
+// URL rather than the URL, keeping a closing parenthesis that has an
+// opening one in the URL
+func trimURLPunctuation(url string) string {
+	for {
+		trimmed := strings.TrimRight(url, ".,;:!?'\"")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+		if trimmed == url {
+			return url
+		}
+		url = trimmed
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteLocalLinks(t *testing.T) {
+	content := "![flow](img/flow.png) and [spec](<docs/my spec.pdf> \"Spec\")\n" +
+		"[site](https://x.io) [top](#intro) [mail](mailto:a@b.c)\n" +
+		"```\n![raw](img/raw.png)\n```"
+	got := RewriteLocalLinks(content, func(target string) string {
+		return "files/" + target
+	})
+	want := "![flow](files/img/flow.png) and [spec](<files/docs/my spec.pdf> \"Spec\")\n" +
+		"[site](https://x.io) [top](#intro) [mail](mailto:a@b.c)\n" +
+		"```\n![raw](img/raw.png)\n```"
+	if got != want {
+		t.Errorf("RewriteLocalLinks =\n%s\nwant\n%s", got, want)
+	}
+
+	for target, local := range map[string]bool{
+		"img/a.png":      true,
+		"/tmp/a.png":     true,
+		"~/a.png":        true,
+		`C:\notes\a.png`: true,
+		"https://x.io/a": false,
+		"#heading":       false,
+		"mailto:a@b.c":   false,
+		"":               false,
+	} {
+		if got := IsLocalLink(target); got != local {
+			t.Errorf("IsLocalLink(%q) = %v, want %v", target, got, local)
+		}
+	}
+}
+
+func TestNumberWebLinks(t *testing.T) {
+	content := "See [docs](https://go.dev/doc) and https://go.dev/blog.\n" +
+		"![logo](https://go.dev/logo.png) `https://code.example` <https://x.io>\n" +
+		"```\nhttps://fenced.example\n```\n" +
+		"Again [the docs](<https://go.dev/doc> \"Docs\") (https://en.wikipedia.org/wiki/Go_(game))"
+	got, urls := NumberWebLinks(content)
+	want := "See [docs](https://go.dev/doc) [1] and https://go.dev/blog [2].\n" +
+		"![logo](https://go.dev/logo.png) `https://code.example` <https://x.io> [3]\n" +
+		"```\nhttps://fenced.example\n```\n" +
+		"Again [the docs](<https://go.dev/doc> \"Docs\") [1] (https://en.wikipedia.org/wiki/Go_(game) [4])"
+	if got != want {
+		t.Errorf("NumberWebLinks =\n%s\nwant\n%s", got, want)
+	}
+
+	wantURLs := []string{"https://go.dev/doc", "https://go.dev/blog", "https://x.io", "https://en.wikipedia.org/wiki/Go_(game)"}
+	if strings.Join(urls, " ") != strings.Join(wantURLs, " ") {
+		t.Errorf("NumberWebLinks URLs = %v, want %v", urls, wantURLs)
+	}
+}
+
+func TestNoteLinks(t *testing.T) {
+	for target, want := range map[string]struct {
+		ref NoteRef
+		ok  bool
+	}{
+		"note://42": {NoteRef{ID: 42}, true},
+		"note://x":  {NoteRef{}, false},
+		"note://4F1C2B3A-5D6E-4F70-8A9B-0C1D2E3F4A5B": {NoteRef{UUID: "4f1c2b3a-5d6e-4f70-8a9b-0c1d2e3f4a5b"}, true},
+		"Meeting%20notes.md":                          {NoteRef{Title: "Meeting notes"}, true},
+		"./notes/plan.md#goals":                       {NoteRef{Title: "plan"}, true},
+		"<Reading list>":                              {NoteRef{Title: "Reading list"}, true},
+		"img/flow.png":                                {NoteRef{}, false},
+		"https://x.io/a.md":                           {NoteRef{}, false},
+		"#heading":                                    {NoteRef{}, false},
+		"/tmp/a.md":                                   {NoteRef{}, false},
+	} {
+		ref, ok := NoteLinkTarget(target)
+		if ref != want.ref || ok != want.ok {
+			t.Errorf("NoteLinkTarget(%q) = %+v, %v, want %+v, %v", target, ref, ok, want.ref, want.ok)
+		}
+	}
+
+	content := "See [plan](note://3), [notes](<Meeting notes.md>) and [site](https://x.io)\n" +
+		"![flow](flow.png) [plan again](note://3)\n```\n[code](note://4)\n```"
+	got := NoteLinks(content)
+	want := []string{"note://3", "Meeting notes.md"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("NoteLinks = %q, want %q", got, want)
+	}
+}
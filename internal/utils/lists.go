@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ListItem describes a markdown list item line such as "  - item" or "3. item"
+type ListItem struct {
+	Indent  string // Leading whitespace before the marker
+	Marker  string // "-", "*", "+" for bullets, or the number with its delimiter ("3.", "2)")
+	Ordered bool   // true for numbered items
+	Number  int    // Item number for ordered items
+	Delim   string // "." or ")" for ordered items
+	Text    string // Item text after the marker
+}
+
+// ParseListItem parses a line as a bullet or ordered list item.
+// Returns false if the line is not a list item.
+func ParseListItem(line string) (ListItem, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+
+	if trimmed == "" {
+		return ListItem{}, false
+	}
+
+	// Bullet items: marker followed by a space (or an empty item)
+	switch trimmed[0] {
+	case '-', '*', '+':
+		if len(trimmed) == 1 {
+			return ListItem{Indent: indent, Marker: trimmed[:1]}, true
+		}
+		if trimmed[1] != ' ' {
+			return ListItem{}, false
+		}
+		return ListItem{
+			Indent: indent,
+			Marker: trimmed[:1],
+			Text:   strings.TrimSpace(trimmed[2:]),
+		}, true
+	}
+
+	// Ordered items: up to 9 digits followed by "." or ")"
+	digits := 0
+	for digits < len(trimmed) && digits < 9 && trimmed[digits] >= '0' && trimmed[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits >= len(trimmed) {
+		return ListItem{}, false
+	}
+	delim := trimmed[digits]
+	if delim != '.' && delim != ')' {
+		return ListItem{}, false
+	}
+	rest := trimmed[digits+1:]
+	if rest != "" && rest[0] != ' ' {
+		return ListItem{}, false
+	}
+
+	number, err := strconv.Atoi(trimmed[:digits])
+	if err != nil {
+		return ListItem{}, false
+	}
+
+	return ListItem{
+		Indent:  indent,
+		Marker:  trimmed[:digits+1],
+		Ordered: true,
+		Number:  number,
+		Delim:   string(delim),
+		Text:    strings.TrimSpace(rest),
+	}, true
+}
+
+// IndentWidth returns the visual width of leading whitespace, counting tabs as 4 columns
+func IndentWidth(indent string) int {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += 4
+		} else if r == ' ' {
+			width++
+		}
+	}
+	return width
+}
+
+// BlockquoteDepth counts the nesting depth of a blockquote line (">", ">>", "> >")
+// and returns the text after the markers
+func BlockquoteDepth(line string) (int, string) {
+	depth := 0
+	rest := strings.TrimLeft(line, " \t")
+	for strings.HasPrefix(rest, ">") {
+		depth++
+		rest = strings.TrimPrefix(rest, ">")
+		rest = strings.TrimLeft(rest, " ")
+	}
+	return depth, rest
+}
+
+// String renders the list item back to markdown
+func (item ListItem) String() string {
+	if item.Text == "" {
+		return item.Indent + item.Marker
+	}
+	return item.Indent + item.Marker + " " + item.Text
+}
+
+// RenumberListAt renumbers the ordered list surrounding the given line so
+// items count up sequentially from the first item's number at each nesting
+// level. Returns the updated content and whether anything changed.
+func RenumberListAt(content string, lineIndex int) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return content, false
+	}
+
+	inBlock := func(line string) bool {
+		if strings.TrimSpace(line) == "" {
+			return false
+		}
+		if _, ok := ParseListItem(line); ok {
+			return true
+		}
+		// Indented continuation lines belong to the list
+		return line[0] == ' ' || line[0] == '\t'
+	}
+
+	if !inBlock(lines[lineIndex]) {
+		return content, false
+	}
+
+	// Find the contiguous list block around the line
+	start := lineIndex
+	for start > 0 && inBlock(lines[start-1]) {
+		start--
+	}
+	end := lineIndex
+	for end < len(lines)-1 && inBlock(lines[end+1]) {
+		end++
+	}
+
+	type level struct {
+		indent int
+		next   int // next number to assign, -1 for bullet levels
+	}
+	var stack []level
+	changed := false
+
+	for i := start; i <= end; i++ {
+		item, ok := ParseListItem(lines[i])
+		if !ok {
+			continue
+		}
+
+		indent := IndentWidth(item.Indent)
+		for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 || stack[len(stack)-1].indent < indent {
+			stack = append(stack, level{indent: indent, next: -1})
+		}
+		top := &stack[len(stack)-1]
+
+		if !item.Ordered {
+			top.next = -1
+			continue
+		}
+
+		if top.next < 0 {
+			top.next = item.Number
+		}
+		if item.Number != top.next {
+			item.Number = top.next
+			item.Marker = strconv.Itoa(item.Number) + item.Delim
+			lines[i] = item.String()
+			changed = true
+		}
+		top.next++
+	}
+
+	if !changed {
+		return content, false
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// ListContinuation returns what the line after a list item starts with:
+// the item's indentation and marker, the next number for ordered items and
+// an unchecked box for tasks. empty reports whether the item has no text,
+// in which case pressing Enter ends the list instead. Returns false if the
+// line is not a list item.
+func ListContinuation(line string) (prefix string, empty bool, ok bool) {
+	item, ok := ParseListItem(line)
+	if !ok {
+		return "", false, false
+	}
+
+	marker := item.Marker
+	if item.Ordered {
+		marker = strconv.Itoa(item.Number+1) + item.Delim
+	}
+	prefix = item.Indent + marker + " "
+
+	text := item.Text
+	if len(text) >= 3 && text[0] == '[' && text[2] == ']' && strings.ContainsRune(" xX", rune(text[1])) {
+		prefix += "[ ] "
+		text = strings.TrimSpace(text[3:])
+	}
+	return prefix, text == "", true
+}
+
+// ListMarkerEnd returns the byte offset in a list item line where its text
+// starts, after the indentation, marker and any task box
+func ListMarkerEnd(line string) int {
+	item, ok := ParseListItem(line)
+	if !ok {
+		return 0
+	}
+	end := min(len(item.Indent)+len(item.Marker)+1, len(line))
+	if rest := line[end:]; len(rest) >= 3 && rest[0] == '[' && rest[2] == ']' {
+		end = min(end+4, len(line))
+	}
+	return end
+}
+
+// IndentListItem moves a list item line one level in, or out when outdent
+// is set, by the width of a marker: two spaces for bullets, or the number
+// and delimiter plus a space for ordered items. Returns the new line and
+// the change in its length, which is 0 when the line isn't a list item or
+// can't be outdented.
+func IndentListItem(line string, outdent bool) (string, int) {
+	item, ok := ParseListItem(line)
+	if !ok {
+		return line, 0
+	}
+	width := len(item.Marker) + 1
+
+	if !outdent {
+		return strings.Repeat(" ", width) + line, width
+	}
+	if strings.HasPrefix(item.Indent, "\t") {
+		return line[1:], -1
+	}
+	spaces := len(item.Indent) - len(strings.TrimLeft(item.Indent, " "))
+	remove := min(width, spaces)
+	return line[remove:], -remove
+}
@@ -0,0 +1,73 @@
+package utils
+
+import "testing"
+
+func TestRenumberListAt(t *testing.T) {
+	content := "Intro\n\n3. first\n1. second\n   1. nested\n   5. nested two\n9. third\n\nAfter"
+	want := "Intro\n\n3. first\n4. second\n   1. nested\n   2. nested two\n5. third\n\nAfter"
+
+	got, changed := RenumberListAt(content, 3)
+	if !changed {
+		t.Fatal("Expected the list to be renumbered")
+	}
+	if got != want {
+		t.Errorf("Unexpected result:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Lines outside a list are left alone
+	if _, changed := RenumberListAt(content, 0); changed {
+		t.Error("Expected no change outside a list")
+	}
+}
+
+func TestListContinuation(t *testing.T) {
+	tests := []struct {
+		line   string
+		prefix string
+		empty  bool
+		ok     bool
+	}{
+		{"- item", "- ", false, true},
+		{"  * nested", "  * ", false, true},
+		{"9. ninth", "10. ", false, true},
+		{"2) second", "3) ", false, true},
+		{"- [x] done", "- [ ] ", false, true},
+		{"1. [ ] task", "2. [ ] ", false, true},
+		{"- ", "- ", true, true},
+		{"- [ ]", "- [ ] ", true, true},
+		{"3.", "4. ", true, true},
+		{"plain text", "", false, false},
+		{"-not a list", "", false, false},
+	}
+	for _, test := range tests {
+		prefix, empty, ok := ListContinuation(test.line)
+		if prefix != test.prefix || empty != test.empty || ok != test.ok {
+			t.Errorf("ListContinuation(%q): expected (%q, %v, %v), got (%q, %v, %v)",
+				test.line, test.prefix, test.empty, test.ok, prefix, empty, ok)
+		}
+	}
+}
+
+func TestIndentListItem(t *testing.T) {
+	tests := []struct {
+		line     string
+		outdent  bool
+		expected string
+		delta    int
+	}{
+		{"- item", false, "  - item", 2},
+		{"10. item", false, "    10. item", 4},
+		{"    - item", true, "  - item", -2},
+		{" - item", true, "- item", -1},
+		{"\t- item", true, "- item", -1},
+		{"- item", true, "- item", 0},
+		{"text", false, "text", 0},
+	}
+	for _, test := range tests {
+		line, delta := IndentListItem(test.line, test.outdent)
+		if line != test.expected || delta != test.delta {
+			t.Errorf("IndentListItem(%q, %v): expected (%q, %d), got (%q, %d)",
+				test.line, test.outdent, test.expected, test.delta, line, delta)
+		}
+	}
+}
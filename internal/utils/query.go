@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"strings"
+	"time"
+
+	"markdown-note-taking-app/internal/models"
+)
+
+// queryDateLayout is the date format accepted by date operators
+const queryDateLayout = "2006-01-02"
+
+// ParseQuery parses a search query into a NoteFilter. Supported syntax:
+//
+//	word            title or content contains word
+//	"two words"     title or content contains the phrase
+//	-word           title and content don't contain word
+//	tag:work        note is tagged work (-tag:work excludes it)
+//	title:meeting   title contains meeting
+//	notebook:work   note is in the work notebook
+//	color:green     note has the green color label (color:none for no label)
+//	is:untagged     note has no tags
+//	is:duplicate    another note has the same title
+//	is:pinned       note is pinned
+//	created:>2024-01-01, created:<=2024-02-01, created:2024-01-15
+//	updated:>2024-01-01 (same comparisons as created:)
+//	after:2024-01-01, before:2024-02-01 (shorthand for created:)
+//
+// Tokens that look like operators but have invalid values are treated as
+// plain search terms.
+func ParseQuery(input string) models.NoteFilter {
+	filter := models.NoteFilter{}
+
+	for _, token := range tokenizeQuery(input) {
+		negated := false
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			negated = true
+			token = token[1:]
+		}
+
+		key, value, hasOperator := strings.Cut(token, ":")
+		if hasOperator && value != "" {
+			if applyOperator(&filter, strings.ToLower(key), unquote(value), negated) {
+				continue
+			}
+		}
+
+		term := unquote(token)
+		if term == "" {
+			continue
+		}
+		if negated {
+			filter.ExcludeTerms = append(filter.ExcludeTerms, term)
+		} else {
+			filter.Terms = append(filter.Terms, term)
+		}
+	}
+
+	return filter
+}
+
+// applyOperator applies a key:value operator to the filter.
+// Returns false if the operator is unknown or its value is invalid.
+func applyOperator(filter *models.NoteFilter, key, value string, negated bool) bool {
+	switch key {
+	case "tag", "tags":
+		if negated {
+			filter.ExcludeTagNames = append(filter.ExcludeTagNames, value)
+		} else {
+			filter.TagNames = append(filter.TagNames, value)
+		}
+		return true
+	case "title":
+		if negated {
+			return false
+		}
+		filter.TitleTerms = append(filter.TitleTerms, value)
+		return true
+	case "notebook", "nb":
+		if negated {
+			return false
+		}
+		filter.Notebook = value
+		return true
+	case "color", "colour":
+		if negated {
+			return false
+		}
+		filter.Color = strings.ToLower(value)
+		return true
+	case "is":
+		if negated {
+			return false
+		}
+		switch strings.ToLower(value) {
+		case "untagged":
+			filter.Untagged = true
+		case "duplicate", "duplicates":
+			filter.Duplicates = true
+		case "pinned":
+			filter.Pinned = true
+		default:
+			return false
+		}
+		return true
+	case "created":
+		return applyDateComparison(value, &filter.CreatedAfter, &filter.CreatedBefore)
+	case "updated":
+		return applyDateComparison(value, &filter.UpdatedAfter, &filter.UpdatedBefore)
+	case "after":
+		return applyDateComparison(">="+value, &filter.CreatedAfter, &filter.CreatedBefore)
+	case "before":
+		return applyDateComparison("<"+value, &filter.CreatedAfter, &filter.CreatedBefore)
+	}
+	return false
+}
+
+// applyDateComparison parses a comparison such as ">2024-01-01" into day-based bounds
+func applyDateComparison(value string, after, before **time.Time) bool {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			value = value[len(prefix):]
+			break
+		}
+	}
+
+	day, err := time.ParseInLocation(queryDateLayout, value, time.Local)
+	if err != nil {
+		return false
+	}
+	nextDay := day.AddDate(0, 0, 1)
+
+	switch op {
+	case ">":
+		*after = &nextDay
+	case ">=":
+		*after = &day
+	case "<":
+		*before = &day
+	case "<=":
+		*before = &nextDay
+	default:
+		// A bare date matches that whole day
+		*after = &day
+		*before = &nextDay
+	}
+	return true
+}
+
+// tokenizeQuery splits a query on whitespace while keeping quoted phrases together
+func tokenizeQuery(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// unquote strips surrounding double quotes from a phrase
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	filter := ParseQuery(`tag:work -tag:archive title:meeting "road map" -draft created:>2024-01-01 color:Green budget`)
+
+	if len(filter.TagNames) != 1 || filter.TagNames[0] != "work" {
+		t.Errorf("Expected tag 'work', got %v", filter.TagNames)
+	}
+	if len(filter.ExcludeTagNames) != 1 || filter.ExcludeTagNames[0] != "archive" {
+		t.Errorf("Expected excluded tag 'archive', got %v", filter.ExcludeTagNames)
+	}
+	if len(filter.TitleTerms) != 1 || filter.TitleTerms[0] != "meeting" {
+		t.Errorf("Expected title term 'meeting', got %v", filter.TitleTerms)
+	}
+	if len(filter.Terms) != 2 || filter.Terms[0] != "road map" || filter.Terms[1] != "budget" {
+		t.Errorf("Expected terms [road map budget], got %v", filter.Terms)
+	}
+	if len(filter.ExcludeTerms) != 1 || filter.ExcludeTerms[0] != "draft" {
+		t.Errorf("Expected excluded term 'draft', got %v", filter.ExcludeTerms)
+	}
+	if filter.Color != "green" {
+		t.Errorf("Expected color 'green', got %q", filter.Color)
+	}
+
+	// created:> is exclusive of the given day
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	if filter.CreatedAfter == nil || !filter.CreatedAfter.Equal(want) {
+		t.Errorf("Expected CreatedAfter %v, got %v", want, filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		t.Errorf("Expected no CreatedBefore, got %v", filter.CreatedBefore)
+	}
+}
+
+func TestParseQueryIs(t *testing.T) {
+	filter := ParseQuery("is:pinned is:untagged")
+	if !filter.Pinned || !filter.Untagged || filter.Duplicates {
+		t.Errorf("Expected pinned and untagged, got %+v", filter)
+	}
+	if filter := ParseQuery("is:nothing"); len(filter.Terms) != 1 {
+		t.Errorf("Expected an unknown is: value to be a plain term, got %v", filter.Terms)
+	}
+}
+
+func TestParseQueryInvalidOperator(t *testing.T) {
+	// Unknown operators and bad dates fall back to plain terms
+	filter := ParseQuery("http://example.com created:yesterday")
+
+	if len(filter.Terms) != 2 {
+		t.Fatalf("Expected 2 plain terms, got %v", filter.Terms)
+	}
+	if filter.CreatedAfter != nil || filter.CreatedBefore != nil {
+		t.Errorf("Expected no date bounds for an invalid date")
+	}
+}
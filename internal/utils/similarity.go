@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ShingleWords is how many consecutive words make up a shingle
+const ShingleWords = 3
+
+// Shingles returns the shingles of a markdown note, every run of
+// ShingleWords consecutive words, lowercased and hashed, as a sorted set.
+// A note of fewer words is a single shingle. Frontmatter, code blocks and
+// link targets are left out, like in Keywords.
+func Shingles(content string) []uint64 {
+	words := noteWords(content)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < ShingleWords {
+		return []uint64{hashString(strings.Join(words, " "))}
+	}
+
+	shingles := make([]uint64, 0, len(words)-ShingleWords+1)
+	for i := 0; i+ShingleWords <= len(words); i++ {
+		shingles = append(shingles, hashString(strings.Join(words[i:i+ShingleWords], " ")))
+	}
+	slices.Sort(shingles)
+	return slices.Compact(shingles)
+}
+
+// copySuffixes are added to the titles of copied notes and dropped before
+// comparing titles
+var copySuffixes = []string{"(copy)", "copy"}
+
+// TitleGrams returns the trigrams of a title, hashed, as a sorted set.
+// Titles are compared by their letters and digits alone, ignoring case
+// and a "(copy)" suffix, so "Meeting notes (copy)" and "meeting-notes"
+// have the same trigrams.
+func TitleGrams(title string) []uint64 {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for _, suffix := range copySuffixes {
+		title = strings.TrimSpace(strings.TrimSuffix(title, suffix))
+	}
+	var runes []rune
+	for _, r := range title {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return nil
+	}
+	if len(runes) < 3 {
+		return []uint64{hashString(string(runes))}
+	}
+
+	grams := make([]uint64, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, hashString(string(runes[i:i+3])))
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
+
+// Jaccard returns the share of the items in either of two sorted sets that
+// are in both, from 0 for nothing in common to 1 for the same items. Two
+// empty sets have nothing in common.
+func Jaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// hashString hashes a string with 64-bit FNV-1a
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
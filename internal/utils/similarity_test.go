@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShingles(t *testing.T) {
+	a := Shingles("---\ntags: [x]\n---\nThe quick brown fox jumps.\n\n```\ncode here\n```")
+	b := Shingles("the QUICK brown fox jumps")
+	if len(a) != 3 || !slices.Equal(a, b) {
+		t.Errorf("Shingles = %v and %v, want the same 3 shingles", a, b)
+	}
+	if got := Shingles("two words"); len(got) != 1 {
+		t.Errorf("Shingles of a short note = %v, want 1 shingle", got)
+	}
+	if got := Shingles("```\nonly code\n```"); got != nil {
+		t.Errorf("Shingles without words = %v, want none", got)
+	}
+}
+
+func TestTitleGrams(t *testing.T) {
+	if a, b := TitleGrams("Meeting notes (copy)"), TitleGrams("meeting-notes"); !slices.Equal(a, b) {
+		t.Errorf("TitleGrams differ for a copy: %v and %v", a, b)
+	}
+	if got := TitleGrams(" !? "); got != nil {
+		t.Errorf("TitleGrams without letters = %v, want none", got)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b []uint64
+		want float64
+	}{
+		{[]uint64{1, 2, 3}, []uint64{1, 2, 3}, 1},
+		{[]uint64{1, 2, 3}, []uint64{2, 3, 4}, 0.5},
+		{[]uint64{1, 2}, []uint64{3, 4}, 0},
+		{nil, nil, 0},
+		{[]uint64{1}, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := Jaccard(tt.a, tt.b); got != tt.want {
+			t.Errorf("Jaccard(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	similar := Jaccard(Shingles("Buy milk, eggs and bread on the way home today"),
+		Shingles("Buy milk, eggs and bread on the way home tomorrow"))
+	if similar < 0.7 || similar == 1 {
+		t.Errorf("Jaccard of notes differing in a word = %v, want above 0.7", similar)
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify converts a title into a lowercase, hyphen-separated file name
+// stem. Returns "untitled" when nothing usable remains.
+func Slugify(title string) string {
+	var b strings.Builder
+	lastHyphen := true // avoid a leading hyphen
+
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteRune('-')
+			lastHyphen = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "untitled"
+	}
+	return slug
+}
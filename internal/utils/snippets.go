@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CursorPlaceholder marks where the cursor goes in an expanded snippet
+const CursorPlaceholder = "{{cursor}}"
+
+// BuiltinSnippets expand when no stored snippet has the same trigger
+var BuiltinSnippets = map[string]string{
+	"/date": "{{date}}",
+	"/time": "{{time}}",
+	"/now":  "{{date}} {{time}}",
+}
+
+// ExpandSnippet fills in the placeholders of a snippet body: {{date}},
+// {{time}} and {{weekday}} for now, and {{cursor}} for where the cursor
+// goes. Lines after the first are indented with indent, so snippets line
+// up inside lists. Returns the text and the cursor's offset into it in
+// runes, which is the end of the text when there is no {{cursor}}.
+func ExpandSnippet(body, indent string, now time.Time) (string, int) {
+	text := strings.NewReplacer(
+		"{{date}}", now.Format("2006-01-02"),
+		"{{time}}", now.Format("15:04"),
+		"{{weekday}}", now.Format("Monday"),
+	).Replace(body)
+	text = strings.ReplaceAll(text, "\n", "\n"+indent)
+
+	before, after, ok := strings.Cut(text, CursorPlaceholder)
+	if !ok {
+		return text, len([]rune(text))
+	}
+	after = strings.ReplaceAll(after, CursorPlaceholder, "")
+	return before + after, len([]rune(before))
+}
+
+// TriggerBefore returns the word ending at rune column col of line, which
+// is what a snippet trigger is matched against
+func TriggerBefore(line string, col int) string {
+	runes := []rune(line)
+	col = min(col, len(runes))
+	start := col
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+	return string(runes[start:col])
+}
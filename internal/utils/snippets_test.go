@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandSnippet(t *testing.T) {
+	now := time.Date(2024, 3, 8, 9, 5, 0, 0, time.UTC)
+	tests := []struct {
+		body, indent string
+		expected     string
+		cursor       int
+	}{
+		{"Cheers,\nSam", "", "Cheers,\nSam", 11},
+		{"{{date}} {{time}}", "", "2024-03-08 09:05", 16},
+		{"## {{weekday}}\n- {{cursor}}\n- {{cursor}}", "  ", "## Friday\n  - \n  - ", 14},
+	}
+	for _, test := range tests {
+		text, cursor := ExpandSnippet(test.body, test.indent, now)
+		if text != test.expected || cursor != test.cursor {
+			t.Errorf("ExpandSnippet(%q): expected %q at %d, got %q at %d", test.body, test.expected, test.cursor, text, cursor)
+		}
+	}
+}
+
+func TestTriggerBefore(t *testing.T) {
+	tests := []struct {
+		line     string
+		col      int
+		expected string
+	}{
+		{"see ;sig", 8, ";sig"},
+		{"/date", 5, "/date"},
+		{"- /date later", 7, "/date"},
+		{"naïve", 5, "naïve"},
+		{"end ", 4, ""},
+		{"", 0, ""},
+	}
+	for _, test := range tests {
+		if got := TriggerBefore(test.line, test.col); got != test.expected {
+			t.Errorf("TriggerBefore(%q, %d): expected %q, got %q", test.line, test.col, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import "strings"
+
+// TagSeparator separates the levels of a nested tag such as work/project-x
+const TagSeparator = "/"
+
+// NormalizeTag trims the spaces around a tag and each of its levels,
+// collapses runs of spaces and drops empty levels, so " work / project  x/"
+// becomes "work/project x"
+func NormalizeTag(name string) string {
+	var levels []string
+	for _, level := range strings.Split(name, TagSeparator) {
+		if level = strings.Join(strings.Fields(level), " "); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return strings.Join(levels, TagSeparator)
+}
+
+// TagKey returns the form tags are compared in, so spellings of the same
+// tag share a key: case is folded, spaces, dashes, underscores and dots
+// are dropped and plurals are made singular. "Golang", "go-lang" and
+// "golangs" all have the key "golang".
+func TagKey(name string) string {
+	levels := strings.Split(NormalizeTag(name), TagSeparator)
+	for i, level := range levels {
+		level = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(" -_.", r) {
+				return -1
+			}
+			return r
+		}, strings.ToLower(level))
+		levels[i] = singular(level)
+	}
+	return strings.Join(levels, TagSeparator)
+}
+
+// singular strips a plural ending from a lowercase word. Short words and
+// endings that are rarely plurals, like the ss of class, are kept.
+func singular(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// TagAncestors returns the parents of a nested tag, outermost first:
+// work/project-x/notes has work and work/project-x
+func TagAncestors(name string) []string {
+	var ancestors []string
+	for i := 0; i < len(name); i++ {
+		if strings.HasPrefix(name[i:], TagSeparator) && i > 0 {
+			ancestors = append(ancestors, name[:i])
+		}
+	}
+	return ancestors
+}
+
+// TagDepth returns how deeply a tag is nested, 0 for a top-level tag
+func TagDepth(name string) int {
+	return strings.Count(name, TagSeparator)
+}
+
+// TagLeaf returns the last level of a nested tag
+func TagLeaf(name string) string {
+	return name[strings.LastIndex(name, TagSeparator)+1:]
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	cases := map[string]string{
+		"work":                "work",
+		" work / project-x/ ": "work/project-x",
+		"//work//x":           "work/x",
+		"  ":                  "",
+		"machine   learning":  "machine learning",
+	}
+	for input, want := range cases {
+		if got := NormalizeTag(input); got != want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestTagAncestors(t *testing.T) {
+	if got := TagAncestors("work"); len(got) != 0 {
+		t.Errorf("Expected no ancestors for a top-level tag, got %v", got)
+	}
+	want := []string{"work", "work/project-x"}
+	if got := TagAncestors("work/project-x/notes"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if TagDepth("work/project-x/notes") != 2 || TagLeaf("work/project-x/notes") != "notes" {
+		t.Errorf("Expected depth 2 and leaf notes")
+	}
+}
+
+func TestTagKey(t *testing.T) {
+	for _, name := range []string{"Golang", "golang", "go-lang", " Go Lang ", "golangs"} {
+		if key := TagKey(name); key != "golang" {
+			t.Errorf("TagKey(%q) = %q, want golang", name, key)
+		}
+	}
+	cases := map[string]string{
+		"Stories":       "story",
+		"class":         "class",
+		"status":        "status",
+		"bus":           "bus",
+		"Work/Projects": "work/project",
+	}
+	for name, want := range cases {
+		if got := TagKey(name); got != want {
+			t.Errorf("TagKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dueDatePattern matches @due(YYYY-MM-DD) annotations in task text
+var dueDatePattern = regexp.MustCompile(`@due\((\d{4}-\d{2}-\d{2})\)`)
+
+// Task is a task item found while scanning note content
+type Task struct {
+	Line int        // Zero-based line index within the content
+	Text string     // Task text with the due annotation removed
+	Done bool       // true when the checkbox is checked
+	Due  *time.Time // Parsed @due(...) date, nil when absent
+}
+
+// TaskLine describes a markdown task list item such as "- [ ] buy milk"
+type TaskLine struct {
+	Indent string // Leading whitespace before the list marker
+	Marker string // List marker ("-", "*" or "+")
+	Done   bool   // true when the checkbox is checked
+	Text   string // Task text after the checkbox
+}
+
+// ParseTaskLine parses a single line as a markdown task item.
+// Returns false if the line is not a task item.
+func ParseTaskLine(line string) (TaskLine, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+
+	if len(trimmed) < 5 {
+		return TaskLine{}, false
+	}
+
+	marker := trimmed[:1]
+	if marker != "-" && marker != "*" && marker != "+" {
+		return TaskLine{}, false
+	}
+	if trimmed[1] != ' ' || trimmed[2] != '[' || trimmed[4] != ']' {
+		return TaskLine{}, false
+	}
+
+	var done bool
+	switch trimmed[3] {
+	case ' ':
+		done = false
+	case 'x', 'X':
+		done = true
+	default:
+		return TaskLine{}, false
+	}
+
+	// The checkbox must be followed by a space or end the line
+	rest := trimmed[5:]
+	if rest != "" && rest[0] != ' ' {
+		return TaskLine{}, false
+	}
+
+	return TaskLine{
+		Indent: indent,
+		Marker: marker,
+		Done:   done,
+		Text:   strings.TrimSpace(rest),
+	}, true
+}
+
+// String renders the task back to its markdown source form
+func (t TaskLine) String() string {
+	box := "[ ]"
+	if t.Done {
+		box = "[x]"
+	}
+	if t.Text == "" {
+		return t.Indent + t.Marker + " " + box
+	}
+	return t.Indent + t.Marker + " " + box + " " + t.Text
+}
+
+// ToggleTaskLine flips the checkbox state of a task line.
+// Returns the updated line and true, or the original line and false if it is not a task.
+func ToggleTaskLine(line string) (string, bool) {
+	task, ok := ParseTaskLine(line)
+	if !ok {
+		return line, false
+	}
+	task.Done = !task.Done
+	return task.String(), true
+}
+
+// ToggleTaskAt toggles the task on the given line index of the content.
+// Returns the updated content and whether a task was toggled.
+func ToggleTaskAt(content string, lineIndex int) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return content, false
+	}
+
+	toggled, ok := ToggleTaskLine(lines[lineIndex])
+	if !ok {
+		return content, false
+	}
+	lines[lineIndex] = toggled
+	return strings.Join(lines, "\n"), true
+}
+
+// ParseTasks scans markdown content and returns all task items in order
+func ParseTasks(content string) []Task {
+	var tasks []Task
+	inCodeBlock := false
+
+	for i, line := range strings.Split(content, "\n") {
+		// Skip fenced code blocks so examples aren't picked up as tasks
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		item, ok := ParseTaskLine(line)
+		if !ok {
+			continue
+		}
+
+		text, due := extractDueDate(item.Text)
+		tasks = append(tasks, Task{
+			Line: i,
+			Text: text,
+			Done: item.Done,
+			Due:  due,
+		})
+	}
+
+	return tasks
+}
+
+// extractDueDate removes a @due(...) annotation from the text and parses its date
+func extractDueDate(text string) (string, *time.Time) {
+	match := dueDatePattern.FindStringSubmatch(text)
+	if match == nil {
+		return text, nil
+	}
+
+	due, err := time.ParseInLocation("2006-01-02", match[1], time.Local)
+	if err != nil {
+		return text, nil
+	}
+
+	cleaned := strings.Join(strings.Fields(dueDatePattern.ReplaceAllString(text, "")), " ")
+	return cleaned, &due
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTaskLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want TaskLine
+		ok   bool
+	}{
+		{"- [ ] buy milk", TaskLine{Marker: "-", Text: "buy milk"}, true},
+		{"  * [x] nested", TaskLine{Indent: "  ", Marker: "*", Done: true, Text: "nested"}, true},
+		{"\t+ [X] upper case", TaskLine{Indent: "\t", Marker: "+", Done: true, Text: "upper case"}, true},
+		{"- [ ]", TaskLine{Marker: "-"}, true},
+		{"- [ ]   padded  ", TaskLine{Marker: "-", Text: "padded"}, true},
+		{"- [x]glued", TaskLine{}, false},
+		{"- [-] partial", TaskLine{}, false},
+		{"- [] empty box", TaskLine{}, false},
+		{"- [ unclosed", TaskLine{}, false},
+		{"-[ ] no space", TaskLine{}, false},
+		{"1. [ ] ordered", TaskLine{}, false},
+		{"- plain item", TaskLine{}, false},
+		{"", TaskLine{}, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseTaskLine(test.line)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ParseTaskLine(%q): expected (%+v, %v), got (%+v, %v)", test.line, test.want, test.ok, got, ok)
+		}
+	}
+}
+
+func TestToggleTaskAt(t *testing.T) {
+	content := "# List\n- [ ] open\n  * [X] done\nplain"
+	tests := []struct {
+		line int
+		want string
+		ok   bool
+	}{
+		{1, "# List\n- [x] open\n  * [X] done\nplain", true},
+		{2, "# List\n- [ ] open\n  * [ ] done\nplain", true},
+		{0, content, false},
+		{3, content, false},
+		{-1, content, false},
+		{4, content, false},
+	}
+	for _, test := range tests {
+		got, ok := ToggleTaskAt(content, test.line)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ToggleTaskAt(line %d): expected (%q, %v), got (%q, %v)", test.line, test.want, test.ok, got, ok)
+		}
+	}
+
+	// Toggling twice restores a lower-case checkbox
+	once, _ := ToggleTaskAt(content, 1)
+	if twice, _ := ToggleTaskAt(once, 1); twice != content {
+		t.Errorf("Expected toggling twice to restore the content, got %q", twice)
+	}
+}
+
+func TestParseTasksDueDates(t *testing.T) {
+	content := "- [ ] pay rent @due(2024-05-01)\n" +
+		"- [x] call @due(2024-02-29) the bank\n" +
+		"- [ ] bad month @due(2024-13-01)\n" +
+		"- [ ] short @due(2024-5-1)\n" +
+		"```\n- [ ] in code @due(2024-05-01)\n```\n" +
+		"  + [ ] no date"
+
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+		return &d
+	}
+	want := []Task{
+		{Line: 0, Text: "pay rent", Due: date(2024, time.May, 1)},
+		{Line: 1, Text: "call the bank", Done: true, Due: date(2024, time.February, 29)},
+		{Line: 2, Text: "bad month @due(2024-13-01)"},
+		{Line: 3, Text: "short @due(2024-5-1)"},
+		{Line: 7, Text: "no date"},
+	}
+
+	got := ParseTasks(content)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d tasks, got %+v", len(want), got)
+	}
+	for i, task := range got {
+		w := want[i]
+		if task.Line != w.Line || task.Text != w.Text || task.Done != w.Done {
+			t.Errorf("Task %d: expected %+v, got %+v", i, w, task)
+		}
+		switch {
+		case w.Due == nil && task.Due != nil:
+			t.Errorf("Task %d: expected no due date, got %v", i, task.Due)
+		case w.Due != nil && (task.Due == nil || !task.Due.Equal(*w.Due)):
+			t.Errorf("Task %d: expected due %v, got %v", i, w.Due, task.Due)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RelativeTime describes t relative to now, e.g. "2h ago" or "3d ago".
+// Times older than four weeks are shown as a date instead.
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 28*24*time.Hour:
+		return fmt.Sprintf("%dw ago", int(d.Hours()/(24*7)))
+	case t.Year() == now.Year():
+		return t.Format("Jan 2")
+	default:
+		return t.Format("Jan 2, 2006")
+	}
+}
+
+// WordCount counts the whitespace-separated words in text, ignoring
+// markdown markers such as "#", "-" and "*" that stand alone
+func WordCount(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.Trim(field, "#-*+>|`") != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{2 * time.Hour, "2h ago"},
+		{3 * 24 * time.Hour, "3d ago"},
+		{15 * 24 * time.Hour, "2w ago"},
+		{60 * 24 * time.Hour, "Apr 16"},
+		{400 * 24 * time.Hour, "May 12, 2023"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("RelativeTime(-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	if got := WordCount("# Title\n\n- one two\n* three"); got != 4 {
+		t.Errorf("WordCount = %d, want 4", got)
+	}
+}
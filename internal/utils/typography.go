@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Smarten applies SmartyPants-style typography to markdown: "---" becomes an
+// em-dash, "..." an ellipsis, and straight quotes become curly quotes. Code
+// blocks, inline code, link destinations and horizontal rules are left as-is,
+// and the number of lines never changes.
+func Smarten(content string) string {
+	lines := strings.Split(content, "\n")
+	inCodeBlock := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || isRuleLine(trimmed) || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		lines[i] = smartenLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isRuleLine reports whether a line is a horizontal rule, setext underline
+// or table separator made only of -, *, _, =, |, : and spaces
+func isRuleLine(trimmed string) bool {
+	if trimmed == "" {
+		return false
+	}
+	return strings.Trim(trimmed, "-*_=|: ") == ""
+}
+
+// smartenLine applies typographic replacements to a single line
+func smartenLine(line string) string {
+	runes := []rune(line)
+	var out strings.Builder
+	out.Grow(len(line))
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '`':
+			// Copy inline code spans verbatim
+			end := indexRune(runes, '`', i+1)
+			if end < 0 {
+				out.WriteString(string(runes[i:]))
+				return out.String()
+			}
+			out.WriteString(string(runes[i : end+1]))
+			i = end
+
+		case r == ']' && i+1 < len(runes) && runes[i+1] == '(':
+			// Copy link destinations verbatim
+			end := indexRune(runes, ')', i+2)
+			if end < 0 {
+				out.WriteString(string(runes[i:]))
+				return out.String()
+			}
+			out.WriteString(string(runes[i : end+1]))
+			i = end
+
+		case r == '-' && hasRunes(runes, i, "---"):
+			out.WriteRune('—')
+			i += 2
+
+		case r == '.' && hasRunes(runes, i, "..."):
+			out.WriteRune('…')
+			i += 2
+
+		case r == '"':
+			if opensQuote(runes, i) {
+				out.WriteRune('“')
+			} else {
+				out.WriteRune('”')
+			}
+
+		case r == '\'':
+			if opensQuote(runes, i) {
+				out.WriteRune('‘')
+			} else {
+				// Closing quote or apostrophe
+				out.WriteRune('’')
+			}
+
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	return out.String()
+}
+
+// opensQuote reports whether the quote at index i opens a quotation: it
+// follows whitespace, an opening bracket or dash, and precedes a non-space
+func opensQuote(runes []rune, i int) bool {
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	return unicode.IsSpace(prev) || strings.ContainsRune("([{<—-*_", prev)
+}
+
+// hasRunes reports whether runes contains s starting at index i
+func hasRunes(runes []rune, i int, s string) bool {
+	for j, r := range []rune(s) {
+		if i+j >= len(runes) || runes[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// indexRune returns the index of the first target rune at or after start, or -1
+func indexRune(runes []rune, target rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
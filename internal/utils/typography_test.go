@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestSmarten(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"dashes and ellipses", "Wait --- what...", "Wait — what…"},
+		{"double quotes", `She said "hello" twice`, "She said “hello” twice"},
+		{"single quotes", "It's 'quoted'", "It’s ‘quoted’"},
+		{"inline code", "Use `\"raw\"...` here", "Use `\"raw\"...` here"},
+		{"link destination", "[a \"b\"](http://x.y/a...b)", "[a “b”](http://x.y/a...b)"},
+		{"horizontal rule", "---", "---"},
+		{"code block", "```\n\"x\" --- y\n```", "```\n\"x\" --- y\n```"},
+	}
+
+	for _, tt := range tests {
+		if got := Smarten(tt.input); got != tt.want {
+			t.Errorf("%s: Smarten(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
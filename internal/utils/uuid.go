@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+)
+
+// uuidNamespace is the namespace NameUUID derives UUIDs in
+var uuidNamespace = [16]byte{
+	0x6b, 0x1f, 0x2c, 0x84, 0x0e, 0x5a, 0x4d, 0x3b,
+	0x9a, 0x51, 0x7c, 0x2e, 0x44, 0xd0, 0x19, 0xf6,
+}
+
+// NewUUID returns a random (version 4) UUID
+func NewUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand doesn't fail on supported platforms
+		panic(fmt.Sprintf("failed to generate UUID: %v", err))
+	}
+	return formatUUID(b, 4)
+}
+
+// NameUUID returns a name-based (version 5) UUID, the same for the same name
+func NameUUID(name string) string {
+	hash := sha1.New()
+	hash.Write(uuidNamespace[:])
+	hash.Write([]byte(name))
+
+	var b [16]byte
+	copy(b[:], hash.Sum(nil))
+	return formatUUID(b, 5)
+}
+
+// formatUUID sets the version and variant bits of b and formats it
+func formatUUID(b [16]byte, version byte) string {
+	b[6] = b[6]&0x0f | version<<4
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IsUUID reports whether s is formatted as a UUID
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestUUID(t *testing.T) {
+	first, second := NewUUID(), NewUUID()
+	if first == second {
+		t.Errorf("Expected random UUIDs to differ, got %s twice", first)
+	}
+	for _, id := range []string{first, NameUUID("note")} {
+		if !IsUUID(id) {
+			t.Errorf("Expected %q to be a UUID", id)
+		}
+	}
+	if first[14] != '4' || NameUUID("note")[14] != '5' {
+		t.Errorf("Unexpected versions in %s and %s", first, NameUUID("note"))
+	}
+	if NameUUID("note") != NameUUID("note") || NameUUID("note") == NameUUID("other") {
+		t.Error("Expected name-based UUIDs to depend only on the name")
+	}
+
+	for _, s := range []string{"", "12", "6b1f2c84-0e5a-4d3b-9a51-7c2e44d019f", "6b1f2c84x0e5a-4d3b-9a51-7c2e44d019f6", "6b1f2c84-0e5a-4d3b-9a51-7c2e44d019g6"} {
+		if IsUUID(s) {
+			t.Errorf("Expected %q not to be a UUID", s)
+		}
+	}
+}